@@ -1,37 +1,85 @@
-package main
-
-import (
-	"log"
-
-	"prime-erp-core/config"
-	"prime-erp-core/internal/cronjob"
-	"prime-erp-core/internal/middleware"
-	"prime-erp-core/internal/routes"
-
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-)
-
-func main() {
-
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file ")
-	}
-	cronjob.AutoStartCronJobs()
-
-	// Initialize endpoint constants after loading .env
-	config.Initialize()
-
-	ginEngine := gin.Default()
-
-	middleware.RegisterMiddlewares(ginEngine)
-
-	routes.RegisterRoutes(ginEngine)
-
-	port := "9115"
-	log.Printf("Starting server on port %s\n", port)
-	if err := ginEngine.Run(":" + port); err != nil {
-		log.Fatalf("Could not start server: %s\n", err)
-	}
-}
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"prime-erp-core/config"
+	"prime-erp-core/internal/cronjob"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/routes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+func main() {
+
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file ")
+	}
+
+	// Shared connection pools for every service; closed on shutdown
+	registry := db.NewRegistry()
+	db.SetDefaultRegistry(registry)
+	registry.StartHealthCheck(db.HealthCheckInterval())
+
+	cronjob.AutoStartCronJobs()
+
+	// Initialize endpoint constants after loading .env
+	config.Initialize()
+
+	ginEngine := gin.Default()
+
+	ginEngine.Use(db.RegistryMiddleware(registry))
+	middleware.RegisterMiddlewares(ginEngine)
+
+	routes.RegisterRoutes(ginEngine)
+
+	port := "9115"
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: ginEngine,
+	}
+
+	go func() {
+		log.Printf("Starting server on port %s\n", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Could not start server: %s\n", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server forced to shutdown: %s\n", err)
+	}
+
+	select {
+	case <-cronjob.StopCronJobs().Done():
+	case <-shutdownCtx.Done():
+		log.Println("Timed out waiting for cron jobs to finish")
+	}
+
+	if err := registry.Close(); err != nil {
+		log.Printf("Failed to close database pools: %s\n", err)
+	}
+
+	log.Println("Server exited")
+}
@@ -1,36 +1,31 @@
-package db
-
-import (
-	"fmt"
-	"os"
-
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-)
-
-func ConnectGORM(databaseName string) (*gorm.DB, error) {
-	dabaseUrl := os.Getenv(fmt.Sprintf("database_gorm_url_%s", databaseName))
-	if dabaseUrl == `` {
-		return nil, fmt.Errorf("not found database_gorm_url")
-	}
-
-	db, err := gorm.Open(postgres.Open(dabaseUrl), &gorm.Config{})
-	if err != nil {
-		return nil, fmt.Errorf("not connect gorm")
-	}
-
-	return db, nil
-}
-
-func CloseGORM(gormDB *gorm.DB) error {
-	sqlDB, err := gormDB.DB()
-	if err != nil {
-		return fmt.Errorf("failed to get sqlDB from GORM: %v", err)
-	}
-
-	if err := sqlDB.Close(); err != nil {
-		fmt.Printf("Failed to close sqlDB: %v", err)
-	}
-
-	return nil
-}
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ConnectGORM returns the shared gorm pool of databaseName from the default registry.
+// The handle is pooled; do not close it.
+func ConnectGORM(databaseName string) (*gorm.DB, error) {
+	return DefaultRegistry().GORM(databaseName)
+}
+
+// CloseGORM closes a handle opened outside the registry (seed scripts); pooled handles are left open.
+func CloseGORM(gormDB *gorm.DB) error {
+	if DefaultRegistry().owns(gormDB) {
+		return nil
+	}
+
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get sqlDB from GORM: %v", err)
+	}
+
+	if err := sqlDB.Close(); err != nil {
+		fmt.Printf("Failed to close sqlDB: %v", err)
+	}
+
+	return nil
+}
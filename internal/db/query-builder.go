@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryBuilder assembles a postgres statement whose values are always sent as bind parameters.
+// Fragments are plain SQL; every "?" in a fragment becomes the next $n placeholder.
+type QueryBuilder struct {
+	sb   strings.Builder
+	args []interface{}
+}
+
+func NewQueryBuilder(query string, args ...interface{}) *QueryBuilder {
+	qb := &QueryBuilder{}
+	return qb.Append(query, args...)
+}
+
+// Append adds a fragment, binding one arg per "?" in order.
+func (qb *QueryBuilder) Append(fragment string, args ...interface{}) *QueryBuilder {
+	argIndex := 0
+	for _, r := range fragment {
+		if r == '?' && argIndex < len(args) {
+			qb.sb.WriteString(qb.Arg(args[argIndex]))
+			argIndex++
+			continue
+		}
+		qb.sb.WriteRune(r)
+	}
+
+	return qb
+}
+
+// Arg binds one value and returns its placeholder.
+func (qb *QueryBuilder) Arg(value interface{}) string {
+	qb.args = append(qb.args, value)
+	return "$" + strconv.Itoa(len(qb.args))
+}
+
+// In binds every value and returns "($1,$2,...)". An empty list yields "(NULL)", which matches nothing.
+func (qb *QueryBuilder) In(values ...interface{}) string {
+	if len(values) == 0 {
+		return "(NULL)"
+	}
+
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = qb.Arg(value)
+	}
+
+	return "(" + strings.Join(placeholders, ",") + ")"
+}
+
+// InStrings is In for a string slice.
+func (qb *QueryBuilder) InStrings(values []string) string {
+	return qb.In(toInterfaces(values)...)
+}
+
+// InTuples binds a row-value list for "(a, b) in (...)" filters, e.g. "(($1,$2),($3,$4))".
+func (qb *QueryBuilder) InTuples(tuples [][]interface{}) string {
+	if len(tuples) == 0 {
+		return "(NULL)"
+	}
+
+	rows := make([]string, len(tuples))
+	for i, tuple := range tuples {
+		rows[i] = qb.In(tuple...)
+	}
+
+	return "(" + strings.Join(rows, ",") + ")"
+}
+
+func (qb *QueryBuilder) Build() (string, []interface{}) {
+	return qb.sb.String(), qb.args
+}
+
+func (qb *QueryBuilder) String() string {
+	return qb.sb.String()
+}
+
+func (qb *QueryBuilder) Args() []interface{} {
+	return qb.args
+}
+
+// ContainsPattern returns an ILIKE/LIKE pattern matching value anywhere, with wildcards in value escaped.
+func ContainsPattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(value) + "%"
+}
+
+// Select runs a bound query and scans every row into T using its `db` tags.
+func Select[T any](db *sqlx.DB, query string, args ...interface{}) ([]T, error) {
+	results := []T{}
+	if err := db.SelectContext(context.Background(), &results, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to execute query: %v", err)
+	}
+
+	return results, nil
+}
+
+// SelectBuilder is Select for a QueryBuilder.
+func SelectBuilder[T any](db *sqlx.DB, qb *QueryBuilder) ([]T, error) {
+	query, args := qb.Build()
+	return Select[T](db, query, args...)
+}
+
+func toInterfaces[T any](values []T) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+
+	return result
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_BindsPlaceholdersInOrder(t *testing.T) {
+	qb := NewQueryBuilder(`select * from sale where company_code = ?`, "C1")
+	qb.Append(` and customer_code in ` + qb.InStrings([]string{"A", "B"}))
+	qb.Append(` and site_code = ? and status = ?`, "S1", "PENDING")
+
+	query, args := qb.Build()
+	assert.Equal(t, `select * from sale where company_code = $1 and customer_code in ($2,$3) and site_code = $4 and status = $5`, query)
+	assert.Equal(t, []interface{}{"C1", "A", "B", "S1", "PENDING"}, args)
+}
+
+func TestQueryBuilder_HostileValuesStayOutOfSQL(t *testing.T) {
+	hostile := []string{`x') or 1=1 --`, `O'Brien`, `a'; drop table sale; --`}
+
+	qb := NewQueryBuilder(`select * from credit where customer_code in `)
+	qb.Append(qb.InStrings(hostile))
+
+	query, args := qb.Build()
+	assert.Equal(t, `select * from credit where customer_code in ($1,$2,$3)`, query)
+	assert.NotContains(t, query, "'")
+	assert.Equal(t, []interface{}{hostile[0], hostile[1], hostile[2]}, args)
+}
+
+func TestQueryBuilder_EmptyAndTupleLists(t *testing.T) {
+	qb := NewQueryBuilder(`select 1 where code in `)
+	qb.Append(qb.InStrings(nil))
+	qb.Append(` and (doc, item) in ` + qb.InTuples([][]interface{}{{"INV1", "001"}, {"INV2", "002"}}))
+
+	query, args := qb.Build()
+	assert.Equal(t, `select 1 where code in (NULL) and (doc, item) in (($1,$2),($3,$4))`, query)
+	assert.Equal(t, []interface{}{"INV1", "001", "INV2", "002"}, args)
+}
+
+func TestContainsPattern(t *testing.T) {
+	assert.Equal(t, `%SO-1%`, ContainsPattern("SO-1"))
+	assert.Equal(t, `%100\%\_x\\%`, ContainsPattern(`100%_x\`))
+}
@@ -0,0 +1,432 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const ContextRegistry = "db_registry"
+
+// PoolConfig sizes the connection pool of one database.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// LoadPoolConfig reads database_<setting>_<name> from env, falling back to database_<setting> and then to defaults.
+// Settings: max_open_conns, max_idle_conns, conn_max_lifetime_seconds, conn_max_idle_seconds.
+func LoadPoolConfig(databaseName string) PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    poolEnvInt(databaseName, "max_open_conns", 20),
+		MaxIdleConns:    poolEnvInt(databaseName, "max_idle_conns", 5),
+		ConnMaxLifetime: time.Duration(poolEnvInt(databaseName, "conn_max_lifetime_seconds", 1800)) * time.Second,
+		ConnMaxIdleTime: time.Duration(poolEnvInt(databaseName, "conn_max_idle_seconds", 300)) * time.Second,
+	}
+}
+
+func poolEnvInt(databaseName string, setting string, defaultValue int) int {
+	keys := []string{"database_" + setting}
+	if databaseName != "" {
+		keys = append([]string{fmt.Sprintf("database_%s_%s", setting, databaseName)}, keys...)
+	}
+
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+				return parsed
+			}
+		}
+	}
+
+	return defaultValue
+}
+
+// HealthCheckInterval reads database_health_check_seconds from env, defaulting to 30 seconds; 0 disables it.
+func HealthCheckInterval() time.Duration {
+	return time.Duration(poolEnvInt("", "health_check_seconds", 30)) * time.Second
+}
+
+func (c PoolConfig) apply(sqlDB *sql.DB) {
+	sqlDB.SetMaxOpenConns(c.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+}
+
+// PoolStats is the sql.DBStats snapshot of one pool.
+type PoolStats struct {
+	Database          string `json:"database"`
+	Driver            string `json:"driver"`
+	MaxOpenConns      int    `json:"max_open_conns"`
+	OpenConnections   int    `json:"open_connections"`
+	InUse             int    `json:"in_use"`
+	Idle              int    `json:"idle"`
+	WaitCount         int64  `json:"wait_count"`
+	WaitDurationMs    int64  `json:"wait_duration_ms"`
+	MaxIdleClosed     int64  `json:"max_idle_closed"`
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed"`
+	Healthy           bool   `json:"healthy"`
+	LastError         string `json:"last_error,omitempty"`
+}
+
+type pool struct {
+	sqlx    *sqlx.DB
+	gorm    *gorm.DB
+	gormSQL *sql.DB
+	health  map[string]error
+}
+
+// Registry hands out one shared, pooled handle per database name and driver.
+// Handles must not be closed by callers; Close releases everything at shutdown.
+type Registry struct {
+	mu     sync.Mutex
+	pools  map[string]*pool
+	closed bool
+
+	// ConfigFor overrides LoadPoolConfig, mainly for tests.
+	ConfigFor func(databaseName string) PoolConfig
+
+	stopHealth chan struct{}
+	healthWG   sync.WaitGroup
+}
+
+func NewRegistry() *Registry {
+	return &Registry{pools: map[string]*pool{}}
+}
+
+func (r *Registry) config(databaseName string) PoolConfig {
+	if r.ConfigFor != nil {
+		return r.ConfigFor(databaseName)
+	}
+
+	return LoadPoolConfig(databaseName)
+}
+
+func (r *Registry) entry(databaseName string) (*pool, error) {
+	if r.closed {
+		return nil, fmt.Errorf("database registry is closed")
+	}
+
+	p, exists := r.pools[databaseName]
+	if !exists {
+		p = &pool{health: map[string]error{}}
+		r.pools[databaseName] = p
+	}
+
+	return p, nil
+}
+
+// Sqlx returns the shared sqlx pool of databaseName, opening it on first use.
+func (r *Registry) Sqlx(databaseName string) (*sqlx.DB, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.entry(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	if p.sqlx != nil {
+		return p.sqlx, nil
+	}
+
+	sqlxInstance, err := openSqlx(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	r.config(databaseName).apply(sqlxInstance.DB)
+	p.sqlx = sqlxInstance
+
+	return p.sqlx, nil
+}
+
+// GORM returns the shared gorm pool of databaseName, opening it on first use.
+func (r *Registry) GORM(databaseName string) (*gorm.DB, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.entry(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	if p.gorm != nil {
+		return p.gorm, nil
+	}
+
+	gormDB, err := openGORM(databaseName)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sqlDB from GORM: %v", err)
+	}
+	r.config(databaseName).apply(sqlDB)
+	p.gorm = gormDB
+	p.gormSQL = sqlDB
+
+	return p.gorm, nil
+}
+
+// owns reports whether gormDB is one of the registry's shared handles.
+func (r *Registry) owns(gormDB *gorm.DB) bool {
+	if gormDB == nil {
+		return false
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.pools {
+		if p.gormSQL == sqlDB {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ping checks every open pool and records the result for Stats. Keys are "<database>|<driver>".
+func (r *Registry) Ping(ctx context.Context) map[string]error {
+	type target struct {
+		pool   *pool
+		driver string
+		sqlDB  *sql.DB
+	}
+
+	r.mu.Lock()
+	targets := map[string]target{}
+	for name, p := range r.pools {
+		if p.sqlx != nil {
+			targets[name+"|sqlx"] = target{pool: p, driver: "sqlx", sqlDB: p.sqlx.DB}
+		}
+		if p.gormSQL != nil {
+			targets[name+"|gorm"] = target{pool: p, driver: "gorm", sqlDB: p.gormSQL}
+		}
+	}
+	r.mu.Unlock()
+
+	results := map[string]error{}
+	for key, t := range targets {
+		results[key] = t.sqlDB.PingContext(ctx)
+	}
+
+	r.mu.Lock()
+	for key, t := range targets {
+		t.pool.health[t.driver] = results[key]
+	}
+	r.mu.Unlock()
+
+	return results
+}
+
+// StartHealthCheck pings every pool on interval until Close, logging failures.
+func (r *Registry) StartHealthCheck(interval time.Duration) {
+	r.mu.Lock()
+	if r.stopHealth != nil || interval <= 0 {
+		r.mu.Unlock()
+		return
+	}
+	r.stopHealth = make(chan struct{})
+	stop := r.stopHealth
+	r.mu.Unlock()
+
+	r.healthWG.Add(1)
+	go func() {
+		defer r.healthWG.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				for key, err := range r.Ping(ctx) {
+					if err != nil {
+						log.Printf("database health check failed for %s: %v", key, err)
+					}
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stats returns a snapshot of every open pool.
+func (r *Registry) Stats() []PoolStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := []PoolStats{}
+	for name, p := range r.pools {
+		if p.sqlx != nil {
+			stats = append(stats, newPoolStats(name, "sqlx", p.sqlx.Stats(), p.health["sqlx"]))
+		}
+		if p.gormSQL != nil {
+			stats = append(stats, newPoolStats(name, "gorm", p.gormSQL.Stats(), p.health["gorm"]))
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Database != stats[j].Database {
+			return stats[i].Database < stats[j].Database
+		}
+		return stats[i].Driver < stats[j].Driver
+	})
+
+	return stats
+}
+
+func newPoolStats(databaseName string, driver string, s sql.DBStats, healthErr error) PoolStats {
+	stats := PoolStats{
+		Database:          databaseName,
+		Driver:            driver,
+		MaxOpenConns:      s.MaxOpenConnections,
+		OpenConnections:   s.OpenConnections,
+		InUse:             s.InUse,
+		Idle:              s.Idle,
+		WaitCount:         s.WaitCount,
+		WaitDurationMs:    s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     s.MaxIdleClosed,
+		MaxLifetimeClosed: s.MaxLifetimeClosed,
+		Healthy:           healthErr == nil,
+	}
+	if healthErr != nil {
+		stats.LastError = healthErr.Error()
+	}
+
+	return stats
+}
+
+// Close stops the health check and closes every pool. Later lookups fail.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	stop := r.stopHealth
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		r.healthWG.Wait()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for name, p := range r.pools {
+		if p.sqlx != nil {
+			if err := p.sqlx.Close(); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("failed to close sqlx pool %s: %v", name, err)
+			}
+		}
+		if p.gormSQL != nil {
+			if err := p.gormSQL.Close(); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("failed to close gorm pool %s: %v", name, err)
+			}
+		}
+	}
+	r.pools = map[string]*pool{}
+
+	return firstErr
+}
+
+var (
+	defaultRegistryMu sync.RWMutex
+	defaultRegistry   = NewRegistry()
+)
+
+// SetDefaultRegistry replaces the registry used by ConnectSqlx, ConnectGORM and FromContext fallbacks.
+func SetDefaultRegistry(registry *Registry) {
+	defaultRegistryMu.Lock()
+	defer defaultRegistryMu.Unlock()
+
+	defaultRegistry = registry
+}
+
+func DefaultRegistry() *Registry {
+	defaultRegistryMu.RLock()
+	defer defaultRegistryMu.RUnlock()
+
+	return defaultRegistry
+}
+
+// RegistryMiddleware injects registry into every request so services resolve handles with FromContext.
+func RegistryMiddleware(registry *Registry) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ContextRegistry, registry)
+		ctx.Next()
+	}
+}
+
+// FromContext returns the registry injected into ctx, or the default registry.
+func FromContext(ctx *gin.Context) *Registry {
+	if ctx != nil {
+		if value, exists := ctx.Get(ContextRegistry); exists {
+			if registry, ok := value.(*Registry); ok && registry != nil {
+				return registry
+			}
+		}
+	}
+
+	return DefaultRegistry()
+}
+
+// HealthHandler pings every pool of the request's registry and answers 503 when any of them fails.
+func HealthHandler(ctx *gin.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	status := http.StatusOK
+	checks := map[string]string{}
+	for key, err := range FromContext(ctx).Ping(pingCtx) {
+		checks[key] = "ok"
+		if err != nil {
+			checks[key] = err.Error()
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	ctx.JSON(status, gin.H{"databases": checks})
+}
+
+// PoolStatsHandler returns the pool stats of the request's registry.
+func PoolStatsHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, FromContext(ctx).Stats())
+}
+
+func openGORM(databaseName string) (*gorm.DB, error) {
+	dabaseUrl := os.Getenv(fmt.Sprintf("database_gorm_url_%s", databaseName))
+	if dabaseUrl == `` {
+		return nil, fmt.Errorf("not found database_gorm_url")
+	}
+
+	db, err := gorm.Open(postgres.Open(dabaseUrl), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("not connect gorm")
+	}
+
+	return db, nil
+}
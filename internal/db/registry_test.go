@@ -0,0 +1,63 @@
+package db
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPoolConfig_EnvPrecedence(t *testing.T) {
+	t.Setenv("database_max_open_conns", "40")
+	t.Setenv("database_max_open_conns_prime_erp", "60")
+	t.Setenv("database_max_idle_conns", "not-a-number")
+	t.Setenv("database_conn_max_lifetime_seconds", "120")
+
+	config := LoadPoolConfig("prime_erp")
+	assert.Equal(t, 60, config.MaxOpenConns)
+	assert.Equal(t, 5, config.MaxIdleConns)
+	assert.Equal(t, 2*time.Minute, config.ConnMaxLifetime)
+
+	assert.Equal(t, 40, LoadPoolConfig("other").MaxOpenConns)
+}
+
+func TestRegistry_ClosedRejectsLookups(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Close())
+	assert.NoError(t, registry.Close(), "closing twice is a no-op")
+
+	_, err := registry.Sqlx("prime_erp")
+	assert.Error(t, err)
+	_, err = registry.GORM("prime_erp")
+	assert.Error(t, err)
+	assert.Empty(t, registry.Stats())
+}
+
+func TestRegistry_MissingURLIsNotCached(t *testing.T) {
+	registry := NewRegistry()
+	defer registry.Close()
+
+	_, err := registry.Sqlx("registry_test_missing")
+	assert.Error(t, err)
+	assert.Empty(t, registry.Stats())
+}
+
+func TestFromContext_PrefersInjectedRegistry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	injected := NewRegistry()
+
+	var seen *Registry
+	engine := gin.New()
+	engine.Use(RegistryMiddleware(injected))
+	engine.GET("/", func(c *gin.Context) {
+		seen = FromContext(c)
+		c.Status(http.StatusOK)
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Same(t, injected, seen)
+	assert.Same(t, DefaultRegistry(), FromContext(nil))
+}
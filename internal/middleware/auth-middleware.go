@@ -0,0 +1,301 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ContextUserIdentity = "user_identity"
+	ContextUserCode     = "user_code"
+	ContextTenantCode   = "tenant_code"
+	ContextCompanyCode  = "company_code"
+	ContextSiteCode     = "site_code"
+
+	// SystemUser is the actor recorded when no authenticated user is present (cron jobs, internal calls).
+	SystemUser = "system"
+)
+
+// UserIdentity is the authenticated actor resolved from the bearer token.
+type UserIdentity struct {
+	UserCode    string   `json:"user_code"`
+	TenantCode  string   `json:"tenant_code"`
+	CompanyCode string   `json:"company_code"`
+	SiteCode    string   `json:"site_code"`
+	RoleCodes   []string `json:"role_codes"`  // roles the user holds, for role requesters
+	GroupCodes  []string `json:"group_codes"` // groups the user belongs to, for group requesters
+	IsService   bool     `json:"-"`           // authenticated with the service token rather than a user token
+}
+
+// TokenVerifier validates a raw bearer token and returns the identity it carries.
+type TokenVerifier interface {
+	Verify(token string) (*UserIdentity, error)
+}
+
+var (
+	verifierMu sync.RWMutex
+	verifier   TokenVerifier
+)
+
+// SetTokenVerifier replaces the verifier used by AuthMiddleware (e.g. a remote introspection verifier).
+func SetTokenVerifier(v TokenVerifier) {
+	verifierMu.Lock()
+	defer verifierMu.Unlock()
+
+	verifier = v
+}
+
+func getTokenVerifier() TokenVerifier {
+	verifierMu.RLock()
+	v := verifier
+	verifierMu.RUnlock()
+	if v != nil {
+		return v
+	}
+
+	verifierMu.Lock()
+	defer verifierMu.Unlock()
+	if verifier == nil {
+		verifier = NewDefaultTokenVerifier()
+	}
+
+	return verifier
+}
+
+// NewDefaultTokenVerifier builds a verifier from env: jwt_signing_key (HS256) and auth_service_token.
+func NewDefaultTokenVerifier() TokenVerifier {
+	chain := ChainVerifier{}
+
+	if serviceToken := os.Getenv("auth_service_token"); serviceToken != "" {
+		chain = append(chain, StaticTokenVerifier{
+			Token:    serviceToken,
+			Identity: UserIdentity{UserCode: SystemUser, IsService: true},
+		})
+	}
+
+	if signingKey := os.Getenv("jwt_signing_key"); signingKey != "" {
+		chain = append(chain, JWTVerifier{SigningKey: []byte(signingKey)})
+	}
+
+	return chain
+}
+
+// publicPaths are served without a token: the probes load balancers and orchestrators call.
+var publicPaths = map[string]bool{
+	"/system/Health":      true,
+	"/system/DBPoolStats": true,
+}
+
+func AuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if publicPaths[ctx.FullPath()] {
+			ctx.Next()
+			return
+		}
+
+		token, err := bearerToken(ctx.GetHeader("Authorization"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		identity, err := getTokenVerifier().Verify(token)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		SetUserIdentity(ctx, *identity)
+
+		ctx.Next()
+	}
+}
+
+// SetUserIdentity stores the identity on the gin context under the documented keys.
+func SetUserIdentity(ctx *gin.Context, identity UserIdentity) {
+	ctx.Set(ContextUserIdentity, identity)
+	ctx.Set(ContextUserCode, identity.UserCode)
+	ctx.Set(ContextTenantCode, identity.TenantCode)
+	ctx.Set(ContextCompanyCode, identity.CompanyCode)
+	ctx.Set(ContextSiteCode, identity.SiteCode)
+}
+
+// GetUserIdentity returns the authenticated identity, or the system identity when the call
+// did not come through AuthMiddleware (ctx is nil for cron and internal service calls).
+func GetUserIdentity(ctx *gin.Context) UserIdentity {
+	if ctx != nil {
+		if value, exists := ctx.Get(ContextUserIdentity); exists {
+			if identity, ok := value.(UserIdentity); ok && identity.UserCode != "" {
+				return identity
+			}
+		}
+	}
+
+	return UserIdentity{UserCode: SystemUser}
+}
+
+// GetUserCode returns the user code to stamp on CreateBy/UpdateBy.
+func GetUserCode(ctx *gin.Context) string {
+	return GetUserIdentity(ctx).UserCode
+}
+
+// SetServiceAuthorization adds the service token to calls this service makes to its own API.
+func SetServiceAuthorization(req *http.Request) {
+	if serviceToken := os.Getenv("auth_service_token"); serviceToken != "" {
+		req.Header.Set("Authorization", "Bearer "+serviceToken)
+	}
+}
+
+func bearerToken(header string) (string, error) {
+	if header == "" {
+		return "", errors.New("missing authorization header")
+	}
+
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", errors.New("authorization header must be a bearer token")
+	}
+
+	return strings.TrimSpace(token), nil
+}
+
+// ChainVerifier tries each verifier in order and returns the first identity that verifies.
+type ChainVerifier []TokenVerifier
+
+func (chain ChainVerifier) Verify(token string) (*UserIdentity, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("no token verifier configured")
+	}
+
+	var lastErr error
+	for _, v := range chain {
+		identity, err := v.Verify(token)
+		if err == nil {
+			return identity, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// StaticTokenVerifier accepts one shared secret token, used for service-to-service calls.
+type StaticTokenVerifier struct {
+	Token    string
+	Identity UserIdentity
+}
+
+func (v StaticTokenVerifier) Verify(token string) (*UserIdentity, error) {
+	if v.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(v.Token)) != 1 {
+		return nil, errors.New("invalid token")
+	}
+
+	identity := v.Identity
+	return &identity, nil
+}
+
+// JWTVerifier validates HS256 signed JWTs and maps their claims to a UserIdentity.
+type JWTVerifier struct {
+	SigningKey []byte
+	Now        func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject     string   `json:"sub"`
+	UserCode    string   `json:"user_code"`
+	TenantCode  string   `json:"tenant_code"`
+	CompanyCode string   `json:"company_code"`
+	SiteCode    string   `json:"site_code"`
+	RoleCodes   []string `json:"role_codes"`
+	GroupCodes  []string `json:"group_codes"`
+	ExpiresAt   *int64   `json:"exp"`
+	NotBefore   *int64   `json:"nbf"`
+}
+
+func (v JWTVerifier) Verify(token string) (*UserIdentity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid token format")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("invalid token header")
+	}
+
+	header := jwtHeader{}
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, errors.New("invalid token header")
+	}
+	if header.Alg != "HS256" {
+		return nil, errors.New("unsupported token algorithm: " + header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("invalid token signature")
+	}
+
+	mac := hmac.New(sha256.New, v.SigningKey)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	claimBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("invalid token claims")
+	}
+
+	claims := jwtClaims{}
+	if err := json.Unmarshal(claimBytes, &claims); err != nil {
+		return nil, errors.New("invalid token claims")
+	}
+
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if claims.ExpiresAt != nil && now.Unix() >= *claims.ExpiresAt {
+		return nil, errors.New("token expired")
+	}
+	if claims.NotBefore != nil && now.Unix() < *claims.NotBefore {
+		return nil, errors.New("token not yet valid")
+	}
+
+	userCode := claims.UserCode
+	if userCode == "" {
+		userCode = claims.Subject
+	}
+	if userCode == "" {
+		return nil, errors.New("token has no user_code")
+	}
+	if strings.EqualFold(userCode, SystemUser) {
+		return nil, errors.New("token user_code is reserved")
+	}
+
+	return &UserIdentity{
+		UserCode:    userCode,
+		TenantCode:  claims.TenantCode,
+		CompanyCode: claims.CompanyCode,
+		SiteCode:    claims.SiteCode,
+		RoleCodes:   claims.RoleCodes,
+		GroupCodes:  claims.GroupCodes,
+	}, nil
+}
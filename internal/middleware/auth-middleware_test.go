@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func signTestToken(t *testing.T, key string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newAuthTestEngine(v TokenVerifier) (*gin.Engine, *UserIdentity) {
+	gin.SetMode(gin.TestMode)
+	SetTokenVerifier(v)
+
+	seen := &UserIdentity{}
+	engine := gin.New()
+	engine.Use(AuthMiddleware())
+	engine.POST("/who", func(c *gin.Context) {
+		*seen = GetUserIdentity(c)
+		c.Status(http.StatusOK)
+	})
+
+	return engine, seen
+}
+
+func TestJWTVerifier_ValidToken(t *testing.T) {
+	token := signTestToken(t, "secret", map[string]interface{}{
+		"user_code":    "U001",
+		"tenant_code":  "T1",
+		"company_code": "C1",
+		"site_code":    "S1",
+		"role_codes":   []string{"PRICING"},
+		"group_codes":  []string{"BKK"},
+		"exp":          time.Now().Add(time.Hour).Unix(),
+	})
+
+	identity, err := JWTVerifier{SigningKey: []byte("secret")}.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, UserIdentity{UserCode: "U001", TenantCode: "T1", CompanyCode: "C1", SiteCode: "S1", RoleCodes: []string{"PRICING"}, GroupCodes: []string{"BKK"}}, *identity)
+}
+
+func TestJWTVerifier_Rejects(t *testing.T) {
+	v := JWTVerifier{SigningKey: []byte("secret")}
+
+	wrongKey := signTestToken(t, "other", map[string]interface{}{"user_code": "U001"})
+	_, err := v.Verify(wrongKey)
+	assert.Error(t, err)
+
+	expired := signTestToken(t, "secret", map[string]interface{}{"user_code": "U001", "exp": time.Now().Add(-time.Minute).Unix()})
+	_, err = v.Verify(expired)
+	assert.Error(t, err)
+
+	noUser := signTestToken(t, "secret", map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()})
+	_, err = v.Verify(noUser)
+	assert.Error(t, err)
+
+	systemUser := signTestToken(t, "secret", map[string]interface{}{"user_code": "SYSTEM", "exp": time.Now().Add(time.Hour).Unix()})
+	_, err = v.Verify(systemUser)
+	assert.Error(t, err, "the system identity is never a token's user")
+
+	_, err = v.Verify("not-a-jwt")
+	assert.Error(t, err)
+}
+
+func TestAuthMiddleware_SetsIdentity(t *testing.T) {
+	engine, seen := newAuthTestEngine(JWTVerifier{SigningKey: []byte("secret")})
+	defer SetTokenVerifier(nil)
+
+	token := signTestToken(t, "secret", map[string]interface{}{"sub": "U002", "company_code": "C1"})
+	req := httptest.NewRequest(http.MethodPost, "/who", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "U002", seen.UserCode)
+	assert.Equal(t, "C1", seen.CompanyCode)
+}
+
+func TestAuthMiddleware_MissingOrInvalidToken(t *testing.T) {
+	engine, _ := newAuthTestEngine(JWTVerifier{SigningKey: []byte("secret")})
+	defer SetTokenVerifier(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/who", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/who", nil)
+	req.Header.Set("Authorization", "Bearer bogus")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_ServiceToken(t *testing.T) {
+	engine, seen := newAuthTestEngine(ChainVerifier{
+		StaticTokenVerifier{Token: "svc", Identity: UserIdentity{UserCode: SystemUser}},
+		JWTVerifier{SigningKey: []byte("secret")},
+	})
+	defer SetTokenVerifier(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/who", nil)
+	req.Header.Set("Authorization", "Bearer svc")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, SystemUser, seen.UserCode)
+}
+
+func TestGetUserCode_WithoutContext(t *testing.T) {
+	assert.Equal(t, SystemUser, GetUserCode(nil))
+}
+
+func TestAuthMiddleware_PublicPaths(t *testing.T) {
+	engine, _ := newAuthTestEngine(JWTVerifier{SigningKey: []byte("secret")})
+	defer SetTokenVerifier(nil)
+
+	engine.GET("/system/Health", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/system/Health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
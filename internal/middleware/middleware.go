@@ -1,7 +1,8 @@
-package middleware
-
-import "github.com/gin-gonic/gin"
-
-func RegisterMiddlewares(ctx *gin.Engine) {
-	ctx.Use(CORSMiddleware())
-}
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+func RegisterMiddlewares(ctx *gin.Engine) {
+	ctx.Use(CORSMiddleware())
+	ctx.Use(AuthMiddleware())
+}
@@ -0,0 +1,223 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationService "prime-erp-core/internal/services/authentication-service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPermissionCacheTTL = 5 * time.Minute
+
+// PermissionChecker answers whether a user may perform an action code.
+type PermissionChecker interface {
+	HasPermission(identity UserIdentity, actionCode string) (bool, error)
+}
+
+// Requester types of the authorization service: an action is granted to a user, or to everyone holding a role or
+// belonging to a group.
+const (
+	RequesterTypeUser  = "USER"
+	RequesterTypeRole  = "ROLE"
+	RequesterTypeGroup = "GROUP"
+)
+
+// RequesterPermissionChecker resolves permissions through the authorization service's get-requester API.
+type RequesterPermissionChecker struct{}
+
+func (RequesterPermissionChecker) HasPermission(identity UserIdentity, actionCode string) (bool, error) {
+	requesters, err := authenticationService.GetRequester(requesterQuery(identity, actionCode))
+	if err != nil {
+		return false, err
+	}
+
+	return requestersGrant(requesters, identity), nil
+}
+
+// requesterQuery asks for the requesters of actionCode that are the user or one of the user's roles or groups.
+func requesterQuery(identity UserIdentity, actionCode string) map[string]interface{} {
+	requesterIDs := []string{identity.UserCode}
+	requesterIDs = append(requesterIDs, identity.RoleCodes...)
+	requesterIDs = append(requesterIDs, identity.GroupCodes...)
+
+	return map[string]interface{}{
+		"action_code":  []string{actionCode},
+		"requester_id": requesterIDs,
+	}
+}
+
+// requestersGrant says whether one of the requesters is the user, a role the user holds or a group the user belongs
+// to. A requester is matched by its type, so a role never grants a user of the same code. Requesters without a type
+// are users.
+func requestersGrant(requesters []authenticationService.Requester, identity UserIdentity) bool {
+	matches := func(requester authenticationService.Requester, codes ...string) bool {
+		for _, code := range codes {
+			if code != "" && (requester.RequesterCode == code || requester.RequesterID == code) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, requester := range requesters {
+		switch strings.ToUpper(requester.RequesterType) {
+		case RequesterTypeUser, "":
+			if matches(requester, identity.UserCode) {
+				return true
+			}
+		case RequesterTypeRole:
+			if matches(requester, identity.RoleCodes...) {
+				return true
+			}
+		case RequesterTypeGroup:
+			if matches(requester, identity.GroupCodes...) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type permissionEntry struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+// CachedPermissionChecker memoizes lookups per user and action code for a TTL. A user whose roles or groups changed
+// is looked up again.
+type CachedPermissionChecker struct {
+	Checker PermissionChecker
+	TTL     time.Duration
+	Now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]map[string]permissionEntry
+}
+
+func NewCachedPermissionChecker(checker PermissionChecker, ttl time.Duration) *CachedPermissionChecker {
+	return &CachedPermissionChecker{
+		Checker: checker,
+		TTL:     ttl,
+		entries: map[string]map[string]permissionEntry{},
+	}
+}
+
+func (c *CachedPermissionChecker) HasPermission(identity UserIdentity, actionCode string) (bool, error) {
+	now := time.Now()
+	if c.Now != nil {
+		now = c.Now()
+	}
+
+	userCode := identity.UserCode
+	entryKey := actionCode + "|" + strings.Join(identity.RoleCodes, ",") + "|" + strings.Join(identity.GroupCodes, ",")
+
+	c.mu.Lock()
+	if userEntries, exists := c.entries[userCode]; exists {
+		if entry, found := userEntries[entryKey]; found && now.Before(entry.expiresAt) {
+			c.mu.Unlock()
+			return entry.allowed, nil
+		}
+	}
+	c.mu.Unlock()
+
+	allowed, err := c.Checker.HasPermission(identity, actionCode)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]map[string]permissionEntry{}
+	}
+	if _, exists := c.entries[userCode]; !exists {
+		c.entries[userCode] = map[string]permissionEntry{}
+	}
+	c.entries[userCode][entryKey] = permissionEntry{allowed: allowed, expiresAt: now.Add(c.TTL)}
+
+	return allowed, nil
+}
+
+// Invalidate drops cached permissions of one user, or of everyone when userCode is empty.
+func (c *CachedPermissionChecker) Invalidate(userCode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if userCode == "" {
+		c.entries = map[string]map[string]permissionEntry{}
+		return
+	}
+	delete(c.entries, userCode)
+}
+
+var (
+	permissionMu      sync.RWMutex
+	permissionChecker PermissionChecker
+)
+
+// SetPermissionChecker replaces the checker used by RequirePermission.
+func SetPermissionChecker(checker PermissionChecker) {
+	permissionMu.Lock()
+	defer permissionMu.Unlock()
+
+	permissionChecker = checker
+}
+
+func getPermissionChecker() PermissionChecker {
+	permissionMu.RLock()
+	checker := permissionChecker
+	permissionMu.RUnlock()
+	if checker != nil {
+		return checker
+	}
+
+	permissionMu.Lock()
+	defer permissionMu.Unlock()
+	if permissionChecker == nil {
+		permissionChecker = NewCachedPermissionChecker(RequesterPermissionChecker{}, permissionCacheTTL())
+	}
+
+	return permissionChecker
+}
+
+// permissionCacheTTL reads permission_cache_ttl_seconds from env, defaulting to five minutes.
+func permissionCacheTTL() time.Duration {
+	if value := os.Getenv("permission_cache_ttl_seconds"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return defaultPermissionCacheTTL
+}
+
+// RequirePermission rejects the request with 403 unless the authenticated user holds actionCode.
+// The service identity (auth_service_token) is trusted and always passes.
+func RequirePermission(actionCode string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		identity := GetUserIdentity(ctx)
+		if identity.IsService {
+			ctx.Next()
+			return
+		}
+
+		allowed, err := getPermissionChecker().HasPermission(identity, actionCode)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve permission: " + err.Error()})
+			return
+		}
+		if !allowed {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied: " + actionCode})
+			return
+		}
+
+		ctx.Next()
+	}
+}
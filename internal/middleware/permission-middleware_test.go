@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authenticationService "prime-erp-core/internal/services/authentication-service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePermissionChecker struct {
+	allowed map[string]bool
+	calls   int
+}
+
+func (f *fakePermissionChecker) HasPermission(identity UserIdentity, actionCode string) (bool, error) {
+	f.calls++
+	return f.allowed[identity.UserCode+"|"+actionCode], nil
+}
+
+func TestCachedPermissionChecker_TTL(t *testing.T) {
+	fake := &fakePermissionChecker{allowed: map[string]bool{"U001|PRICE_EDIT": true}}
+	now := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	cached := NewCachedPermissionChecker(fake, time.Minute)
+	cached.Now = func() time.Time { return now }
+
+	allowed, err := cached.HasPermission(UserIdentity{UserCode: "U001"}, "PRICE_EDIT")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, _ = cached.HasPermission(UserIdentity{UserCode: "U001"}, "PRICE_EDIT")
+	assert.True(t, allowed)
+	assert.Equal(t, 1, fake.calls, "second lookup within TTL should hit the cache")
+
+	denied, _ := cached.HasPermission(UserIdentity{UserCode: "U001"}, "PRICE_DELETE")
+	assert.False(t, denied)
+	assert.Equal(t, 2, fake.calls)
+
+	now = now.Add(2 * time.Minute)
+	_, _ = cached.HasPermission(UserIdentity{UserCode: "U001"}, "PRICE_EDIT")
+	assert.Equal(t, 3, fake.calls, "expired entry should be looked up again")
+
+	cached.Invalidate("U001")
+	_, _ = cached.HasPermission(UserIdentity{UserCode: "U001"}, "PRICE_EDIT")
+	assert.Equal(t, 4, fake.calls)
+
+	_, _ = cached.HasPermission(UserIdentity{UserCode: "U001", RoleCodes: []string{"PRICING"}}, "PRICE_EDIT")
+	assert.Equal(t, 5, fake.calls, "a change of roles is looked up again")
+}
+
+func TestRequesterPermissionChecker_MatchesByRequesterType(t *testing.T) {
+	identity := UserIdentity{UserCode: "U001", RoleCodes: []string{"PRICING"}, GroupCodes: []string{"BKK"}}
+
+	query := requesterQuery(identity, "PRICE_EDIT")
+	assert.Equal(t, []string{"PRICE_EDIT"}, query["action_code"])
+	assert.Equal(t, []string{"U001", "PRICING", "BKK"}, query["requester_id"])
+
+	grant := func(requesterType string, code string) bool {
+		return requestersGrant([]authenticationService.Requester{{RequesterType: requesterType, RequesterCode: code}}, identity)
+	}
+	assert.True(t, grant(RequesterTypeUser, "U001"))
+	assert.True(t, grant("", "U001"), "requesters without a type are users")
+	assert.True(t, grant(RequesterTypeRole, "PRICING"))
+	assert.True(t, grant(RequesterTypeGroup, "BKK"))
+	assert.False(t, grant(RequesterTypeRole, "U001"), "a role never grants a user of the same code")
+	assert.False(t, grant(RequesterTypeGroup, "PRICING"))
+	assert.False(t, grant(RequesterTypeUser, "U002"))
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := &fakePermissionChecker{allowed: map[string]bool{"U001|PRICE_EDIT": true}}
+	SetPermissionChecker(fake)
+	defer SetPermissionChecker(nil)
+
+	serve := func(identity UserIdentity) int {
+		engine := gin.New()
+		engine.Use(func(c *gin.Context) { SetUserIdentity(c, identity) })
+		engine.POST("/price/UploadPriceList", RequirePermission("PRICE_EDIT"), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/price/UploadPriceList", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(UserIdentity{UserCode: "U001"}))
+	assert.Equal(t, http.StatusForbidden, serve(UserIdentity{UserCode: "U002"}))
+	assert.Equal(t, http.StatusForbidden, serve(UserIdentity{UserCode: SystemUser}))
+	assert.Equal(t, http.StatusOK, serve(UserIdentity{UserCode: SystemUser, IsService: true}))
+}
@@ -1,105 +1,171 @@
-package models
-
-import (
-	"time"
-
-	"github.com/google/uuid"
-	"gorm.io/datatypes"
-)
-
-type Approval struct {
-	ID            uuid.UUID      `json:"id"`
-	ApproveCode   string         `json:"approve_code"`
-	ApproveTopic  string         `json:"approve_topic"`
-	DocumentType  string         `json:"document_type"`
-	DocumentCode  string         `json:"document_code"`
-	DocumentData  datatypes.JSON `json:"document_data"`
-	ActionDate    time.Time      `json:"action_date"`
-	Status        string         `json:"status"`
-	Remark        string         `json:"remark"`
-	CurentStepSeq int            `json:"curent_step_seq"`
-	CreateBy      string         `json:"create_by"`
-	CreateDate    time.Time      `gorm:"autoCreateTime;<-:create" json:"create_date"`
-	UpdateBy      string         `json:"update_by"`
-	UpdateDate    time.Time      `gorm:"autoUpdateTime;<-" json:"update_date"`
-	MDItemCode    string         `gorm:"-" json:"md_item_code"`
-	ApprovalItem  []ApprovalItem `gorm:"foreignKey:ApprovalID;references:ID" json:"approval_item"`
-}
-
-func (Approval) TableName() string {
-	return "approval"
-}
-
-type ApprovalItem struct {
-	ID                     uuid.UUID                `json:"id"`
-	ApprovalID             uuid.UUID                `json:"approval_id"`
-	StepSeq                int                      `json:"step_seq"`
-	IsCondition            bool                     `json:"is_condition"`
-	Condition              datatypes.JSON           `json:"condition"`
-	Status                 string                   `json:"status"`
-	ActionBy               string                   `json:"action_by"`
-	ActionDate             time.Time                `json:"action_date"`
-	CreateBy               string                   `gorm:"type:varchar(100)" json:"create_by"`
-	CreateDate             time.Time                `gorm:"autoCreateTime;<-:create" json:"create_date"`
-	UpdateBy               string                   `gorm:"type:varchar(100)" json:"update_by"`
-	UpdateDate             time.Time                `gorm:"autoUpdateTime;<-" json:"update_date"`
-	ApprovalItemPermission []ApprovalItemPermission `gorm:"foreignKey:ApprovalItemID;references:ID" json:"approval_item_permission"`
-}
-
-func (ApprovalItem) TableName() string {
-	return "approval_item"
-}
-
-type ApprovalItemPermission struct {
-	ID             uuid.UUID `json:"id"`
-	ApprovalItemID uuid.UUID `json:"approval_item_id"`
-	UserCode       string    `json:"user_code"`
-}
-
-func (ApprovalItemPermission) TableName() string {
-	return "approval_item_permission"
-}
-
-/* type Approval struct {
-	ID             uuid.UUID  `json:"id"`
-	ApproveCode    string     `json:"approve_code"`
-	ApproveTopic   string     `json:"approve_topic"`
-	DocumentType   string     `json:"document_type"`
-	DocumentCode   string     `json:"document_code"`
-	DocumentData   string     `json:"document_data"`
-	ActionDate     *time.Time `json:"action_date"`
-	Status         string     `json:"status"`
-	Remark         string     `json:"remark"`
-	CurrentStepSeq int        `json:"curent_step_seq"`
-	CreateDate     *time.Time `json:"create_date"`
-	CreateBy       string     `json:"create_by"`
-	UpdateDate     *time.Time `json:"update_date"`
-	UpdateBy       string     `json:"update_by"`
-}
-
-func (Approval) TableName() string { return "approval" }
-
-type ApprovalItem struct {
-	ID          uuid.UUID  `json:"id"`
-	ApprovalID  uuid.UUID  `json:"approval_id"`
-	StepSeq     int        `json:"step_seq"`
-	IsCondition bool       `json:"is_condition"`
-	Condition   string     `json:"condition"`
-	Status      string     `json:"status"`
-	ActionBy    string     `json:"action_by"`
-	ActionDate  *time.Time `json:"action_date"`
-	CreateDate  *time.Time `json:"create_date"`
-	CreateBy    string     `json:"create_by"`
-	UpdateDate  *time.Time `json:"update_date"`
-	UpdateBy    string     `json:"update_by"`
-}
-
-func (ApprovalItem) TableName() string { return "approval_item" }
-
-type ApprovalItemPermission struct {
-	ID             uuid.UUID `json:"id"`
-	ApprovalItemID uuid.UUID `json:"approval_item_id"`
-	UserCode       string    `json:"user_code"`
-}
-
-func (ApprovalItemPermission) TableName() string { return "approval_item_permission" } */
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Approval workflow values. An approval stays PENDING until its last step completes or it is rejected outright.
+const (
+	ApprovalStatusPending   = "PENDING"
+	ApprovalStatusReview    = "REVIEW"
+	ApprovalStatusCompleted = "COMPLETED"
+	ApprovalStatusReject    = "REJECT"
+
+	ApprovalStepWaiting = "WAITING" // a later step that has not been reached yet
+
+	ApprovalEscalate   = "ESCALATE"    // add backup approvers to an overdue step
+	ApprovalAutoReject = "AUTO_REJECT" // reject an overdue approval and its document
+
+	ApproveModeAny = "ANY" // one approver completes the step
+	ApproveModeAll = "ALL" // every approver must approve the step
+)
+
+type Approval struct {
+	ID                 uuid.UUID            `json:"id"`
+	ApproveCode        string               `json:"approve_code"`
+	ApproveTopic       string               `json:"approve_topic"`
+	DocumentType       string               `json:"document_type"`
+	DocumentCode       string               `json:"document_code"`
+	DocumentData       datatypes.JSON       `json:"document_data"`
+	ActionDate         time.Time            `json:"action_date"`
+	Status             string               `json:"status"`
+	Remark             string               `json:"remark"`
+	CurentStepSeq      int                  `json:"curent_step_seq"`
+	CreateBy           string               `json:"create_by"`
+	CreateDate         time.Time            `gorm:"autoCreateTime;<-:create" json:"create_date"`
+	UpdateBy           string               `json:"update_by"`
+	UpdateDate         time.Time            `gorm:"autoUpdateTime;<-" json:"update_date"`
+	MDItemCode         string               `gorm:"-" json:"md_item_code"`
+	ApprovalItem       []ApprovalItem       `gorm:"foreignKey:ApprovalID;references:ID" json:"approval_item"`
+	ApprovalEscalation []ApprovalEscalation `gorm:"foreignKey:ApprovalID;references:ID" json:"approval_escalation"`
+}
+
+func (Approval) TableName() string {
+	return "approval"
+}
+
+type ApprovalItem struct {
+	ID                     uuid.UUID                `json:"id"`
+	ApprovalID             uuid.UUID                `json:"approval_id"`
+	StepSeq                int                      `json:"step_seq"`
+	IsCondition            bool                     `json:"is_condition"`
+	Condition              datatypes.JSON           `json:"condition"`
+	Status                 string                   `json:"status"`
+	ActionBy               string                   `json:"action_by"`
+	ActionDate             time.Time                `json:"action_date"`
+	CreateBy               string                   `gorm:"type:varchar(100)" json:"create_by"`
+	CreateDate             time.Time                `gorm:"autoCreateTime;<-:create" json:"create_date"`
+	UpdateBy               string                   `gorm:"type:varchar(100)" json:"update_by"`
+	UpdateDate             time.Time                `gorm:"autoUpdateTime;<-" json:"update_date"`
+	PendingDtm             *time.Time               `json:"pending_dtm"` // when the step last became the current step
+	ApprovalItemPermission []ApprovalItemPermission `gorm:"foreignKey:ApprovalItemID;references:ID" json:"approval_item_permission"`
+}
+
+func (ApprovalItem) TableName() string {
+	return "approval_item"
+}
+
+type ApprovalItemPermission struct {
+	ID             uuid.UUID  `json:"id"`
+	ApprovalItemID uuid.UUID  `json:"approval_item_id"`
+	UserCode       string     `json:"user_code"`
+	Status         string     `json:"status"` // this approver's own decision on the step
+	ActionDate     *time.Time `json:"action_date"`
+	Remark         string     `json:"remark"`
+}
+
+func (ApprovalItemPermission) TableName() string {
+	return "approval_item_permission"
+}
+
+// ApprovalEscalation records one SLA action the escalation job took on an overdue approval step.
+type ApprovalEscalation struct {
+	ID             uuid.UUID      `json:"id"`
+	ApprovalID     uuid.UUID      `json:"approval_id"`
+	ApprovalItemID uuid.UUID      `json:"approval_item_id"`
+	StepSeq        int            `json:"step_seq"`
+	Action         string         `json:"action"` // ESCALATE or AUTO_REJECT
+	PendingSince   time.Time      `json:"pending_since"`
+	SlaHours       float64        `json:"sla_hours"`
+	UserCodes      datatypes.JSON `json:"user_codes"` // backup approvers added
+	CreateDtm      time.Time      `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+}
+
+func (ApprovalEscalation) TableName() string {
+	return "approval_escalation"
+}
+
+// ApprovalStepRule is the template rule copied onto ApprovalItem.Condition when the step is created.
+type ApprovalStepRule struct {
+	StepName    string `json:"step_name"`
+	Expression  string `json:"expression"`
+	ApproveMode string `json:"approve_mode"`
+}
+
+// ApprovalTemplate is one step of the approval workflow of an ApproveTopic (SO, PO, QUOTATION, CREDIT, ...).
+type ApprovalTemplate struct {
+	ID           uuid.UUID      `json:"id"`
+	ApproveTopic string         `json:"approve_topic"`
+	StepSeq      int            `json:"step_seq"`
+	StepName     string         `json:"step_name"`
+	Condition    string         `json:"condition"`    // expr-lang expression over the document data; empty = always
+	ApproveMode  string         `json:"approve_mode"` // ANY or ALL
+	MDItemCode   string         `json:"md_item_code"` // approvers come from this menu's APPROVE permission when UserCodes is empty
+	UserCodes    datatypes.JSON `json:"user_codes"`
+	IsActive     bool           `json:"is_active"`
+	CreateBy     string         `gorm:"type:varchar(100)" json:"create_by"`
+	CreateDate   time.Time      `gorm:"autoCreateTime;<-:create" json:"create_date"`
+	UpdateBy     string         `gorm:"type:varchar(100)" json:"update_by"`
+	UpdateDate   time.Time      `gorm:"autoUpdateTime;<-" json:"update_date"`
+}
+
+func (ApprovalTemplate) TableName() string {
+	return "approval_template"
+}
+
+/* type Approval struct {
+	ID             uuid.UUID  `json:"id"`
+	ApproveCode    string     `json:"approve_code"`
+	ApproveTopic   string     `json:"approve_topic"`
+	DocumentType   string     `json:"document_type"`
+	DocumentCode   string     `json:"document_code"`
+	DocumentData   string     `json:"document_data"`
+	ActionDate     *time.Time `json:"action_date"`
+	Status         string     `json:"status"`
+	Remark         string     `json:"remark"`
+	CurrentStepSeq int        `json:"curent_step_seq"`
+	CreateDate     *time.Time `json:"create_date"`
+	CreateBy       string     `json:"create_by"`
+	UpdateDate     *time.Time `json:"update_date"`
+	UpdateBy       string     `json:"update_by"`
+}
+
+func (Approval) TableName() string { return "approval" }
+
+type ApprovalItem struct {
+	ID          uuid.UUID  `json:"id"`
+	ApprovalID  uuid.UUID  `json:"approval_id"`
+	StepSeq     int        `json:"step_seq"`
+	IsCondition bool       `json:"is_condition"`
+	Condition   string     `json:"condition"`
+	Status      string     `json:"status"`
+	ActionBy    string     `json:"action_by"`
+	ActionDate  *time.Time `json:"action_date"`
+	CreateDate  *time.Time `json:"create_date"`
+	CreateBy    string     `json:"create_by"`
+	UpdateDate  *time.Time `json:"update_date"`
+	UpdateBy    string     `json:"update_by"`
+}
+
+func (ApprovalItem) TableName() string { return "approval_item" }
+
+type ApprovalItemPermission struct {
+	ID             uuid.UUID `json:"id"`
+	ApprovalItemID uuid.UUID `json:"approval_item_id"`
+	UserCode       string    `json:"user_code"`
+}
+
+func (ApprovalItemPermission) TableName() string { return "approval_item_permission" } */
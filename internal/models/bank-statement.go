@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Bank statement file formats.
+const (
+	BankStatementFormatCSV     = "CSV"
+	BankStatementFormatCamt053 = "CAMT053" // ISO 20022 camt.053 XML
+)
+
+// Bank statement line states. A line is PROPOSED when one match stands out, and UNMATCHED, the reconciliation
+// queue, otherwise; it ends MATCHED once confirmed into a payment or IGNORED.
+const (
+	BankLineUnmatched = "UNMATCHED"
+	BankLineProposed  = "PROPOSED"
+	BankLineMatched   = "MATCHED"
+	BankLineIgnored   = "IGNORED"
+)
+
+type BankStatement struct {
+	ID            uuid.UUID  `json:"id"`
+	FileName      string     `json:"file_name"`
+	Format        string     `json:"format"`
+	AccountNo     string     `json:"account_no"`
+	StatementRef  string     `json:"statement_ref"`
+	StatementDate *time.Time `json:"statement_date"`
+	LineCount     int        `json:"line_count"`
+	CreateBy      string     `json:"create_by"`
+	CreateDtm     time.Time  `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+}
+
+func (BankStatement) TableName() string { return "bank_statement" }
+
+// BankStatementLine is one incoming credit of a statement with the matches proposed for it.
+type BankStatementLine struct {
+	ID                  uuid.UUID      `json:"id"`
+	StatementID         uuid.UUID      `json:"statement_id"`
+	LineNo              int            `json:"line_no"`
+	BookingDate         time.Time      `json:"booking_date"`
+	ValueDate           *time.Time     `json:"value_date"`
+	Amount              float64        `json:"amount"`
+	Currency            string         `json:"currency"`
+	Reference           string         `json:"reference"`
+	CounterpartyName    string         `json:"counterparty_name"`
+	CounterpartyAccount string         `json:"counterparty_account"`
+	BankRef             string         `json:"bank_ref"`
+	LineHash            string         `json:"line_hash"`
+	Status              string         `json:"status"`
+	Proposals           datatypes.JSON `json:"proposals"`
+	CustomerCode        string         `json:"customer_code"`
+	PaymentCode         string         `json:"payment_code"`
+	Remark              string         `json:"remark"`
+	CreateBy            string         `json:"create_by"`
+	CreateDtm           time.Time      `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+	UpdateBy            string         `json:"update_by"`
+	UpdateDtm           time.Time      `gorm:"autoUpdateTime;<-" json:"update_dtm"`
+}
+
+func (BankStatementLine) TableName() string { return "bank_statement_line" }
+
+// CustomerBankAccount is an account a customer has paid from.
+type CustomerBankAccount struct {
+	AccountNo    string    `json:"account_no"`
+	CustomerCode string    `json:"customer_code"`
+	CreateBy     string    `json:"create_by"`
+	CreateDtm    time.Time `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+}
+
+func (CustomerBankAccount) TableName() string { return "customer_bank_account" }
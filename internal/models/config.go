@@ -1,29 +1,39 @@
-package models
-
-import "github.com/google/uuid"
-
-type SystemConfig struct {
-	TopicCode  string     `json:"topic_code"`
-	ConfigCode string     `json:"config_code"`
-	TenantID   *uuid.UUID `json:"tenant_id,omitempty"`
-	ConfigName string     `json:"config_name"`
-	Cond1      string     `json:"cond1"`
-	Cond2      string     `json:"cond2"`
-	Value      string     `json:"value"`
-	Sequence   int        `json:"sequence"`
-	Remark     string     `json:"remark"`
-	JSON       string     `json:"json"`
-}
-
-func (SystemConfig) TableName() string {
-	return "system_config"
-}
-
-// RunningConfigJSON represents the JSON structure for running number configuration
-type RunningConfigJSON struct {
-	Year           string `json:"year"`
-	Month          string `json:"month"`
-	Prefix         string `json:"prefix"`
-	RunningDigit   int    `json:"running_digit"`
-	CurrentRunning int    `json:"current_running"`
-}
+package models
+
+import "github.com/google/uuid"
+
+type SystemConfig struct {
+	TopicCode  string     `json:"topic_code"`
+	ConfigCode string     `json:"config_code"`
+	TenantID   *uuid.UUID `json:"tenant_id,omitempty"`
+	ConfigName string     `json:"config_name"`
+	Cond1      string     `json:"cond1"`
+	Cond2      string     `json:"cond2"`
+	Value      string     `json:"value"`
+	Sequence   int        `json:"sequence"`
+	Remark     string     `json:"remark"`
+	JSON       string     `json:"json"`
+}
+
+func (SystemConfig) TableName() string {
+	return "system_config"
+}
+
+// RunningConfigJSON represents the JSON structure for running number configuration.
+// Year, Month and CurrentRunning only seed a number_range counter the first time it is used.
+type RunningConfigJSON struct {
+	Year           string `json:"year"`
+	Month          string `json:"month"`
+	Prefix         string `json:"prefix"`
+	RunningDigit   int    `json:"running_digit"`
+	CurrentRunning int    `json:"current_running"`
+	ResetPolicy    string `json:"reset_policy,omitempty"` // YEARLY, MONTHLY (default), NEVER
+	YearFormat     string `json:"year_format,omitempty"`  // YYYY (default), BE_YY
+}
+
+// ApprovalSLAConfigJSON is the JSON of an APPROVAL system_config row; the row's config_code is the approve topic and its value the SLA in hours.
+type ApprovalSLAConfigJSON struct {
+	Action          string   `json:"action"`           // ESCALATE (default) or AUTO_REJECT
+	BackupApprovers []string `json:"backup_approvers"` // user codes added to the overdue step, when it is an any-of step
+	MaxEscalations  int      `json:"max_escalations"`  // escalations per step, default 1
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Credit hold states. A hold is OPEN until all of it is either converted or released; it ends CONVERTED when any of
+// it was invoiced.
+const (
+	CreditHoldOpen      = "OPEN"
+	CreditHoldConverted = "CONVERTED"
+	CreditHoldReleased  = "RELEASED"
+
+	CreditHoldReleaseCanceled  = "CANCELED"  // the sale was canceled
+	CreditHoldReleaseShortfall = "SHORTFALL" // the sale closed invoiced for less than it held
+	CreditHoldReleaseExpired   = "EXPIRED"
+)
+
+// CreditHold is the credit one sale reserves. Its open amount counts against the customer's balance until the ledger
+// carries the sale itself.
+type CreditHold struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	CustomerCode    string     `json:"customer_code" db:"customer_code"`
+	SaleCode        string     `json:"sale_code" db:"sale_code"`
+	Amount          float64    `json:"amount" db:"amount"`
+	ConvertedAmount float64    `json:"converted_amount" db:"converted_amount"`
+	ReleasedAmount  float64    `json:"released_amount" db:"released_amount"`
+	Status          string     `json:"status" db:"status"`
+	ReleaseReason   string     `json:"release_reason" db:"release_reason"`
+	ExpireDtm       time.Time  `json:"expire_dtm" db:"expire_dtm"`
+	SettleDtm       *time.Time `json:"settle_dtm" db:"settle_dtm"`
+	CreateBy        string     `json:"create_by" db:"create_by"`
+	CreateDtm       time.Time  `gorm:"autoCreateTime;<-:create" json:"create_dtm" db:"create_dtm"`
+	UpdateBy        string     `json:"update_by" db:"update_by"`
+	UpdateDtm       time.Time  `gorm:"autoUpdateTime;<-" json:"update_dtm" db:"update_dtm"`
+}
+
+func (CreditHold) TableName() string { return "credit_hold" }
+
+// OpenAmount is what the hold still reserves.
+func (h CreditHold) OpenAmount() float64 {
+	if h.Status != CreditHoldOpen {
+		return 0
+	}
+	return h.Amount - h.ConvertedAmount - h.ReleasedAmount
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Credit ledger buckets: what an entry moves. A customer's balance is credit + the extra in effect + remain deposit - used.
+const (
+	CreditBucketCredit  = "CREDIT"
+	CreditBucketExtra   = "EXTRA"
+	CreditBucketDeposit = "DEPOSIT"
+	CreditBucketUsed    = "USED"
+)
+
+// Credit ledger sources: the document an entry comes from.
+const (
+	CreditSourceCredit           = "CREDIT"       // credit.id
+	CreditSourceExtra            = "CREDIT_EXTRA" // credit_extra.id
+	CreditSourceDeposit          = "DEPOSIT"      // deposit_code
+	CreditSourceSale             = "SALE"         // sale_code of an approved, open sale
+	CreditSourcePayment          = "PAYMENT"      // AR invoice_code the payments were made on
+	CreditSourceCreditNote       = "CN"           // invoice_code of the CN
+	CreditSourceDebitNote        = "DN"           // invoice_code of the DN
+	CreditSourceDebitNotePayment = "DN_PAYMENT"   // invoice_code of the DN the payments were made on
+)
+
+// OutboxCreditLedgerPost carries what a business event changed in a customer's credit to the credit ledger.
+const OutboxCreditLedgerPost = "CREDIT_LEDGER_POST"
+
+// CreditLedgerPosting is what one source document contributes to a customer's credit after a business event, and when
+// the event took effect. The ledger writes the difference to what it carried for the source before.
+type CreditLedgerPosting struct {
+	CustomerCode string     `json:"customer_code"`
+	Bucket       string     `json:"bucket"`
+	SourceType   string     `json:"source_type"`
+	SourceRef    string     `json:"source_ref"`
+	Amount       float64    `json:"amount"`
+	IsActive     *bool      `json:"is_active"`     // credits only
+	EffectiveDtm *time.Time `json:"effective_dtm"` // extras only, with expire_dtm the window they count in
+	ExpireDtm    *time.Time `json:"expire_dtm"`
+	EventDtm     time.Time  `json:"event_dtm"`
+}
+
+// CreditLedgerPostPayload is the outbox payload of a credit ledger post: the postings of one customer for one event.
+// Sources of a replaced type that the postings leave out are reversed, for events that post all of a type.
+type CreditLedgerPostPayload struct {
+	CustomerCode string                `json:"customer_code"`
+	SourceType   string                `json:"source_type"` // the document of the event
+	SourceRef    string                `json:"source_ref"`
+	Postings     []CreditLedgerPosting `json:"postings"`
+	Replace      []string              `json:"replace"`
+}
+
+// CreditLedgerEntry is one append-only change to a customer's credit, dated by event_dtm, when its business event took
+// effect. Extras carry the window they count in, and credit entries the is_active of the credit after the change.
+type CreditLedgerEntry struct {
+	ID           int64      `json:"id" db:"id"`
+	CustomerCode string     `json:"customer_code" db:"customer_code"`
+	Bucket       string     `json:"bucket" db:"bucket"`
+	SourceType   string     `json:"source_type" db:"source_type"`
+	SourceRef    string     `json:"source_ref" db:"source_ref"`
+	Amount       float64    `json:"amount" db:"amount"`
+	IsActive     *bool      `json:"is_active" db:"is_active"`
+	EffectiveDtm *time.Time `json:"effective_dtm" db:"effective_dtm"`
+	ExpireDtm    *time.Time `json:"expire_dtm" db:"expire_dtm"`
+	EventDtm     time.Time  `json:"event_dtm" db:"event_dtm"`
+	EntryDtm     time.Time  `json:"entry_dtm" db:"entry_dtm"`
+	CreateBy     string     `json:"create_by" db:"create_by"`
+}
+
+func (CreditLedgerEntry) TableName() string { return "credit_ledger" }
+
+// CreditBalance is the running total of a customer's ledger, less the extras, which depend on when it is read.
+type CreditBalance struct {
+	CustomerCode  string    `json:"customer_code" db:"customer_code"`
+	IsActive      bool      `json:"is_active" db:"is_active"`
+	Credit        float64   `json:"credit" db:"credit"`
+	RemainDeposit float64   `json:"remain_deposit" db:"remain_deposit"`
+	Used          float64   `json:"used" db:"used"`
+	LastEntryID   int64     `json:"last_entry_id" db:"last_entry_id"`
+	UpdateDtm     time.Time `json:"update_dtm" db:"update_dtm"`
+}
+
+func (CreditBalance) TableName() string { return "credit_balance" }
+
+// CreditLedgerDrift is a customer whose ledger balance differed from the recomputed one in a reconciliation run.
+type CreditLedgerDrift struct {
+	ID                    uuid.UUID `json:"id" db:"id"`
+	RunID                 uuid.UUID `json:"run_id" db:"run_id"`
+	CustomerCode          string    `json:"customer_code" db:"customer_code"`
+	LedgerIsActive        bool      `json:"ledger_is_active" db:"ledger_is_active"`
+	LedgerCredit          float64   `json:"ledger_credit" db:"ledger_credit"`
+	LedgerExtra           float64   `json:"ledger_extra" db:"ledger_extra"`
+	LedgerRemainDeposit   float64   `json:"ledger_remain_deposit" db:"ledger_remain_deposit"`
+	LedgerUsed            float64   `json:"ledger_used" db:"ledger_used"`
+	ComputedIsActive      bool      `json:"computed_is_active" db:"computed_is_active"`
+	ComputedCredit        float64   `json:"computed_credit" db:"computed_credit"`
+	ComputedExtra         float64   `json:"computed_extra" db:"computed_extra"`
+	ComputedRemainDeposit float64   `json:"computed_remain_deposit" db:"computed_remain_deposit"`
+	ComputedUsed          float64   `json:"computed_used" db:"computed_used"`
+	Difference            float64   `json:"difference" db:"difference"` // ledger balance - computed balance
+	CreateDtm             time.Time `json:"create_dtm" db:"create_dtm"`
+}
+
+func (CreditLedgerDrift) TableName() string { return "credit_ledger_drift" }
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NumberResetYearly  = "YEARLY"
+	NumberResetMonthly = "MONTHLY"
+	NumberResetNever   = "NEVER"
+
+	NumberYearFormatFull = "YYYY"
+	NumberYearFormatBE   = "BE_YY"
+
+	NumberIssueReserved = "RESERVED"
+	NumberIssueVoid     = "VOID"
+)
+
+// NumberRange is the counter of one running config per company/site scope and reset period.
+type NumberRange struct {
+	ID             uuid.UUID `json:"id"`
+	ConfigCode     string    `json:"config_code"`
+	CompanyCode    string    `json:"company_code"`
+	SiteCode       string    `json:"site_code"`
+	Period         string    `json:"period"`
+	CurrentRunning int       `json:"current_running"`
+	CreateDtm      time.Time `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+	UpdateDtm      time.Time `gorm:"autoUpdateTime;<-" json:"update_dtm"`
+}
+
+func (NumberRange) TableName() string { return "number_range" }
+
+// NumberRangeIssue records every reserved number so unused or voided ones can be audited.
+type NumberRangeIssue struct {
+	ID            uuid.UUID  `json:"id"`
+	NumberRangeID uuid.UUID  `json:"number_range_id"`
+	ConfigCode    string     `json:"config_code"`
+	CompanyCode   string     `json:"company_code"`
+	SiteCode      string     `json:"site_code"`
+	Period        string     `json:"period"`
+	Running       int        `json:"running"`
+	DocumentCode  string     `json:"document_code"`
+	Status        string     `json:"status"`
+	Remark        string     `json:"remark"`
+	CreateBy      string     `gorm:"type:varchar(100)" json:"create_by"`
+	CreateDtm     time.Time  `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+	UpdateBy      string     `gorm:"type:varchar(100)" json:"update_by"`
+	UpdateDtm     *time.Time `json:"update_dtm"`
+}
+
+func (NumberRangeIssue) TableName() string { return "number_range_issue" }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Outbox event states. An event is PENDING until its handler succeeds (DONE) or it runs out of attempts (DEAD).
+const (
+	OutboxStatusPending    = "PENDING"
+	OutboxStatusProcessing = "PROCESSING"
+	OutboxStatusDone       = "DONE"
+	OutboxStatusDead       = "DEAD"
+)
+
+// OutboxEvent is a call to another service, written in the same transaction as the change that needs it.
+type OutboxEvent struct {
+	ID             uuid.UUID      `json:"id"`
+	EventType      string         `json:"event_type"`
+	AggregateType  string         `json:"aggregate_type"`
+	AggregateCode  string         `json:"aggregate_code"`
+	IdempotencyKey string         `json:"idempotency_key"` // unique; enqueueing the same key twice is a no-op
+	Payload        datatypes.JSON `json:"payload"`
+	Status         string         `json:"status"`
+	Attempts       int            `json:"attempts"`
+	MaxAttempts    int            `json:"max_attempts"`
+	NextAttemptDtm time.Time      `json:"next_attempt_dtm"`
+	LastError      string         `json:"last_error"`
+	Response       datatypes.JSON `json:"response"`
+	ProcessedDtm   *time.Time     `json:"processed_dtm"`
+	CreateBy       string         `json:"create_by"`
+	CreateDtm      time.Time      `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+	UpdateBy       string         `json:"update_by"`
+	UpdateDtm      time.Time      `gorm:"autoUpdateTime;<-" json:"update_dtm"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_event"
+}
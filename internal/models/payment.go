@@ -1,45 +1,65 @@
-package models
-
-import (
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type Payment struct {
-	ID             uuid.UUID        `json:"id"`
-	PaymentCode    string           `json:"payment_code"`
-	CustomerCode   string           `json:"customer_code"`
-	PaymentDate    time.Time        `json:"payment_date"`
-	Amount         float64          `json:"amount"`
-	Method         string           `json:"method"` // CASH, BANK_TRANSFER, CHEQUE
-	Status         string           `json:"status"` // PENDING, COMPLETED, CANCELLED
-	Remark         string           `json:"remark"`
-	CreateBy       string           `gorm:"type:varchar(100)" json:"create_by"`
-	CreateDtm      time.Time        `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
-	UpdateBy       string           `gorm:"type:varchar(100)" json:"update_by"`
-	UpdateDate     time.Time        `gorm:"autoUpdateTime;<-" json:"update_date"`
-	ExternalID     string           `json:"external_id"`
-	PaymentInvoice []PaymentInvoice `json:"payment_invoice"`
-}
-
-func (Payment) TableName() string {
-	return "payment"
-}
-
-type PaymentInvoice struct {
-	ID          uuid.UUID `json:"id"`
-	PaymentID   uuid.UUID `json:"payment_id"`
-	InvoiceCode string    `json:"invoice_code"`
-	Amount      float64   `json:"amount"`
-	ApplyDate   time.Time `json:"apply_date"`
-
-	CreateBy   string    `json:"create_by"`
-	CreateDtm  time.Time `json:"create_dtm"`
-	UpdateBy   string    `json:"update_by"`
-	UpdateDate time.Time `json:"update_date"`
-}
-
-func (PaymentInvoice) TableName() string {
-	return "payment_invoice"
-}
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Payment struct {
+	ID             uuid.UUID        `json:"id"`
+	PaymentCode    string           `json:"payment_code"`
+	CustomerCode   string           `json:"customer_code"`
+	PaymentDate    time.Time        `json:"payment_date"`
+	Amount         float64          `json:"amount"`
+	Method         string           `json:"method"` // CASH, BANK_TRANSFER, CHEQUE
+	Status         string           `json:"status"` // PENDING, COMPLETED, CANCELLED
+	Remark         string           `json:"remark"`
+	CreateBy       string           `gorm:"type:varchar(100)" json:"create_by"`
+	CreateDtm      time.Time        `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+	UpdateBy       string           `gorm:"type:varchar(100)" json:"update_by"`
+	UpdateDate     time.Time        `gorm:"autoUpdateTime;<-" json:"update_date"`
+	ExternalID     string           `json:"external_id"`
+	Reference      string           `json:"reference"` // the customer's reference, e.g. the invoice or sale it pays
+	PaymentInvoice []PaymentInvoice `json:"payment_invoice"`
+
+	Allocation      string  `gorm:"-" json:"allocation"` // how to allocate what payment_invoice leaves: MANUAL (default), OLDEST_DUE, REFERENCE
+	AllocatedAmount float64 `gorm:"-" json:"allocated_amount"`
+	UnappliedAmount float64 `gorm:"-" json:"unapplied_amount"` // on-account credit, left for a later allocation
+}
+
+// Payment allocation modes. MANUAL applies only the allocations sent; the others also allocate the rest of the
+// payment, to the customer's open invoices oldest due first or to those matching the payment's reference.
+const (
+	PaymentAllocationManual    = "MANUAL"
+	PaymentAllocationOldestDue = "OLDEST_DUE"
+	PaymentAllocationReference = "REFERENCE"
+)
+
+// Invoice payment statuses, derived from the payments allocated to the invoice.
+const (
+	InvoicePaymentUnpaid  = "Unpaid"
+	InvoicePaymentPartial = "Partial"
+	InvoicePaymentPaid    = "Paid"
+)
+
+func (Payment) TableName() string {
+	return "payment"
+}
+
+type PaymentInvoice struct {
+	ID          uuid.UUID `json:"id"`
+	PaymentID   uuid.UUID `json:"payment_id"`
+	InvoiceCode string    `json:"invoice_code"`
+	Amount      float64   `json:"amount"`
+	ApplyDate   time.Time `json:"apply_date"`
+
+	CreateBy   string    `json:"create_by"`
+	CreateDtm  time.Time `json:"create_dtm"`
+	UpdateBy   string    `json:"update_by"`
+	UpdateDate time.Time `json:"update_date"`
+}
+
+func (PaymentInvoice) TableName() string {
+	return "payment_invoice"
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Price agreement values. An agreement belongs to one customer, or to every customer in a customer master group.
+const (
+	PriceAgreementActive   = "ACTIVE"
+	PriceAgreementCanceled = "CANCELED"
+
+	PriceAgreementFixed    = "FIXED"    // the line's price_unit / price_weight is the net price
+	PriceAgreementDiscount = "DISCOUNT" // percent and/or amount off the list price
+	PriceAgreementMarkup   = "MARKUP"   // percent and/or amount over cost
+)
+
+// PriceAgreement is a customer's contract pricing, valid from valid_from until (not including) valid_to.
+type PriceAgreement struct {
+	ID                 uuid.UUID            `json:"id"`
+	AgreementCode      string               `json:"agreement_code"`
+	CompanyCode        string               `json:"company_code"`
+	SiteCode           string               `json:"site_code"` // empty for every site of the company
+	CustomerCode       string               `json:"customer_code"`
+	CustomerGroupCode  string               `json:"customer_group_code"` // customer master group, used when customer_code is empty
+	CustomerGroupValue string               `json:"customer_group_value"`
+	ValidFrom          time.Time            `json:"valid_from"`
+	ValidTo            *time.Time           `json:"valid_to"`
+	Status             string               `json:"status"`
+	Remark             string               `json:"remark"`
+	CreateBy           string               `json:"create_by"`
+	CreateDtm          time.Time            `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+	UpdateBy           string               `json:"update_by"`
+	UpdateDtm          time.Time            `gorm:"autoUpdateTime;<-" json:"update_dtm"`
+	Lines              []PriceAgreementLine `gorm:"foreignKey:AgreementID;references:ID" json:"lines"`
+}
+
+func (PriceAgreement) TableName() string { return "price_agreement" }
+
+// PriceAgreementLine prices one target. An empty target field matches anything, so a line with no group, sub group
+// or product applies to everything the customer buys. Lines of the same target form quantity or weight tiers.
+type PriceAgreementLine struct {
+	ID           uuid.UUID `json:"id"`
+	AgreementID  uuid.UUID `json:"agreement_id"`
+	GroupCode    string    `json:"group_code"` // price_list_group.group_code
+	SubGroupCode string    `json:"subgroup_code" gorm:"column:subgroup_code"`
+	ProductCode  string    `json:"product_code"`
+	PricingType  string    `json:"pricing_type"`
+	PriceUnit    float64   `json:"price_unit"`
+	PriceWeight  float64   `json:"price_weight"`
+	Percent      float64   `json:"percent"`
+	Amount       float64   `json:"amount"`
+	MinQty       float64   `json:"min_qty"`
+	MinWeight    float64   `json:"min_weight"`
+	Seq          int       `json:"seq"`
+}
+
+func (PriceAgreementLine) TableName() string { return "price_agreement_line" }
+
+// CustomerGroupRef is one group of a customer in the customer master.
+type CustomerGroupRef struct {
+	GroupCode  string `json:"group_code"`
+	GroupValue string `json:"group_value"`
+}
+
+// AgreementPrice is the price an agreement line gives, in place of the list price.
+type AgreementPrice struct {
+	AgreementID   uuid.UUID `json:"agreement_id"`
+	AgreementCode string    `json:"agreement_code"`
+	LineID        uuid.UUID `json:"line_id"`
+	PricingType   string    `json:"pricing_type"`
+	PriceUnit     float64   `json:"price_unit"`
+	PriceWeight   float64   `json:"price_weight"`
+	MinQty        float64   `json:"min_qty"`
+	MinWeight     float64   `json:"min_weight"`
+}
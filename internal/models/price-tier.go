@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Price tier bases. A break table is on quantity or on weight, never both.
+const (
+	PriceTierQty    = "QTY"
+	PriceTierWeight = "WEIGHT"
+)
+
+// PriceListTier is one break of a group's, or a sub group's, volume discount table. A sub group with a table of its
+// own does not use its group's. The tier applies from min_value up to (not including) max_value.
+type PriceListTier struct {
+	ID              uuid.UUID `json:"id"`
+	CompanyCode     string    `json:"company_code"`
+	GroupCode       string    `json:"group_code"`
+	SubGroupCode    string    `json:"subgroup_code" gorm:"column:subgroup_code"` // empty for the whole group
+	Basis           string    `json:"basis"`
+	MinValue        float64   `json:"min_value"`
+	MaxValue        *float64  `json:"max_value"` // nil for no upper bound
+	DiscountPercent float64   `json:"discount_percent"`
+	DiscountUnit    float64   `json:"discount_unit"`   // amount off per piece
+	DiscountWeight  float64   `json:"discount_weight"` // amount off per kg
+	CreateBy        string    `json:"create_by"`
+	CreateDtm       time.Time `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+	UpdateBy        string    `json:"update_by"`
+	UpdateDtm       time.Time `gorm:"autoUpdateTime;<-" json:"update_dtm"`
+}
+
+func (PriceListTier) TableName() string { return "price_list_tier" }
+
+// PriceTierPrice is a sub group's price within one tier.
+type PriceTierPrice struct {
+	TierID      uuid.UUID `json:"tier_id"`
+	Basis       string    `json:"basis"`
+	MinValue    float64   `json:"min_value"`
+	MaxValue    *float64  `json:"max_value"`
+	PriceUnit   float64   `json:"price_unit"`
+	PriceWeight float64   `json:"price_weight"`
+	Applied     bool      `json:"applied"` // the tier the requested qty or weight falls in
+}
@@ -1,119 +1,158 @@
-package models
-
-import (
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type Sale struct {
-	ID                          uuid.UUID     `json:"id"`
-	SaleCode                    string        `json:"sale_code"`
-	CompanyCode                 string        `json:"company_code"`
-	SiteCode                    string        `json:"site_code"`
-	CustomerCode                string        `json:"customer_code"`
-	CustomerName                string        `json:"customer_name"`
-	DeliveryDate                *time.Time    `json:"delivery_date"`
-	SoldToCode                  string        `json:"sold_to_code"`
-	SoldToAddress               string        `json:"sold_to_address"`
-	BillToCode                  string        `json:"bill_to_code"`
-	BillToAddress               string        `json:"bill_to_address"`
-	ShipToCode                  string        `json:"ship_to_code"`
-	ShipToType                  string        `json:"ship_to_type"`
-	ShipToAddress               string        `json:"ship_to_address"`
-	DeliveryMethod              string        `json:"delivery_method"`
-	TransportCostType           string        `json:"transport_cost_type"`
-	TotalTransportCost          float64       `json:"total_transport_cost"`
-	TotalAmount                 float64       `json:"total_amount"` //TotalPrice
-	TotalWeight                 float64       `json:"total_weight"`
-	SubtotalExclTransport       float64       `json:"subtotal_excl_transport"`        //TotalNetPrice
-	SubtotalWeightExclTransport float64       `json:"subtotal_weight_excl_transport"` //TotalNetPriceWeight
-	PaymentMethod               string        `json:"payment_method"`
-	PeymentTermCode             string        `json:"peyment_term_code"`
-	SalePersonCode              string        `json:"sale_person_code"`
-	EffectiveDatePrice          *time.Time    `json:"effective_date_price"`
-	ExpirePriceDay              int           `json:"expire_price_day"`
-	ExpirePriceDate             *time.Time    `json:"expire_price_date"`
-	PassPriceList               string        `json:"pass_price_list"`
-	PassAtpCheck                string        `json:"pass_atp_check"`
-	PassCreditLimit             string        `json:"pass_credit_limit"`
-	PassPriceExpire             string        `json:"pass_price_expire"`
-	Status                      string        `json:"status"`
-	StatusPayment               string        `json:"status_payment"`
-	Remark                      string        `json:"remark"`
-	IsApproved                  bool          `json:"is_approved"`
-	StatusApprove               string        `json:"status_approve"`
-	TotalVat                    float64       `json:"total_vat"`
-	TotalDiscount               float64       `json:"total_discount"`
-	SubtotalExclVat             float64       `json:"subtotal_excl_vat"`
-	TotalTransportCostVat       float64       `json:"total_transport_cost_vat"`
-	RemarkApproval              string        `json:"remark_approval"`
-	RefPoDoc                    string        `json:"ref_po_doc"`
-	CreditTerm                  string        `json:"credit_term"`
-	PayerTerm                   string        `json:"payer_term"`
-	CreateDate                  *time.Time    `json:"create_date"`
-	CreateBy                    string        `json:"create_by"`
-	UpdateDate                  *time.Time    `json:"update_date"`
-	UpdateBy                    string        `json:"update_by"`
-	SaleItem                    []SaleItem    `json:"sale_item"`
-	SaleDeposit                 []SaleDeposit `json:"sale_deposit"`
-}
-
-func (Sale) TableName() string { return "sale" }
-
-type SaleItem struct {
-	ID                             uuid.UUID      `json:"id"`
-	SaleID                         uuid.UUID      `json:"sale_id"`
-	SaleItem                       string         `json:"sale_item"`
-	ProductCode                    string         `json:"product_code"`
-	ProductDesc                    string         `json:"product_desc"`
-	DocumentRef                    string         `json:"document_ref"`
-	DocumentRefItem                string         `json:"document_ref_item"`
-	Qty                            float64        `json:"qty"`
-	OriginQty                      float64        `json:"origin_qty"`
-	Unit                           string         `json:"unit"`
-	PriceListUnit                  float64        `json:"price_list_unit"`
-	SaleQty                        float64        `json:"sale_qty"`
-	SaleUnit                       string         `json:"sale_unit"`
-	SaleUnitType                   string         `json:"sale_unit_type"`
-	PassPriceUnit                  string         `json:"pass_price_unit"`
-	PassPriceWeight                string         `json:"pass_price_weight"`
-	PriceUnit                      float64        `json:"price_unit"`
-	TotalAmount                    float64        `json:"total_amount"` //TotalPrice
-	TransportCostUnit              float64        `json:"transport_cost_unit"`
-	SubtotalExclTransport          float64        `json:"subtotal_excl_transport"`       //TotalNetPrice
-	NetPriceUnitExclTransport      float64        `json:"net_price_unit_excl_transport"` //NetPriceUnit
-	WeightUnit                     float64        `json:"weight_unit"`
-	AvgWeightUnit                  float64        `json:"avg_weight_unit"`
-	TotalWeight                    float64        `json:"total_weight"`
-	TransportCostWeightUnit        float64        `json:"transport_cost_weight_unit"`
-	SubtotalWeightExclTransport    float64        `json:"subtotal_weight_excl_transport"`      //TotalNetPriceWeight
-	NetPricePerWeightExclTransport float64        `json:"net_price_per_weight_excl_transport"` //NetPriceWeightUnit
-	Status                         string         `json:"status"`
-	Remark                         string         `json:"remark"`
-	SubtotalExclVat                float64        `json:"subtotal_excl_vat"`
-	TotalVat                       float64        `json:"total_vat"`
-	UnitUom                        string         `json:"unit_uom"`
-	TotalDiscount                  float64        `json:"total_discount"`
-	TotalDiscountPercent           float64        `json:"total_discount_percent"`
-	OldPriceListUnit               float64        `json:"old_price_list_unit"`
-	CreateDate                     *time.Time     `json:"create_date"`
-	CreateBy                       string         `json:"create_by"`
-	UpdateDate                     *time.Time     `json:"update_date"`
-	UpdateBy                       string         `json:"update_by"`
-	DeliveryItems                  []DeliveryItem `gorm:"foreignKey:DocumentRefItem;references:SaleItem" json:"delivery_items,omitempty"`
-}
-
-func (SaleItem) TableName() string { return "sale_item" }
-
-type SaleDeposit struct {
-	ID           uuid.UUID  `json:"id"`
-	SaleID       uuid.UUID  `json:"sale_id"`
-	DepositCode  string     `json:"deposit_code"`
-	DepositDate  *time.Time `json:"deposit_date"`
-	AmountTotal  float64    `json:"amount_total"`
-	AmountUsed   float64    `json:"amount_used"`
-	AmountRemain float64    `json:"amount_remain"`
-}
-
-func (SaleDeposit) TableName() string { return "sale_deposit" }
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sale and sale item statuses. CANCELED keeps the spelling already stored in sale.status.
+const (
+	SaleStatusPending          = "PENDING"
+	SaleStatusApproved         = "APPROVED"
+	SaleStatusPartialDelivered = "PARTIAL_DELIVERED"
+	SaleStatusDelivered        = "DELIVERED"
+	SaleStatusCompleted        = "COMPLETED"
+	SaleStatusCanceled         = "CANCELED"
+)
+
+// SaleOpenStatuses are the statuses of a sale that is neither completed nor canceled.
+var SaleOpenStatuses = []string{SaleStatusPending, SaleStatusApproved, SaleStatusPartialDelivered, SaleStatusDelivered}
+
+// Sale approval sub-states stored in sale.status_approve.
+const (
+	SaleApprovePending   = "PENDING"
+	SaleApproveProcess   = "PROCESS"
+	SaleApproveReview    = "REVIEW"
+	SaleApproveReject    = "REJECT"
+	SaleApproveCompleted = "COMPLETED"
+)
+
+type Sale struct {
+	ID                          uuid.UUID     `json:"id"`
+	SaleCode                    string        `json:"sale_code"`
+	CompanyCode                 string        `json:"company_code"`
+	SiteCode                    string        `json:"site_code"`
+	CustomerCode                string        `json:"customer_code"`
+	CustomerName                string        `json:"customer_name"`
+	DeliveryDate                *time.Time    `json:"delivery_date"`
+	SoldToCode                  string        `json:"sold_to_code"`
+	SoldToAddress               string        `json:"sold_to_address"`
+	BillToCode                  string        `json:"bill_to_code"`
+	BillToAddress               string        `json:"bill_to_address"`
+	ShipToCode                  string        `json:"ship_to_code"`
+	ShipToType                  string        `json:"ship_to_type"`
+	ShipToAddress               string        `json:"ship_to_address"`
+	DeliveryMethod              string        `json:"delivery_method"`
+	TransportCostType           string        `json:"transport_cost_type"`
+	TotalTransportCost          float64       `json:"total_transport_cost"`
+	TotalAmount                 float64       `json:"total_amount"` //TotalPrice
+	TotalWeight                 float64       `json:"total_weight"`
+	SubtotalExclTransport       float64       `json:"subtotal_excl_transport"`        //TotalNetPrice
+	SubtotalWeightExclTransport float64       `json:"subtotal_weight_excl_transport"` //TotalNetPriceWeight
+	PaymentMethod               string        `json:"payment_method"`
+	PeymentTermCode             string        `json:"peyment_term_code"`
+	SalePersonCode              string        `json:"sale_person_code"`
+	EffectiveDatePrice          *time.Time    `json:"effective_date_price"`
+	ExpirePriceDay              int           `json:"expire_price_day"`
+	ExpirePriceDate             *time.Time    `json:"expire_price_date"`
+	PassPriceList               string        `json:"pass_price_list"`
+	PassAtpCheck                string        `json:"pass_atp_check"`
+	PassCreditLimit             string        `json:"pass_credit_limit"`
+	PassPriceExpire             string        `json:"pass_price_expire"`
+	Status                      string        `json:"status"`
+	StatusPayment               string        `json:"status_payment"`
+	Remark                      string        `json:"remark"`
+	IsApproved                  bool          `json:"is_approved"`
+	StatusApprove               string        `json:"status_approve"`
+	TotalVat                    float64       `json:"total_vat"`
+	TotalDiscount               float64       `json:"total_discount"`
+	SubtotalExclVat             float64       `json:"subtotal_excl_vat"`
+	TotalTransportCostVat       float64       `json:"total_transport_cost_vat"`
+	RemarkApproval              string        `json:"remark_approval"`
+	RefPoDoc                    string        `json:"ref_po_doc"`
+	CreditTerm                  string        `json:"credit_term"`
+	PayerTerm                   string        `json:"payer_term"`
+	CreateDate                  *time.Time    `json:"create_date"`
+	CreateBy                    string        `json:"create_by"`
+	UpdateDate                  *time.Time    `json:"update_date"`
+	UpdateBy                    string        `json:"update_by"`
+	SaleItem                    []SaleItem    `json:"sale_item"`
+	SaleDeposit                 []SaleDeposit `json:"sale_deposit"`
+}
+
+func (Sale) TableName() string { return "sale" }
+
+type SaleItem struct {
+	ID                             uuid.UUID      `json:"id"`
+	SaleID                         uuid.UUID      `json:"sale_id"`
+	SaleItem                       string         `json:"sale_item"`
+	ProductCode                    string         `json:"product_code"`
+	ProductDesc                    string         `json:"product_desc"`
+	DocumentRef                    string         `json:"document_ref"`
+	DocumentRefItem                string         `json:"document_ref_item"`
+	Qty                            float64        `json:"qty"`
+	OriginQty                      float64        `json:"origin_qty"`
+	Unit                           string         `json:"unit"`
+	PriceListUnit                  float64        `json:"price_list_unit"`
+	SaleQty                        float64        `json:"sale_qty"`
+	SaleUnit                       string         `json:"sale_unit"`
+	SaleUnitType                   string         `json:"sale_unit_type"`
+	PassPriceUnit                  string         `json:"pass_price_unit"`
+	PassPriceWeight                string         `json:"pass_price_weight"`
+	PriceUnit                      float64        `json:"price_unit"`
+	TotalAmount                    float64        `json:"total_amount"` //TotalPrice
+	TransportCostUnit              float64        `json:"transport_cost_unit"`
+	SubtotalExclTransport          float64        `json:"subtotal_excl_transport"`       //TotalNetPrice
+	NetPriceUnitExclTransport      float64        `json:"net_price_unit_excl_transport"` //NetPriceUnit
+	WeightUnit                     float64        `json:"weight_unit"`
+	AvgWeightUnit                  float64        `json:"avg_weight_unit"`
+	TotalWeight                    float64        `json:"total_weight"`
+	TransportCostWeightUnit        float64        `json:"transport_cost_weight_unit"`
+	SubtotalWeightExclTransport    float64        `json:"subtotal_weight_excl_transport"`      //TotalNetPriceWeight
+	NetPricePerWeightExclTransport float64        `json:"net_price_per_weight_excl_transport"` //NetPriceWeightUnit
+	Status                         string         `json:"status"`
+	Remark                         string         `json:"remark"`
+	SubtotalExclVat                float64        `json:"subtotal_excl_vat"`
+	TotalVat                       float64        `json:"total_vat"`
+	UnitUom                        string         `json:"unit_uom"`
+	TotalDiscount                  float64        `json:"total_discount"`
+	TotalDiscountPercent           float64        `json:"total_discount_percent"`
+	OldPriceListUnit               float64        `json:"old_price_list_unit"`
+	CreateDate                     *time.Time     `json:"create_date"`
+	CreateBy                       string         `json:"create_by"`
+	UpdateDate                     *time.Time     `json:"update_date"`
+	UpdateBy                       string         `json:"update_by"`
+	DeliveryItems                  []DeliveryItem `gorm:"foreignKey:DocumentRefItem;references:SaleItem" json:"delivery_items,omitempty"`
+}
+
+func (SaleItem) TableName() string { return "sale_item" }
+
+type SaleDeposit struct {
+	ID           uuid.UUID  `json:"id"`
+	SaleID       uuid.UUID  `json:"sale_id"`
+	DepositCode  string     `json:"deposit_code"`
+	DepositDate  *time.Time `json:"deposit_date"`
+	AmountTotal  float64    `json:"amount_total"`
+	AmountUsed   float64    `json:"amount_used"`
+	AmountRemain float64    `json:"amount_remain"`
+}
+
+func (SaleDeposit) TableName() string { return "sale_deposit" }
+
+// SaleStatusHistory records one status or approval transition of a sale or sale item.
+type SaleStatusHistory struct {
+	ID         uuid.UUID  `json:"id"`
+	SaleID     uuid.UUID  `json:"sale_id"`
+	SaleCode   string     `json:"sale_code"`
+	SaleItemID *uuid.UUID `json:"sale_item_id"`
+	SaleItem   string     `json:"sale_item"`
+	Field      string     `json:"field"` // status or status_approve
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	Remark     string     `json:"remark"`
+	CreateBy   string     `gorm:"type:varchar(100)" json:"create_by"`
+	CreateDtm  time.Time  `gorm:"autoCreateTime;<-:create" json:"create_dtm"`
+}
+
+func (SaleStatusHistory) TableName() string { return "sale_status_history" }
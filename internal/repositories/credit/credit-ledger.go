@@ -0,0 +1,280 @@
+package repositoryCredit
+
+import (
+	"fmt"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// activeInvoiceStatuses are the invoice statuses that count for credit.
+var activeInvoiceStatuses = []string{"PENDING", "COMPLETED"}
+
+// CreditLedgerReplaceCredit are the source types CreditPostings posts in full for its customers.
+var CreditLedgerReplaceCredit = []string{models.CreditSourceCredit, models.CreditSourceExtra}
+
+// CreditPostings reads the credits of customers with their extras, every credit source of theirs, posted at at.
+func CreditPostings(tx *gorm.DB, customerCodes []string, at time.Time) ([]models.CreditLedgerPosting, error) {
+	postings := []models.CreditLedgerPosting{}
+	if len(customerCodes) == 0 {
+		return postings, nil
+	}
+
+	credits := []models.Credit{}
+	if err := tx.Preload("CreditExtra").Where("customer_code IN ?", customerCodes).Find(&credits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get credits: %v", err)
+	}
+	for _, credit := range credits {
+		isActive := credit.IsActive
+		postings = append(postings, models.CreditLedgerPosting{
+			CustomerCode: credit.CustomerCode,
+			Bucket:       models.CreditBucketCredit,
+			SourceType:   models.CreditSourceCredit,
+			SourceRef:    credit.ID.String(),
+			Amount:       credit.Amount,
+			IsActive:     &isActive,
+			EventDtm:     at,
+		})
+		for _, extra := range credit.CreditExtra {
+			postings = append(postings, models.CreditLedgerPosting{
+				CustomerCode: credit.CustomerCode,
+				Bucket:       models.CreditBucketExtra,
+				SourceType:   models.CreditSourceExtra,
+				SourceRef:    extra.ID.String(),
+				Amount:       extra.Amount,
+				EffectiveDtm: extra.EffectiveDtm,
+				ExpireDtm:    extra.ExpireDtm,
+				EventDtm:     at,
+			})
+		}
+	}
+	return postings, nil
+}
+
+type depositPostingRow struct {
+	CustomerCode string
+	DepositCode  string
+	AmountRemain float64
+	DepositDate  *time.Time
+}
+
+// DepositPostings reads what deposits leave to their customers, posted at their deposit date, or at without one.
+func DepositPostings(tx *gorm.DB, depositCodes []string, at time.Time) ([]models.CreditLedgerPosting, error) {
+	postings := []models.CreditLedgerPosting{}
+	if len(depositCodes) == 0 {
+		return postings, nil
+	}
+
+	rows := []depositPostingRow{}
+	if err := tx.Raw(`
+		select customer_code, deposit_code, sum(coalesce(amount_remain, 0)) amount_remain, max(deposit_date) deposit_date
+		from deposit
+		where deposit_code in ?
+		group by customer_code, deposit_code`, depositCodes).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get deposits: %v", err)
+	}
+	for _, row := range rows {
+		eventDtm := at
+		if row.DepositDate != nil {
+			eventDtm = *row.DepositDate
+		}
+		postings = append(postings, models.CreditLedgerPosting{
+			CustomerCode: row.CustomerCode,
+			Bucket:       models.CreditBucketDeposit,
+			SourceType:   models.CreditSourceDeposit,
+			SourceRef:    row.DepositCode,
+			Amount:       row.AmountRemain,
+			EventDtm:     eventDtm,
+		})
+	}
+	return postings, nil
+}
+
+// openSaleCondition says whether sale s is approved and still open, which is when it and its invoices use credit.
+const openSaleCondition = `(s.is_approved = true or s.status_approve = 'COMPLETED') and s.status in ?`
+
+type salePostingRow struct {
+	SaleCode     string
+	CustomerCode string
+	Amount       float64
+	IsOpen       bool
+}
+
+// SalePostings reads what sales use of their customers' credit, posted at at: an approved open sale its total, with
+// the transport cost when it is charged on top, and what was paid on its invoices and adjusted by CN and DN. A sale
+// that is not approved or no longer open uses nothing.
+func SalePostings(tx *gorm.DB, saleCodes []string, at time.Time) ([]models.CreditLedgerPosting, error) {
+	postings := []models.CreditLedgerPosting{}
+	if len(saleCodes) == 0 {
+		return postings, nil
+	}
+
+	rows := []salePostingRow{}
+	if err := tx.Raw(`
+		select s.sale_code, s.customer_code
+			, coalesce(s.total_amount, 0) + case when s.transport_cost_type = 'EXCL' then coalesce(s.total_transport_cost, 0) else 0 end amount
+			, (`+openSaleCondition+`) is_open
+		from sale s
+		where s.sale_code in ?`, models.SaleOpenStatuses, saleCodes).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sales: %v", err)
+	}
+	for _, row := range rows {
+		amount := 0.0
+		if row.IsOpen {
+			amount = row.Amount
+		}
+		postings = append(postings, models.CreditLedgerPosting{
+			CustomerCode: row.CustomerCode,
+			Bucket:       models.CreditBucketUsed,
+			SourceType:   models.CreditSourceSale,
+			SourceRef:    row.SaleCode,
+			Amount:       amount,
+			EventDtm:     at,
+		})
+	}
+
+	invoiceCodes := []string{}
+	if err := tx.Raw(`
+		select i.invoice_code
+		from invoice i
+		join invoice_item ii on ii.invoice_id = i.id
+		where i.invoice_type = 'AR' and ii.document_ref in ?
+		union
+		select c.invoice_code
+		from invoice c
+		join invoice_item ci on ci.invoice_id = c.id
+		join invoice ar on ar.invoice_code = ci.document_ref and ar.invoice_type = 'AR'
+		join invoice_item ai on ai.invoice_id = ar.id and ai.invoice_item = ci.document_ref_item
+		where c.invoice_type in ('CN', 'DN') and ai.document_ref in ?`, saleCodes, saleCodes).Scan(&invoiceCodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get invoices of sales: %v", err)
+	}
+
+	invoicePostings, err := InvoicePostings(tx, invoiceCodes, at)
+	if err != nil {
+		return nil, err
+	}
+	return append(postings, invoicePostings...), nil
+}
+
+// paidQuery adds up what payments that are not cancelled paid, on the invoice the caller appends a condition for.
+const paidQuery = `select sum(pi.amount) from payment_invoice pi join payment p on p.id = pi.payment_id where coalesce(p.status, '') <> 'CANCELLED'`
+
+type arInvoicePostingRow struct {
+	InvoiceCode  string
+	CustomerCode string
+	Paid         float64
+	IsOpen       bool
+}
+
+type adjustmentPostingRow struct {
+	InvoiceCode string
+	InvoiceType string
+	PartyCode   string
+	ArPartyCode string
+	Amount      float64
+	IsOpen      bool
+	Paid        float64
+}
+
+// InvoicePostings reads what invoices take off or add to what their sales use, posted at at: the payments that are not
+// cancelled on an AR invoice, a CN or DN on such an invoice's items and the payments on the DN. Only invoices of
+// approved open sales count.
+func InvoicePostings(tx *gorm.DB, invoiceCodes []string, at time.Time) ([]models.CreditLedgerPosting, error) {
+	postings := []models.CreditLedgerPosting{}
+	if len(invoiceCodes) == 0 {
+		return postings, nil
+	}
+	used := func(customerCode string, sourceType string, sourceRef string, amount float64) {
+		postings = append(postings, models.CreditLedgerPosting{
+			CustomerCode: customerCode,
+			Bucket:       models.CreditBucketUsed,
+			SourceType:   sourceType,
+			SourceRef:    sourceRef,
+			Amount:       amount,
+			EventDtm:     at,
+		})
+	}
+
+	arRows := []arInvoicePostingRow{}
+	if err := tx.Raw(`
+		select i.invoice_code, coalesce(i.party_code, '') customer_code
+			, coalesce((`+paidQuery+` and pi.invoice_code = i.invoice_code), 0) paid
+			, (i.status in ? and exists (
+				select 1
+				from invoice_item ii
+				join sale s on s.sale_code = ii.document_ref and s.customer_code = i.party_code
+				where ii.invoice_id = i.id and `+openSaleCondition+`)) is_open
+		from invoice i
+		where i.invoice_type = 'AR' and i.invoice_code in ?`,
+		activeInvoiceStatuses, models.SaleOpenStatuses, invoiceCodes).Scan(&arRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get AR invoices: %v", err)
+	}
+	for _, row := range arRows {
+		paid := 0.0
+		if row.IsOpen {
+			paid = row.Paid
+		}
+		used(row.CustomerCode, models.CreditSourcePayment, row.InvoiceCode, -paid)
+	}
+
+	adjustmentRows := []adjustmentPostingRow{}
+	if err := tx.Raw(`
+		select c.invoice_code, c.invoice_type, coalesce(c.party_code, '') party_code, coalesce(ar.party_code, '') ar_party_code
+			, coalesce(ci.total_amount, 0) amount
+			, coalesce((`+paidQuery+` and pi.invoice_code = c.invoice_code), 0) paid
+			, (c.status in ? and coalesce(ar.status in ?, false) and exists (
+				select 1
+				from invoice_item ai
+				join sale s on s.sale_code = ai.document_ref and s.customer_code = ar.party_code
+				where ai.invoice_id = ar.id and ai.invoice_item = ci.document_ref_item and `+openSaleCondition+`)) is_open
+		from invoice c
+		join invoice_item ci on ci.invoice_id = c.id
+		left join invoice ar on ar.invoice_code = ci.document_ref and ar.invoice_type = 'AR'
+		where c.invoice_type in ('CN', 'DN') and c.invoice_code in ?`,
+		activeInvoiceStatuses, activeInvoiceStatuses, models.SaleOpenStatuses, invoiceCodes).Scan(&adjustmentRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get CN and DN: %v", err)
+	}
+
+	// A CN or DN counts for the items it has on invoices of open sales, under the customer of those invoices
+	type adjustment struct {
+		invoiceType  string
+		customerCode string
+		amount       float64
+		paid         float64
+		isOpen       bool
+	}
+	order := []string{}
+	adjustments := map[string]*adjustment{}
+	for _, row := range adjustmentRows {
+		a, ok := adjustments[row.InvoiceCode]
+		if !ok {
+			a = &adjustment{invoiceType: row.InvoiceType, customerCode: row.PartyCode, paid: row.Paid}
+			adjustments[row.InvoiceCode] = a
+			order = append(order, row.InvoiceCode)
+		}
+		if row.ArPartyCode != "" {
+			a.customerCode = row.ArPartyCode
+		}
+		if row.IsOpen {
+			a.amount += row.Amount
+			a.isOpen = true
+		}
+	}
+	for _, invoiceCode := range order {
+		a := adjustments[invoiceCode]
+		switch a.invoiceType {
+		case "CN":
+			used(a.customerCode, models.CreditSourceCreditNote, invoiceCode, -a.amount)
+		case "DN":
+			used(a.customerCode, models.CreditSourceDebitNote, invoiceCode, a.amount)
+			paid := 0.0
+			if a.isOpen {
+				paid = a.paid
+			}
+			used(a.customerCode, models.CreditSourceDebitNotePayment, invoiceCode, -paid)
+		}
+	}
+	return postings, nil
+}
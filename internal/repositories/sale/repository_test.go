@@ -0,0 +1,20 @@
+package saleRepository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildStatusFilterConditions_ApprovedSales(t *testing.T) {
+	approved := buildStatusFilterConditions([]string{"approved"})
+	assert.Contains(t, approved, "sale.status IN ('PENDING', 'APPROVED')")
+	assert.Contains(t, approved, "sale.status_approve = 'COMPLETED'")
+
+	partial := buildStatusFilterConditions([]string{"partial"})
+	assert.Contains(t, partial, "sale.status = 'PARTIAL_DELIVERED'")
+	assert.Contains(t, partial, "'APPROVED'")
+
+	assert.Equal(t, " AND (sale.status = 'DELIVERED')", buildStatusFilterConditions([]string{"delivered"}))
+	assert.Empty(t, buildStatusFilterConditions(nil))
+}
@@ -0,0 +1,22 @@
+package routes
+
+// Action codes checked against the authorization service before a route runs.
+const (
+	ActionPriceEdit            = "PRICE_EDIT"
+	ActionPriceDelete          = "PRICE_DELETE"
+	ActionQuotationEdit        = "QUOTATION_EDIT"
+	ActionQuotationApprove     = "QUOTATION_APPROVE"
+	ActionSaleEdit             = "SALE_EDIT"
+	ActionSaleApprove          = "SALE_APPROVE"
+	ActionDeliveryEdit         = "DELIVERY_EDIT"
+	ActionInvoiceEdit          = "INVOICE_EDIT"
+	ActionPaymentEdit          = "PAYMENT_EDIT"
+	ActionDepositEdit          = "DEPOSIT_EDIT"
+	ActionCreditEdit           = "CREDIT_EDIT"
+	ActionApprove              = "APPROVE"
+	ActionPurchaseEdit         = "PO_EDIT"
+	ActionPurchaseApprove      = "PO_APPROVE"
+	ActionNumberRangeEdit      = "NUMBER_RANGE_EDIT"
+	ActionApprovalTemplateEdit = "APPROVAL_TEMPLATE_EDIT"
+	ActionOutboxReplay         = "OUTBOX_REPLAY"
+)
@@ -1,356 +1,503 @@
-package routes
-
-import (
-	"prime-erp-core/internal/utils"
-
-	approvalService "prime-erp-core/internal/services/approval-service"
-	creditService "prime-erp-core/internal/services/credit-service"
-	CronjobService "prime-erp-core/internal/services/cronjob-service"
-	depositService "prime-erp-core/internal/services/deposit-service"
-	emailservice "prime-erp-core/internal/services/email-service"
-	groupService "prime-erp-core/internal/services/group-service"
-	invoiceService "prime-erp-core/internal/services/invoice-service"
-	paymentService "prime-erp-core/internal/services/payment-service"
-	prePurchaseService "prime-erp-core/internal/services/pre-purchase-service"
-	priceService "prime-erp-core/internal/services/price-service"
-	purchaseService "prime-erp-core/internal/services/purchase-service"
-
-	deliveryService "prime-erp-core/internal/services/delivery-service"
-	quotationService "prime-erp-core/internal/services/quotation-service"
-	saleService "prime-erp-core/internal/services/sale-service"
-	summaryService "prime-erp-core/internal/services/summary-credit"
-	timeService "prime-erp-core/internal/services/time-service"
-	unitService "prime-erp-core/internal/services/unit-service"
-	verifyService "prime-erp-core/internal/services/verify-service"
-
-	"github.com/gin-gonic/gin"
-)
-
-func RegisterRoutes(ctx *gin.Engine) {
-	//group
-	group := ctx.Group("/group")
-
-	group.POST("/GetGroupMaster", func(c *gin.Context) {
-		utils.ProcessRequest(c, groupService.GetGroup)
-	})
-
-	//price
-	price := ctx.Group("/price")
-
-	price.POST("/GetPriceListGroup", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.GetPriceListGroup)
-	})
-	price.POST("/GetPaymentTerm", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.GetPaymentTerm)
-	})
-	price.POST("/GetComparePrice", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.GetComparePrice)
-	})
-	price.POST("/GetPriceList", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.GetPriceList)
-	}) // for Base Price and price list feature
-	price.POST("/CreatePriceListGroupBase", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.CreatePriceListBase)
-	})
-	price.POST("/UpdatePriceListGroupBase", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.UpdatePriceListBase)
-	})
-	price.POST("/UpdatePriceListSubGroup", func(c *gin.Context) {
-		utils.ProcessRequestWithBinding(c, priceService.UpdatePriceListSubGroup)
-	})
-	price.POST("/DeletePriceListGroupBase", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.DeletePriceListBase)
-	})
-	price.POST("/GetPriceDetail", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.GetPriceDetail)
-	})
-	price.POST("/GetPriceExportTable", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.GetPriceExportTable)
-	})
-	price.POST("/SubGroup/UpdateLatest", func(c *gin.Context) {
-		utils.ProcessRequestWithBinding(c, priceService.UpdateLatestPriceListSubGroup)
-	})
-	price.POST("/SubGroup/GetCalculated", func(c *gin.Context) {
-		utils.ProcessRequestWithBinding(c, priceService.GetCalculatedPriceListSubGroup)
-	})
-	price.POST("/UpdatePriceListExtra", func(c *gin.Context) {
-		utils.ProcessRequest(c, priceService.UpdateExtras)
-	})
-	price.POST("/UploadPriceList", func(c *gin.Context) {
-		utils.ProcessRequestMultiPart(c, priceService.UploadPricelistMultipart)
-	})
-	// config extra get[3] create[2] update delete
-	// extra create update delete [4]
-
-	//quotation
-	quotation := ctx.Group("/quotation")
-
-	quotation.POST("/GetQuotation", func(c *gin.Context) {
-		utils.ProcessRequest(c, quotationService.GetQuotation)
-	})
-	quotation.POST("/CreateQuotation", func(c *gin.Context) {
-		utils.ProcessRequest(c, quotationService.CreateQuotation)
-	})
-	quotation.POST("/UpdateQuotation", func(c *gin.Context) {
-		utils.ProcessRequest(c, quotationService.UpdateQuotation)
-	})
-	quotation.POST("/EditQuotation", func(c *gin.Context) {
-		utils.ProcessRequest(c, quotationService.EditQuotation)
-	})
-	quotation.POST("/CancelQuotation", func(c *gin.Context) {
-		utils.ProcessRequest(c, quotationService.CancelQuotation)
-	})
-
-	quotation.POST("/RequestApproveQuotation", func(c *gin.Context) {
-		utils.ProcessRequest(c, quotationService.RequestApproveQuotation)
-	})
-	quotation.POST("/UpdateStatusApproveQuotation", func(c *gin.Context) {
-		utils.ProcessRequest(c, quotationService.UpdateStatusApproveQuotation)
-	})
-	//invoice
-	invoice := ctx.Group("/invoice")
-	invoice.POST("/GetInvoice", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.GetInvoice)
-	})
-	invoice.POST("/CreateInvoice", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.CreateInvoice)
-	})
-	invoice.POST("/UpdateInvoice", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.UpdateInvoice)
-	})
-	invoice.POST("/CreateInvoiceAP", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.CreateInvoiceAP)
-	})
-	invoice.POST("/UpdateInvoiceAP", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.UpdateInvoiceAP)
-	})
-	invoice.POST("/CreateInvoiceAR", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.CreateInvoiceAR)
-	})
-	invoice.POST("/UpdateInvoiceAR", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.UpdateInvoiceAR)
-	})
-	invoice.POST("/CreateInvoiceCN", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.CreateInvoiceCN)
-	})
-	invoice.POST("/UpdateInvoiceCN", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.UpdateInvoiceCN)
-	})
-	invoice.POST("/CreateInvoiceDN", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.CreateInvoiceDN)
-	})
-	invoice.POST("/UpdateInvoiceDN", func(c *gin.Context) {
-		utils.ProcessRequest(c, invoiceService.UpdateInvoiceDN)
-	})
-	//payment
-	payment := ctx.Group("/payment")
-	payment.POST("/GetPayment", func(c *gin.Context) {
-		utils.ProcessRequest(c, paymentService.GetPayment)
-	})
-	payment.POST("/CreatePayment", func(c *gin.Context) {
-		utils.ProcessRequest(c, paymentService.CreatePayment)
-	})
-	payment.POST("/DeletePayment", func(c *gin.Context) {
-		utils.ProcessRequest(c, paymentService.DeletePayment)
-	})
-
-	//sale
-	sale := ctx.Group("/sale")
-	sale.POST("/CreateSale", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.CreateSale)
-	})
-	sale.POST("/UpdateSaleStatusPayment", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.UpdateSaleStatusPayment)
-	})
-	sale.POST("/UpdateStatusSale", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.UpdateStatusSale)
-	})
-
-	sale.POST("/EditSale", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.EditSale)
-	})
-	sale.POST("/GetSale", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.GetSale)
-	})
-	sale.POST("/UpdateSale", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.UpdateSale)
-	})
-	sale.POST("/RequestApproveSale", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.RequestApproveSale)
-	})
-	sale.POST("/UpdateStatusApproveSale", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.UpdateStatusApproveSale)
-	})
-
-	sale.POST("/GetSalePack", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.GetSalePack)
-	})
-
-	sale.POST("/ValidateSaleOrder", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.ValidateSale)
-	})
-
-	sale.POST("/UpdateSaleItemStatus", func(c *gin.Context) {
-		utils.ProcessRequest(c, saleService.UpdateSaleItemStatus)
-	})
-	//delivery
-	delivery := ctx.Group("/delivery")
-	delivery.POST("/CreateDelivery", func(c *gin.Context) {
-		utils.ProcessRequest(c, deliveryService.CreateDelivery)
-	})
-	delivery.POST("/GetDelivery", func(c *gin.Context) {
-		utils.ProcessRequest(c, deliveryService.GetDelivery)
-	})
-	delivery.POST("/UpdateDelivery", func(c *gin.Context) {
-		utils.ProcessRequest(c, deliveryService.UpdateDelivery)
-	})
-	delivery.POST("/UpdateStatusDelivery", func(c *gin.Context) {
-		utils.ProcessRequest(c, deliveryService.UpdateStatusDelivery)
-	})
-	delivery.POST("/GetDeliveryCO", func(c *gin.Context) {
-		utils.ProcessRequest(c, deliveryService.GetDeliveryCO)
-	})
-	/* 	delivery.POST("/GetDeliverySO", func(c *gin.Context) {
-	   		utils.ProcessRequest(c, deliveryService.GetDeliverySO)
-	   	})
-	*/
-	//time
-	time := ctx.Group("/time")
-	time.POST("/GetTime", func(c *gin.Context) {
-		utils.ProcessRequest(c, timeService.GetTime)
-	})
-	//deposit
-	deposit := ctx.Group("/deposit")
-	deposit.POST("/GetDeposit", func(c *gin.Context) {
-		utils.ProcessRequest(c, depositService.GetDeposit)
-	})
-	deposit.POST("/CreateDepost", func(c *gin.Context) {
-		utils.ProcessRequest(c, depositService.CreateDepost)
-	})
-
-	//approval
-	approval := ctx.Group("/approval")
-	approval.POST("/VerifyApprove", func(c *gin.Context) {
-		utils.ProcessRequest(c, verifyService.VerifyApprove)
-	})
-	approval.POST("/GetApproval", func(c *gin.Context) {
-		utils.ProcessRequest(c, approvalService.GetApproval)
-	})
-	approval.POST("/CreateApproval", func(c *gin.Context) {
-		utils.ProcessRequest(c, approvalService.CreateApproval)
-	})
-	approval.POST("/UpdateApproval", func(c *gin.Context) {
-		utils.ProcessRequest(c, approvalService.UpdateApproval)
-	})
-	//credit
-	credit := ctx.Group("/credit")
-	credit.POST("/GetCreditCurrent", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetCreditCurrentAPI)
-	})
-	credit.POST("/GetCreditRequest", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetCreditRequests)
-	})
-	credit.POST("/GetCreditRequestCronjob", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetCreditRequestCronjob)
-	})
-	credit.POST("/GetCustomerCredit", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetCustomerCreditRest)
-	})
-
-	credit.POST("/CreateCreditRequest", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.CreateCreditRequest)
-	})
-	credit.POST("/UpdateCreditRequest", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.UpdateCreditRequest)
-	})
-	credit.POST("/GetCredit", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetCredit)
-	})
-	credit.POST("/CreateCredit", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.CreateCredit)
-	})
-	credit.POST("/GetHistory", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetHistory)
-	})
-	credit.POST("/GetSummaryCredit", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetSummaryCredit)
-	})
-	credit.POST("/GetTransaction", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.GetTransaction)
-	})
-	credit.POST("/CreateCreditTransaction", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.CreateCreditTransaction)
-	})
-	credit.POST("/DeleteCreditExtra", func(c *gin.Context) {
-		utils.ProcessRequest(c, creditService.DeleteCreditExtra)
-	})
-
-	//summaryService
-	summary := ctx.Group("/summary")
-	summary.POST("/GetConsumend", func(c *gin.Context) {
-		utils.ProcessRequest(c, summaryService.GetConsumend)
-	})
-
-	summary.POST("/GetOutStandingSo", func(c *gin.Context) {
-		utils.ProcessRequest(c, summaryService.GetOutStandingSo)
-	})
-
-	//unit
-	unit := ctx.Group("/unit")
-	unit.POST("/GetAllUnit", func(c *gin.Context) {
-		utils.ProcessRequest(c, unitService.GetAllUnit)
-	})
-
-	purchase := ctx.Group("/purchase")
-	//pre-purchase
-	purchase.POST("/CreatePOBigLot", func(c *gin.Context) {
-		utils.ProcessRequest(c, prePurchaseService.CreatePOBigLot)
-	})
-	purchase.POST("/GetPOBigLot", func(c *gin.Context) {
-		utils.ProcessRequest(c, prePurchaseService.GetPOBigLot)
-	})
-	purchase.POST("/UpdatePOBigLot", func(c *gin.Context) {
-		utils.ProcessRequest(c, prePurchaseService.UpdatePOBigLot)
-	})
-	purchase.POST("/UpdateStatusApprovePOBigLot", func(c *gin.Context) {
-		utils.ProcessRequest(c, prePurchaseService.UpdateStatusApprovePOBigLot)
-	})
-
-	//purchase
-	purchase.POST("/CreatePO", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.CreatePO)
-	})
-	purchase.POST("/GetPO", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.GetPO)
-	})
-	purchase.POST("/GetPOItemForGR", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.GetPOItem)
-	})
-	purchase.POST("/UpdatePO", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.UpdatePO)
-	})
-	purchase.POST("/UpdateStatusApprovePO", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.UpdateStatusApprovePO)
-	})
-	purchase.POST("/CompleteStatusPaymentPO", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.CompleteStatusPaymentPO)
-	})
-	purchase.POST("/CompletePO", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.CompletePO)
-	})
-	purchase.POST("/CompletePOItem", func(c *gin.Context) {
-		utils.ProcessRequest(c, purchaseService.CompletePOItem)
-	})
-
-	///cronjob
-	cronjob := ctx.Group("/cronjob")
-	cronjob.POST("/credit-request", func(c *gin.Context) {
-		utils.ProcessRequest(c, CronjobService.GetKernalManual)
-	})
-	//email alert
-	emailAlert := ctx.Group("/emailAlert")
-	emailAlert.POST("/SendEmailAlertForNewBrand", func(c *gin.Context) {
-		utils.ProcessRequest(c, emailservice.SendEmailAlertForNewBrand)
-	})
-
-}
+package routes
+
+import (
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/utils"
+
+	approvalService "prime-erp-core/internal/services/approval-service"
+	creditService "prime-erp-core/internal/services/credit-service"
+	CronjobService "prime-erp-core/internal/services/cronjob-service"
+	depositService "prime-erp-core/internal/services/deposit-service"
+	emailservice "prime-erp-core/internal/services/email-service"
+	groupService "prime-erp-core/internal/services/group-service"
+	invoiceService "prime-erp-core/internal/services/invoice-service"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+	paymentService "prime-erp-core/internal/services/payment-service"
+	prePurchaseService "prime-erp-core/internal/services/pre-purchase-service"
+	priceService "prime-erp-core/internal/services/price-service"
+	purchaseService "prime-erp-core/internal/services/purchase-service"
+
+	deliveryService "prime-erp-core/internal/services/delivery-service"
+	quotationService "prime-erp-core/internal/services/quotation-service"
+	saleService "prime-erp-core/internal/services/sale-service"
+	summaryService "prime-erp-core/internal/services/summary-credit"
+	systemConfigService "prime-erp-core/internal/services/system-config"
+	timeService "prime-erp-core/internal/services/time-service"
+	unitService "prime-erp-core/internal/services/unit-service"
+	verifyService "prime-erp-core/internal/services/verify-service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(ctx *gin.Engine) {
+	//group
+	group := ctx.Group("/group")
+
+	group.POST("/GetGroupMaster", func(c *gin.Context) {
+		utils.ProcessRequest(c, groupService.GetGroup)
+	})
+
+	//price
+	price := ctx.Group("/price")
+
+	price.POST("/GetPriceListGroup", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPriceListGroup)
+	})
+	price.POST("/GetPaymentTerm", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPaymentTerm)
+	})
+	price.POST("/GetComparePrice", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetComparePrice)
+	})
+	price.POST("/GetPriceList", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPriceList)
+	}) // for Base Price and price list feature
+	price.POST("/CreatePriceListGroupBase", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.CreatePriceListBase)
+	})
+	price.POST("/UpdatePriceListGroupBase", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.UpdatePriceListBase)
+	})
+	price.POST("/UpdatePriceListSubGroup", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequestWithBinding(c, priceService.UpdatePriceListSubGroup)
+	})
+	price.POST("/DeletePriceListGroupBase", middleware.RequirePermission(ActionPriceDelete), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.DeletePriceListBase)
+	})
+	price.POST("/GetPriceDetail", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPriceDetail)
+	})
+	price.POST("/GetPriceExportTable", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPriceExportTable)
+	})
+	price.POST("/SubGroup/UpdateLatest", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequestWithBinding(c, priceService.UpdateLatestPriceListSubGroup)
+	})
+	price.POST("/SubGroup/GetCalculated", func(c *gin.Context) {
+		utils.ProcessRequestWithBinding(c, priceService.GetCalculatedPriceListSubGroup)
+	})
+	price.POST("/UpdatePriceListExtra", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.UpdateExtras)
+	})
+	price.POST("/UploadPriceList", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequestMultiPart(c, priceService.UploadPricelistMultipart)
+	})
+	price.POST("/DownloadTemplate", func(c *gin.Context) {
+		utils.ProcessRequestFile(c, priceService.DownloadTemplate)
+	})
+	price.POST("/ExportWorkbook", func(c *gin.Context) {
+		utils.ProcessRequestFile(c, priceService.ExportWorkbook)
+	})
+	price.POST("/GetPriceVersions", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPriceVersions)
+	})
+	price.POST("/CancelPriceVersions", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.CancelPriceVersions)
+	})
+	price.POST("/Formula/Test", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.TestFormula)
+	})
+	price.POST("/Formula/Validate", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.ValidateFormulas)
+	})
+	price.POST("/Formula/Get", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetFormulas)
+	})
+	price.POST("/Formula/GetVersions", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetFormulaVersions)
+	})
+	price.POST("/Formula/Create", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.CreateFormula)
+	})
+	price.POST("/Formula/Update", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.UpdateFormula)
+	})
+	price.POST("/Formula/Deprecate", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.DeprecateFormulas)
+	})
+	price.POST("/Formula/Assign", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.AssignFormula)
+	})
+	price.POST("/Agreement/Get", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPriceAgreements)
+	})
+	price.POST("/Agreement/Create", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.CreatePriceAgreement)
+	})
+	price.POST("/Agreement/Cancel", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.CancelPriceAgreements)
+	})
+	price.POST("/ImpactAnalysis", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.ImpactAnalysis)
+	})
+	price.POST("/Tier/Get", func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.GetPriceTiers)
+	})
+	price.POST("/Tier/Save", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.SavePriceTiers)
+	})
+	// config extra get[3] create[2] update delete
+	// extra create update delete [4]
+
+	//quotation
+	quotation := ctx.Group("/quotation")
+
+	quotation.POST("/GetQuotation", func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.GetQuotation)
+	})
+	quotation.POST("/CreateQuotation", middleware.RequirePermission(ActionQuotationEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.CreateQuotation)
+	})
+	quotation.POST("/UpdateQuotation", middleware.RequirePermission(ActionQuotationEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.UpdateQuotation)
+	})
+	quotation.POST("/EditQuotation", middleware.RequirePermission(ActionQuotationEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.EditQuotation)
+	})
+	quotation.POST("/CancelQuotation", middleware.RequirePermission(ActionQuotationEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.CancelQuotation)
+	})
+
+	quotation.POST("/RequestApproveQuotation", middleware.RequirePermission(ActionQuotationEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.RequestApproveQuotation)
+	})
+	quotation.POST("/UpdateStatusApproveQuotation", middleware.RequirePermission(ActionQuotationApprove), func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.UpdateStatusApproveQuotation)
+	})
+	quotation.POST("/ConvertToSale", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.ConvertToSale)
+	})
+	quotation.POST("/GetRevisions", func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.GetRevisions)
+	})
+	quotation.POST("/DiffRevisions", func(c *gin.Context) {
+		utils.ProcessRequest(c, quotationService.DiffRevisions)
+	})
+	//invoice
+	invoice := ctx.Group("/invoice")
+	invoice.POST("/GetInvoice", func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.GetInvoice)
+	})
+	invoice.POST("/GetARAging", func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.GetARAging)
+	})
+	invoice.POST("/ExportARAging", func(c *gin.Context) {
+		utils.ProcessRequestFile(c, invoiceService.ExportARAging)
+	})
+	invoice.POST("/CreateInvoice", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.CreateInvoice)
+	})
+	invoice.POST("/UpdateInvoice", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.UpdateInvoice)
+	})
+	invoice.POST("/CreateInvoiceAP", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.CreateInvoiceAP)
+	})
+	invoice.POST("/UpdateInvoiceAP", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.UpdateInvoiceAP)
+	})
+	invoice.POST("/CreateInvoiceAR", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.CreateInvoiceAR)
+	})
+	invoice.POST("/UpdateInvoiceAR", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.UpdateInvoiceAR)
+	})
+	invoice.POST("/CreateInvoiceCN", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.CreateInvoiceCN)
+	})
+	invoice.POST("/UpdateInvoiceCN", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.UpdateInvoiceCN)
+	})
+	invoice.POST("/CreateInvoiceDN", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.CreateInvoiceDN)
+	})
+	invoice.POST("/UpdateInvoiceDN", middleware.RequirePermission(ActionInvoiceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, invoiceService.UpdateInvoiceDN)
+	})
+	//payment
+	payment := ctx.Group("/payment")
+	payment.POST("/GetPayment", func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.GetPayment)
+	})
+	payment.POST("/CreatePayment", middleware.RequirePermission(ActionPaymentEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.CreatePayment)
+	})
+	payment.POST("/DeletePayment", middleware.RequirePermission(ActionPaymentEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.DeletePayment)
+	})
+	payment.POST("/AllocatePayment", middleware.RequirePermission(ActionPaymentEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.AllocatePayment)
+	})
+	payment.POST("/GetUnappliedCredit", func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.GetUnappliedCredit)
+	})
+	payment.POST("/ImportBankStatement", middleware.RequirePermission(ActionPaymentEdit), func(c *gin.Context) {
+		utils.ProcessRequestMultiPart(c, paymentService.ImportBankStatement)
+	})
+	payment.POST("/GetBankStatementLines", func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.GetBankStatementLines)
+	})
+	payment.POST("/MatchBankStatementLines", middleware.RequirePermission(ActionPaymentEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.MatchBankStatementLines)
+	})
+	payment.POST("/ConfirmBankStatementLines", middleware.RequirePermission(ActionPaymentEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.ConfirmBankStatementLines)
+	})
+	payment.POST("/IgnoreBankStatementLines", middleware.RequirePermission(ActionPaymentEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, paymentService.IgnoreBankStatementLines)
+	})
+
+	//sale
+	sale := ctx.Group("/sale")
+	sale.POST("/CreateSale", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.CreateSale)
+	})
+	sale.POST("/UpdateSaleStatusPayment", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.UpdateSaleStatusPayment)
+	})
+	sale.POST("/UpdateStatusSale", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.UpdateStatusSale)
+	})
+
+	sale.POST("/EditSale", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.EditSale)
+	})
+	sale.POST("/GetSale", func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.GetSale)
+	})
+	sale.POST("/UpdateSale", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.UpdateSale)
+	})
+	sale.POST("/RequestApproveSale", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.RequestApproveSale)
+	})
+	sale.POST("/UpdateStatusApproveSale", middleware.RequirePermission(ActionSaleApprove), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.UpdateStatusApproveSale)
+	})
+
+	sale.POST("/GetSalePack", func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.GetSalePack)
+	})
+
+	sale.POST("/ValidateSaleOrder", func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.ValidateSale)
+	})
+
+	sale.POST("/UpdateSaleItemStatus", middleware.RequirePermission(ActionSaleEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.UpdateSaleItemStatus)
+	})
+	sale.POST("/GetStatusHistory", func(c *gin.Context) {
+		utils.ProcessRequest(c, saleService.GetSaleStatusHistory)
+	})
+	//delivery
+	delivery := ctx.Group("/delivery")
+	delivery.POST("/CreateDelivery", middleware.RequirePermission(ActionDeliveryEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, deliveryService.CreateDelivery)
+	})
+	delivery.POST("/GetDelivery", func(c *gin.Context) {
+		utils.ProcessRequest(c, deliveryService.GetDelivery)
+	})
+	delivery.POST("/UpdateDelivery", middleware.RequirePermission(ActionDeliveryEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, deliveryService.UpdateDelivery)
+	})
+	delivery.POST("/UpdateStatusDelivery", middleware.RequirePermission(ActionDeliveryEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, deliveryService.UpdateStatusDelivery)
+	})
+	delivery.POST("/GetDeliveryCO", func(c *gin.Context) {
+		utils.ProcessRequest(c, deliveryService.GetDeliveryCO)
+	})
+	/* 	delivery.POST("/GetDeliverySO", func(c *gin.Context) {
+	   		utils.ProcessRequest(c, deliveryService.GetDeliverySO)
+	   	})
+	*/
+	//time
+	time := ctx.Group("/time")
+	time.POST("/GetTime", func(c *gin.Context) {
+		utils.ProcessRequest(c, timeService.GetTime)
+	})
+	//deposit
+	deposit := ctx.Group("/deposit")
+	deposit.POST("/GetDeposit", func(c *gin.Context) {
+		utils.ProcessRequest(c, depositService.GetDeposit)
+	})
+	deposit.POST("/CreateDepost", middleware.RequirePermission(ActionDepositEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, depositService.CreateDepost)
+	})
+
+	//approval
+	approval := ctx.Group("/approval")
+	approval.POST("/VerifyApprove", func(c *gin.Context) {
+		utils.ProcessRequest(c, verifyService.VerifyApprove)
+	})
+	approval.POST("/GetApproval", func(c *gin.Context) {
+		utils.ProcessRequest(c, approvalService.GetApproval)
+	})
+	approval.POST("/CreateApproval", middleware.RequirePermission(ActionApprove), func(c *gin.Context) {
+		utils.ProcessRequest(c, approvalService.CreateApproval)
+	})
+	approval.POST("/UpdateApproval", middleware.RequirePermission(ActionApprove), func(c *gin.Context) {
+		utils.ProcessRequest(c, approvalService.UpdateApproval)
+	})
+	approval.POST("/GetTemplate", func(c *gin.Context) {
+		utils.ProcessRequest(c, approvalService.GetApprovalTemplate)
+	})
+	approval.POST("/SaveTemplate", middleware.RequirePermission(ActionApprovalTemplateEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, approvalService.SaveApprovalTemplate)
+	})
+	//credit
+	credit := ctx.Group("/credit")
+	credit.POST("/GetCreditCurrent", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetCreditCurrentAPI)
+	})
+	credit.POST("/GetCreditLedger", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetCreditLedger)
+	})
+	credit.POST("/GetHolds", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetHolds)
+	})
+	credit.POST("/SyncCreditLedger", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.SyncCreditLedgerAPI)
+	})
+	credit.POST("/ReconcileCreditLedger", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.ReconcileCreditLedger)
+	})
+	credit.POST("/GetCreditRequest", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetCreditRequests)
+	})
+	credit.POST("/GetCreditRequestCronjob", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetCreditRequestCronjob)
+	})
+	credit.POST("/GetCustomerCredit", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetCustomerCreditRest)
+	})
+
+	credit.POST("/CreateCreditRequest", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.CreateCreditRequest)
+	})
+	credit.POST("/UpdateCreditRequest", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.UpdateCreditRequest)
+	})
+	credit.POST("/GetCredit", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetCredit)
+	})
+	credit.POST("/CreateCredit", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.CreateCredit)
+	})
+	credit.POST("/GetHistory", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetHistory)
+	})
+	credit.POST("/GetSummaryCredit", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetSummaryCredit)
+	})
+	credit.POST("/GetTransaction", func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.GetTransaction)
+	})
+	credit.POST("/CreateCreditTransaction", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.CreateCreditTransaction)
+	})
+	credit.POST("/DeleteCreditExtra", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, creditService.DeleteCreditExtra)
+	})
+
+	//summaryService
+	summary := ctx.Group("/summary")
+	summary.POST("/GetConsumend", func(c *gin.Context) {
+		utils.ProcessRequest(c, summaryService.GetConsumend)
+	})
+
+	summary.POST("/GetOutStandingSo", func(c *gin.Context) {
+		utils.ProcessRequest(c, summaryService.GetOutStandingSo)
+	})
+
+	//unit
+	unit := ctx.Group("/unit")
+	unit.POST("/GetAllUnit", func(c *gin.Context) {
+		utils.ProcessRequest(c, unitService.GetAllUnit)
+	})
+
+	purchase := ctx.Group("/purchase")
+	//pre-purchase
+	purchase.POST("/CreatePOBigLot", middleware.RequirePermission(ActionPurchaseEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, prePurchaseService.CreatePOBigLot)
+	})
+	purchase.POST("/GetPOBigLot", func(c *gin.Context) {
+		utils.ProcessRequest(c, prePurchaseService.GetPOBigLot)
+	})
+	purchase.POST("/UpdatePOBigLot", middleware.RequirePermission(ActionPurchaseEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, prePurchaseService.UpdatePOBigLot)
+	})
+	purchase.POST("/UpdateStatusApprovePOBigLot", middleware.RequirePermission(ActionPurchaseApprove), func(c *gin.Context) {
+		utils.ProcessRequest(c, prePurchaseService.UpdateStatusApprovePOBigLot)
+	})
+
+	//purchase
+	purchase.POST("/CreatePO", middleware.RequirePermission(ActionPurchaseEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.CreatePO)
+	})
+	purchase.POST("/GetPO", func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.GetPO)
+	})
+	purchase.POST("/GetPOItemForGR", func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.GetPOItem)
+	})
+	purchase.POST("/UpdatePO", middleware.RequirePermission(ActionPurchaseEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.UpdatePO)
+	})
+	purchase.POST("/UpdateStatusApprovePO", middleware.RequirePermission(ActionPurchaseApprove), func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.UpdateStatusApprovePO)
+	})
+	purchase.POST("/CompleteStatusPaymentPO", middleware.RequirePermission(ActionPurchaseEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.CompleteStatusPaymentPO)
+	})
+	purchase.POST("/CompletePO", middleware.RequirePermission(ActionPurchaseEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.CompletePO)
+	})
+	purchase.POST("/CompletePOItem", middleware.RequirePermission(ActionPurchaseEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, purchaseService.CompletePOItem)
+	})
+
+	///cronjob
+	cronjob := ctx.Group("/cronjob")
+	cronjob.POST("/credit-request", middleware.RequirePermission(ActionCreditEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, CronjobService.GetKernalManual)
+	})
+	cronjob.POST("/approval-escalation", middleware.RequirePermission(ActionApprove), func(c *gin.Context) {
+		utils.ProcessRequest(c, approvalService.EscalateApprovals)
+	})
+	cronjob.POST("/outbox-dispatch", middleware.RequirePermission(ActionOutboxReplay), func(c *gin.Context) {
+		utils.ProcessRequest(c, outboxService.DispatchOutbox)
+	})
+	cronjob.POST("/price-version-activation", middleware.RequirePermission(ActionPriceEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, priceService.ActivatePriceVersions)
+	})
+
+	//outbox
+	outbox := ctx.Group("/outbox")
+	outbox.POST("/GetDeadLetters", func(c *gin.Context) {
+		utils.ProcessRequest(c, outboxService.GetDeadLetters)
+	})
+	outbox.POST("/Replay", middleware.RequirePermission(ActionOutboxReplay), func(c *gin.Context) {
+		utils.ProcessRequest(c, outboxService.ReplayDeadLetters)
+	})
+	//system
+	system := ctx.Group("/system")
+	system.GET("/Health", db.HealthHandler)
+	system.GET("/DBPoolStats", db.PoolStatsHandler)
+
+	//number range
+	numberRange := ctx.Group("/numberRange")
+	numberRange.POST("/GetGaps", func(c *gin.Context) {
+		utils.ProcessRequest(c, systemConfigService.GetNumberRangeGaps)
+	})
+	numberRange.POST("/VoidNumbers", middleware.RequirePermission(ActionNumberRangeEdit), func(c *gin.Context) {
+		utils.ProcessRequest(c, systemConfigService.VoidNumbers)
+	})
+
+	//email alert
+	emailAlert := ctx.Group("/emailAlert")
+	emailAlert.POST("/SendEmailAlertForNewBrand", func(c *gin.Context) {
+		utils.ProcessRequest(c, emailservice.SendEmailAlertForNewBrand)
+	})
+
+}
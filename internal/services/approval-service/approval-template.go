@@ -0,0 +1,123 @@
+package approvalService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	models "prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GetApprovalTemplateRequest struct {
+	ApproveTopic []string `json:"approve_topic"`
+}
+
+type SaveApprovalTemplateRequest struct {
+	ApproveTopic string                    `json:"approve_topic"`
+	Steps        []models.ApprovalTemplate `json:"steps"`
+}
+
+func GetApprovalTemplate(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req GetApprovalTemplateRequest
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Model(&models.ApprovalTemplate{})
+	if len(req.ApproveTopic) > 0 {
+		query = query.Where("approve_topic IN ?", req.ApproveTopic)
+	}
+
+	templates := []models.ApprovalTemplate{}
+	if err := query.Order("approve_topic, step_seq").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get approval templates: %v", err)
+	}
+
+	return templates, nil
+}
+
+// SaveApprovalTemplate replaces the workflow steps of an approve topic. Approvals already created keep the steps they were created with.
+func SaveApprovalTemplate(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req SaveApprovalTemplateRequest
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	if req.ApproveTopic == "" {
+		return nil, errors.New("approve_topic is required")
+	}
+
+	user := middleware.GetUserCode(ctx)
+	seenSteps := map[int]bool{}
+	for i := range req.Steps {
+		step := &req.Steps[i]
+		if step.StepSeq <= 0 || seenSteps[step.StepSeq] {
+			return nil, fmt.Errorf("step_seq %d must be positive and unique", step.StepSeq)
+		}
+		seenSteps[step.StepSeq] = true
+
+		switch step.ApproveMode {
+		case "":
+			step.ApproveMode = models.ApproveModeAny
+		case models.ApproveModeAny, models.ApproveModeAll:
+		default:
+			return nil, fmt.Errorf("step %d: approve_mode must be %s or %s", step.StepSeq, models.ApproveModeAny, models.ApproveModeAll)
+		}
+
+		if step.Condition != "" {
+			if _, err := compileApprovalCondition(step.Condition, map[string]interface{}{}); err != nil {
+				return nil, fmt.Errorf("step %d: invalid condition: %v", step.StepSeq, err)
+			}
+		}
+		if len(step.UserCodes) > 0 {
+			userCodes := []string{}
+			if err := json.Unmarshal(step.UserCodes, &userCodes); err != nil {
+				return nil, fmt.Errorf("step %d: user_codes must be a list of user codes", step.StepSeq)
+			}
+		}
+
+		step.ID = uuid.New()
+		step.ApproveTopic = req.ApproveTopic
+		step.CreateBy = user
+		step.UpdateBy = user
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("approve_topic = ?", req.ApproveTopic).Delete(&models.ApprovalTemplate{}).Error; err != nil {
+			return fmt.Errorf("failed to delete approval templates: %v", err)
+		}
+		if len(req.Steps) > 0 {
+			if err := tx.Create(&req.Steps).Error; err != nil {
+				return fmt.Errorf("failed to create approval templates: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "success",
+		"message": "Approval template saved successfully",
+	}, nil
+}
@@ -1,96 +1,143 @@
-package approvalService
-
-import (
-	"encoding/json"
-	"errors"
-	models "prime-erp-core/internal/models"
-	repositoryApproval "prime-erp-core/internal/repositories/approval"
-	authenticationService "prime-erp-core/internal/services/authentication-service"
-	"time"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-func CreateApproval(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	var req []models.Approval
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-
-	approvalValue := []models.Approval{}
-	approvalItemValue := []models.ApprovalItem{}
-	approvalItemPermissionValue := []models.ApprovalItemPermission{}
-	approvalIDForReturn := []uuid.UUID{}
-	mdiItemCode := []string{}
-
-	for _, approval := range req {
-		mdiItemCode = append(mdiItemCode, approval.MDItemCode)
-	}
-
-	requestData := map[string]interface{}{
-		"md_item_code": mdiItemCode,
-		"action_code":  []string{"APPROVE"},
-	}
-	requester, errGetRequester := authenticationService.GetRequester(requestData)
-	if errGetRequester != nil {
-		return nil, errGetRequester
-	}
-
-	for i, approval := range req {
-
-		approvalID := uuid.New()
-		req[i].ID = approvalID
-		approvalIDForReturn = append(approvalIDForReturn, approvalID)
-
-		approvalItemID := uuid.New()
-		newApprovalItem := models.ApprovalItem{
-			ID:                     approvalItemID,
-			ApprovalID:             approvalID,
-			StepSeq:                1,
-			IsCondition:            false,
-			Condition:              nil,
-			Status:                 "PENDING",
-			ActionBy:               req[i].CreateBy,
-			ActionDate:             time.Now(),
-			CreateBy:               req[i].CreateBy,
-			UpdateBy:               req[i].CreateBy,
-			ApprovalItemPermission: []models.ApprovalItemPermission{},
-		}
-
-		for _, requesterValue := range requester {
-			approvalItemPermissionID := uuid.New()
-			newApprovalItemPermission := models.ApprovalItemPermission{
-				ID:             approvalItemPermissionID,
-				ApprovalItemID: approvalItemID,
-				UserCode:       requesterValue.RequesterCode,
-			}
-			approvalItemPermissionValue = append(approvalItemPermissionValue, newApprovalItemPermission)
-		}
-		approvalItemValue = append(approvalItemValue, newApprovalItem)
-
-		if req[i].ApproveCode != "" {
-			req[i].ApproveCode = approval.ApproveCode
-		} else {
-			req[i].ApproveCode = uuid.New().String()
-		}
-		/* 	jsonDataApproval, _ := json.Marshal(req[i])
-		req[i].DocumentData = jsonDataApproval */
-		req[i].ApprovalItem = []models.ApprovalItem{}
-
-		approvalValue = append(approvalValue, req[i])
-	}
-
-	errCreateApproval := repositoryApproval.CreateApproval(approvalValue, approvalItemValue, approvalItemPermissionValue)
-	if errCreateApproval != nil {
-		return nil, errCreateApproval
-	}
-
-	return map[string]interface{}{
-		"id":      approvalIDForReturn,
-		"status":  "success",
-		"message": "Approval create successfully",
-	}, nil
-}
+package approvalService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	models "prime-erp-core/internal/models"
+	repositoryApproval "prime-erp-core/internal/repositories/approval"
+	authenticationService "prime-erp-core/internal/services/authentication-service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func CreateApproval(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req []models.Approval
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	approvalValue := []models.Approval{}
+	approvalItemValue := []models.ApprovalItem{}
+	approvalItemPermissionValue := []models.ApprovalItemPermission{}
+	approvalIDForReturn := []uuid.UUID{}
+	mdiItemCode := []string{}
+
+	for _, approval := range req {
+		mdiItemCode = append(mdiItemCode, approval.MDItemCode)
+	}
+
+	requestData := map[string]interface{}{
+		"md_item_code": mdiItemCode,
+		"action_code":  []string{"APPROVE"},
+	}
+	requester, errGetRequester := authenticationService.GetRequester(requestData)
+	if errGetRequester != nil {
+		return nil, errGetRequester
+	}
+
+	user := middleware.GetUserCode(ctx)
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	topics := []string{}
+	for _, approval := range req {
+		topics = append(topics, approval.ApproveTopic)
+	}
+	var templates []models.ApprovalTemplate
+	if err := gormx.Where("approve_topic IN ? AND is_active = ?", topics, true).Order("step_seq").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get approval templates: %v", err)
+	}
+	templatesByTopic := map[string][]models.ApprovalTemplate{}
+	for _, template := range templates {
+		templatesByTopic[template.ApproveTopic] = append(templatesByTopic[template.ApproveTopic], template)
+	}
+
+	// Steps name their approvers directly or through a menu's APPROVE permission; steps without either use the requesters above
+	approversByMenu := map[string][]string{}
+	approversFor := func(step models.ApprovalTemplate) ([]string, error) {
+		userCodes := []string{}
+		if len(step.UserCodes) > 0 {
+			if err := json.Unmarshal(step.UserCodes, &userCodes); err != nil {
+				return nil, fmt.Errorf("invalid approvers of %s step %d: %v", step.ApproveTopic, step.StepSeq, err)
+			}
+		}
+		if len(userCodes) > 0 {
+			return userCodes, nil
+		}
+
+		if step.ApproveTopic == "" || step.MDItemCode == "" {
+			for _, requesterValue := range requester {
+				userCodes = append(userCodes, requesterValue.RequesterCode)
+			}
+			return userCodes, nil
+		}
+
+		if cached, exists := approversByMenu[step.MDItemCode]; exists {
+			return cached, nil
+		}
+		menuRequester, err := authenticationService.GetRequester(map[string]interface{}{
+			"md_item_code": []string{step.MDItemCode},
+			"action_code":  []string{"APPROVE"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, requesterValue := range menuRequester {
+			userCodes = append(userCodes, requesterValue.RequesterCode)
+		}
+		approversByMenu[step.MDItemCode] = userCodes
+		return userCodes, nil
+	}
+
+	for i, approval := range req {
+
+		approvalID := uuid.New()
+		req[i].ID = approvalID
+		req[i].CreateBy = user
+		req[i].UpdateBy = user
+		approvalIDForReturn = append(approvalIDForReturn, approvalID)
+
+		steps, err := selectApprovalSteps(templatesByTopic[approval.ApproveTopic], approval.DocumentData)
+		if err != nil {
+			return nil, err
+		}
+		newApprovalItems, newApprovalItemPermissions, err := buildApprovalSteps(&req[i], steps, approversFor, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		approvalItemValue = append(approvalItemValue, newApprovalItems...)
+		approvalItemPermissionValue = append(approvalItemPermissionValue, newApprovalItemPermissions...)
+
+		if req[i].ApproveCode != "" {
+			req[i].ApproveCode = approval.ApproveCode
+		} else {
+			req[i].ApproveCode = uuid.New().String()
+		}
+		/* 	jsonDataApproval, _ := json.Marshal(req[i])
+		req[i].DocumentData = jsonDataApproval */
+		req[i].ApprovalItem = []models.ApprovalItem{}
+
+		approvalValue = append(approvalValue, req[i])
+	}
+
+	errCreateApproval := repositoryApproval.CreateApproval(approvalValue, approvalItemValue, approvalItemPermissionValue)
+	if errCreateApproval != nil {
+		return nil, errCreateApproval
+	}
+
+	return map[string]interface{}{
+		"id":      approvalIDForReturn,
+		"status":  "success",
+		"message": "Approval create successfully",
+	}, nil
+}
@@ -0,0 +1,361 @@
+package approvalService
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"prime-erp-core/internal/cronjob"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ApprovalSLATopic is the system_config topic holding one SLA row per approve topic.
+const ApprovalSLATopic = "APPROVAL"
+
+func init() {
+	cronjob.RegisterJob("approval-escalation", runApprovalEscalation, "*/15 * * * *")
+}
+
+// ApprovalRejectHandler moves a document to rejected when the system rejects its approval.
+type ApprovalRejectHandler func(tx *gorm.DB, approval models.Approval, remark string) error
+
+var (
+	rejectHandlersMu sync.RWMutex
+	rejectHandlers   = map[string]ApprovalRejectHandler{}
+)
+
+// RegisterRejectHandler lets a document service follow auto-rejected approvals of its approve topic.
+// Topics without a handler are never auto-rejected, since their documents would be left waiting on a closed approval.
+func RegisterRejectHandler(approveTopic string, handler ApprovalRejectHandler) {
+	rejectHandlersMu.Lock()
+	defer rejectHandlersMu.Unlock()
+
+	rejectHandlers[approveTopic] = handler
+}
+
+func rejectHandlerFor(approveTopic string) (ApprovalRejectHandler, bool) {
+	rejectHandlersMu.RLock()
+	defer rejectHandlersMu.RUnlock()
+
+	handler, exists := rejectHandlers[approveTopic]
+	return handler, exists
+}
+
+type approvalSLA struct {
+	ApproveTopic string
+	SlaHours     float64
+	models.ApprovalSLAConfigJSON
+}
+
+type overdueApprovalStep struct {
+	ApprovalID     uuid.UUID  `gorm:"column:approval_id"`
+	ApproveTopic   string     `gorm:"column:approve_topic"`
+	DocumentCode   string     `gorm:"column:document_code"`
+	ApprovalItemID uuid.UUID  `gorm:"column:approval_item_id"`
+	StepSeq        int        `gorm:"column:step_seq"`
+	PendingSince   time.Time  `gorm:"column:pending_since"`
+	Escalations    int        `gorm:"column:escalations"`
+	LastEscalation *time.Time `gorm:"column:last_escalation"`
+}
+
+type EscalateApprovalsResponse struct {
+	Escalated []string `json:"escalated"`
+	Rejected  []string `json:"rejected"`
+	Failed    []string `json:"failed"`
+}
+
+// EscalateApprovals runs the escalation job on demand.
+func EscalateApprovals(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	return escalateApprovals(gormx, time.Now())
+}
+
+func runApprovalEscalation() {
+	gormx, err := db.DefaultRegistry().GORM(`prime_erp`)
+	if err != nil {
+		log.Printf("approval escalation: %v\n", err)
+		return
+	}
+
+	res, err := escalateApprovals(gormx, time.Now())
+	if err != nil {
+		log.Printf("approval escalation: %v\n", err)
+		return
+	}
+	if len(res.Escalated)+len(res.Rejected)+len(res.Failed) > 0 {
+		log.Printf("approval escalation: escalated %v, rejected %v, failed %v\n", res.Escalated, res.Rejected, res.Failed)
+	}
+}
+
+// loadApprovalSLAs reads the APPROVAL system_config rows: config_code is the approve topic, value the SLA in hours
+// and json an ApprovalSLAConfigJSON.
+func loadApprovalSLAs(gormx *gorm.DB) (map[string]approvalSLA, error) {
+	var configs []models.SystemConfig
+	if err := gormx.Where("topic_code = ?", ApprovalSLATopic).Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get approval SLA config: %v", err)
+	}
+
+	slas := map[string]approvalSLA{}
+	for _, config := range configs {
+		slaHours, err := strconv.ParseFloat(config.Value, 64)
+		if err != nil || slaHours <= 0 {
+			log.Printf("approval escalation: skipping %s, invalid SLA hours %q\n", config.ConfigCode, config.Value)
+			continue
+		}
+
+		sla := approvalSLA{ApproveTopic: config.ConfigCode, SlaHours: slaHours}
+		if config.JSON != "" {
+			if err := json.Unmarshal([]byte(config.JSON), &sla.ApprovalSLAConfigJSON); err != nil {
+				log.Printf("approval escalation: skipping %s, invalid json: %v\n", config.ConfigCode, err)
+				continue
+			}
+		}
+		if sla.Action == "" {
+			sla.Action = models.ApprovalEscalate
+		}
+		if sla.MaxEscalations <= 0 {
+			sla.MaxEscalations = 1
+		}
+		slas[config.ConfigCode] = sla
+	}
+
+	return slas, nil
+}
+
+// escalationDue says what to do with a current step under its topic's SLA. The SLA clock restarts at every
+// escalation, and a step is escalated at most MaxEscalations times per visit.
+func escalationDue(step overdueApprovalStep, sla approvalSLA, now time.Time) (string, bool) {
+	since := step.PendingSince
+	if step.LastEscalation != nil && step.LastEscalation.After(since) {
+		since = *step.LastEscalation
+	}
+	if now.Sub(since) < time.Duration(sla.SlaHours*float64(time.Hour)) {
+		return "", false
+	}
+
+	if sla.Action == models.ApprovalAutoReject {
+		return models.ApprovalAutoReject, true
+	}
+	if step.Escalations >= sla.MaxEscalations || len(sla.BackupApprovers) == 0 {
+		return "", false
+	}
+	return models.ApprovalEscalate, true
+}
+
+func escalateApprovals(gormx *gorm.DB, now time.Time) (EscalateApprovalsResponse, error) {
+	res := EscalateApprovalsResponse{Escalated: []string{}, Rejected: []string{}, Failed: []string{}}
+
+	slas, err := loadApprovalSLAs(gormx)
+	if err != nil {
+		return res, err
+	}
+	if len(slas) == 0 {
+		return res, nil
+	}
+
+	topics := []string{}
+	for topic := range slas {
+		topics = append(topics, topic)
+	}
+
+	// Escalations only count while the step is current; a step reached again after a return starts over
+	var steps []overdueApprovalStep
+	if err := gormx.Raw(`
+		SELECT a.id AS approval_id, a.approve_topic, a.document_code,
+		       ai.id AS approval_item_id, ai.step_seq,
+		       COALESCE(ai.pending_dtm, ai.create_date) AS pending_since,
+		       COUNT(e.id) AS escalations,
+		       MAX(e.create_dtm) AS last_escalation
+		FROM approval a
+		JOIN approval_item ai ON ai.approval_id = a.id AND ai.step_seq = a.curent_step_seq
+		LEFT JOIN approval_escalation e ON e.approval_item_id = ai.id AND e.create_dtm >= COALESCE(ai.pending_dtm, ai.create_date)
+		WHERE a.status IN ?
+		  AND ai.status = ?
+		  AND a.approve_topic IN ?
+		GROUP BY a.id, a.approve_topic, a.document_code, ai.id, ai.step_seq, ai.pending_dtm, ai.create_date`,
+		[]string{models.ApprovalStatusPending, models.ApprovalStatusReview, "PROCESS"},
+		models.ApprovalStatusPending,
+		topics,
+	).Scan(&steps).Error; err != nil {
+		return res, fmt.Errorf("failed to get pending approval steps: %v", err)
+	}
+
+	for _, step := range steps {
+		sla := slas[step.ApproveTopic]
+		action, due := escalationDue(step, sla, now)
+		if !due {
+			continue
+		}
+
+		acted := false
+		switch action {
+		case models.ApprovalEscalate:
+			err = gormx.Transaction(func(tx *gorm.DB) error {
+				var err error
+				acted, err = escalateApprovalStep(tx, step, sla, now)
+				return err
+			})
+			if err == nil && acted {
+				res.Escalated = append(res.Escalated, step.DocumentCode)
+			}
+		case models.ApprovalAutoReject:
+			handler, exists := rejectHandlerFor(step.ApproveTopic)
+			if !exists {
+				err = fmt.Errorf("auto-reject is not supported for approve topic %s", step.ApproveTopic)
+				break
+			}
+			err = gormx.Transaction(func(tx *gorm.DB) error {
+				var err error
+				acted, err = autoRejectApprovalStep(tx, step, sla, handler, now)
+				return err
+			})
+			if err == nil && acted {
+				res.Rejected = append(res.Rejected, step.DocumentCode)
+			}
+		}
+		if err != nil {
+			log.Printf("approval escalation: %s: %v\n", step.DocumentCode, err)
+			res.Failed = append(res.Failed, step.DocumentCode)
+		}
+	}
+
+	return res, nil
+}
+
+// lockCurrentApprovalStep locks the approval and reloads the step, with the escalations it had on this visit, and
+// says whether it is still the pending current step and still due. An approval locked by another run of the job, or
+// by an approver deciding it, is skipped until the next run, and a step another run just escalated is no longer due.
+func lockCurrentApprovalStep(tx *gorm.DB, step overdueApprovalStep, sla approvalSLA, now time.Time) (models.Approval, models.ApprovalItem, bool, error) {
+	var approval models.Approval
+	var item models.ApprovalItem
+
+	var approvals []models.Approval
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).Where("id = ?", step.ApprovalID).Find(&approvals).Error; err != nil {
+		return approval, item, false, fmt.Errorf("failed to get approval: %v", err)
+	}
+	if len(approvals) == 0 {
+		return approval, item, false, nil
+	}
+	approval = approvals[0]
+
+	if err := tx.Where("id = ?", step.ApprovalItemID).First(&item).Error; err != nil {
+		return approval, item, false, fmt.Errorf("failed to get approval step: %v", err)
+	}
+	if approval.CurentStepSeq != item.StepSeq || item.Status != models.ApprovalStatusPending {
+		return approval, item, false, nil
+	}
+
+	if err := tx.Raw(`
+		SELECT COALESCE(ai.pending_dtm, ai.create_date) AS pending_since,
+		       COUNT(e.id) AS escalations,
+		       MAX(e.create_dtm) AS last_escalation
+		FROM approval_item ai
+		LEFT JOIN approval_escalation e ON e.approval_item_id = ai.id AND e.create_dtm >= COALESCE(ai.pending_dtm, ai.create_date)
+		WHERE ai.id = ?
+		GROUP BY ai.pending_dtm, ai.create_date`, step.ApprovalItemID).Scan(&step).Error; err != nil {
+		return approval, item, false, fmt.Errorf("failed to get approval escalations: %v", err)
+	}
+	_, due := escalationDue(step, sla, now)
+
+	return approval, item, due, nil
+}
+
+func recordApprovalEscalation(tx *gorm.DB, step overdueApprovalStep, sla approvalSLA, action string, userCodes []string) error {
+	userCodesJSON, _ := json.Marshal(userCodes)
+	escalation := models.ApprovalEscalation{
+		ID:             uuid.New(),
+		ApprovalID:     step.ApprovalID,
+		ApprovalItemID: step.ApprovalItemID,
+		StepSeq:        step.StepSeq,
+		Action:         action,
+		PendingSince:   step.PendingSince,
+		SlaHours:       sla.SlaHours,
+		UserCodes:      userCodesJSON,
+	}
+	if err := tx.Create(&escalation).Error; err != nil {
+		return fmt.Errorf("failed to record approval escalation: %v", err)
+	}
+	return nil
+}
+
+// escalateApprovalStep adds the topic's backup approvers to the overdue step, and says whether it did. Only any-of
+// steps are escalated: on an all-of step a backup would be one more approver the step waits for.
+func escalateApprovalStep(tx *gorm.DB, step overdueApprovalStep, sla approvalSLA, now time.Time) (bool, error) {
+	_, item, due, err := lockCurrentApprovalStep(tx, step, sla, now)
+	if err != nil || !due {
+		return false, err
+	}
+	if approvalStepMode(item) == models.ApproveModeAll {
+		return false, nil
+	}
+
+	var existing []string
+	if err := tx.Model(&models.ApprovalItemPermission{}).Where("approval_item_id = ?", step.ApprovalItemID).Pluck("user_code", &existing).Error; err != nil {
+		return false, fmt.Errorf("failed to get approvers: %v", err)
+	}
+	onStep := map[string]bool{}
+	for _, userCode := range existing {
+		onStep[userCode] = true
+	}
+
+	added := []string{}
+	permissions := []models.ApprovalItemPermission{}
+	for _, userCode := range sla.BackupApprovers {
+		if onStep[userCode] {
+			continue
+		}
+		onStep[userCode] = true
+		added = append(added, userCode)
+		permissions = append(permissions, models.ApprovalItemPermission{
+			ID:             uuid.New(),
+			ApprovalItemID: step.ApprovalItemID,
+			UserCode:       userCode,
+			Status:         models.ApprovalStatusPending,
+		})
+	}
+	if len(permissions) > 0 {
+		if err := tx.Create(&permissions).Error; err != nil {
+			return false, fmt.Errorf("failed to add backup approvers: %v", err)
+		}
+	}
+
+	if err := recordApprovalEscalation(tx, step, sla, models.ApprovalEscalate, added); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// autoRejectApprovalStep rejects the approval outright and lets the document service reject the document in the same
+// transaction, and says whether it did.
+func autoRejectApprovalStep(tx *gorm.DB, step overdueApprovalStep, sla approvalSLA, handler ApprovalRejectHandler, now time.Time) (bool, error) {
+	approval, _, due, err := lockCurrentApprovalStep(tx, step, sla, now)
+	if err != nil || !due {
+		return false, err
+	}
+
+	remark := fmt.Sprintf("Auto-rejected: step %d pending longer than %v hours", step.StepSeq, sla.SlaHours)
+	if _, err := rejectApprovalAsSystem(tx, step.ApprovalID, remark); err != nil {
+		return false, err
+	}
+	if err := handler(tx, approval, remark); err != nil {
+		return false, err
+	}
+
+	if err := recordApprovalEscalation(tx, step, sla, models.ApprovalAutoReject, []string{}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,44 @@
+package approvalService
+
+import (
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscalationDue(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	escalate := approvalSLA{SlaHours: 24, ApprovalSLAConfigJSON: models.ApprovalSLAConfigJSON{
+		Action:          models.ApprovalEscalate,
+		BackupApprovers: []string{"backup1"},
+		MaxEscalations:  2,
+	}}
+
+	step := overdueApprovalStep{PendingSince: now.Add(-23 * time.Hour)}
+	_, due := escalationDue(step, escalate, now)
+	assert.False(t, due, "still inside the SLA")
+
+	step.PendingSince = now.Add(-25 * time.Hour)
+	action, due := escalationDue(step, escalate, now)
+	assert.True(t, due)
+	assert.Equal(t, models.ApprovalEscalate, action)
+
+	lastEscalation := now.Add(-2 * time.Hour)
+	step.Escalations = 1
+	step.LastEscalation = &lastEscalation
+	_, due = escalationDue(step, escalate, now)
+	assert.False(t, due, "the SLA clock restarts at the last escalation")
+
+	step.LastEscalation = nil
+	step.Escalations = 2
+	_, due = escalationDue(step, escalate, now)
+	assert.False(t, due, "max escalations reached")
+
+	reject := approvalSLA{SlaHours: 24, ApprovalSLAConfigJSON: models.ApprovalSLAConfigJSON{Action: models.ApprovalAutoReject}}
+	action, due = escalationDue(step, reject, now)
+	assert.True(t, due)
+	assert.Equal(t, models.ApprovalAutoReject, action)
+}
@@ -0,0 +1,397 @@
+package approvalService
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Approver decisions accepted by the workflow. COMPLETED is what the document approve endpoints send for an approval.
+const (
+	ApprovalActionApprove = "APPROVE"
+	ApprovalActionReject  = "REJECT"
+)
+
+type ApprovalActResult struct {
+	ApprovalID    uuid.UUID `json:"approval_id"`
+	DocumentCode  string    `json:"document_code"`
+	Status        string    `json:"status"` // approval status after the decision
+	CurentStepSeq int       `json:"curent_step_seq"`
+	IsFinal       bool      `json:"is_final"`    // the approval is COMPLETED or REJECT and the document can follow it
+	IsReturned    bool      `json:"is_returned"` // a rejection sent the approval back to the previous step
+}
+
+// selectApprovalSteps keeps the template steps whose condition holds for the document data, in step order.
+// A condition that cannot be evaluated against the document keeps its step, so missing data never skips an approver.
+func selectApprovalSteps(templates []models.ApprovalTemplate, documentData []byte) ([]models.ApprovalTemplate, error) {
+	data := map[string]interface{}{}
+	if len(documentData) > 0 && string(documentData) != "null" {
+		if err := json.Unmarshal(documentData, &data); err != nil {
+			return nil, fmt.Errorf("document data is not a JSON object: %v", err)
+		}
+	}
+
+	sorted := append([]models.ApprovalTemplate{}, templates...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StepSeq < sorted[j].StepSeq })
+
+	steps := []models.ApprovalTemplate{}
+	for _, template := range sorted {
+		if !template.IsActive {
+			continue
+		}
+		ok, err := evaluateApprovalCondition(template.Condition, data)
+		if err != nil || ok {
+			steps = append(steps, template)
+		}
+	}
+
+	return steps, nil
+}
+
+// evaluateApprovalCondition runs an expr-lang condition such as `total_amount > 500000` against the document data.
+func evaluateApprovalCondition(expression string, data map[string]interface{}) (bool, error) {
+	if expression == "" {
+		return true, nil
+	}
+
+	program, err := compileApprovalCondition(expression, data)
+	if err != nil {
+		return false, fmt.Errorf("condition compile failed: %w", err)
+	}
+
+	result, err := expr.Run(program, data)
+	if err != nil {
+		return false, fmt.Errorf("condition run failed: %w", err)
+	}
+
+	return result.(bool), nil
+}
+
+func compileApprovalCondition(expression string, data map[string]interface{}) (*vm.Program, error) {
+	return expr.Compile(expression, expr.Env(data), expr.AllowUndefinedVariables(), expr.AsBool())
+}
+
+// buildApprovalSteps creates the approval items and approver permissions of a new approval from its template steps.
+// Without templates the approval gets the single any-of step every approval had before templates existed.
+func buildApprovalSteps(approval *models.Approval, steps []models.ApprovalTemplate, approversFor func(models.ApprovalTemplate) ([]string, error), now time.Time) ([]models.ApprovalItem, []models.ApprovalItemPermission, error) {
+	if len(steps) == 0 {
+		steps = []models.ApprovalTemplate{{StepSeq: 1, ApproveMode: models.ApproveModeAny, MDItemCode: approval.MDItemCode}}
+	}
+
+	items := []models.ApprovalItem{}
+	permissions := []models.ApprovalItemPermission{}
+	for i, step := range steps {
+		approvers, err := approversFor(step)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mode := step.ApproveMode
+		if mode == "" {
+			mode = models.ApproveModeAny
+		}
+		rule, _ := json.Marshal(models.ApprovalStepRule{
+			StepName:    step.StepName,
+			Expression:  step.Condition,
+			ApproveMode: mode,
+		})
+
+		status := models.ApprovalStepWaiting
+		var pendingDtm *time.Time
+		if i == 0 {
+			status = models.ApprovalStatusPending
+			pendingDtm = &now
+			approval.CurentStepSeq = step.StepSeq
+		}
+
+		item := models.ApprovalItem{
+			ID:          uuid.New(),
+			ApprovalID:  approval.ID,
+			StepSeq:     step.StepSeq,
+			IsCondition: step.Condition != "",
+			Condition:   rule,
+			Status:      status,
+			ActionBy:    approval.CreateBy,
+			ActionDate:  now,
+			CreateBy:    approval.CreateBy,
+			UpdateBy:    approval.CreateBy,
+			PendingDtm:  pendingDtm,
+		}
+		items = append(items, item)
+
+		for _, userCode := range approvers {
+			permissions = append(permissions, models.ApprovalItemPermission{
+				ID:             uuid.New(),
+				ApprovalItemID: item.ID,
+				UserCode:       userCode,
+				Status:         models.ApprovalStatusPending,
+			})
+		}
+	}
+
+	return items, permissions, nil
+}
+
+func approvalStepMode(item models.ApprovalItem) string {
+	rule := models.ApprovalStepRule{}
+	if len(item.Condition) > 0 {
+		_ = json.Unmarshal(item.Condition, &rule)
+	}
+	if rule.ApproveMode == models.ApproveModeAll {
+		return models.ApproveModeAll
+	}
+	return models.ApproveModeAny
+}
+
+func resetApprovalStep(item *models.ApprovalItem, status string, now time.Time) {
+	item.Status = status
+	item.PendingDtm = nil
+	if status == models.ApprovalStatusPending {
+		item.PendingDtm = &now
+	}
+	for i := range item.ApprovalItemPermission {
+		item.ApprovalItemPermission[i].Status = models.ApprovalStatusPending
+		item.ApprovalItemPermission[i].ActionDate = nil
+		item.ApprovalItemPermission[i].Remark = ""
+	}
+}
+
+// applyApprovalAction records one approver's decision on the current step and moves the approval along.
+// Only an approver of the step may decide it. Approving completes an any-of step at once and an all-of step once every approver has approved; the next step
+// then becomes current, or the approval completes. Rejecting sends the approval back to the previous step,
+// unless it is on its first step or finalReject is set, in which case the approval is rejected.
+func applyApprovalAction(approval *models.Approval, items []models.ApprovalItem, user string, action string, remark string, finalReject bool, now time.Time) (ApprovalActResult, error) {
+	return applyApprovalStepAction(approval, items, user, action, remark, finalReject, false, now)
+}
+
+// applyApprovalStepAction is applyApprovalAction, with asSystem for the jobs of this package that act on a step
+// without being one of its approvers.
+func applyApprovalStepAction(approval *models.Approval, items []models.ApprovalItem, user string, action string, remark string, finalReject bool, asSystem bool, now time.Time) (ApprovalActResult, error) {
+	result := ApprovalActResult{ApprovalID: approval.ID, DocumentCode: approval.DocumentCode}
+
+	switch approval.Status {
+	case models.ApprovalStatusPending, models.ApprovalStatusReview, "PROCESS", "":
+	default:
+		return result, &utils.ConflictError{
+			Code:    "APPROVAL_CLOSED",
+			Message: fmt.Sprintf("approval of %s is already %s", approval.DocumentCode, approval.Status),
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].StepSeq < items[j].StepSeq })
+
+	current := -1
+	for i, item := range items {
+		if item.StepSeq == approval.CurentStepSeq {
+			current = i
+			break
+		}
+	}
+	if current < 0 {
+		// Approvals created before steps were tracked have no current step; take the first open one
+		for i, item := range items {
+			if item.Status == models.ApprovalStatusPending || item.Status == "" {
+				current = i
+				break
+			}
+		}
+	}
+	if current < 0 {
+		return result, &utils.ConflictError{
+			Code:    "APPROVAL_CLOSED",
+			Message: fmt.Sprintf("approval of %s has no open step", approval.DocumentCode),
+		}
+	}
+
+	step := &items[current]
+	var permission *models.ApprovalItemPermission
+	for i := range step.ApprovalItemPermission {
+		if step.ApprovalItemPermission[i].UserCode == user {
+			permission = &step.ApprovalItemPermission[i]
+			break
+		}
+	}
+	if permission == nil && len(step.ApprovalItemPermission) > 0 && !asSystem {
+		return result, &utils.ConflictError{
+			Code:    "APPROVAL_NOT_APPROVER",
+			Message: fmt.Sprintf("%s is not an approver of step %d of %s", user, step.StepSeq, approval.DocumentCode),
+		}
+	}
+	if permission != nil && permission.Status == models.ApprovalStatusCompleted {
+		return result, &utils.ConflictError{
+			Code:    "APPROVAL_ALREADY_ACTED",
+			Message: fmt.Sprintf("%s has already approved step %d of %s", user, step.StepSeq, approval.DocumentCode),
+		}
+	}
+
+	approval.ActionDate = now
+	approval.UpdateBy = user
+	step.ActionBy = user
+	step.ActionDate = now
+	step.UpdateBy = user
+	if permission != nil {
+		permission.ActionDate = &now
+		permission.Remark = remark
+	}
+
+	switch action {
+	case ApprovalActionApprove, models.ApprovalStatusCompleted:
+		if permission != nil {
+			permission.Status = models.ApprovalStatusCompleted
+		}
+
+		done := true
+		if approvalStepMode(*step) == models.ApproveModeAll {
+			for _, p := range step.ApprovalItemPermission {
+				if p.Status != models.ApprovalStatusCompleted {
+					done = false
+				}
+			}
+		}
+		approval.Status = models.ApprovalStatusPending
+		if done {
+			step.Status = models.ApprovalStatusCompleted
+			if current+1 < len(items) {
+				next := &items[current+1]
+				resetApprovalStep(next, models.ApprovalStatusPending, now)
+				approval.CurentStepSeq = next.StepSeq
+			} else {
+				approval.Status = models.ApprovalStatusCompleted
+				result.IsFinal = true
+			}
+		}
+
+	case ApprovalActionReject:
+		approval.Remark = remark
+		if current > 0 && !finalReject {
+			resetApprovalStep(step, models.ApprovalStepWaiting, now)
+			previous := &items[current-1]
+			resetApprovalStep(previous, models.ApprovalStatusPending, now)
+			approval.CurentStepSeq = previous.StepSeq
+			approval.Status = models.ApprovalStatusPending
+			result.IsReturned = true
+		} else {
+			if permission != nil {
+				permission.Status = models.ApprovalStatusReject
+			}
+			step.Status = models.ApprovalStatusReject
+			approval.Status = models.ApprovalStatusReject
+			result.IsFinal = true
+		}
+
+	default:
+		return result, fmt.Errorf("invalid approval action: %s", action)
+	}
+
+	result.Status = approval.Status
+	result.CurentStepSeq = approval.CurentStepSeq
+	return result, nil
+}
+
+// ApplyApprovalDecision locks the approval, applies the decision of user and saves the workflow state in tx.
+func ApplyApprovalDecision(tx *gorm.DB, approvalID uuid.UUID, user string, action string, remark string, finalReject bool) (*ApprovalActResult, error) {
+	var approval models.Approval
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", approvalID).First(&approval).Error; err != nil {
+		return nil, fmt.Errorf("failed to get approval %v: %v", approvalID, err)
+	}
+
+	return applyAndSaveApprovalDecision(tx, approval, user, action, remark, finalReject, false)
+}
+
+// rejectApprovalAsSystem locks the approval and rejects it outright as the system, in tx. It is the entry point of the
+// escalation job, which is not an approver of the steps it times out.
+func rejectApprovalAsSystem(tx *gorm.DB, approvalID uuid.UUID, remark string) (*ApprovalActResult, error) {
+	var approval models.Approval
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", approvalID).First(&approval).Error; err != nil {
+		return nil, fmt.Errorf("failed to get approval %v: %v", approvalID, err)
+	}
+
+	return applyAndSaveApprovalDecision(tx, approval, middleware.SystemUser, ApprovalActionReject, remark, true, true)
+}
+
+// ApplyDocumentApprovalDecision is ApplyApprovalDecision for the latest approval of a document.
+// Documents without an approval have nothing to wait for, so the decision is final straight away.
+func ApplyDocumentApprovalDecision(tx *gorm.DB, documentCode string, user string, action string, remark string, finalReject bool) (*ApprovalActResult, error) {
+	var approvals []models.Approval
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("document_code = ?", documentCode).
+		Order("create_date desc").
+		Limit(1).
+		Find(&approvals).Error; err != nil {
+		return nil, fmt.Errorf("failed to get approval of %s: %v", documentCode, err)
+	}
+	if len(approvals) == 0 {
+		status := models.ApprovalStatusCompleted
+		if action == ApprovalActionReject {
+			status = models.ApprovalStatusReject
+		}
+		return &ApprovalActResult{DocumentCode: documentCode, Status: status, IsFinal: true}, nil
+	}
+
+	return applyAndSaveApprovalDecision(tx, approvals[0], user, action, remark, finalReject, false)
+}
+
+func applyAndSaveApprovalDecision(tx *gorm.DB, approval models.Approval, user string, action string, remark string, finalReject bool, asSystem bool) (*ApprovalActResult, error) {
+	var items []models.ApprovalItem
+	if err := tx.Preload("ApprovalItemPermission").Where("approval_id = ?", approval.ID).Order("step_seq").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to get approval items: %v", err)
+	}
+
+	result, err := applyApprovalStepAction(&approval, items, user, action, remark, finalReject, asSystem, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(&models.Approval{}).
+		Where("id = ?", approval.ID).
+		Updates(map[string]interface{}{
+			"status":          approval.Status,
+			"curent_step_seq": approval.CurentStepSeq,
+			"remark":          approval.Remark,
+			"action_date":     approval.ActionDate,
+			"update_by":       user,
+			"update_date":     tx.NowFunc(),
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update approval: %v", err)
+	}
+
+	for _, item := range items {
+		if err := tx.Model(&models.ApprovalItem{}).
+			Where("id = ?", item.ID).
+			Updates(map[string]interface{}{
+				"status":      item.Status,
+				"action_by":   item.ActionBy,
+				"action_date": item.ActionDate,
+				"pending_dtm": item.PendingDtm,
+				"update_by":   item.UpdateBy,
+				"update_date": tx.NowFunc(),
+			}).Error; err != nil {
+			return nil, fmt.Errorf("failed to update approval step %d: %v", item.StepSeq, err)
+		}
+
+		for _, permission := range item.ApprovalItemPermission {
+			if err := tx.Model(&models.ApprovalItemPermission{}).
+				Where("id = ?", permission.ID).
+				Updates(map[string]interface{}{
+					"status":      permission.Status,
+					"action_date": permission.ActionDate,
+					"remark":      permission.Remark,
+				}).Error; err != nil {
+				return nil, fmt.Errorf("failed to update approver %s: %v", permission.UserCode, err)
+			}
+		}
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,131 @@
+package approvalService
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func workflowTemplates() []models.ApprovalTemplate {
+	return []models.ApprovalTemplate{
+		{ApproveTopic: "SO", StepSeq: 2, StepName: "Director", Condition: "total_amount > 500000", ApproveMode: models.ApproveModeAll, IsActive: true},
+		{ApproveTopic: "SO", StepSeq: 1, StepName: "Manager", ApproveMode: models.ApproveModeAny, IsActive: true},
+		{ApproveTopic: "SO", StepSeq: 3, StepName: "Retired", IsActive: false},
+	}
+}
+
+func TestSelectApprovalSteps(t *testing.T) {
+	steps, err := selectApprovalSteps(workflowTemplates(), []byte(`{"total_amount": 100000}`))
+	assert.NoError(t, err)
+	assert.Len(t, steps, 1)
+	assert.Equal(t, "Manager", steps[0].StepName)
+
+	steps, err = selectApprovalSteps(workflowTemplates(), []byte(`{"total_amount": 600000}`))
+	assert.NoError(t, err)
+	assert.Len(t, steps, 2)
+	assert.Equal(t, 1, steps[0].StepSeq)
+	assert.Equal(t, 2, steps[1].StepSeq)
+
+	steps, err = selectApprovalSteps(workflowTemplates(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, steps, 2, "a condition on missing data keeps its step")
+}
+
+func buildWorkflow(t *testing.T, steps []models.ApprovalTemplate, approvers map[int][]string) (models.Approval, []models.ApprovalItem) {
+	approval := models.Approval{DocumentCode: "SO1", Status: models.ApprovalStatusPending}
+	items, permissions, err := buildApprovalSteps(&approval, steps, func(step models.ApprovalTemplate) ([]string, error) {
+		return approvers[step.StepSeq], nil
+	}, time.Now())
+	assert.NoError(t, err)
+
+	for i := range items {
+		for _, permission := range permissions {
+			if permission.ApprovalItemID == items[i].ID {
+				items[i].ApprovalItemPermission = append(items[i].ApprovalItemPermission, permission)
+			}
+		}
+	}
+	return approval, items
+}
+
+func TestApplyApprovalAction_AnyThenAll(t *testing.T) {
+	steps, _ := selectApprovalSteps(workflowTemplates(), []byte(`{"total_amount": 600000}`))
+	approval, items := buildWorkflow(t, steps, map[int][]string{1: {"m1", "m2"}, 2: {"d1", "d2"}})
+	assert.Equal(t, 1, approval.CurentStepSeq)
+	assert.Equal(t, models.ApprovalStepWaiting, items[1].Status)
+
+	result, err := applyApprovalAction(&approval, items, "m2", ApprovalActionApprove, "", false, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, result.IsFinal)
+	assert.Equal(t, 2, result.CurentStepSeq, "one manager completes an any-of step")
+
+	result, err = applyApprovalAction(&approval, items, "d1", models.ApprovalStatusCompleted, "", false, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, result.IsFinal, "all-of step waits for every director")
+
+	_, err = applyApprovalAction(&approval, items, "d1", ApprovalActionApprove, "", false, time.Now())
+	var conflictErr *utils.ConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "APPROVAL_ALREADY_ACTED", conflictErr.Code)
+
+	result, err = applyApprovalAction(&approval, items, "d2", ApprovalActionApprove, "", false, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, result.IsFinal)
+	assert.Equal(t, models.ApprovalStatusCompleted, approval.Status)
+}
+
+func TestApplyApprovalAction_RejectReturnsToPreviousStep(t *testing.T) {
+	steps, _ := selectApprovalSteps(workflowTemplates(), []byte(`{"total_amount": 600000}`))
+	approval, items := buildWorkflow(t, steps, map[int][]string{1: {"m1"}, 2: {"d1", "d2"}})
+
+	_, err := applyApprovalAction(&approval, items, "m1", ApprovalActionApprove, "", false, time.Now())
+	assert.NoError(t, err)
+	_, err = applyApprovalAction(&approval, items, "d1", ApprovalActionApprove, "", false, time.Now())
+	assert.NoError(t, err)
+
+	result, err := applyApprovalAction(&approval, items, "d2", ApprovalActionReject, "price too low", false, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, result.IsReturned)
+	assert.False(t, result.IsFinal)
+	assert.Equal(t, 1, approval.CurentStepSeq)
+	assert.Equal(t, models.ApprovalStatusPending, items[0].Status)
+	assert.Equal(t, models.ApprovalStepWaiting, items[1].Status)
+	assert.Equal(t, models.ApprovalStatusPending, items[1].ApprovalItemPermission[0].Status, "the step starts over when reached again")
+
+	result, err = applyApprovalAction(&approval, items, "m1", ApprovalActionReject, "", false, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, result.IsFinal, "rejecting the first step rejects the approval")
+	assert.Equal(t, models.ApprovalStatusReject, approval.Status)
+
+	_, err = applyApprovalAction(&approval, items, "m1", ApprovalActionApprove, "", false, time.Now())
+	var conflictErr *utils.ConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "APPROVAL_CLOSED", conflictErr.Code)
+}
+
+func TestApplyApprovalAction_NotApprover(t *testing.T) {
+	approval, items := buildWorkflow(t, nil, map[int][]string{1: {"m1"}})
+	rule := models.ApprovalStepRule{}
+	assert.NoError(t, json.Unmarshal(items[0].Condition, &rule))
+	assert.Equal(t, models.ApproveModeAny, rule.ApproveMode, "no templates gives the single any-of step")
+
+	_, err := applyApprovalAction(&approval, items, "x9", ApprovalActionApprove, "", false, time.Now())
+	var conflictErr *utils.ConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "APPROVAL_NOT_APPROVER", conflictErr.Code)
+
+	_, err = applyApprovalAction(&approval, items, middleware.SystemUser, ApprovalActionReject, "", true, time.Now())
+	assert.True(t, errors.As(err, &conflictErr), "the system identity is no approver either")
+	assert.Equal(t, "APPROVAL_NOT_APPROVER", conflictErr.Code)
+
+	result, err := applyApprovalStepAction(&approval, items, middleware.SystemUser, ApprovalActionReject, "overdue", true, true, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, models.ApprovalStatusReject, result.Status)
+}
@@ -0,0 +1,435 @@
+package creditService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"prime-erp-core/internal/cronjob"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// creditHoldExpiry is how long a hold reserves credit when the caller gives no expiry.
+const creditHoldExpiry = 30 * 24 * time.Hour
+
+func init() {
+	cronjob.RegisterJob("credit-hold-expiry", runCreditHoldExpiry, "0 * * * *")
+}
+
+type CreditHoldRequest struct {
+	CustomerCode string
+	SaleCode     string
+	Amount       float64 // the sale total, with the transport cost when it is charged on top
+	Enforce      bool    // fail with CREDIT_LIMIT_EXCEEDED when the customer's balance does not cover the hold
+	ExpireDtm    *time.Time
+}
+
+// HoldCredit reserves credit for sales inside the transaction that creates or approves them, one hold per sale;
+// holding a sale again replaces its hold. The customers are locked until tx ends, so the balance checked for an
+// enforced hold cannot be used by another sale before the hold is committed.
+func HoldCredit(tx *gorm.DB, sqlx *sqlx.DB, user string, reqs []CreditHoldRequest) ([]models.CreditHold, error) {
+	if len(reqs) == 0 {
+		return []models.CreditHold{}, nil
+	}
+
+	customerStrs := []string{}
+	byCustomer := map[string][]CreditHoldRequest{}
+	enforced := map[string][]CreditHoldRequest{}
+	for _, req := range reqs {
+		if req.CustomerCode == "" || req.SaleCode == "" {
+			return nil, errors.New("credit hold requires a customer and a sale")
+		}
+		customerStrs = append(customerStrs, req.CustomerCode)
+		byCustomer[req.CustomerCode] = append(byCustomer[req.CustomerCode], req)
+	}
+	customerStrs = uniqueCustomerCodes(customerStrs)
+	sort.Strings(customerStrs)
+
+	// An enforced hold is checked with the customer's other holds of this call, which the balance cannot see yet
+	for _, req := range reqs {
+		if req.Enforce {
+			enforced[req.CustomerCode] = byCustomer[req.CustomerCode]
+		}
+	}
+
+	for _, customer := range customerStrs {
+		if err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext(?))`, "credit_hold:"+customer).Error; err != nil {
+			return nil, fmt.Errorf("failed to lock credit of %s: %v", customer, err)
+		}
+	}
+
+	if len(enforced) > 0 {
+		if sqlx == nil {
+			return nil, errors.New("an enforced credit hold needs the credit balance")
+		}
+		if err := checkCreditHolds(sqlx, enforced); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	holds := make([]models.CreditHold, 0, len(reqs))
+	for _, req := range reqs {
+		expireDtm := now.Add(creditHoldExpiry)
+		if req.ExpireDtm != nil {
+			expireDtm = *req.ExpireDtm
+		}
+
+		hold := models.CreditHold{
+			ID:           uuid.New(),
+			CustomerCode: req.CustomerCode,
+			SaleCode:     req.SaleCode,
+			Amount:       req.Amount,
+			Status:       models.CreditHoldOpen,
+			ExpireDtm:    expireDtm,
+			CreateBy:     user,
+			UpdateBy:     user,
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "sale_code"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"customer_code":   hold.CustomerCode,
+				"amount":          hold.Amount,
+				"released_amount": 0,
+				"status":          models.CreditHoldOpen,
+				"release_reason":  "",
+				"expire_dtm":      hold.ExpireDtm,
+				"settle_dtm":      nil,
+				"update_by":       user,
+				"update_dtm":      now,
+			}),
+		}).Create(&hold).Error; err != nil {
+			return nil, fmt.Errorf("failed to hold credit for %s: %v", req.SaleCode, err)
+		}
+		if err := tx.Where("sale_code = ?", req.SaleCode).Take(&hold).Error; err != nil {
+			return nil, fmt.Errorf("failed to get credit hold of %s: %v", req.SaleCode, err)
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, nil
+}
+
+// checkCreditHolds fails when a customer's balance does not cover what the holds add to what their sales already take.
+func checkCreditHolds(sqlx *sqlx.DB, enforced map[string][]CreditHoldRequest) error {
+	customerStrs := []string{}
+	saleCodes := []string{}
+	for customer, reqs := range enforced {
+		customerStrs = append(customerStrs, customer)
+		for _, req := range reqs {
+			saleCodes = append(saleCodes, req.SaleCode)
+		}
+	}
+	sort.Strings(customerStrs)
+
+	credit, err := GetCreditCurrent(sqlx, GetCreditRequest{CustomerCodes: customerStrs})
+	if err != nil {
+		return err
+	}
+	exposure, err := GetSaleCreditExposure(sqlx, saleCodes, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, customer := range credit.CreditCustomers {
+		need := 0.0
+		for _, req := range enforced[customer.CustomerCode] {
+			need += req.Amount - exposure[req.SaleCode]
+		}
+		if customer.Balance-need <= -creditLedgerTolerance {
+			return &utils.ConflictError{
+				Code:    "CREDIT_LIMIT_EXCEEDED",
+				Message: fmt.Sprintf("credit of customer %s is not enough: %.2f available, %.2f needed", customer.CustomerCode, customer.Balance, need),
+				Details: map[string]interface{}{
+					"customer_code": customer.CustomerCode,
+					"balance":       customer.Balance,
+					"need_amount":   need,
+				},
+			}
+		}
+	}
+	return nil
+}
+
+type saleAmountRow struct {
+	SaleCode string  `db:"sale_code"`
+	Amount   float64 `db:"amount"`
+}
+
+// GetSaleCreditExposure returns what sales already take from their customers' balance: what the ledger carries for
+// them, or else what their open holds reserve. A check for a sale's amount adds only the difference.
+func GetSaleCreditExposure(sqlx *sqlx.DB, saleCodes []string, at time.Time) (map[string]float64, error) {
+	if len(saleCodes) == 0 {
+		return map[string]float64{}, nil
+	}
+
+	qb := db.NewQueryBuilder(`
+		select source_ref sale_code, sum(amount) amount
+		from credit_ledger
+		where source_type = ? and source_ref in `, models.CreditSourceSale)
+	qb.Append(qb.InStrings(saleCodes))
+	qb.Append(`
+		group by source_ref`)
+	ledger, err := db.SelectBuilder[saleAmountRow](sqlx, qb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit ledger of sales: %v", err)
+	}
+
+	qb = db.NewQueryBuilder(`
+		select sale_code, amount - converted_amount - released_amount amount
+		from credit_hold
+		where status = ? and expire_dtm > ? and sale_code in `, models.CreditHoldOpen, at)
+	qb.Append(qb.InStrings(saleCodes))
+	holds, err := db.SelectBuilder[saleAmountRow](sqlx, qb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit holds of sales: %v", err)
+	}
+
+	return saleCreditExposure(ledger, holds), nil
+}
+
+// saleCreditExposure prefers the ledger: once a sale is posted, its hold no longer counts.
+func saleCreditExposure(ledger []saleAmountRow, holds []saleAmountRow) map[string]float64 {
+	exposure := map[string]float64{}
+	for _, row := range holds {
+		exposure[row.SaleCode] = row.Amount
+	}
+	for _, row := range ledger {
+		if math.Abs(row.Amount) >= creditLedgerTolerance {
+			exposure[row.SaleCode] = row.Amount
+		}
+	}
+	return exposure
+}
+
+type creditHeldRow struct {
+	CustomerCode string  `db:"customer_code"`
+	Held         float64 `db:"held"`
+}
+
+func buildCreditHeldQuery(customerStrs []string, at time.Time) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select h.customer_code, sum(h.amount - h.converted_amount - h.released_amount) held
+		from credit_hold h
+		where h.status = ? and h.expire_dtm > ?`, models.CreditHoldOpen, at)
+	qb.Append(`
+			and h.customer_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+	qb.Append(`
+			and not exists (
+				select 1 from credit_ledger l
+				where l.customer_code = h.customer_code and l.source_type = ? and l.source_ref = h.sale_code
+				having abs(sum(l.amount)) >= ?)
+		group by h.customer_code`, models.CreditSourceSale, creditLedgerTolerance)
+
+	return qb
+}
+
+// applyCreditHeld takes the open holds of sales the ledger does not carry yet off the customers' balance.
+func applyCreditHeld(sqlx *sqlx.DB, res *GetCreditResponse, customerStrs []string, at time.Time) error {
+	rows, err := db.SelectBuilder[creditHeldRow](sqlx, buildCreditHeldQuery(customerStrs, at))
+	if err != nil {
+		return fmt.Errorf("failed to get credit holds: %v", err)
+	}
+
+	held := map[string]float64{}
+	for _, row := range rows {
+		held[row.CustomerCode] = row.Held
+	}
+	for i, customer := range res.CreditCustomers {
+		res.CreditCustomers[i].Held = held[customer.CustomerCode]
+		res.CreditCustomers[i].Balance -= held[customer.CustomerCode]
+	}
+	return nil
+}
+
+// settleCreditHold converts as much of an open hold as the sale has been AR invoiced for, and releases the rest once
+// the sale is canceled or completed or the hold expires. It reports whether the hold changed.
+func settleCreditHold(hold models.CreditHold, invoiced float64, saleStatus string, user string, now time.Time) (models.CreditHold, bool) {
+	if hold.Status != models.CreditHoldOpen {
+		return hold, false
+	}
+
+	settled := hold
+	settled.ConvertedAmount = math.Min(hold.Amount, math.Max(invoiced, 0))
+	remain := settled.Amount - settled.ConvertedAmount - settled.ReleasedAmount
+
+	reason := ""
+	switch {
+	case remain < creditLedgerTolerance:
+	case saleStatus == "" || saleStatus == models.SaleStatusCanceled:
+		reason = models.CreditHoldReleaseCanceled
+	case saleStatus == models.SaleStatusCompleted:
+		reason = models.CreditHoldReleaseShortfall
+	case !now.Before(hold.ExpireDtm):
+		reason = models.CreditHoldReleaseExpired
+	}
+
+	if remain < creditLedgerTolerance || reason != "" {
+		if reason != "" {
+			settled.ReleasedAmount += remain
+			settled.ReleaseReason = reason
+		}
+		settled.Status = models.CreditHoldReleased
+		if settled.ConvertedAmount >= creditLedgerTolerance {
+			settled.Status = models.CreditHoldConverted
+		}
+		settled.SettleDtm = &now
+	}
+
+	if settled.Status == hold.Status && math.Abs(settled.ConvertedAmount-hold.ConvertedAmount) < creditLedgerTolerance {
+		return hold, false
+	}
+	settled.UpdateBy = user
+	settled.UpdateDtm = now
+	return settled, true
+}
+
+type creditHoldSettleRow struct {
+	models.CreditHold
+	SaleStatus string  `db:"sale_status"`
+	Invoiced   float64 `db:"invoiced"`
+}
+
+func buildOpenCreditHoldQuery(customerStrs []string, expiredAt *time.Time) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select h.id, h.customer_code, h.sale_code, h.amount, h.converted_amount, h.released_amount, h.status, h.release_reason
+			, h.expire_dtm, h.settle_dtm, h.create_by, h.create_dtm, h.update_by, h.update_dtm
+			, coalesce(s.status, '') sale_status, coalesce(inv.amount, 0) invoiced
+		from credit_hold h
+		left join sale s on s.sale_code = h.sale_code
+		left join lateral (
+			select sum(coalesce(ii.total_amount, 0)) amount
+			from invoice i
+			join invoice_item ii on i.id = ii.invoice_id
+			where i.status in ('PENDING', 'COMPLETED') and i.invoice_type = 'AR' and ii.document_ref = h.sale_code
+		) inv on true
+		where h.status = ?`, models.CreditHoldOpen)
+	if len(customerStrs) > 0 {
+		qb.Append(`
+			and h.customer_code in `)
+		qb.Append(qb.InStrings(customerStrs))
+	}
+	if expiredAt != nil {
+		qb.Append(`
+			and h.expire_dtm <= ?`, *expiredAt)
+	}
+	qb.Append(`
+		for update of h skip locked`)
+
+	return qb
+}
+
+// settleCreditHolds settles the open holds of customers, or with expiredAt the holds expired by then. Holds a sale
+// transaction has locked are left for the next run.
+func settleCreditHolds(tx *sqlx.Tx, customerStrs []string, expiredAt *time.Time, user string, now time.Time) (int, error) {
+	rows := []creditHoldSettleRow{}
+	query, args := buildOpenCreditHoldQuery(customerStrs, expiredAt).Build()
+	if err := tx.Select(&rows, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to get credit holds: %v", err)
+	}
+
+	settledCount := 0
+	for _, row := range rows {
+		settled, changed := settleCreditHold(row.CreditHold, row.Invoiced, row.SaleStatus, user, now)
+		if !changed {
+			continue
+		}
+		if _, err := tx.NamedExec(`
+			update credit_hold set converted_amount = :converted_amount, released_amount = :released_amount, status = :status
+				, release_reason = :release_reason, settle_dtm = :settle_dtm, update_by = :update_by, update_dtm = :update_dtm
+			where id = :id`, settled); err != nil {
+			return settledCount, fmt.Errorf("failed to settle credit hold of %s: %v", settled.SaleCode, err)
+		}
+		settledCount++
+	}
+	return settledCount, nil
+}
+
+func runCreditHoldExpiry() {
+	sqlx, err := db.DefaultRegistry().Sqlx(`prime_erp`)
+	if err != nil {
+		log.Printf("credit hold expiry: %v\n", err)
+		return
+	}
+
+	tx, err := sqlx.Beginx()
+	if err != nil {
+		log.Printf("credit hold expiry: %v\n", err)
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	settled, err := settleCreditHolds(tx, nil, &now, middleware.SystemUser, now)
+	if err != nil {
+		log.Printf("credit hold expiry: %v\n", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("credit hold expiry: %v\n", err)
+		return
+	}
+	if settled > 0 {
+		log.Printf("credit hold expiry: settled %d holds\n", settled)
+	}
+}
+
+type GetHoldsRequest struct {
+	CustomerCodes []string `json:"customer_codes"`
+	SaleCodes     []string `json:"sale_codes"`
+	Statuses      []string `json:"statuses"` // empty for OPEN
+}
+
+// GetHolds lists credit holds, by default the open ones.
+func GetHolds(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetHoldsRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.CustomerCodes) == 0 && len(req.SaleCodes) == 0 {
+		return nil, errors.New("customer_codes or sale_codes is required")
+	}
+	if len(req.Statuses) == 0 {
+		req.Statuses = []string{models.CreditHoldOpen}
+	}
+
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	qb := db.NewQueryBuilder(`
+		select id, customer_code, sale_code, amount, converted_amount, released_amount, status, release_reason
+			, expire_dtm, settle_dtm, create_by, create_dtm, update_by, update_dtm
+		from credit_hold
+		where status in `)
+	qb.Append(qb.InStrings(req.Statuses))
+	if len(req.CustomerCodes) > 0 {
+		qb.Append(`
+			and customer_code in `)
+		qb.Append(qb.InStrings(req.CustomerCodes))
+	}
+	if len(req.SaleCodes) > 0 {
+		qb.Append(`
+			and sale_code in `)
+		qb.Append(qb.InStrings(req.SaleCodes))
+	}
+	qb.Append(`
+		order by customer_code, create_dtm`)
+
+	return db.SelectBuilder[models.CreditHold](sqlx, qb)
+}
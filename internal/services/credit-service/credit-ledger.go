@@ -0,0 +1,639 @@
+package creditService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"prime-erp-core/internal/cronjob"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	repositoryCredit "prime-erp-core/internal/repositories/credit"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"gorm.io/gorm"
+)
+
+const (
+	// creditLedgerTolerance is the smallest change written to the ledger or reported as drift.
+	creditLedgerTolerance = 0.005
+	reconcileBatchSize    = 500
+)
+
+func init() {
+	outboxService.RegisterHandler(models.OutboxCreditLedgerPost, dispatchCreditLedgerPost)
+	cronjob.RegisterJob("credit-ledger-reconcile", runCreditLedgerReconcile, "0 2 * * *")
+}
+
+func buildCreditLedgerNetQuery(customerStrs []string, buckets []string, asOf *time.Time) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select customer_code, bucket, source_type, source_ref, effective_dtm, expire_dtm, sum(amount) amount
+			, (array_agg(is_active order by id desc) filter (where is_active is not null))[1] is_active
+		from credit_ledger
+		where customer_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+	if len(buckets) > 0 {
+		qb.Append(`
+			and bucket in `)
+		qb.Append(qb.InStrings(buckets))
+	}
+	if asOf != nil {
+		qb.Append(`
+			and event_dtm <= ?`, *asOf)
+	}
+	qb.Append(`
+		group by customer_code, bucket, source_type, source_ref, effective_dtm, expire_dtm
+		order by customer_code, bucket, source_type, source_ref`)
+
+	return qb
+}
+
+// getCreditLedgerNet adds up the ledger entries of each source, optionally of some buckets only and of the events that
+// took effect up to asOf.
+func getCreditLedgerNet(sqlx *sqlx.DB, customerStrs []string, buckets []string, asOf *time.Time) ([]creditContribution, error) {
+	return db.SelectBuilder[creditContribution](sqlx, buildCreditLedgerNetQuery(customerStrs, buckets, asOf))
+}
+
+// getCreditLedgerBalance reads the materialized balances, adding the extra in effect at time at from the ledger.
+func getCreditLedgerBalance(sqlx *sqlx.DB, customerStrs []string, at time.Time) (*GetCreditResponse, error) {
+	qb := db.NewQueryBuilder(`
+		select customer_code, is_active, credit, remain_deposit, used, last_entry_id, update_dtm
+		from credit_balance
+		where customer_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+
+	balances, err := db.SelectBuilder[models.CreditBalance](sqlx, qb)
+	if err != nil {
+		return nil, err
+	}
+
+	contributions, err := getCreditLedgerNet(sqlx, customerStrs, []string{models.CreditBucketExtra}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, balance := range balances {
+		isActive := balance.IsActive
+		contributions = append(contributions,
+			creditContribution{CustomerCode: balance.CustomerCode, Bucket: models.CreditBucketCredit, Amount: balance.Credit, IsActive: &isActive},
+			creditContribution{CustomerCode: balance.CustomerCode, Bucket: models.CreditBucketDeposit, Amount: balance.RemainDeposit},
+			creditContribution{CustomerCode: balance.CustomerCode, Bucket: models.CreditBucketUsed, Amount: balance.Used},
+		)
+	}
+
+	res := summarizeCreditContributions(customerStrs, contributions, at)
+	return &res, nil
+}
+
+// getQueuedCreditLedgerPosts returns the customers with a ledger post still queued.
+func getQueuedCreditLedgerPosts(sqlx *sqlx.DB, customerStrs []string) ([]string, error) {
+	qb := db.NewQueryBuilder(`
+		select distinct aggregate_code
+		from outbox_event
+		where event_type = ? and aggregate_type = ?`, models.OutboxCreditLedgerPost, outboxService.CreditLedgerPostAggregate)
+	qb.Append(`
+			and status in `)
+	qb.Append(qb.InStrings([]string{models.OutboxStatusPending, models.OutboxStatusProcessing}))
+	qb.Append(`
+			and aggregate_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+
+	return db.SelectBuilder[string](sqlx, qb)
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
+
+// creditLedgerDeltas returns the entries that take the ledger from current, the net of each source, to target, the
+// contributions recomputed from the documents. A source that is gone is reversed.
+func creditLedgerDeltas(current []creditContribution, target []creditContribution) []creditContribution {
+	currentByKey := map[string]creditContribution{}
+	for _, c := range current {
+		currentByKey[c.key()] = c
+	}
+
+	deltas := []creditContribution{}
+	targetKeys := map[string]bool{}
+	for _, t := range target {
+		key := t.key()
+		targetKeys[key] = true
+
+		c := currentByKey[key]
+		delta := t
+		delta.Amount = t.Amount - c.Amount
+		activeChanged := t.Bucket == models.CreditBucketCredit && boolValue(t.IsActive) != boolValue(c.IsActive)
+		if math.Abs(delta.Amount) >= creditLedgerTolerance || activeChanged {
+			deltas = append(deltas, delta)
+		}
+	}
+
+	for _, c := range current {
+		if targetKeys[c.key()] {
+			continue
+		}
+		if math.Abs(c.Amount) < creditLedgerTolerance && !boolValue(c.IsActive) {
+			continue
+		}
+
+		delta := c
+		delta.Amount = -c.Amount
+		if c.Bucket == models.CreditBucketCredit {
+			inactive := false
+			delta.IsActive = &inactive
+		}
+		deltas = append(deltas, delta)
+	}
+
+	return deltas
+}
+
+// creditLedgerPostDeltas returns the entries a post writes: for each source it posts, what takes the ledger from what
+// it carries for the source to the posting, and for each replaced source type the reversal of the sources it leaves out.
+func creditLedgerPostDeltas(current []creditContribution, payload models.CreditLedgerPostPayload) []creditContribution {
+	target := []creditContribution{}
+	index := map[string]int{}
+	posted := map[string]bool{}
+	for _, p := range payload.Postings {
+		c := creditContribution{
+			CustomerCode: payload.CustomerCode,
+			Bucket:       p.Bucket,
+			SourceType:   p.SourceType,
+			SourceRef:    p.SourceRef,
+			Amount:       p.Amount,
+			IsActive:     p.IsActive,
+			EffectiveDtm: p.EffectiveDtm,
+			ExpireDtm:    p.ExpireDtm,
+			EventDtm:     p.EventDtm,
+		}
+		posted[c.SourceType+"|"+c.SourceRef] = true
+
+		// A source posted twice in one event stands as posted last
+		if i, ok := index[c.key()]; ok {
+			target[i] = c
+			continue
+		}
+		index[c.key()] = len(target)
+		target = append(target, c)
+	}
+
+	replaced := map[string]bool{}
+	for _, sourceType := range payload.Replace {
+		replaced[sourceType] = true
+	}
+	touched := []creditContribution{}
+	for _, c := range current {
+		if posted[c.SourceType+"|"+c.SourceRef] || replaced[c.SourceType] {
+			touched = append(touched, c)
+		}
+	}
+
+	return creditLedgerDeltas(touched, target)
+}
+
+func lockCreditLedger(tx *sqlx.Tx, customerStrs []string) error {
+	for _, customer := range customerStrs {
+		if _, err := tx.Exec(`select pg_advisory_xact_lock(hashtext($1))`, "credit_ledger:"+customer); err != nil {
+			return fmt.Errorf("failed to lock credit ledger of %s: %v", customer, err)
+		}
+	}
+	return nil
+}
+
+func getCreditLedgerNetTx(tx *sqlx.Tx, customerStrs []string) ([]creditContribution, error) {
+	current := []creditContribution{}
+	query, args := buildCreditLedgerNetQuery(customerStrs, nil, nil).Build()
+	if err := tx.Select(&current, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get credit ledger: %v", err)
+	}
+	return current, nil
+}
+
+// writeCreditLedger appends deltas to the ledger, dated by the event of each or else by eventDtm, and refreshes the
+// customers' balances from the ledger.
+func writeCreditLedger(tx *sqlx.Tx, customerStrs []string, deltas []creditContribution, eventDtm time.Time, user string, now time.Time) ([]models.CreditLedgerEntry, error) {
+	entries := []models.CreditLedgerEntry{}
+	for _, delta := range deltas {
+		entryEventDtm := delta.EventDtm
+		if entryEventDtm.IsZero() {
+			entryEventDtm = eventDtm
+		}
+		entries = append(entries, models.CreditLedgerEntry{
+			CustomerCode: delta.CustomerCode,
+			Bucket:       delta.Bucket,
+			SourceType:   delta.SourceType,
+			SourceRef:    delta.SourceRef,
+			Amount:       delta.Amount,
+			IsActive:     delta.IsActive,
+			EffectiveDtm: delta.EffectiveDtm,
+			ExpireDtm:    delta.ExpireDtm,
+			EventDtm:     entryEventDtm,
+			EntryDtm:     now,
+			CreateBy:     user,
+		})
+	}
+	if len(entries) > 0 {
+		if _, err := tx.NamedExec(`
+			insert into credit_ledger (customer_code, bucket, source_type, source_ref, amount, is_active, effective_dtm, expire_dtm, event_dtm, entry_dtm, create_by)
+			values (:customer_code, :bucket, :source_type, :source_ref, :amount, :is_active, :effective_dtm, :expire_dtm, :event_dtm, :entry_dtm, :create_by)`,
+			entries); err != nil {
+			return nil, fmt.Errorf("failed to write credit ledger: %v", err)
+		}
+	}
+
+	net, err := getCreditLedgerNetTx(tx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+	balances := []models.CreditBalance{}
+	for _, customer := range summarizeCreditContributions(customerStrs, net, now).CreditCustomers {
+		balances = append(balances, models.CreditBalance{
+			CustomerCode:  customer.CustomerCode,
+			IsActive:      customer.IsActive,
+			Credit:        customer.Credit,
+			RemainDeposit: customer.RemainDeposit,
+			Used:          customer.Used,
+			UpdateDtm:     now,
+		})
+	}
+	if _, err := tx.NamedExec(`
+		insert into credit_balance (customer_code, is_active, credit, remain_deposit, used, last_entry_id, update_dtm)
+		values (:customer_code, :is_active, :credit, :remain_deposit, :used
+			, (select coalesce(max(id), 0) from credit_ledger where customer_code = :customer_code), :update_dtm)
+		on conflict (customer_code) do update set
+			is_active = excluded.is_active, credit = excluded.credit, remain_deposit = excluded.remain_deposit
+			, used = excluded.used, last_entry_id = excluded.last_entry_id, update_dtm = excluded.update_dtm`,
+		balances); err != nil {
+		return nil, fmt.Errorf("failed to update credit balance: %v", err)
+	}
+
+	return entries, nil
+}
+
+// PostCreditLedger writes the postings of a business event to a customer's ledger, refreshes their balance and settles
+// their credit holds. Reversals of replaced sources are dated eventDtm. The customer is locked for the post, and posting
+// an event again writes nothing.
+func PostCreditLedger(sqlx *sqlx.DB, payload models.CreditLedgerPostPayload, eventDtm time.Time, user string) ([]models.CreditLedgerEntry, error) {
+	if payload.CustomerCode == "" {
+		return nil, errors.New("credit ledger post has no customer")
+	}
+	customerStrs := []string{payload.CustomerCode}
+
+	tx, err := sqlx.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockCreditLedger(tx, customerStrs); err != nil {
+		return nil, err
+	}
+	current, err := getCreditLedgerNetTx(tx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entries, err := writeCreditLedger(tx, customerStrs, creditLedgerPostDeltas(current, payload), eventDtm, user, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := settleCreditHolds(tx, customerStrs, nil, user, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SyncCreditLedger brings customers' ledger in line with the contributions recomputed from their documents, writing
+// what differs as correcting entries dated now. It repairs the drift a reconciliation found, and opens the ledger of
+// customers whose documents predate it; the ledger is otherwise posted by the business events.
+func SyncCreditLedger(sqlx *sqlx.DB, customerCodes []string, user string) ([]models.CreditLedgerEntry, error) {
+	customerStrs := uniqueCustomerCodes(customerCodes)
+	if len(customerStrs) == 0 {
+		return nil, errors.New("no customer to sync")
+	}
+	sort.Strings(customerStrs)
+
+	tx, err := sqlx.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockCreditLedger(tx, customerStrs); err != nil {
+		return nil, err
+	}
+	current, err := getCreditLedgerNetTx(tx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := getCreditContributions(sqlx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entries, err := writeCreditLedger(tx, customerStrs, creditLedgerDeltas(current, target), now, user, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := settleCreditHolds(tx, customerStrs, nil, user, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// creditCustomersOf returns the customers of credits and of credit extras, for posting their credit. A failed lookup
+// is logged and posts nothing; the reconciliation reports what is missed.
+func creditCustomersOf(ctx *gin.Context, creditIDs []uuid.UUID, extraIDs []uuid.UUID) []string {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		log.Printf("credit ledger post: %v\n", err)
+		return nil
+	}
+
+	customerCodes := []string{}
+	if len(creditIDs) > 0 {
+		if err := gormx.Model(&models.Credit{}).Where("id IN ?", creditIDs).Distinct().Pluck("customer_code", &customerCodes).Error; err != nil {
+			log.Printf("credit ledger post: failed to get credit customers: %v\n", err)
+		}
+	}
+	if len(extraIDs) > 0 {
+		extraCustomers := []string{}
+		if err := gormx.Table("credit_extra ce").Joins("join credit c on c.id = ce.credit_id").
+			Where("ce.id IN ?", extraIDs).Distinct().Pluck("c.customer_code", &extraCustomers).Error; err != nil {
+			log.Printf("credit ledger post: failed to get credit extra customers: %v\n", err)
+		}
+		customerCodes = append(customerCodes, extraCustomers...)
+	}
+	return customerCodes
+}
+
+// queueCreditLedgerPost posts the credits of customers with their extras as they now stand, reversing the ones that
+// are gone.
+func queueCreditLedgerPost(ctx *gin.Context, sourceRef string, customerCodes []string) {
+	customerStrs := uniqueCustomerCodes(customerCodes)
+	if len(customerStrs) == 0 {
+		return
+	}
+	outboxService.QueueCreditLedgerPostAfter(ctx, outboxService.CreditLedgerPost{
+		SourceType:    models.CreditSourceCredit,
+		SourceRef:     sourceRef,
+		Replace:       repositoryCredit.CreditLedgerReplaceCredit,
+		CustomerCodes: customerStrs,
+	}, func(tx *gorm.DB) ([]models.CreditLedgerPosting, error) {
+		return repositoryCredit.CreditPostings(tx, customerStrs, time.Now())
+	})
+}
+
+// dispatchCreditLedgerPost posts a business event queued with the change to the customer's documents. Reversals are
+// dated when the event was queued.
+func dispatchCreditLedgerPost(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+	var payload models.CreditLedgerPostPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid credit ledger post payload: %v", err)
+	}
+
+	sqlx, err := db.DefaultRegistry().Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := PostCreditLedger(sqlx, payload, event.CreateDtm, event.CreateBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"customer_code": payload.CustomerCode,
+		"entries":       len(entries),
+	}, nil
+}
+
+type SyncCreditLedgerRequest struct {
+	CustomerCodes []string `json:"customer_codes"`
+}
+
+// SyncCreditLedgerAPI syncs customers on demand, after a reconciliation found drift or to open their ledger.
+func SyncCreditLedgerAPI(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := SyncCreditLedgerRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.CustomerCodes) == 0 {
+		return nil, fmt.Errorf("require at least one customer code")
+	}
+
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	return SyncCreditLedger(sqlx, req.CustomerCodes, middleware.GetUserCode(ctx))
+}
+
+type GetCreditLedgerRequest struct {
+	CustomerCode string     `json:"customer_code"`
+	From         *time.Time `json:"from"`
+	To           *time.Time `json:"to"`
+}
+
+// GetCreditLedger lists a customer's ledger entries in the order their events took effect.
+func GetCreditLedger(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetCreditLedgerRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if req.CustomerCode == "" {
+		return nil, errors.New("customer_code is required")
+	}
+
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	qb := db.NewQueryBuilder(`
+		select id, customer_code, bucket, source_type, source_ref, amount, is_active, effective_dtm, expire_dtm, event_dtm, entry_dtm, create_by
+		from credit_ledger
+		where customer_code = ?`, req.CustomerCode)
+	if req.From != nil {
+		qb.Append(` and event_dtm >= ?`, *req.From)
+	}
+	if req.To != nil {
+		qb.Append(` and event_dtm <= ?`, *req.To)
+	}
+	qb.Append(`
+		order by event_dtm, id`)
+
+	return db.SelectBuilder[models.CreditLedgerEntry](sqlx, qb)
+}
+
+type ReconcileCreditLedgerRequest struct {
+	CustomerCodes []string `json:"customer_codes"` // empty for every customer with a balance
+}
+
+type ReconcileCreditLedgerResponse struct {
+	RunID   uuid.UUID                  `json:"run_id"`
+	Checked int                        `json:"checked"`
+	Skipped []string                   `json:"skipped"` // customers with a ledger post still queued
+	Drifts  []models.CreditLedgerDrift `json:"drifts"`
+}
+
+// ReconcileCreditLedger runs the reconciliation on demand.
+func ReconcileCreditLedger(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := ReconcileCreditLedgerRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	return reconcileCreditLedger(sqlx, req.CustomerCodes, time.Now())
+}
+
+func runCreditLedgerReconcile() {
+	sqlx, err := db.DefaultRegistry().Sqlx(`prime_erp`)
+	if err != nil {
+		log.Printf("credit ledger reconcile: %v\n", err)
+		return
+	}
+
+	res, err := reconcileCreditLedger(sqlx, nil, time.Now())
+	if err != nil {
+		log.Printf("credit ledger reconcile: %v\n", err)
+		return
+	}
+	if len(res.Drifts) > 0 {
+		log.Printf("credit ledger reconcile: run %s, %d of %d customers drifted\n", res.RunID, len(res.Drifts), res.Checked)
+	}
+}
+
+// creditLedgerDrifts compares the ledger with the recomputation, customer by customer, and returns those that differ.
+func creditLedgerDrifts(runID uuid.UUID, ledger []CreditCustomer, computed []CreditCustomer, now time.Time) []models.CreditLedgerDrift {
+	computedByCustomer := map[string]CreditCustomer{}
+	for _, customer := range computed {
+		computedByCustomer[customer.CustomerCode] = customer
+	}
+
+	differs := func(a, b float64) bool { return math.Abs(a-b) >= creditLedgerTolerance }
+
+	drifts := []models.CreditLedgerDrift{}
+	for _, l := range ledger {
+		c := computedByCustomer[l.CustomerCode]
+		if l.IsActive == c.IsActive && !differs(l.Credit, c.Credit) && !differs(l.Extra, c.Extra) &&
+			!differs(l.RemainDeposit, c.RemainDeposit) && !differs(l.Used, c.Used) {
+			continue
+		}
+
+		drifts = append(drifts, models.CreditLedgerDrift{
+			ID:                    uuid.New(),
+			RunID:                 runID,
+			CustomerCode:          l.CustomerCode,
+			LedgerIsActive:        l.IsActive,
+			LedgerCredit:          l.Credit,
+			LedgerExtra:           l.Extra,
+			LedgerRemainDeposit:   l.RemainDeposit,
+			LedgerUsed:            l.Used,
+			ComputedIsActive:      c.IsActive,
+			ComputedCredit:        c.Credit,
+			ComputedExtra:         c.Extra,
+			ComputedRemainDeposit: c.RemainDeposit,
+			ComputedUsed:          c.Used,
+			Difference:            l.Balance - c.Balance,
+			CreateDtm:             now,
+		})
+	}
+	return drifts
+}
+
+// reconcileCreditLedger compares the ledger balance of customers with the one recomputed from their documents and
+// records the ones that drifted. Customers with a ledger post still queued are expected to differ and are skipped.
+func reconcileCreditLedger(sqlx *sqlx.DB, customerCodes []string, now time.Time) (ReconcileCreditLedgerResponse, error) {
+	res := ReconcileCreditLedgerResponse{RunID: uuid.New(), Skipped: []string{}, Drifts: []models.CreditLedgerDrift{}}
+
+	customerStrs := uniqueCustomerCodes(customerCodes)
+	if len(customerStrs) == 0 {
+		all, err := db.Select[string](sqlx, `select customer_code from credit_balance order by customer_code`)
+		if err != nil {
+			return res, err
+		}
+		customerStrs = all
+	}
+
+	for start := 0; start < len(customerStrs); start += reconcileBatchSize {
+		batch := customerStrs[start:min(start+reconcileBatchSize, len(customerStrs))]
+
+		queued, err := getQueuedCreditLedgerPosts(sqlx, batch)
+		if err != nil {
+			return res, err
+		}
+		if len(queued) > 0 {
+			skip := map[string]bool{}
+			for _, customer := range queued {
+				skip[customer] = true
+			}
+			checked := []string{}
+			for _, customer := range batch {
+				if skip[customer] {
+					res.Skipped = append(res.Skipped, customer)
+				} else {
+					checked = append(checked, customer)
+				}
+			}
+			batch = checked
+		}
+		if len(batch) == 0 {
+			continue
+		}
+
+		ledger, err := getCreditLedgerBalance(sqlx, batch, now)
+		if err != nil {
+			return res, err
+		}
+		computed, err := recomputeCreditCurrent(sqlx, batch, now)
+		if err != nil {
+			return res, err
+		}
+
+		res.Checked += len(batch)
+		res.Drifts = append(res.Drifts, creditLedgerDrifts(res.RunID, ledger.CreditCustomers, computed.CreditCustomers, now)...)
+	}
+
+	if len(res.Drifts) > 0 {
+		if _, err := sqlx.NamedExec(`
+			insert into credit_ledger_drift (id, run_id, customer_code
+				, ledger_is_active, ledger_credit, ledger_extra, ledger_remain_deposit, ledger_used
+				, computed_is_active, computed_credit, computed_extra, computed_remain_deposit, computed_used
+				, difference, create_dtm)
+			values (:id, :run_id, :customer_code
+				, :ledger_is_active, :ledger_credit, :ledger_extra, :ledger_remain_deposit, :ledger_used
+				, :computed_is_active, :computed_credit, :computed_extra, :computed_remain_deposit, :computed_used
+				, :difference, :create_dtm)`,
+			res.Drifts); err != nil {
+			return res, fmt.Errorf("failed to record credit ledger drift: %v", err)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,231 @@
+package creditService
+
+import (
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestCreditLedgerDeltas(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+	mar := feb.AddDate(0, 1, 0)
+
+	current := []creditContribution{
+		{CustomerCode: "C1", Bucket: models.CreditBucketCredit, SourceType: models.CreditSourceCredit, SourceRef: "CR1", Amount: 1000, IsActive: boolPtr(true)},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourceSale, SourceRef: "SO1", Amount: 300},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourceSale, SourceRef: "SO2", Amount: 200},
+		{CustomerCode: "C1", Bucket: models.CreditBucketExtra, SourceType: models.CreditSourceExtra, SourceRef: "EX1", Amount: 100, EffectiveDtm: &jan, ExpireDtm: &feb},
+	}
+	target := []creditContribution{
+		{CustomerCode: "C1", Bucket: models.CreditBucketCredit, SourceType: models.CreditSourceCredit, SourceRef: "CR1", Amount: 1000, IsActive: boolPtr(false)},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourceSale, SourceRef: "SO1", Amount: 300.001},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourcePayment, SourceRef: "INV1", Amount: -50},
+		{CustomerCode: "C1", Bucket: models.CreditBucketExtra, SourceType: models.CreditSourceExtra, SourceRef: "EX1", Amount: 100, EffectiveDtm: &jan, ExpireDtm: &mar},
+	}
+
+	deltas := creditLedgerDeltas(current, target)
+	require.Len(t, deltas, 5)
+
+	assert.Equal(t, models.CreditSourceCredit, deltas[0].SourceType, "deactivating the credit is an entry of its own")
+	assert.Equal(t, 0.0, deltas[0].Amount)
+	assert.False(t, *deltas[0].IsActive)
+
+	assert.Equal(t, "INV1", deltas[1].SourceRef, "a new source is added in full")
+	assert.Equal(t, -50.0, deltas[1].Amount)
+
+	assert.Equal(t, "EX1", deltas[2].SourceRef, "a moved window is a new extra...")
+	assert.Equal(t, 100.0, deltas[2].Amount)
+	assert.Equal(t, mar, *deltas[2].ExpireDtm)
+
+	assert.Equal(t, "SO2", deltas[3].SourceRef, "a source that is gone is reversed")
+	assert.Equal(t, -200.0, deltas[3].Amount)
+
+	assert.Equal(t, "EX1", deltas[4].SourceRef, "...and the old window is reversed")
+	assert.Equal(t, -100.0, deltas[4].Amount)
+	assert.Equal(t, feb, *deltas[4].ExpireDtm)
+
+	assert.Empty(t, creditLedgerDeltas(target, target), "syncing again writes nothing")
+}
+
+func TestCreditLedgerPostDeltas(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+
+	current := []creditContribution{
+		{CustomerCode: "C1", Bucket: models.CreditBucketCredit, SourceType: models.CreditSourceCredit, SourceRef: "CR1", Amount: 1000, IsActive: boolPtr(true)},
+		{CustomerCode: "C1", Bucket: models.CreditBucketExtra, SourceType: models.CreditSourceExtra, SourceRef: "EX1", Amount: 100},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourceSale, SourceRef: "SO1", Amount: 300},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourceSale, SourceRef: "SO2", Amount: 200},
+	}
+
+	t.Run("a source is posted as it now stands", func(t *testing.T) {
+		deltas := creditLedgerPostDeltas(current, models.CreditLedgerPostPayload{
+			CustomerCode: "C1",
+			Postings: []models.CreditLedgerPosting{
+				{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourceSale, SourceRef: "SO1", Amount: 0, EventDtm: jan},
+				{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourcePayment, SourceRef: "INV1", Amount: -50, EventDtm: feb},
+			},
+		})
+		require.Len(t, deltas, 2, "the sources the event does not post are left alone")
+		assert.Equal(t, "SO1", deltas[0].SourceRef)
+		assert.Equal(t, -300.0, deltas[0].Amount)
+		assert.Equal(t, jan, deltas[0].EventDtm, "an entry is dated by the event that posted it")
+		assert.Equal(t, "INV1", deltas[1].SourceRef)
+		assert.Equal(t, -50.0, deltas[1].Amount)
+		assert.Equal(t, feb, deltas[1].EventDtm)
+	})
+
+	t.Run("replaced source types are reversed when left out", func(t *testing.T) {
+		deltas := creditLedgerPostDeltas(current, models.CreditLedgerPostPayload{
+			CustomerCode: "C1",
+			Replace:      []string{models.CreditSourceCredit, models.CreditSourceExtra},
+			Postings: []models.CreditLedgerPosting{
+				{CustomerCode: "C1", Bucket: models.CreditBucketCredit, SourceType: models.CreditSourceCredit, SourceRef: "CR1", Amount: 1500, IsActive: boolPtr(true), EventDtm: feb},
+			},
+		})
+		require.Len(t, deltas, 2)
+		assert.Equal(t, "CR1", deltas[0].SourceRef)
+		assert.Equal(t, 500.0, deltas[0].Amount)
+		assert.Equal(t, "EX1", deltas[1].SourceRef)
+		assert.Equal(t, -100.0, deltas[1].Amount)
+		assert.True(t, deltas[1].EventDtm.IsZero(), "a reversal takes the date of the post")
+	})
+
+	t.Run("posting again writes nothing", func(t *testing.T) {
+		assert.Empty(t, creditLedgerPostDeltas(current, models.CreditLedgerPostPayload{
+			CustomerCode: "C1",
+			Postings: []models.CreditLedgerPosting{
+				{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceType: models.CreditSourceSale, SourceRef: "SO2", Amount: 200, EventDtm: jan},
+			},
+		}))
+	})
+}
+
+func TestSummarizeCreditContributions(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+	mar := feb.AddDate(0, 1, 0)
+	mid := jan.AddDate(0, 0, 45)
+
+	contributions := []creditContribution{
+		{CustomerCode: "C1", Bucket: models.CreditBucketCredit, SourceRef: "CR1", Amount: 1000, IsActive: boolPtr(true)},
+		{CustomerCode: "C1", Bucket: models.CreditBucketExtra, SourceRef: "EX1", Amount: 100, EffectiveDtm: &jan, ExpireDtm: &mar},
+		{CustomerCode: "C1", Bucket: models.CreditBucketExtra, SourceRef: "EX2", Amount: 250, EffectiveDtm: &feb, ExpireDtm: &mar},
+		{CustomerCode: "C1", Bucket: models.CreditBucketDeposit, SourceRef: "DP1", Amount: 80},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceRef: "SO1", Amount: 500},
+		{CustomerCode: "C1", Bucket: models.CreditBucketUsed, SourceRef: "INV1", Amount: -120},
+		{CustomerCode: "OTHER", Bucket: models.CreditBucketUsed, SourceRef: "SO9", Amount: 999},
+	}
+
+	res := summarizeCreditContributions([]string{"C1", "C2"}, contributions, mid)
+	require.Len(t, res.CreditCustomers, 2)
+
+	c1 := res.CreditCustomers[0]
+	assert.True(t, c1.IsActive)
+	assert.Equal(t, 250.0, c1.Extra, "of the extras in effect, the one that took effect last counts")
+	assert.Equal(t, 80.0, c1.RemainDeposit)
+	assert.Equal(t, 380.0, c1.Used)
+	assert.Equal(t, 950.0, c1.Balance)
+
+	assert.Equal(t, CreditCustomer{CustomerCode: "C2"}, res.CreditCustomers[1])
+
+	before := summarizeCreditContributions([]string{"C1"}, contributions, jan.AddDate(0, 0, 10))
+	assert.Equal(t, 100.0, before.CreditCustomers[0].Extra)
+	after := summarizeCreditContributions([]string{"C1"}, contributions, mar.AddDate(0, 0, 1))
+	assert.Equal(t, 0.0, after.CreditCustomers[0].Extra)
+}
+
+func TestCreditLedgerDrifts(t *testing.T) {
+	runID := uuid.New()
+	ledger := []CreditCustomer{
+		{CustomerCode: "C1", IsActive: true, Credit: 1000, Used: 300, Balance: 700},
+		{CustomerCode: "C2", IsActive: true, Credit: 500, Used: 100.001, Balance: 399.999},
+	}
+	computed := []CreditCustomer{
+		{CustomerCode: "C1", IsActive: true, Credit: 1000, Used: 450, Balance: 550},
+		{CustomerCode: "C2", IsActive: true, Credit: 500, Used: 100, Balance: 400},
+	}
+
+	drifts := creditLedgerDrifts(runID, ledger, computed, time.Now())
+	require.Len(t, drifts, 1)
+	assert.Equal(t, "C1", drifts[0].CustomerCode)
+	assert.Equal(t, runID, drifts[0].RunID)
+	assert.Equal(t, 300.0, drifts[0].LedgerUsed)
+	assert.Equal(t, 450.0, drifts[0].ComputedUsed)
+	assert.Equal(t, 150.0, drifts[0].Difference)
+}
+
+func TestCreditLedgerNetQuery_BindsValues(t *testing.T) {
+	asOf := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	qb := buildCreditLedgerNetQuery(hostileCustomerCodes, []string{models.CreditBucketExtra}, &asOf)
+	assertBoundLiterals(t, qb, hostileCustomerCodes)
+
+	query, args := qb.Build()
+	assert.Contains(t, query, "bucket in ($4)")
+	assert.Contains(t, query, "event_dtm <= $5")
+	assert.Equal(t, asOf, args[4])
+}
+
+func TestSettleCreditHold(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	open := models.CreditHold{SaleCode: "SO1", Amount: 1000, Status: models.CreditHoldOpen, ExpireDtm: now.AddDate(0, 0, 10)}
+
+	_, changed := settleCreditHold(open, 0, models.SaleStatusApproved, "system", now)
+	assert.False(t, changed, "an open sale with nothing invoiced keeps its hold")
+
+	partial, changed := settleCreditHold(open, 400, models.SaleStatusPartialDelivered, "system", now)
+	require.True(t, changed)
+	assert.Equal(t, models.CreditHoldOpen, partial.Status)
+	assert.Equal(t, 400.0, partial.ConvertedAmount)
+	assert.Equal(t, 600.0, partial.OpenAmount())
+
+	full, _ := settleCreditHold(open, 1200, models.SaleStatusDelivered, "system", now)
+	assert.Equal(t, models.CreditHoldConverted, full.Status)
+	assert.Equal(t, 1000.0, full.ConvertedAmount, "no more than the hold is converted")
+	assert.Empty(t, full.ReleaseReason)
+
+	shortfall, _ := settleCreditHold(open, 900, models.SaleStatusCompleted, "system", now)
+	assert.Equal(t, models.CreditHoldConverted, shortfall.Status)
+	assert.Equal(t, models.CreditHoldReleaseShortfall, shortfall.ReleaseReason)
+	assert.Equal(t, 100.0, shortfall.ReleasedAmount)
+
+	canceled, _ := settleCreditHold(open, 0, models.SaleStatusCanceled, "system", now)
+	assert.Equal(t, models.CreditHoldReleased, canceled.Status)
+	assert.Equal(t, models.CreditHoldReleaseCanceled, canceled.ReleaseReason)
+	assert.Equal(t, 1000.0, canceled.ReleasedAmount)
+
+	expired, _ := settleCreditHold(open, 0, models.SaleStatusApproved, "system", now.AddDate(0, 0, 11))
+	assert.Equal(t, models.CreditHoldReleaseExpired, expired.ReleaseReason)
+	assert.Equal(t, 0.0, expired.OpenAmount())
+
+	_, changed = settleCreditHold(canceled, 0, models.SaleStatusCanceled, "system", now)
+	assert.False(t, changed, "a settled hold stays as it is")
+}
+
+func TestSaleCreditExposure(t *testing.T) {
+	exposure := saleCreditExposure(
+		[]saleAmountRow{{SaleCode: "SO1", Amount: 500}, {SaleCode: "SO2", Amount: 0.001}},
+		[]saleAmountRow{{SaleCode: "SO1", Amount: 450}, {SaleCode: "SO2", Amount: 300}},
+	)
+	assert.Equal(t, 500.0, exposure["SO1"], "a sale the ledger carries counts once, by the ledger")
+	assert.Equal(t, 300.0, exposure["SO2"], "a sale the ledger does not carry counts by its hold")
+	assert.Zero(t, exposure["SO3"])
+}
+
+func TestCreditHeldQuery_BindsValues(t *testing.T) {
+	at := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+	qb := buildCreditHeldQuery(hostileCustomerCodes, at)
+	assertBoundLiterals(t, qb, hostileCustomerCodes)
+
+	_, args := qb.Build()
+	assert.Equal(t, []interface{}{models.CreditHoldOpen, at}, args[:2])
+	assert.Equal(t, models.CreditSourceSale, args[len(args)-2])
+}
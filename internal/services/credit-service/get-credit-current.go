@@ -1,396 +1,551 @@
-package creditService
-
-import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"prime-erp-core/internal/db"
-	"strings"
-
-	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
-)
-
-type GetCreditRequest struct {
-	CustomerCodes []string `json:"customer_codes"`
-}
-
-type GetCreditResponse struct {
-	CreditCustomers []CreditCustomer `json:"credit_customers"`
-}
-
-type CreditCustomer struct {
-	CustomerCode  string  `json:"customer_code"`
-	IsActive      bool    `json:"is_active"`
-	Credit        float64 `json:"credit"`
-	Extra         float64 `json:"extra"`
-	RemainDeposit float64 `json:"remain_deposit"`
-	Used          float64 `json:"used"`
-	Balance       float64 `json:"balance"`
-}
-
-func GetCreditCurrentAPI(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-	req := GetCreditRequest{}
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-
-	sqlx, err := db.ConnectSqlx(`prime_erp`)
-	if err != nil {
-		return nil, err
-	}
-	defer sqlx.Close()
-
-	if len(req.CustomerCodes) == 0 {
-		return nil, fmt.Errorf("require at least one customer code")
-	}
-
-	return GetCreditCurrent(sqlx, req)
-}
-
-func GetCreditCurrent(sqlx *sqlx.DB, req GetCreditRequest) (*GetCreditResponse, error) {
-	res := GetCreditResponse{}
-	customerCheck := map[string]bool{}
-	customerStrs := []string{}
-
-	for _, customer := range req.CustomerCodes {
-		if !customerCheck[customer] {
-			res.CreditCustomers = append(res.CreditCustomers, CreditCustomer{
-				CustomerCode:  customer,
-				IsActive:      false,
-				Credit:        0,
-				Extra:         0,
-				RemainDeposit: 0,
-				Used:          0,
-				Balance:       0,
-			})
-
-			customerStrs = append(customerStrs, customer)
-			customerCheck[customer] = true
-		}
-	}
-
-	if len(customerStrs) == 0 {
-		return nil, fmt.Errorf("no customer to check credit")
-	}
-
-	res, err := getCreditByCustomer(sqlx, res, customerStrs)
-	if err != nil {
-		return nil, err
-	}
-
-	res, err = getDepositByCustomer(sqlx, res, customerStrs)
-	if err != nil {
-		return nil, err
-	}
-
-	res, err = getUsedByCustomer(sqlx, res, customerStrs)
-	if err != nil {
-		return nil, err
-	}
-
-	for i, customer := range res.CreditCustomers {
-		customer.Balance = customer.Credit + customer.Extra + customer.RemainDeposit - customer.Used
-		res.CreditCustomers[i] = customer
-	}
-
-	return &res, nil
-}
-
-func getDepositByCustomer(sqlx *sqlx.DB, res GetCreditResponse, customerStrs []string) (GetCreditResponse, error) {
-	query := fmt.Sprintf(`
-		select customer_code , sum(coalesce (amount_total ,0)) amount_total , sum(coalesce(amount_used,0))  amount_used,  sum(coalesce (amount_remain, 0)) amount_remain
-		from deposit d 
-		where customer_code in ('%s')
-		group by customer_code
-	`, strings.Join(customerStrs, `','`))
-	rows, err := db.ExecuteQuery(sqlx, query)
-	if err != nil {
-		return res, err
-	}
-
-	for _, row := range rows {
-		customerCode := row["customer_code"].(string)
-		amountRemain := row["amount_remain"].(float64)
-
-		for i, customer := range res.CreditCustomers {
-			if customer.CustomerCode == customerCode {
-				customer.RemainDeposit += amountRemain
-				res.CreditCustomers[i] = customer
-				break
-			}
-		}
-	}
-
-	return res, nil
-}
-
-func getCreditByCustomer(sqlx *sqlx.DB, res GetCreditResponse, customerStrs []string) (GetCreditResponse, error) {
-	query := fmt.Sprintf(`
-		select c.customer_code , coalesce(c.amount,0) credit_amount, coalesce (c.is_active,false) credit_is_active
-			, coalesce(ce.amount, 0) extra_amount
-		from credit c 
-		left join credit_extra ce ON c.id = ce.credit_id  and ce.expire_dtm >= now() and ce.effective_dtm <= now()
-		where 1=1
-		and customer_code in ('%s')
-	`, strings.Join(customerStrs, `','`))
-	rows, err := db.ExecuteQuery(sqlx, query)
-	if err != nil {
-		return res, err
-	}
-
-	for _, row := range rows {
-		customerCode := row["customer_code"].(string)
-		creditAmount := row["credit_amount"].(float64)
-		creditIsActive := row["credit_is_active"].(bool)
-		extraAmount := row["extra_amount"].(float64)
-
-		for i, customer := range res.CreditCustomers {
-			if customer.CustomerCode == customerCode {
-				customer.IsActive = creditIsActive
-				customer.Credit = creditAmount
-				customer.Extra = extraAmount
-
-				res.CreditCustomers[i] = customer
-			}
-		}
-	}
-
-	return res, nil
-}
-
-func getUsedByCustomer(sqlx *sqlx.DB, res GetCreditResponse, customerStrs []string) (GetCreditResponse, error) {
-	type customer struct {
-		CustomerCode       string
-		TotalPrice         float64
-		TotalTransportCost float64
-		Paid               float64
-	}
-
-	//Sale Order
-	querySale := fmt.Sprintf(`
-		select s.sale_code ,s.customer_code, coalesce(s.total_amount, 0) total_amount , coalesce(s.total_transport_cost, 0)  total_transport_cost
-			, coalesce(s.transport_cost_type, '') transport_cost_type
-		from sale s 
-		where s.status = 'PENDING' and (s.is_approved = true or s.status_approve = 'COMPLETED')  
-			and s.customer_code in ('%s')
-	`, strings.Join(customerStrs, `','`))
-	rowsSale, err := db.ExecuteQuery(sqlx, querySale)
-	if err != nil {
-		return res, err
-	}
-
-	if len(rowsSale) == 0 {
-		return res, nil
-	}
-
-	saleCodes := []string{}
-	saleCodesMap := map[string]bool{}
-	custMap := map[string]customer{}
-	for _, row := range rowsSale {
-		saleCode := row["sale_code"].(string)
-		customerCode := row["customer_code"].(string)
-		totalAmount := row["total_amount"].(float64)
-		totalTransportCost := row["total_transport_cost"].(float64)
-		transportCostType := row["transport_cost_type"].(string)
-		transportCost := 0.0
-
-		if transportCostType == "EXCL" {
-			transportCost = totalTransportCost
-		}
-
-		if _, ok := saleCodesMap[saleCode]; !ok {
-			saleCodes = append(saleCodes, saleCode)
-			saleCodesMap[saleCode] = true
-		}
-
-		cust, existsCust := custMap[customerCode]
-		if !existsCust {
-			cust = customer{
-				CustomerCode:       customerCode,
-				TotalPrice:         0,
-				TotalTransportCost: 0,
-				Paid:               0,
-			}
-		}
-
-		cust.TotalPrice += totalAmount
-		cust.TotalTransportCost += transportCost
-		custMap[customerCode] = cust
-	}
-
-	//Invoice
-	queryInv := fmt.Sprintf(`
-		select i.invoice_code, coalesce(i.party_code, '') customer_code, i.invoice_type 
- 			, ii.invoice_item 
- 			, coalesce(ii.document_ref, '') as sale_code, coalesce(ii.document_ref_item, '') as sale_item
-			, i.party_code as customer_code
- 		from invoice i 
- 		left join invoice_item ii on i.id = ii.invoice_id 
- 		where i.status in ('PENDING', 'COMPLETED') and i.invoice_type = 'AR'
-			and i.party_code in ('%s')
-			and ii.document_ref in ('%s')
-	`, strings.Join(customerStrs, `','`), strings.Join(saleCodes, `','`))
-	rowsInv, err := db.ExecuteQuery(sqlx, queryInv)
-	if err != nil {
-		return res, err
-	}
-
-	if len(rowsInv) != 0 {
-		invoiceCodeMap := map[string]string{}
-		invoiceCodeItem := map[string]string{}
-		saleCodeItem := map[string]string{}
-		invoiceCustomerMap := map[string]string{}
-
-		for _, row := range rowsInv {
-			customerCode := row["customer_code"].(string)
-			invoiceCode := row["invoice_code"].(string)
-			invoiceItem := row["invoice_item"].(string)
-			saleCode := row["sale_code"].(string)
-			saleItem := row["sale_item"].(string)
-
-			if _, ok := invoiceCodeMap[invoiceCode]; !ok {
-				invoiceCodeMap[invoiceCode] = invoiceCode
-			}
-
-			invoiceCodeItemKey := invoiceCode + "|" + invoiceItem
-			if _, ok := invoiceCodeItem[invoiceCodeItemKey]; !ok {
-				invoiceCodeItem[invoiceCodeItemKey] = fmt.Sprintf(`('%s','%s')`, invoiceCode, invoiceItem)
-			}
-
-			saleCodeItemKey := saleCode + "|" + saleItem
-			if _, ok := saleCodeItem[saleCodeItemKey]; !ok {
-				saleCodeItem[saleCodeItemKey] = fmt.Sprintf(`('%s','%s')`, saleCode, saleItem)
-			}
-
-			if _, ok := invoiceCustomerMap[invoiceCode]; !ok {
-				invoiceCustomerMap[invoiceCode] = customerCode
-			}
-		}
-
-		invoiceCodeString := mapToString(invoiceCodeMap)
-
-		//AR Payment
-		queryPayment := fmt.Sprintf(`
-			select t.invoice_code , coalesce(t.amount, 0) amount
-			from payment_invoice t 
-			where t.invoice_code in ('%s')
-		`, strings.Join(invoiceCodeString, `','`))
-		fmt.Println(queryPayment)
-		rowsPayment, err := db.ExecuteQuery(sqlx, queryPayment)
-		if err != nil {
-			return res, err
-		}
-
-		for _, row := range rowsPayment {
-			invoiceCode := row["invoice_code"].(string)
-			amount := row["amount"].(float64)
-
-			customerCode := invoiceCustomerMap[invoiceCode]
-			cust := custMap[customerCode]
-			cust.Paid += amount
-			custMap[customerCode] = cust
-		}
-
-		invoiceCodeItemString := mapToString(invoiceCodeItem)
-
-		//DN & CN
-		queryDN := fmt.Sprintf(`
-			select i.invoice_code, i.invoice_type
-				, ii.document_ref as invoice_ref, ii.document_ref_item as invoice_item_ref, coalesce(ii.total_amount, 0) as amount
-			from invoice i 
-			left join invoice_item ii on i.id = ii.invoice_id 
-			where i.status in ('PENDING', 'COMPLETED') and i.invoice_type in ('CN', 'DN')
-				and ii.document_ref <> '' and ii.document_ref_item != ''
-				and (ii.document_ref, ii.document_ref_item ) in (%s) 
-		`, strings.Join(invoiceCodeItemString, `,`))
-		fmt.Println(queryDN)
-		rowsDN, err := db.ExecuteQuery(sqlx, queryDN)
-		if err != nil {
-			return res, err
-		}
-
-		dnInvoiceCodeMap := map[string]string{}
-		for _, row := range rowsDN {
-			invoiceCode := row["invoice_code"].(string)
-			invoiceType := row["invoice_type"].(string)
-			invoiceRef := row["invoice_ref"].(string)
-			amount := row["amount"].(float64)
-
-			dnInvoiceCodeMap[invoiceCode] = invoiceCode
-
-			customerCode := invoiceCustomerMap[invoiceRef]
-			cust := custMap[customerCode]
-
-			//Adjust Total Price for CN
-			if invoiceType == "CN" {
-				cust.TotalPrice -= amount
-			}
-
-			//Adjust Total Price for DN && add for find payment
-			if invoiceType == "DN" {
-				cust.TotalPrice += amount
-				dnInvoiceCodeMap[invoiceCode] = invoiceCode
-			}
-
-			custMap[customerCode] = cust
-		}
-
-		//DN Payment
-		if len(dnInvoiceCodeMap) > 0 {
-			queryDNPayment := fmt.Sprintf(`
-				select t.invoice_code , coalesce(t.amount, 0) amount
-				from payment_invoice t 
-				where t.invoice_code in ('%s')
-			`, strings.Join(mapKeys(dnInvoiceCodeMap), `','`))
-			rowsDNPayment, err := db.ExecuteQuery(sqlx, queryDNPayment)
-			if err != nil {
-				return res, err
-			}
-
-			for _, row := range rowsDNPayment {
-				invoiceCode := row["invoice_code"].(string)
-				amount := row["amount"].(float64)
-
-				customerCode := invoiceCustomerMap[invoiceCode]
-				cust := custMap[customerCode]
-				cust.Paid += amount
-				custMap[customerCode] = cust
-			}
-
-		}
-	}
-
-	//Summarize Used
-	for _, cust := range custMap {
-		used := cust.TotalPrice + cust.TotalTransportCost - cust.Paid
-
-		for i, customer := range res.CreditCustomers {
-			if customer.CustomerCode == cust.CustomerCode {
-				customer.Used += used
-				res.CreditCustomers[i] = customer
-
-				break
-			}
-		}
-	}
-
-	return res, nil
-}
-
-func mapToString(m map[string]string) []string {
-	var sb []string
-	for _, v := range m {
-		sb = append(sb, v)
-	}
-	return sb
-}
-
-func mapKeys(m map[string]string) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
+package creditService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type GetCreditRequest struct {
+	CustomerCodes []string   `json:"customer_codes"`
+	AsOf          *time.Time `json:"as_of"` // the balance after the events that took effect by then; empty for now
+}
+
+type GetCreditResponse struct {
+	CreditCustomers []CreditCustomer `json:"credit_customers"`
+}
+
+type CreditCustomer struct {
+	CustomerCode  string  `json:"customer_code"`
+	IsActive      bool    `json:"is_active"`
+	Credit        float64 `json:"credit"`
+	Extra         float64 `json:"extra"`
+	RemainDeposit float64 `json:"remain_deposit"`
+	Used          float64 `json:"used"`
+	Held          float64 `json:"held"` // open holds of sales the ledger does not carry yet
+	Balance       float64 `json:"balance"`
+}
+
+func GetCreditCurrentAPI(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetCreditRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.CustomerCodes) == 0 {
+		return nil, fmt.Errorf("require at least one customer code")
+	}
+
+	return GetCreditCurrent(sqlx, req)
+}
+
+// GetCreditCurrent reads customers' credit from the credit ledger: the materialized balance less the open credit
+// holds, or with AsOf the ledger entries of the events that took effect up to then. It only reads; a post still queued
+// is not in the balance yet, and the open hold of a sale approved meanwhile covers it.
+func GetCreditCurrent(sqlx *sqlx.DB, req GetCreditRequest) (*GetCreditResponse, error) {
+	customerStrs := uniqueCustomerCodes(req.CustomerCodes)
+	if len(customerStrs) == 0 {
+		return nil, fmt.Errorf("no customer to check credit")
+	}
+
+	if req.AsOf != nil {
+		rows, err := getCreditLedgerNet(sqlx, customerStrs, nil, req.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		res := summarizeCreditContributions(customerStrs, rows, *req.AsOf)
+		return &res, nil
+	}
+
+	now := time.Now()
+	res, err := getCreditLedgerBalance(sqlx, customerStrs, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCreditHeld(sqlx, res, customerStrs, now); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// recomputeCreditCurrent works customers' credit out from the source documents, the way it was done before the
+// ledger. Only the reconciliation, and the sync that repairs what it finds, use it.
+func recomputeCreditCurrent(sqlx *sqlx.DB, customerStrs []string, at time.Time) (*GetCreditResponse, error) {
+	contributions, err := getCreditContributions(sqlx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := summarizeCreditContributions(customerStrs, contributions, at)
+	return &res, nil
+}
+
+func uniqueCustomerCodes(customerCodes []string) []string {
+	customerCheck := map[string]bool{}
+	customerStrs := []string{}
+	for _, customer := range customerCodes {
+		if !customerCheck[customer] {
+			customerStrs = append(customerStrs, customer)
+			customerCheck[customer] = true
+		}
+	}
+	return customerStrs
+}
+
+// creditContribution is what one source document adds to a bucket of a customer's credit. The ledger holds the
+// changes to each contribution, so the entries of a source add up to its contribution after its last event.
+type creditContribution struct {
+	CustomerCode string     `db:"customer_code"`
+	Bucket       string     `db:"bucket"`
+	SourceType   string     `db:"source_type"`
+	SourceRef    string     `db:"source_ref"`
+	Amount       float64    `db:"amount"`
+	IsActive     *bool      `db:"is_active"`
+	EffectiveDtm *time.Time `db:"effective_dtm"`
+	ExpireDtm    *time.Time `db:"expire_dtm"`
+	EventDtm     time.Time  `db:"-"` // when the event that posted it took effect
+}
+
+func formatLedgerTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// key identifies the contribution in the ledger; an extra whose window changes is a different contribution.
+func (c creditContribution) key() string {
+	return c.CustomerCode + "|" + c.Bucket + "|" + c.SourceType + "|" + c.SourceRef + "|" +
+		formatLedgerTime(c.EffectiveDtm) + "|" + formatLedgerTime(c.ExpireDtm)
+}
+
+// inEffect says whether an extra counts at time at; its window includes both ends.
+func (c creditContribution) inEffect(at time.Time) bool {
+	return c.EffectiveDtm != nil && c.ExpireDtm != nil && !c.EffectiveDtm.After(at) && !c.ExpireDtm.Before(at)
+}
+
+// mergeCreditContributions adds up contributions of the same source, keeping the first one's order.
+func mergeCreditContributions(contributions []creditContribution) []creditContribution {
+	merged := []creditContribution{}
+	index := map[string]int{}
+	for _, c := range contributions {
+		key := c.key()
+		if i, ok := index[key]; ok {
+			merged[i].Amount += c.Amount
+			if c.IsActive != nil {
+				merged[i].IsActive = c.IsActive
+			}
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, c)
+	}
+	return merged
+}
+
+// summarizeCreditContributions totals contributions per customer at time at. Only one extra counts: of those in
+// effect, the one that took effect last.
+func summarizeCreditContributions(customerStrs []string, contributions []creditContribution, at time.Time) GetCreditResponse {
+	res := GetCreditResponse{CreditCustomers: make([]CreditCustomer, 0, len(customerStrs))}
+	index := map[string]int{}
+	for _, customer := range customerStrs {
+		index[customer] = len(res.CreditCustomers)
+		res.CreditCustomers = append(res.CreditCustomers, CreditCustomer{CustomerCode: customer})
+	}
+
+	extras := map[int]creditContribution{}
+	for _, c := range contributions {
+		i, ok := index[c.CustomerCode]
+		if !ok {
+			continue
+		}
+
+		customer := &res.CreditCustomers[i]
+		switch c.Bucket {
+		case models.CreditBucketCredit:
+			customer.Credit += c.Amount
+			if c.IsActive != nil && *c.IsActive {
+				customer.IsActive = true
+			}
+		case models.CreditBucketExtra:
+			if math.Abs(c.Amount) < creditLedgerTolerance || !c.inEffect(at) {
+				continue
+			}
+			current, exists := extras[i]
+			if !exists || c.EffectiveDtm.After(*current.EffectiveDtm) ||
+				(c.EffectiveDtm.Equal(*current.EffectiveDtm) && c.SourceRef > current.SourceRef) {
+				extras[i] = c
+			}
+		case models.CreditBucketDeposit:
+			customer.RemainDeposit += c.Amount
+		case models.CreditBucketUsed:
+			customer.Used += c.Amount
+		}
+	}
+
+	for i, extra := range extras {
+		res.CreditCustomers[i].Extra = extra.Amount
+	}
+	for i, customer := range res.CreditCustomers {
+		customer.Balance = customer.Credit + customer.Extra + customer.RemainDeposit - customer.Used
+		res.CreditCustomers[i] = customer
+	}
+
+	return res
+}
+
+// getCreditContributions reads the contribution of every source document of the customers.
+func getCreditContributions(sqlx *sqlx.DB, customerStrs []string) ([]creditContribution, error) {
+	contributions, err := getCreditByCustomer(sqlx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+
+	deposits, err := getDepositByCustomer(sqlx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+	contributions = append(contributions, deposits...)
+
+	used, err := getUsedByCustomer(sqlx, customerStrs)
+	if err != nil {
+		return nil, err
+	}
+	contributions = append(contributions, used...)
+
+	return mergeCreditContributions(contributions), nil
+}
+
+type depositByCustomerRow struct {
+	CustomerCode string  `db:"customer_code"`
+	DepositCode  string  `db:"deposit_code"`
+	AmountTotal  float64 `db:"amount_total"`
+	AmountUsed   float64 `db:"amount_used"`
+	AmountRemain float64 `db:"amount_remain"`
+}
+
+func buildDepositByCustomerQuery(customerStrs []string) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select customer_code , coalesce(deposit_code, '') deposit_code, sum(coalesce (amount_total ,0)) amount_total , sum(coalesce(amount_used,0))  amount_used,  sum(coalesce (amount_remain, 0)) amount_remain
+		from deposit d 
+		where customer_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+	qb.Append(`
+		group by customer_code, deposit_code
+	`)
+
+	return qb
+}
+
+func getDepositByCustomer(sqlx *sqlx.DB, customerStrs []string) ([]creditContribution, error) {
+	rows, err := db.SelectBuilder[depositByCustomerRow](sqlx, buildDepositByCustomerQuery(customerStrs))
+	if err != nil {
+		return nil, err
+	}
+
+	contributions := []creditContribution{}
+	for _, row := range rows {
+		contributions = append(contributions, creditContribution{
+			CustomerCode: row.CustomerCode,
+			Bucket:       models.CreditBucketDeposit,
+			SourceType:   models.CreditSourceDeposit,
+			SourceRef:    row.DepositCode,
+			Amount:       row.AmountRemain,
+		})
+	}
+
+	return contributions, nil
+}
+
+type creditByCustomerRow struct {
+	CreditID          string     `db:"credit_id"`
+	CustomerCode      string     `db:"customer_code"`
+	CreditAmount      float64    `db:"credit_amount"`
+	CreditIsActive    bool       `db:"credit_is_active"`
+	ExtraID           string     `db:"extra_id"`
+	ExtraAmount       float64    `db:"extra_amount"`
+	ExtraEffectiveDtm *time.Time `db:"extra_effective_dtm"`
+	ExtraExpireDtm    *time.Time `db:"extra_expire_dtm"`
+}
+
+func buildCreditByCustomerQuery(customerStrs []string) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select c.id::text credit_id, c.customer_code , coalesce(c.amount,0) credit_amount, coalesce (c.is_active,false) credit_is_active
+			, coalesce(ce.id::text, '') extra_id, coalesce(ce.amount, 0) extra_amount
+			, ce.effective_dtm extra_effective_dtm, ce.expire_dtm extra_expire_dtm
+		from credit c 
+		left join credit_extra ce ON c.id = ce.credit_id
+		where 1=1
+		and customer_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+
+	return qb
+}
+
+// getCreditByCustomer reads the credits and every extra of them, whatever its window; the window decides when an
+// extra counts.
+func getCreditByCustomer(sqlx *sqlx.DB, customerStrs []string) ([]creditContribution, error) {
+	rows, err := db.SelectBuilder[creditByCustomerRow](sqlx, buildCreditByCustomerQuery(customerStrs))
+	if err != nil {
+		return nil, err
+	}
+
+	contributions := []creditContribution{}
+	creditSeen := map[string]bool{}
+	for _, row := range rows {
+		if !creditSeen[row.CreditID] {
+			isActive := row.CreditIsActive
+			contributions = append(contributions, creditContribution{
+				CustomerCode: row.CustomerCode,
+				Bucket:       models.CreditBucketCredit,
+				SourceType:   models.CreditSourceCredit,
+				SourceRef:    row.CreditID,
+				Amount:       row.CreditAmount,
+				IsActive:     &isActive,
+			})
+			creditSeen[row.CreditID] = true
+		}
+
+		if row.ExtraID != "" {
+			contributions = append(contributions, creditContribution{
+				CustomerCode: row.CustomerCode,
+				Bucket:       models.CreditBucketExtra,
+				SourceType:   models.CreditSourceExtra,
+				SourceRef:    row.ExtraID,
+				Amount:       row.ExtraAmount,
+				EffectiveDtm: row.ExtraEffectiveDtm,
+				ExpireDtm:    row.ExtraExpireDtm,
+			})
+		}
+	}
+
+	return contributions, nil
+}
+
+type usedSaleRow struct {
+	SaleCode           string  `db:"sale_code"`
+	CustomerCode       string  `db:"customer_code"`
+	TotalAmount        float64 `db:"total_amount"`
+	TotalTransportCost float64 `db:"total_transport_cost"`
+	TransportCostType  string  `db:"transport_cost_type"`
+}
+
+type usedInvoiceRow struct {
+	InvoiceCode  string `db:"invoice_code"`
+	CustomerCode string `db:"customer_code"`
+	InvoiceType  string `db:"invoice_type"`
+	InvoiceItem  string `db:"invoice_item"`
+	SaleCode     string `db:"sale_code"`
+	SaleItem     string `db:"sale_item"`
+}
+
+type usedPaymentRow struct {
+	InvoiceCode string  `db:"invoice_code"`
+	Amount      float64 `db:"amount"`
+}
+
+type usedAdjustmentRow struct {
+	InvoiceCode    string  `db:"invoice_code"`
+	InvoiceType    string  `db:"invoice_type"`
+	InvoiceRef     string  `db:"invoice_ref"`
+	InvoiceItemRef string  `db:"invoice_item_ref"`
+	Amount         float64 `db:"amount"`
+}
+
+func buildUsedSaleQuery(customerStrs []string) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select s.sale_code ,s.customer_code, coalesce(s.total_amount, 0) total_amount , coalesce(s.total_transport_cost, 0)  total_transport_cost
+			, coalesce(s.transport_cost_type, '') transport_cost_type
+		from sale s 
+		where (s.is_approved = true or s.status_approve = 'COMPLETED') and s.status in `)
+	qb.Append(qb.InStrings(models.SaleOpenStatuses))
+	qb.Append(`
+			and s.customer_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+
+	return qb
+}
+
+func buildUsedInvoiceQuery(customerStrs []string, saleCodes []string) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select i.invoice_code, i.invoice_type 
+ 			, coalesce(ii.invoice_item, '') invoice_item 
+ 			, coalesce(ii.document_ref, '') as sale_code, coalesce(ii.document_ref_item, '') as sale_item
+			, coalesce(i.party_code, '') as customer_code
+ 		from invoice i 
+ 		left join invoice_item ii on i.id = ii.invoice_id 
+ 		where i.status in ('PENDING', 'COMPLETED') and i.invoice_type = 'AR'
+			and i.party_code in `)
+	qb.Append(qb.InStrings(customerStrs))
+	qb.Append(`
+			and ii.document_ref in `)
+	qb.Append(qb.InStrings(saleCodes))
+
+	return qb
+}
+
+func buildPaymentInvoiceQuery(invoiceCodes []string) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+			select t.invoice_code , coalesce(t.amount, 0) amount
+			from payment_invoice t 
+			join payment p on p.id = t.payment_id
+			where coalesce(p.status, '') <> 'CANCELLED' and t.invoice_code in `)
+	qb.Append(qb.InStrings(invoiceCodes))
+
+	return qb
+}
+
+func buildAdjustmentQuery(invoiceItems [][]interface{}) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+			select i.invoice_code, i.invoice_type
+				, ii.document_ref as invoice_ref, ii.document_ref_item as invoice_item_ref, coalesce(ii.total_amount, 0) as amount
+			from invoice i 
+			left join invoice_item ii on i.id = ii.invoice_id 
+			where i.status in ('PENDING', 'COMPLETED') and i.invoice_type in ('CN', 'DN')
+				and ii.document_ref <> '' and ii.document_ref_item != ''
+				and (ii.document_ref, ii.document_ref_item ) in `)
+	qb.Append(qb.InTuples(invoiceItems))
+
+	return qb
+}
+
+// getUsedByCustomer reads what the customers' approved open sales use: the sale total, with the transport cost when it
+// is charged on top, less what was paid on their AR invoices and adjusted by CN and DN.
+func getUsedByCustomer(sqlx *sqlx.DB, customerStrs []string) ([]creditContribution, error) {
+	contributions := []creditContribution{}
+	used := func(customerCode string, sourceType string, sourceRef string, amount float64) {
+		contributions = append(contributions, creditContribution{
+			CustomerCode: customerCode,
+			Bucket:       models.CreditBucketUsed,
+			SourceType:   sourceType,
+			SourceRef:    sourceRef,
+			Amount:       amount,
+		})
+	}
+
+	//Sale Order
+	rowsSale, err := db.SelectBuilder[usedSaleRow](sqlx, buildUsedSaleQuery(customerStrs))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rowsSale) == 0 {
+		return contributions, nil
+	}
+
+	saleCodes := []string{}
+	saleCodesMap := map[string]bool{}
+	for _, row := range rowsSale {
+		transportCost := 0.0
+
+		if row.TransportCostType == "EXCL" {
+			transportCost = row.TotalTransportCost
+		}
+
+		if _, ok := saleCodesMap[row.SaleCode]; !ok {
+			saleCodes = append(saleCodes, row.SaleCode)
+			saleCodesMap[row.SaleCode] = true
+		}
+
+		used(row.CustomerCode, models.CreditSourceSale, row.SaleCode, row.TotalAmount+transportCost)
+	}
+
+	//Invoice
+	rowsInv, err := db.SelectBuilder[usedInvoiceRow](sqlx, buildUsedInvoiceQuery(customerStrs, saleCodes))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rowsInv) == 0 {
+		return contributions, nil
+	}
+
+	invoiceCodeMap := map[string]string{}
+	invoiceCodeItems := [][]interface{}{}
+	invoiceCodeItemSeen := map[string]bool{}
+	invoiceCustomerMap := map[string]string{}
+
+	for _, row := range rowsInv {
+		if _, ok := invoiceCodeMap[row.InvoiceCode]; !ok {
+			invoiceCodeMap[row.InvoiceCode] = row.InvoiceCode
+		}
+
+		invoiceCodeItemKey := row.InvoiceCode + "|" + row.InvoiceItem
+		if !invoiceCodeItemSeen[invoiceCodeItemKey] {
+			invoiceCodeItems = append(invoiceCodeItems, []interface{}{row.InvoiceCode, row.InvoiceItem})
+			invoiceCodeItemSeen[invoiceCodeItemKey] = true
+		}
+
+		if _, ok := invoiceCustomerMap[row.InvoiceCode]; !ok {
+			invoiceCustomerMap[row.InvoiceCode] = row.CustomerCode
+		}
+	}
+
+	//AR Payment
+	rowsPayment, err := db.SelectBuilder[usedPaymentRow](sqlx, buildPaymentInvoiceQuery(mapKeys(invoiceCodeMap)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rowsPayment {
+		used(invoiceCustomerMap[row.InvoiceCode], models.CreditSourcePayment, row.InvoiceCode, -row.Amount)
+	}
+
+	//DN & CN
+	rowsDN, err := db.SelectBuilder[usedAdjustmentRow](sqlx, buildAdjustmentQuery(invoiceCodeItems))
+	if err != nil {
+		return nil, err
+	}
+
+	dnInvoiceCodeMap := map[string]string{}
+	for _, row := range rowsDN {
+		customerCode := invoiceCustomerMap[row.InvoiceRef]
+
+		//Adjust Total Price for CN
+		if row.InvoiceType == "CN" {
+			used(customerCode, models.CreditSourceCreditNote, row.InvoiceCode, -row.Amount)
+		}
+
+		//Adjust Total Price for DN && add for find payment
+		if row.InvoiceType == "DN" {
+			used(customerCode, models.CreditSourceDebitNote, row.InvoiceCode, row.Amount)
+			dnInvoiceCodeMap[row.InvoiceCode] = row.InvoiceRef
+		}
+	}
+
+	//DN Payment
+	if len(dnInvoiceCodeMap) > 0 {
+		rowsDNPayment, err := db.SelectBuilder[usedPaymentRow](sqlx, buildPaymentInvoiceQuery(mapKeys(dnInvoiceCodeMap)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rowsDNPayment {
+			customerCode := invoiceCustomerMap[dnInvoiceCodeMap[row.InvoiceCode]]
+			used(customerCode, models.CreditSourceDebitNotePayment, row.InvoiceCode, -row.Amount)
+		}
+	}
+
+	return contributions, nil
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,62 @@
+package creditService
+
+import (
+	"strings"
+	"testing"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var hostileCustomerCodes = []string{`C001`, `x') or 1=1 --`, `O'Brien`}
+
+func assertBoundLiterals(t *testing.T, qb *db.QueryBuilder, literals []string) {
+	query, args := qb.Build()
+	for _, literal := range literals {
+		assert.NotContains(t, query, literal, "value must not be spliced into SQL")
+		assert.Contains(t, args, literal, "value must be passed as a bind argument")
+	}
+	assert.False(t, strings.Contains(query, "%s"))
+}
+
+func TestCreditQueries_BindCustomerCodes(t *testing.T) {
+	assertBoundLiterals(t, buildCreditByCustomerQuery(hostileCustomerCodes), hostileCustomerCodes)
+	assertBoundLiterals(t, buildDepositByCustomerQuery(hostileCustomerCodes), hostileCustomerCodes)
+	assertBoundLiterals(t, buildUsedSaleQuery(hostileCustomerCodes), hostileCustomerCodes)
+
+	saleCodes := []string{`SO'1`, `SO2') --`}
+	qb := buildUsedInvoiceQuery(hostileCustomerCodes, saleCodes)
+	assertBoundLiterals(t, qb, append(append([]string{}, hostileCustomerCodes...), saleCodes...))
+
+	query, args := qb.Build()
+	assert.Contains(t, query, "i.party_code in ($1,$2,$3)")
+	assert.Contains(t, query, "ii.document_ref in ($4,$5)")
+	assert.Len(t, args, 5)
+}
+
+func TestCreditQueries_BindInvoiceTuples(t *testing.T) {
+	qb := buildAdjustmentQuery([][]interface{}{{`INV'1`, `001`}, {`INV2`, `002') or ('1'='1`}})
+
+	query, args := qb.Build()
+	assert.Contains(t, query, "(ii.document_ref, ii.document_ref_item ) in (($1,$2),($3,$4))")
+	assert.Equal(t, []interface{}{`INV'1`, `001`, `INV2`, `002') or ('1'='1`}, args)
+
+	assertBoundLiterals(t, buildPaymentInvoiceQuery([]string{`INV'1`}), []string{`INV'1`})
+}
+
+func TestPaymentInvoiceQuery_SkipsCancelledPayments(t *testing.T) {
+	query, _ := buildPaymentInvoiceQuery([]string{"INV1"}).Build()
+	assert.Contains(t, query, "join payment p on p.id = t.payment_id")
+	assert.Contains(t, query, "coalesce(p.status, '') <> 'CANCELLED'")
+}
+
+func TestUsedSaleQuery_CountsApprovedAndDeliveringSales(t *testing.T) {
+	_, args := buildUsedSaleQuery([]string{"C001"}).Build()
+	for _, status := range []string{models.SaleStatusPending, models.SaleStatusApproved, models.SaleStatusPartialDelivered, models.SaleStatusDelivered} {
+		assert.Contains(t, args, status)
+	}
+	assert.NotContains(t, args, models.SaleStatusCompleted)
+	assert.NotContains(t, args, models.SaleStatusCanceled)
+}
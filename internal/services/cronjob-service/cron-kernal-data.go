@@ -1,40 +1,40 @@
-package CronjobService
-
-import (
-	"prime-erp-core/internal/cronjob"
-	"sync"
-
-	"github.com/gin-gonic/gin"
-)
-
-func init() {
-	cronjob.RegisterJob("wms-kernal", GetKernal, "*/1 * * * *")
-}
-
-func GetKernalManual(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	GetKernal()
-
-	return nil, nil
-}
-
-func GetKernal() {
-	ttt := "start kernal service"
-	println(ttt)
-	var wg sync.WaitGroup
-	wg.Add(3)
-	go func() {
-		defer wg.Done()
-		CreditRequestEffectiveDtmPending()
-	}()
-
-	go func() {
-		defer wg.Done()
-		CreditRequestEffectiveDtm()
-	}()
-
-	go func() {
-		defer wg.Done()
-		CreditExtra()
-	}()
-}
+package CronjobService
+
+import (
+	"prime-erp-core/internal/cronjob"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	cronjob.RegisterJob("wms-kernal", GetKernal, "*/1 * * * *")
+}
+
+func GetKernalManual(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	GetKernal()
+
+	return nil, nil
+}
+
+func GetKernal() {
+	ttt := "start kernal service"
+	println(ttt)
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		CreditRequestEffectiveDtmPending()
+	}()
+
+	go func() {
+		defer wg.Done()
+		CreditRequestEffectiveDtm()
+	}()
+
+	go func() {
+		defer wg.Done()
+		CreditExtra()
+	}()
+}
@@ -0,0 +1,115 @@
+package deliveryService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	orderExternalService "prime-erp-core/external/order-service"
+	"prime-erp-core/internal/models"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox event types for the order service; each event carries one delivery.
+const (
+	OutboxCreateOrder           = "ORDER_CREATE"
+	OutboxCancelOrder           = "ORDER_CANCEL"
+	OutboxUpdateOrderByDelivery = "ORDER_UPDATE_BY_DELIVERY"
+
+	outboxAggregateDelivery = "DELIVERY"
+)
+
+func init() {
+	outboxService.RegisterHandler(OutboxCreateOrder, dispatchCreateOrder)
+	outboxService.RegisterHandler(OutboxCancelOrder, dispatchCancelOrder)
+	outboxService.RegisterHandler(OutboxUpdateOrderByDelivery, dispatchUpdateOrderByDelivery)
+}
+
+// enqueueCreateOrders queues one order per delivery. The order ID is fixed in the payload, so a retried call
+// sends the same order again rather than a new one.
+func enqueueCreateOrders(tx *gorm.DB, user string, orders []orderExternalService.CreateOrderDetail) ([]uuid.UUID, error) {
+	eventIDs := []uuid.UUID{}
+	for _, order := range orders {
+		event, err := outboxService.Enqueue(tx, outboxService.OutboxMessage{
+			EventType:      OutboxCreateOrder,
+			AggregateType:  outboxAggregateDelivery,
+			AggregateCode:  order.DocumentRef,
+			IdempotencyKey: OutboxCreateOrder + ":" + order.DocumentRef,
+			Payload:        orderExternalService.CreateOrderRequest{Orders: []orderExternalService.CreateOrderDetail{order}},
+			CreateBy:       user,
+		})
+		if err != nil {
+			return nil, err
+		}
+		eventIDs = append(eventIDs, event.ID)
+	}
+
+	return eventIDs, nil
+}
+
+func enqueueCancelOrder(tx *gorm.DB, user string, delivery models.Delivery) error {
+	_, err := outboxService.Enqueue(tx, outboxService.OutboxMessage{
+		EventType:      OutboxCancelOrder,
+		AggregateType:  outboxAggregateDelivery,
+		AggregateCode:  delivery.DeliveryCode,
+		IdempotencyKey: OutboxCancelOrder + ":" + delivery.DeliveryCode,
+		Payload:        orderExternalService.CancelOrderRequest{DocumentRef: []string{delivery.DeliveryCode}},
+		CreateBy:       user,
+	})
+	return err
+}
+
+// enqueueUpdateOrderByDelivery queues a delivery change. Every update is its own event, so the key is unique per call.
+func enqueueUpdateOrderByDelivery(tx *gorm.DB, user string, deliveryCode string, req orderExternalService.UpdateOrderByDeliveryRequest) error {
+	_, err := outboxService.Enqueue(tx, outboxService.OutboxMessage{
+		EventType:      OutboxUpdateOrderByDelivery,
+		AggregateType:  outboxAggregateDelivery,
+		AggregateCode:  deliveryCode,
+		IdempotencyKey: fmt.Sprintf("%s:%s:%s", OutboxUpdateOrderByDelivery, deliveryCode, uuid.New()),
+		Payload:        req,
+		CreateBy:       user,
+	})
+	return err
+}
+
+func dispatchCreateOrder(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+	var req orderExternalService.CreateOrderRequest
+	if err := json.Unmarshal(event.Payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid create order payload: %v", err)
+	}
+
+	res, err := orderExternalService.CreateOrder(req)
+	if err != nil {
+		return nil, errors.New("Error create order : " + err.Error())
+	}
+	return res, nil
+}
+
+func dispatchCancelOrder(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+	var req orderExternalService.CancelOrderRequest
+	if err := json.Unmarshal(event.Payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid cancel order payload: %v", err)
+	}
+
+	res, err := orderExternalService.CancelOrder(req)
+	if err != nil {
+		return nil, errors.New("Error cancel order : " + err.Error())
+	}
+	return res, nil
+}
+
+func dispatchUpdateOrderByDelivery(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+	var req orderExternalService.UpdateOrderByDeliveryRequest
+	if err := json.Unmarshal(event.Payload, &req); err != nil {
+		return nil, fmt.Errorf("invalid update order payload: %v", err)
+	}
+
+	res, err := orderExternalService.UpdateOrderByDelivery(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call UpdateOrderByDelivery: %v", err)
+	}
+	return res, nil
+}
@@ -1,72 +1,84 @@
-package depositService
-
-import (
-	"encoding/json"
-	"errors"
-	models "prime-erp-core/internal/models"
-	repositoryDeposit "prime-erp-core/internal/repositories/deposit"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-func CreateDepost(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	var req []models.Deposit
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-	depositValue := []models.Deposit{}
-	depositIDForReturn := []uuid.UUID{}
-	depositCode := []string{}
-	for i := range req {
-		depositID := uuid.New()
-		req[i].ID = depositID
-
-		depositIDForReturn = append(depositIDForReturn, depositID)
-
-		if req[i].DepositCode == "" {
-			req[i].DepositCode = uuid.New().String()
-		}
-
-		depositValue = append(depositValue, req[i])
-	}
-	for _, deposit := range req {
-		depositCode = append(depositCode, deposit.DepositCode)
-	}
-
-	requestGetDeposit := map[string][]string{
-		"deposit_code": depositCode,
-	}
-	jsonBytesGetDeposit, err := json.Marshal(requestGetDeposit)
-	if err != nil {
-		return nil, err
-	}
-	getDeposit, errWarehouse := GetDeposit(ctx, string(jsonBytesGetDeposit))
-	if errWarehouse != nil {
-		return nil, errWarehouse
-	}
-	resultDeposit := getDeposit.(ResultDeposit).Deposit
-	if len(resultDeposit) > 0 {
-		depositID := []uuid.UUID{}
-		for _, resultDepositValue := range resultDeposit {
-			depositID = append(depositID, resultDepositValue.ID)
-		}
-		errDeleteDeposit := repositoryDeposit.DeleteDeposit(depositID)
-		if errDeleteDeposit != nil {
-			return nil, errDeleteDeposit
-		}
-	}
-
-	errCreateApproval := repositoryDeposit.CreateDeposit(depositValue)
-	if errCreateApproval != nil {
-		return nil, errCreateApproval
-	}
-
-	return map[string]interface{}{
-		"id":      depositIDForReturn,
-		"status":  "success",
-		"message": "Create Deposit Successfully",
-	}, nil
-}
+package depositService
+
+import (
+	"encoding/json"
+	"errors"
+	"prime-erp-core/internal/middleware"
+	models "prime-erp-core/internal/models"
+	repositoryCredit "prime-erp-core/internal/repositories/credit"
+	repositoryDeposit "prime-erp-core/internal/repositories/deposit"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func CreateDepost(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req []models.Deposit
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	depositValue := []models.Deposit{}
+	depositIDForReturn := []uuid.UUID{}
+	depositCode := []string{}
+	user := middleware.GetUserCode(ctx)
+	for i := range req {
+		depositID := uuid.New()
+		req[i].ID = depositID
+		req[i].CreateBy = user
+		req[i].UpdateBy = user
+
+		depositIDForReturn = append(depositIDForReturn, depositID)
+
+		if req[i].DepositCode == "" {
+			req[i].DepositCode = uuid.New().String()
+		}
+
+		depositValue = append(depositValue, req[i])
+	}
+	for _, deposit := range req {
+		depositCode = append(depositCode, deposit.DepositCode)
+	}
+
+	requestGetDeposit := map[string][]string{
+		"deposit_code": depositCode,
+	}
+	jsonBytesGetDeposit, err := json.Marshal(requestGetDeposit)
+	if err != nil {
+		return nil, err
+	}
+	getDeposit, errWarehouse := GetDeposit(ctx, string(jsonBytesGetDeposit))
+	if errWarehouse != nil {
+		return nil, errWarehouse
+	}
+	resultDeposit := getDeposit.(ResultDeposit).Deposit
+	if len(resultDeposit) > 0 {
+		depositID := []uuid.UUID{}
+		for _, resultDepositValue := range resultDeposit {
+			depositID = append(depositID, resultDepositValue.ID)
+		}
+		errDeleteDeposit := repositoryDeposit.DeleteDeposit(depositID)
+		if errDeleteDeposit != nil {
+			return nil, errDeleteDeposit
+		}
+	}
+
+	errCreateApproval := repositoryDeposit.CreateDeposit(depositValue)
+	if errCreateApproval != nil {
+		return nil, errCreateApproval
+	}
+	outboxService.QueueCreditLedgerPostAfter(ctx, outboxService.CreditLedgerPost{SourceType: models.CreditSourceDeposit},
+		func(tx *gorm.DB) ([]models.CreditLedgerPosting, error) {
+			return repositoryCredit.DepositPostings(tx, depositCode, time.Now())
+		})
+
+	return map[string]interface{}{
+		"id":      depositIDForReturn,
+		"status":  "success",
+		"message": "Create Deposit Successfully",
+	}, nil
+}
@@ -1,221 +1,122 @@
-package invoiceService
-
-import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	models "prime-erp-core/internal/models"
-	repositoryDeposit "prime-erp-core/internal/repositories/deposit"
-	customerService "prime-erp-core/internal/services/customer-service"
-	interfaceService "prime-erp-core/internal/services/interface-service"
-	systemConfigService "prime-erp-core/internal/services/system-config"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
-)
-
-func CreateInvoiceAR(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	var req []models.Invoice
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-
-	customerCode := []string{}
-
-	for _, reqValue := range req {
-		customerCode = append(customerCode, reqValue.PartyCode)
-	}
-
-	requestDataGetCustomers := map[string]interface{}{
-		"customer_code": customerCode,
-	}
-
-	customers, err := customerService.GetCustomers(requestDataGetCustomers)
-	if err != nil {
-		return nil, err
-	}
-
-	convertCustomerMap := map[string]customerService.GetCustomerResponse{}
-	for _, customer := range customers.Customers {
-		convertCustomerMap[customer.CustomerCode] = customer
-	}
-	prefix := "IV"
-	if req[0].PaymentMethod == "CASH" {
-		prefix = "CS"
-	}
-	if req[0].PaymentMethod == "CREDIT" {
-		prefix = "IV"
-	}
-	configCodeValue := "RUNNING_AR"
-	count := len(req)
-	purchaseCodes, err := GenerateInvoiceCodes(ctx, count, prefix, configCodeValue)
-	if err != nil {
-		return nil, errors.New("failed to generate invoice codes: " + err.Error())
-	}
-	fmt.Println(purchaseCodes)
-
-	for i := range req {
-		conMapCustomer, exist := convertCustomerMap[req[i].PartyCode]
-		if exist {
-			req[i].PartyName = conMapCustomer.CustomerName
-			for _, soldValue := range conMapCustomer.Billing {
-				req[i].PartyBranch = soldValue.BranchID
-				req[i].PartyAddress = conMapCustomer.Address
-			}
-			req[i].PartyEmail = conMapCustomer.Email
-			req[i].PartyTel = conMapCustomer.Phone
-			req[i].PartyTaxID = conMapCustomer.TaxID
-			req[i].PartyExternalID = conMapCustomer.ExternalID
-		}
-		req[i].InvoiceCode = purchaseCodes[i]
-	}
-
-	requestData := map[string]interface{}{
-		"module":    []string{"INVOICE"},
-		"topic":     []string{"AR"},
-		"sub_topic": []string{"CREATE"},
-	}
-
-	hookConfig, err := interfaceService.GetHookConfig(requestData)
-	if err != nil {
-		return nil, err
-	}
-	if len(hookConfig) > 0 {
-		urlHook := ""
-		for _, hookConfigValue := range hookConfig {
-			urlHook = hookConfigValue.HookUrl
-		}
-
-		requestDataCreateHook := interfaceService.HookInterfaceRequest{
-			RequestData: req,
-			UrlHook:     urlHook,
-		}
-		HookInterfaceValue, err := interfaceService.HookInterface(requestDataCreateHook)
-		if err != nil {
-			return nil, err
-		}
-		if HookInterfaceValue != nil {
-			str, _ := HookInterfaceValue.(string)
-
-			/* 	invoiceValue := []models.Invoice{}
-			invoiceValue = append(invoiceValue, models.Invoice{
-				ID:         idInvoice[0],
-				ExternalID: str,
-			})
-
-			_, errCreateApproval := repositoryInvoice.UpdateInvoice(invoiceValue, []models.InvoiceItem{})
-			if errCreateApproval != nil {
-				return nil, errCreateApproval
-			} */
-			req[0].ExternalID = str
-			jsonBytesCreateInvoice, err := json.Marshal(req)
-			if err != nil {
-				return nil, err
-			}
-
-			createInvoiceReturn, errCreateInvoice := CreateInvoice(ctx, string(jsonBytesCreateInvoice))
-			if errCreateInvoice != nil {
-				return nil, errCreateInvoice
-			}
-
-			depositMapResult, err := interfaceService.GetDeposit(str)
-			if err != nil {
-				return nil, err
-			}
-			if len(depositMapResult) > 0 {
-				var deposit []models.Deposit
-
-				for _, v := range depositMapResult {
-					depMap, _ := v.(map[string]interface{})
-
-					totalFloat, err := strconv.ParseFloat(depMap["total"].(string), 64)
-					if err != nil {
-						totalFloat = 0
-					}
-					drFloat, err := strconv.ParseFloat(depMap["dr"].(string), 64)
-					if err != nil {
-						totalFloat = 0
-					}
-					crFloat, err := strconv.ParseFloat(depMap["cr"].(string), 64)
-					if err != nil {
-						totalFloat = 0
-					}
-
-					deposit = append(deposit, models.Deposit{
-						DepositCode:  depMap["anchor"].(string),
-						CustomerCode: req[0].PartyCode,
-						AmountTotal:  totalFloat,
-						AmountUsed:   drFloat,
-						AmountRemain: crFloat,
-						Status:       "PENDING",
-					})
-				}
-				errDeposit := repositoryDeposit.CreateDeposit(deposit)
-				if errDeposit != nil {
-					return nil, errDeposit
-				}
-
-			}
-			return createInvoiceReturn, nil
-		}
-	} else {
-		jsonBytesCreateInvoice, err := json.Marshal(req)
-		if err != nil {
-			return nil, err
-		}
-
-		createInvoiceReturn, errCreateInvoice := CreateInvoice(ctx, string(jsonBytesCreateInvoice))
-		if errCreateInvoice != nil {
-			return nil, errCreateInvoice
-		}
-		return createInvoiceReturn, nil
-	}
-	return nil, nil
-}
-func GenerateInvoiceCodes(ctx *gin.Context, count int, prefix string, configCodeValue string) ([]string, error) {
-	if count <= 0 {
-		return []string{}, nil // No purchases to generate codes for
-	}
-
-	configCode := configCodeValue
-
-	getReq := systemConfigService.GetRunningSystemConfigRequest{
-		ConfigCode: configCode,
-		Count:      count,
-		Prefix:     prefix,
-	}
-
-	reqJSON, err := json.Marshal(getReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal get request: %v", err)
-	}
-
-	purchaseCodeResponse, err := systemConfigService.GetRunningSystemConfigInvoice(ctx, string(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate purchase order codes: %v", err)
-	}
-
-	updateReq := systemConfigService.UpdateRunningSystemConfigRequest{
-		ConfigCode: configCode,
-		Count:      count,
-	}
-
-	reqUpdateJSON, err := json.Marshal(updateReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal update request: %v", err)
-	}
-
-	_, err = systemConfigService.UpdateRunningSystemConfigInvoice(ctx, string(reqUpdateJSON))
-	if err != nil {
-		return nil, fmt.Errorf("failed to update running config: %v", err)
-	}
-
-	purchaseCodeResult, ok := purchaseCodeResponse.(systemConfigService.GetRunningSystemConfigResponse)
-	if !ok || len(purchaseCodeResult.Data) != count {
-		return nil, errors.New("failed to get correct number of purchase order codes from system config")
-	}
-
-	return purchaseCodeResult.Data, nil
-}
+package invoiceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	models "prime-erp-core/internal/models"
+	customerService "prime-erp-core/internal/services/customer-service"
+	interfaceService "prime-erp-core/internal/services/interface-service"
+	systemConfigService "prime-erp-core/internal/services/system-config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateInvoiceAR(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req []models.Invoice
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	customerCode := []string{}
+
+	for _, reqValue := range req {
+		customerCode = append(customerCode, reqValue.PartyCode)
+	}
+
+	requestDataGetCustomers := map[string]interface{}{
+		"customer_code": customerCode,
+	}
+
+	customers, err := customerService.GetCustomers(requestDataGetCustomers)
+	if err != nil {
+		return nil, err
+	}
+
+	convertCustomerMap := map[string]customerService.GetCustomerResponse{}
+	for _, customer := range customers.Customers {
+		convertCustomerMap[customer.CustomerCode] = customer
+	}
+	prefix := "IV"
+	if req[0].PaymentMethod == "CASH" {
+		prefix = "CS"
+	}
+	if req[0].PaymentMethod == "CREDIT" {
+		prefix = "IV"
+	}
+	configCodeValue := "RUNNING_AR"
+	purchaseCodes, err := GenerateInvoiceCodes(ctx, req, prefix, configCodeValue)
+	if err != nil {
+		return nil, errors.New("failed to generate invoice codes: " + err.Error())
+	}
+	fmt.Println(purchaseCodes)
+
+	for i := range req {
+		conMapCustomer, exist := convertCustomerMap[req[i].PartyCode]
+		if exist {
+			req[i].PartyName = conMapCustomer.CustomerName
+			for _, soldValue := range conMapCustomer.Billing {
+				req[i].PartyBranch = soldValue.BranchID
+				req[i].PartyAddress = conMapCustomer.Address
+			}
+			req[i].PartyEmail = conMapCustomer.Email
+			req[i].PartyTel = conMapCustomer.Phone
+			req[i].PartyTaxID = conMapCustomer.TaxID
+			req[i].PartyExternalID = conMapCustomer.ExternalID
+		}
+		req[i].InvoiceCode = purchaseCodes[i]
+	}
+
+	requestData := map[string]interface{}{
+		"module":    []string{"INVOICE"},
+		"topic":     []string{"AR"},
+		"sub_topic": []string{"CREATE"},
+	}
+
+	hookConfig, err := interfaceService.GetHookConfig(requestData)
+	if err != nil {
+		return nil, err
+	}
+	if len(hookConfig) > 0 {
+		urlHook := ""
+		for _, hookConfigValue := range hookConfig {
+			urlHook = hookConfigValue.HookUrl
+		}
+
+		// The hook goes out through the outbox, so the invoice is saved even when the document service is down
+		return createInvoicesWithHook(ctx, req, urlHook)
+	} else {
+		jsonBytesCreateInvoice, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+
+		createInvoiceReturn, errCreateInvoice := CreateInvoice(ctx, string(jsonBytesCreateInvoice))
+		if errCreateInvoice != nil {
+			return nil, errCreateInvoice
+		}
+		return createInvoiceReturn, nil
+	}
+}
+
+// GenerateInvoiceCodes reserves one code per invoice from the configCodeValue number range, scoped by the invoice company/site.
+// Invoice codes keep the Buddhist-era short year, e.g. IV6811-0001.
+func GenerateInvoiceCodes(ctx *gin.Context, invoices []models.Invoice, prefix string, configCodeValue string) ([]string, error) {
+	scopes := make([]systemConfigService.NumberScope, 0, len(invoices))
+	for _, invoice := range invoices {
+		scopes = append(scopes, systemConfigService.NumberScope{CompanyCode: invoice.CompanyCode, SiteCode: invoice.SiteCode})
+	}
+
+	codes, err := systemConfigService.ReserveNumbersByScope(ctx, systemConfigService.ReserveNumbersRequest{
+		ConfigCode: configCodeValue,
+		Prefix:     prefix,
+		YearFormat: models.NumberYearFormatBE,
+	}, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice codes: %w", err)
+	}
+
+	return codes, nil
+}
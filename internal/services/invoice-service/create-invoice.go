@@ -1,94 +1,151 @@
-package invoiceService
-
-import (
-	"encoding/json"
-	"errors"
-	models "prime-erp-core/internal/models"
-	repositoryInvoice "prime-erp-core/internal/repositories/invoice"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-func CreateInvoice(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	var req []models.Invoice
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-	invoiceValue := []models.Invoice{}
-	invoiceItemValue := []models.InvoiceItem{}
-	invoiceDepositValue := []models.InvoiceDeposit{}
-	invoiceIDForReturn := []uuid.UUID{}
-	invoiceCode := []string{}
-	for i, invoice := range req {
-		invoiceID := uuid.New()
-		req[i].ID = invoiceID
-
-		invoiceIDForReturn = append(invoiceIDForReturn, invoiceID)
-
-		if req[i].InvoiceCode == "" {
-			req[i].InvoiceCode = uuid.New().String()
-		}
-
-		for o := range invoice.InvoiceItem {
-			invoiceItemID := uuid.New()
-			req[i].InvoiceItem[o].ID = invoiceItemID
-			req[i].InvoiceItem[o].InvoiceID = invoiceID
-			req[i].InvoiceItem[o].InvoiceItem = strconv.Itoa(i)
-			invoiceItemValue = append(invoiceItemValue, req[i].InvoiceItem[o])
-		}
-		for d := range invoice.InvoiceDeposit {
-			depositID := uuid.New()
-			if req[i].InvoiceDeposit[d].DepositCode == "" {
-				req[i].InvoiceDeposit[d].DepositCode = uuid.New().String()
-			}
-			req[i].InvoiceDeposit[d].ID = depositID
-			req[i].InvoiceDeposit[d].InvoiceID = invoiceID
-			invoiceDepositValue = append(invoiceDepositValue, req[i].InvoiceDeposit[d])
-		}
-
-		req[i].InvoiceItem = []models.InvoiceItem{}
-		req[i].InvoiceDeposit = []models.InvoiceDeposit{}
-		invoiceValue = append(invoiceValue, req[i])
-	}
-	for _, invoice := range req {
-		invoiceCode = append(invoiceCode, invoice.InvoiceCode)
-	}
-
-	requestGetInvoice := map[string][]string{
-		"invoice_code": invoiceCode,
-	}
-	jsonBytesGetInvoice, err := json.Marshal(requestGetInvoice)
-	if err != nil {
-		return nil, err
-	}
-	getInvoice, errWarehouse := GetInvoice(ctx, string(jsonBytesGetInvoice))
-	if errWarehouse != nil {
-		return nil, errWarehouse
-	}
-	resultInvoice := getInvoice.(ResultInvoice).Invoice
-	if len(resultInvoice) > 0 {
-		invoiceID := []uuid.UUID{}
-		for _, resultInvoiceValue := range resultInvoice {
-			invoiceID = append(invoiceID, resultInvoiceValue.ID)
-		}
-		errDeleteInvoice := repositoryInvoice.DeleteInvoice(invoiceID)
-		if errDeleteInvoice != nil {
-			return nil, errDeleteInvoice
-		}
-	}
-
-	errCreateApproval := repositoryInvoice.CreateInvoice(invoiceValue, invoiceItemValue, invoiceDepositValue)
-	if errCreateApproval != nil {
-		return nil, errCreateApproval
-	}
-
-	return map[string]interface{}{
-		"id":      invoiceIDForReturn,
-		"status":  "success",
-		"message": "Create Invoice Successfully",
-	}, nil
-}
+package invoiceService
+
+import (
+	"encoding/json"
+	"errors"
+	"prime-erp-core/internal/middleware"
+	models "prime-erp-core/internal/models"
+	repositoryCredit "prime-erp-core/internal/repositories/credit"
+	repositoryInvoice "prime-erp-core/internal/repositories/invoice"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func CreateInvoice(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req []models.Invoice
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	invoiceValue, invoiceItemValue, invoiceDepositValue, invoiceIDForReturn := prepareInvoices(req, middleware.GetUserCode(ctx))
+	invoiceCode := []string{}
+	for _, invoice := range req {
+		invoiceCode = append(invoiceCode, invoice.InvoiceCode)
+	}
+
+	requestGetInvoice := map[string][]string{
+		"invoice_code": invoiceCode,
+	}
+	jsonBytesGetInvoice, err := json.Marshal(requestGetInvoice)
+	if err != nil {
+		return nil, err
+	}
+	getInvoice, errWarehouse := GetInvoice(ctx, string(jsonBytesGetInvoice))
+	if errWarehouse != nil {
+		return nil, errWarehouse
+	}
+	resultInvoice := getInvoice.(ResultInvoice).Invoice
+	if len(resultInvoice) > 0 {
+		invoiceID := []uuid.UUID{}
+		for _, resultInvoiceValue := range resultInvoice {
+			invoiceID = append(invoiceID, resultInvoiceValue.ID)
+		}
+		errDeleteInvoice := repositoryInvoice.DeleteInvoice(invoiceID)
+		if errDeleteInvoice != nil {
+			return nil, errDeleteInvoice
+		}
+	}
+
+	errCreateApproval := repositoryInvoice.CreateInvoice(invoiceValue, invoiceItemValue, invoiceDepositValue)
+	if errCreateApproval != nil {
+		return nil, errCreateApproval
+	}
+	queueInvoiceCreditLedgerPost(ctx, invoiceValue)
+
+	return map[string]interface{}{
+		"id":      invoiceIDForReturn,
+		"status":  "success",
+		"message": "Create Invoice Successfully",
+	}, nil
+}
+
+// creditInvoiceTypes are the invoice types that move a customer's credit.
+var creditInvoiceTypes = []string{"AR", "CN", "DN"}
+
+// invoiceCreditLedgerSource is the source type of the credit ledger posts of saved invoices.
+const invoiceCreditLedgerSource = "INVOICE"
+
+// invoiceCreditLedgerPost is the post of the AR, CN and DN invoices saved: what they now take off or add to what their
+// sales use, at their document dates.
+func invoiceCreditLedgerPost(tx *gorm.DB, invoices []models.Invoice) (outboxService.CreditLedgerPost, error) {
+	post := outboxService.CreditLedgerPost{SourceType: invoiceCreditLedgerSource}
+	for _, invoice := range invoices {
+		if !slices.Contains(creditInvoiceTypes, invoice.InvoiceType) {
+			continue
+		}
+		at := time.Now()
+		if invoice.DocumentDate != nil {
+			at = *invoice.DocumentDate
+		}
+		postings, err := repositoryCredit.InvoicePostings(tx, []string{invoice.InvoiceCode}, at)
+		if err != nil {
+			return post, err
+		}
+		post.Postings = append(post.Postings, postings...)
+		post.CustomerCodes = append(post.CustomerCodes, invoice.PartyCode)
+	}
+	return post, nil
+}
+
+// queueInvoiceCreditLedgerPost queues the post of invoices saved without a transaction.
+func queueInvoiceCreditLedgerPost(ctx *gin.Context, invoices []models.Invoice) {
+	post := outboxService.CreditLedgerPost{SourceType: invoiceCreditLedgerSource}
+	outboxService.QueueCreditLedgerPostAfter(ctx, post, func(tx *gorm.DB) ([]models.CreditLedgerPosting, error) {
+		invoicePost, err := invoiceCreditLedgerPost(tx, invoices)
+		return invoicePost.Postings, err
+	})
+}
+
+// prepareInvoices assigns IDs, codes and audit users to the invoices of req and splits them into rows for insert.
+func prepareInvoices(req []models.Invoice, user string) ([]models.Invoice, []models.InvoiceItem, []models.InvoiceDeposit, []uuid.UUID) {
+	invoiceValue := []models.Invoice{}
+	invoiceItemValue := []models.InvoiceItem{}
+	invoiceDepositValue := []models.InvoiceDeposit{}
+	invoiceIDForReturn := []uuid.UUID{}
+	for i, invoice := range req {
+		invoiceID := uuid.New()
+		req[i].ID = invoiceID
+		req[i].CreateBy = user
+		req[i].UpdateBy = user
+
+		invoiceIDForReturn = append(invoiceIDForReturn, invoiceID)
+
+		if req[i].InvoiceCode == "" {
+			req[i].InvoiceCode = uuid.New().String()
+		}
+
+		for o := range invoice.InvoiceItem {
+			invoiceItemID := uuid.New()
+			req[i].InvoiceItem[o].ID = invoiceItemID
+			req[i].InvoiceItem[o].InvoiceID = invoiceID
+			req[i].InvoiceItem[o].InvoiceItem = strconv.Itoa(i)
+			req[i].InvoiceItem[o].CreateBy = user
+			req[i].InvoiceItem[o].UpdateBy = user
+			invoiceItemValue = append(invoiceItemValue, req[i].InvoiceItem[o])
+		}
+		for d := range invoice.InvoiceDeposit {
+			depositID := uuid.New()
+			if req[i].InvoiceDeposit[d].DepositCode == "" {
+				req[i].InvoiceDeposit[d].DepositCode = uuid.New().String()
+			}
+			req[i].InvoiceDeposit[d].ID = depositID
+			req[i].InvoiceDeposit[d].InvoiceID = invoiceID
+			req[i].InvoiceDeposit[d].CreateBy = user
+			req[i].InvoiceDeposit[d].UpdateBy = user
+			invoiceDepositValue = append(invoiceDepositValue, req[i].InvoiceDeposit[d])
+		}
+
+		req[i].InvoiceItem = []models.InvoiceItem{}
+		req[i].InvoiceDeposit = []models.InvoiceDeposit{}
+		invoiceValue = append(invoiceValue, req[i])
+	}
+
+	return invoiceValue, invoiceItemValue, invoiceDepositValue, invoiceIDForReturn
+}
@@ -0,0 +1,113 @@
+package invoiceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	arAgingSummarySheet = "summary"
+	arAgingInvoiceSheet = "invoices"
+	arAgingDateFormat   = "2006-01-02"
+)
+
+// ExportARAging answers GetARAging as a workbook: a sheet of customers by bucket and a sheet of their open invoices.
+func ExportARAging(ctx *gin.Context, jsonPayload string) (*utils.FileResponse, error) {
+	req := GetARAgingRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	req.IncludeInvoices = true
+
+	res, err := getARAging(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := layoutARAgingWorkbook(res)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write AR aging workbook: %w", err)
+	}
+
+	return &utils.FileResponse{
+		FileName:    fmt.Sprintf("ar_aging_%s.xlsx", res.AsOf.Format("20060102")),
+		ContentType: xlsxContentType,
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+func layoutARAgingWorkbook(res *GetARAgingResponse) (*excelize.File, error) {
+	f := excelize.NewFile()
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	if err := f.SetSheetName("Sheet1", arAgingSummarySheet); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(arAgingInvoiceSheet); err != nil {
+		return nil, err
+	}
+
+	summary := [][]interface{}{}
+	header := []interface{}{"customer_code", "customer_name"}
+	for _, bucket := range res.Buckets {
+		header = append(header, bucket.Label)
+	}
+	summary = append(summary, append(header, "total"))
+	for _, customer := range res.Customers {
+		row := []interface{}{customer.CustomerCode, customer.CustomerName}
+		for _, amount := range customer.Amounts {
+			row = append(row, amount)
+		}
+		summary = append(summary, append(row, customer.Total))
+	}
+	totals := []interface{}{"total", ""}
+	for _, amount := range res.Amounts {
+		totals = append(totals, amount)
+	}
+	summary = append(summary, append(totals, res.Total))
+
+	invoices := [][]interface{}{{"customer_code", "customer_name", "invoice_code", "invoice_type", "invoice_ref",
+		"document_date", "due_date", "days_past_due", "bucket", "total_amount", "paid", "credited", "open_amount"}}
+	for _, customer := range res.Customers {
+		for _, invoice := range customer.Invoices {
+			invoices = append(invoices, []interface{}{invoice.CustomerCode, invoice.CustomerName, invoice.InvoiceCode,
+				invoice.InvoiceType, invoice.InvoiceRef, invoice.DocumentDate.Format(arAgingDateFormat),
+				invoice.DueDate.Format(arAgingDateFormat), invoice.DaysPastDue, invoice.Bucket, invoice.TotalAmount,
+				invoice.Paid, invoice.Credited, invoice.OpenAmount})
+		}
+	}
+
+	for sheet, rows := range map[string][][]interface{}{arAgingSummarySheet: summary, arAgingInvoiceSheet: invoices} {
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+				return nil, err
+			}
+		}
+		if err := f.SetRowStyle(sheet, 1, 1, headerStyle); err != nil {
+			return nil, err
+		}
+		if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,392 @@
+package invoiceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"gorm.io/gorm"
+)
+
+const (
+	// arAgingConfigTopic/arAgingBucketConfig is the system_config row whose value lists the bucket boundaries in days
+	// past due, e.g. "30,60,90" for current, 1-30, 31-60, 61-90 and 90+.
+	arAgingConfigTopic  = "AR_AGING"
+	arAgingBucketConfig = "BUCKET_DAYS"
+
+	arAgingTolerance = 0.005
+)
+
+var defaultARAgingBucketDays = []int{30, 60, 90}
+
+type GetARAgingRequest struct {
+	AsOf            *time.Time `json:"as_of"` // empty for today
+	CompanyCode     string     `json:"company_code"`
+	SiteCode        string     `json:"site_code"`
+	CustomerCodes   []string   `json:"customer_codes"`
+	BucketDays      []int      `json:"bucket_days"`      // empty for the configured boundaries
+	IncludeInvoices bool       `json:"include_invoices"` // drill down to the open invoices of each customer
+}
+
+type ARAgingBucket struct {
+	Label   string `json:"label"`
+	FromDay *int   `json:"from_day"` // days past due; empty for not yet due
+	ToDay   *int   `json:"to_day"`   // empty for the last bucket
+}
+
+type ARAgingInvoice struct {
+	InvoiceCode  string    `json:"invoice_code"`
+	InvoiceType  string    `json:"invoice_type"`
+	InvoiceRef   string    `json:"invoice_ref"` // the AR a DN or unapplied CN refers to
+	CustomerCode string    `json:"customer_code"`
+	CustomerName string    `json:"customer_name"`
+	DocumentDate time.Time `json:"document_date"`
+	DueDate      time.Time `json:"due_date"`
+	TotalAmount  float64   `json:"total_amount"`
+	Paid         float64   `json:"paid"`
+	Credited     float64   `json:"credited"`
+	OpenAmount   float64   `json:"open_amount"`
+	DaysPastDue  int       `json:"days_past_due"`
+	Bucket       string    `json:"bucket"`
+}
+
+type ARAgingCustomer struct {
+	CustomerCode string           `json:"customer_code"`
+	CustomerName string           `json:"customer_name"`
+	Amounts      []float64        `json:"amounts"` // by bucket, in the order of the response buckets
+	Total        float64          `json:"total"`
+	Invoices     []ARAgingInvoice `json:"invoices,omitempty"`
+}
+
+type GetARAgingResponse struct {
+	AsOf      time.Time         `json:"as_of"`
+	Buckets   []ARAgingBucket   `json:"buckets"`
+	Customers []ARAgingCustomer `json:"customers"`
+	Amounts   []float64         `json:"amounts"`
+	Total     float64           `json:"total"`
+}
+
+// GetARAging ages each customer's open AR as of a date: AR and DN invoices less what was paid on them and the CN
+// issued against them, bucketed by days past due.
+func GetARAging(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetARAgingRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	return getARAging(ctx, req)
+}
+
+func getARAging(ctx *gin.Context, req GetARAgingRequest) (*GetARAgingResponse, error) {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketDays := req.BucketDays
+	if len(bucketDays) == 0 {
+		if bucketDays, err = loadARAgingBucketDays(gormx); err != nil {
+			return nil, err
+		}
+	}
+	buckets, err := arAgingBuckets(bucketDays)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	asOf := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if req.AsOf != nil {
+		asOf = time.Date(req.AsOf.Year(), req.AsOf.Month(), req.AsOf.Day(), 0, 0, 0, 0, req.AsOf.Location())
+	}
+
+	documents, err := getARAgingDocuments(sqlx, req, asOf)
+	if err != nil {
+		return nil, err
+	}
+	invoices := ageReceivables(documents, asOf, bucketDays, buckets)
+
+	res := summarizeARAging(invoices, buckets, req.IncludeInvoices)
+	res.AsOf = asOf
+	return &res, nil
+}
+
+// loadARAgingBucketDays reads the configured bucket boundaries, falling back to 30, 60 and 90 days.
+func loadARAgingBucketDays(gormx *gorm.DB) ([]int, error) {
+	var configs []models.SystemConfig
+	if err := gormx.Where("topic_code = ? AND config_code = ?", arAgingConfigTopic, arAgingBucketConfig).Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get AR aging config: %v", err)
+	}
+	if len(configs) == 0 || strings.TrimSpace(configs[0].Value) == "" {
+		return defaultARAgingBucketDays, nil
+	}
+
+	days := []int{}
+	for _, value := range strings.Split(configs[0].Value, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AR aging bucket days %q: %v", configs[0].Value, err)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// arAgingBuckets labels the buckets of boundaries in days past due: not yet due, one bucket up to each boundary and
+// one past the last.
+func arAgingBuckets(bucketDays []int) ([]ARAgingBucket, error) {
+	if len(bucketDays) == 0 {
+		return nil, errors.New("at least one bucket boundary is required")
+	}
+	for i, day := range bucketDays {
+		if day <= 0 || (i > 0 && day <= bucketDays[i-1]) {
+			return nil, fmt.Errorf("bucket days must be positive and ascending, got %v", bucketDays)
+		}
+	}
+
+	buckets := []ARAgingBucket{{Label: "CURRENT"}}
+	from := 1
+	for _, day := range bucketDays {
+		fromDay, toDay := from, day
+		buckets = append(buckets, ARAgingBucket{Label: fmt.Sprintf("%d-%d", from, day), FromDay: &fromDay, ToDay: &toDay})
+		from = day + 1
+	}
+	fromDay := from
+	buckets = append(buckets, ARAgingBucket{Label: fmt.Sprintf("%d+", from-1), FromDay: &fromDay})
+	return buckets, nil
+}
+
+// arAgingBucketIndex returns the bucket of an amount daysPastDue days past due.
+func arAgingBucketIndex(bucketDays []int, daysPastDue int) int {
+	if daysPastDue <= 0 {
+		return 0
+	}
+	for i, day := range bucketDays {
+		if daysPastDue <= day {
+			return i + 1
+		}
+	}
+	return len(bucketDays) + 1
+}
+
+type arAgingDocumentRow struct {
+	InvoiceCode   string     `db:"invoice_code"`
+	InvoiceType   string     `db:"invoice_type"`
+	InvoiceRef    string     `db:"invoice_ref"`
+	CustomerCode  string     `db:"customer_code"`
+	CustomerName  string     `db:"customer_name"`
+	DocumentDate  time.Time  `db:"document_date"`
+	DueDate       *time.Time `db:"due_date"`
+	CreditTermDay float64    `db:"credit_term_day"`
+	TotalAmount   float64    `db:"total_amount"`
+	Paid          float64    `db:"paid"`
+}
+
+type arAgingCreditRow struct {
+	InvoiceCode string  `db:"invoice_code"`
+	InvoiceRef  string  `db:"invoice_ref"`
+	Amount      float64 `db:"amount"`
+}
+
+type arAgingDocuments struct {
+	Invoices []arAgingDocumentRow
+	Credits  []arAgingCreditRow // CN amounts by the invoice they were issued against
+}
+
+// buildARAgingDocumentQuery selects the AR, DN and CN invoices dated up to asOf, with what was paid on them by then.
+func buildARAgingDocumentQuery(req GetARAgingRequest, asOf time.Time) *db.QueryBuilder {
+	before := asOf.AddDate(0, 0, 1)
+	qb := db.NewQueryBuilder(`
+		select i.invoice_code, i.invoice_type, coalesce(i.invoice_ref, '') invoice_ref
+			, coalesce(i.party_code, '') customer_code, coalesce(i.party_name, '') customer_name
+			, coalesce(i.invoice_date, i.document_date, i.create_dtm) document_date, i.due_date
+			, coalesce(i.credit_term_day, 0) credit_term_day, coalesce(i.total_amount, 0) total_amount
+			, coalesce((
+				select sum(pi.amount)
+				from payment_invoice pi
+				join payment p on p.id = pi.payment_id
+				where pi.invoice_code = i.invoice_code and pi.apply_date < ? and coalesce(p.status, '') <> 'CANCELLED'
+			), 0) paid
+		from invoice i
+		where i.invoice_type in ('AR', 'DN', 'CN') and i.status in ('PENDING', 'COMPLETED')
+			and coalesce(i.invoice_date, i.document_date, i.create_dtm) < ?`, before, before)
+	appendARAgingFilter(qb, req)
+	qb.Append(`
+		order by i.party_code, document_date, i.invoice_code`)
+
+	return qb
+}
+
+// buildARAgingCreditQuery splits the CN dated up to asOf by the invoice each item was issued against.
+func buildARAgingCreditQuery(req GetARAgingRequest, asOf time.Time) *db.QueryBuilder {
+	qb := db.NewQueryBuilder(`
+		select i.invoice_code, coalesce(nullif(ii.document_ref, ''), i.invoice_ref, '') invoice_ref
+			, sum(coalesce(ii.total_amount, 0)) amount
+		from invoice i
+		join invoice_item ii on i.id = ii.invoice_id
+		where i.invoice_type = 'CN' and i.status in ('PENDING', 'COMPLETED')
+			and coalesce(i.invoice_date, i.document_date, i.create_dtm) < ?`, asOf.AddDate(0, 0, 1))
+	appendARAgingFilter(qb, req)
+	qb.Append(`
+		group by i.invoice_code, coalesce(nullif(ii.document_ref, ''), i.invoice_ref, '')`)
+
+	return qb
+}
+
+func appendARAgingFilter(qb *db.QueryBuilder, req GetARAgingRequest) {
+	if req.CompanyCode != "" {
+		qb.Append(`
+			and i.company_code = ?`, req.CompanyCode)
+	}
+	if req.SiteCode != "" {
+		qb.Append(`
+			and i.site_code = ?`, req.SiteCode)
+	}
+	if len(req.CustomerCodes) > 0 {
+		qb.Append(`
+			and i.party_code in `)
+		qb.Append(qb.InStrings(req.CustomerCodes))
+	}
+}
+
+func getARAgingDocuments(sqlx *sqlx.DB, req GetARAgingRequest, asOf time.Time) (arAgingDocuments, error) {
+	invoices, err := db.SelectBuilder[arAgingDocumentRow](sqlx, buildARAgingDocumentQuery(req, asOf))
+	if err != nil {
+		return arAgingDocuments{}, fmt.Errorf("failed to get AR invoices: %v", err)
+	}
+	credits, err := db.SelectBuilder[arAgingCreditRow](sqlx, buildARAgingCreditQuery(req, asOf))
+	if err != nil {
+		return arAgingDocuments{}, fmt.Errorf("failed to get credit notes: %v", err)
+	}
+	return arAgingDocuments{Invoices: invoices, Credits: credits}, nil
+}
+
+// arAgingDueDate is the invoice's due date, or else its date plus its credit term.
+func arAgingDueDate(row arAgingDocumentRow) time.Time {
+	due := row.DocumentDate
+	if row.DueDate != nil {
+		due = *row.DueDate
+	} else if row.CreditTermDay > 0 {
+		due = due.AddDate(0, 0, int(row.CreditTermDay))
+	}
+	return time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, due.Location())
+}
+
+func arAgingDaysPastDue(due time.Time, asOf time.Time) int {
+	dueDay := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	asOfDay := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	return int(asOfDay.Sub(dueDay).Hours() / 24)
+}
+
+// ageReceivables nets the documents into open items. A CN reduces the AR or DN it was issued against; what it does
+// not find open to reduce is aged as an unapplied credit from its own date. Items settled in full are left out.
+func ageReceivables(documents arAgingDocuments, asOf time.Time, bucketDays []int, buckets []ARAgingBucket) []ARAgingInvoice {
+	items := []ARAgingInvoice{}
+	index := map[string]int{}
+	cnIndex := map[string]int{}
+	for _, row := range documents.Invoices {
+		item := ARAgingInvoice{
+			InvoiceCode:  row.InvoiceCode,
+			InvoiceType:  row.InvoiceType,
+			InvoiceRef:   row.InvoiceRef,
+			CustomerCode: row.CustomerCode,
+			CustomerName: row.CustomerName,
+			DocumentDate: row.DocumentDate,
+			DueDate:      arAgingDueDate(row),
+			TotalAmount:  row.TotalAmount,
+			Paid:         row.Paid,
+		}
+		if row.InvoiceType == "CN" {
+			// a credit note is due at once; what was paid on it is a refund
+			item.DueDate = arAgingDueDate(arAgingDocumentRow{DocumentDate: row.DocumentDate})
+			item.TotalAmount = -row.TotalAmount
+			item.Paid = -row.Paid
+			cnIndex[row.InvoiceCode] = len(items)
+		} else {
+			index[row.InvoiceCode] = len(items)
+		}
+		items = append(items, item)
+	}
+
+	for _, credit := range documents.Credits {
+		cn, ok := cnIndex[credit.InvoiceCode]
+		if !ok {
+			continue
+		}
+		i, ok := index[credit.InvoiceRef]
+		if !ok {
+			continue
+		}
+		items[i].Credited += credit.Amount
+		items[cn].Credited -= credit.Amount
+	}
+
+	aged := []ARAgingInvoice{}
+	for _, item := range items {
+		item.OpenAmount = item.TotalAmount - item.Paid - item.Credited
+		if math.Abs(item.OpenAmount) < arAgingTolerance {
+			continue
+		}
+		item.DaysPastDue = arAgingDaysPastDue(item.DueDate, asOf)
+		item.Bucket = buckets[arAgingBucketIndex(bucketDays, item.DaysPastDue)].Label
+		aged = append(aged, item)
+	}
+	return aged
+}
+
+// summarizeARAging totals open items by customer and bucket, customers in code order.
+func summarizeARAging(invoices []ARAgingInvoice, buckets []ARAgingBucket, includeInvoices bool) GetARAgingResponse {
+	res := GetARAgingResponse{
+		Buckets:   buckets,
+		Customers: []ARAgingCustomer{},
+		Amounts:   make([]float64, len(buckets)),
+	}
+
+	bucketIndex := map[string]int{}
+	for i, bucket := range buckets {
+		bucketIndex[bucket.Label] = i
+	}
+
+	customerIndex := map[string]int{}
+	for _, invoice := range invoices {
+		c, ok := customerIndex[invoice.CustomerCode]
+		if !ok {
+			c = len(res.Customers)
+			customerIndex[invoice.CustomerCode] = c
+			res.Customers = append(res.Customers, ARAgingCustomer{
+				CustomerCode: invoice.CustomerCode,
+				CustomerName: invoice.CustomerName,
+				Amounts:      make([]float64, len(buckets)),
+			})
+		}
+
+		customer := &res.Customers[c]
+		b := bucketIndex[invoice.Bucket]
+		customer.Amounts[b] += invoice.OpenAmount
+		customer.Total += invoice.OpenAmount
+		res.Amounts[b] += invoice.OpenAmount
+		res.Total += invoice.OpenAmount
+		if includeInvoices {
+			customer.Invoices = append(customer.Invoices, invoice)
+		}
+	}
+
+	sort.SliceStable(res.Customers, func(i, j int) bool {
+		return res.Customers[i].CustomerCode < res.Customers[j].CustomerCode
+	})
+	return res
+}
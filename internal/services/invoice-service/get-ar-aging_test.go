@@ -0,0 +1,81 @@
+package invoiceService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestARAgingBuckets(t *testing.T) {
+	buckets, err := arAgingBuckets([]int{30, 60, 90})
+	require.NoError(t, err)
+
+	labels := []string{}
+	for _, bucket := range buckets {
+		labels = append(labels, bucket.Label)
+	}
+	assert.Equal(t, []string{"CURRENT", "1-30", "31-60", "61-90", "90+"}, labels)
+	assert.Equal(t, 91, *buckets[4].FromDay)
+	assert.Nil(t, buckets[4].ToDay)
+
+	assert.Equal(t, 0, arAgingBucketIndex([]int{30, 60, 90}, 0))
+	assert.Equal(t, 1, arAgingBucketIndex([]int{30, 60, 90}, 30))
+	assert.Equal(t, 2, arAgingBucketIndex([]int{30, 60, 90}, 31))
+	assert.Equal(t, 4, arAgingBucketIndex([]int{30, 60, 90}, 91))
+
+	_, err = arAgingBuckets([]int{30, 30})
+	assert.Error(t, err)
+	_, err = arAgingBuckets(nil)
+	assert.Error(t, err)
+}
+
+func TestAgeReceivables(t *testing.T) {
+	asOf := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)
+	jan := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	apr := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)
+
+	bucketDays := []int{30, 60, 90}
+	buckets, err := arAgingBuckets(bucketDays)
+	require.NoError(t, err)
+
+	documents := arAgingDocuments{
+		Invoices: []arAgingDocumentRow{
+			{InvoiceCode: "AR1", InvoiceType: "AR", CustomerCode: "C1", DocumentDate: jan, CreditTermDay: 30, TotalAmount: 1000, Paid: 400},
+			{InvoiceCode: "AR2", InvoiceType: "AR", CustomerCode: "C1", DocumentDate: apr, DueDate: &due, TotalAmount: 500},
+			{InvoiceCode: "AR3", InvoiceType: "AR", CustomerCode: "C1", DocumentDate: apr, TotalAmount: 300, Paid: 300},
+			{InvoiceCode: "DN1", InvoiceType: "DN", InvoiceRef: "AR2", CustomerCode: "C1", DocumentDate: apr, CreditTermDay: 60, TotalAmount: 50},
+			{InvoiceCode: "CN1", InvoiceType: "CN", CustomerCode: "C1", DocumentDate: apr, TotalAmount: 150},
+			{InvoiceCode: "AR9", InvoiceType: "AR", CustomerCode: "C2", DocumentDate: asOf, TotalAmount: 80},
+		},
+		Credits: []arAgingCreditRow{
+			{InvoiceCode: "CN1", InvoiceRef: "AR2", Amount: 100},
+			{InvoiceCode: "CN1", InvoiceRef: "GONE", Amount: 50},
+		},
+	}
+
+	invoices := ageReceivables(documents, asOf, bucketDays, buckets)
+	byCode := map[string]ARAgingInvoice{}
+	for _, invoice := range invoices {
+		byCode[invoice.InvoiceCode] = invoice
+	}
+
+	require.Len(t, invoices, 5, "AR3 is paid in full and left out")
+	assert.Equal(t, 600.0, byCode["AR1"].OpenAmount)
+	assert.Equal(t, 80, byCode["AR1"].DaysPastDue, "due on the invoice date plus its credit term")
+	assert.Equal(t, "61-90", byCode["AR1"].Bucket)
+	assert.Equal(t, 400.0, byCode["AR2"].OpenAmount, "less the CN issued against it")
+	assert.Equal(t, "1-30", byCode["AR2"].Bucket)
+	assert.Equal(t, "CURRENT", byCode["DN1"].Bucket)
+	assert.Equal(t, -50.0, byCode["CN1"].OpenAmount, "what the CN could not be applied to stays open as a credit")
+
+	res := summarizeARAging(invoices, buckets, false)
+	require.Len(t, res.Customers, 2)
+	assert.Equal(t, "C1", res.Customers[0].CustomerCode)
+	assert.Equal(t, []float64{50, 350, 0, 600, 0}, res.Customers[0].Amounts)
+	assert.Equal(t, 1000.0, res.Customers[0].Total)
+	assert.Nil(t, res.Customers[0].Invoices)
+	assert.Equal(t, 1080.0, res.Total)
+}
@@ -0,0 +1,216 @@
+package invoiceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	models "prime-erp-core/internal/models"
+	repositoryCredit "prime-erp-core/internal/repositories/credit"
+	interfaceService "prime-erp-core/internal/services/interface-service"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxInvoiceARHook sends a created AR invoice to the document service interface hook.
+const OutboxInvoiceARHook = "INVOICE_AR_HOOK"
+
+func init() {
+	outboxService.RegisterHandler(OutboxInvoiceARHook, dispatchInvoiceARHook)
+}
+
+// InvoiceHookPayload is the outbox payload of an AR hook: the hook request as it was built when the invoices were created.
+type InvoiceHookPayload struct {
+	UrlHook      string          `json:"url_hook"`
+	InvoiceCodes []string        `json:"invoice_codes"`
+	RequestData  json.RawMessage `json:"request_data"`
+}
+
+// createInvoicesWithHook saves the invoices and queues their interface hook in one transaction, replacing invoices
+// that already have the same code as CreateInvoice does.
+func createInvoicesWithHook(ctx *gin.Context, req []models.Invoice, urlHook string) (interface{}, error) {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	requestData, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+	invoiceValue, invoiceItemValue, invoiceDepositValue, invoiceIDs := prepareInvoices(req, user)
+	invoiceCodes := []string{}
+	for _, invoice := range invoiceValue {
+		invoiceCodes = append(invoiceCodes, invoice.InvoiceCode)
+	}
+
+	var event models.OutboxEvent
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		var existingIDs []uuid.UUID
+		if err := tx.Model(&models.Invoice{}).Where("invoice_code IN ?", invoiceCodes).Pluck("id", &existingIDs).Error; err != nil {
+			return err
+		}
+		if len(existingIDs) > 0 {
+			if err := tx.Where("id IN ?", existingIDs).Delete(&models.Invoice{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("invoice_id IN ?", existingIDs).Delete(&models.InvoiceItem{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&invoiceValue).Error; err != nil {
+			return err
+		}
+		if len(invoiceItemValue) > 0 {
+			if err := tx.Create(&invoiceItemValue).Error; err != nil {
+				return err
+			}
+		}
+		if len(invoiceDepositValue) > 0 {
+			if err := tx.Create(&invoiceDepositValue).Error; err != nil {
+				return err
+			}
+		}
+
+		post, err := invoiceCreditLedgerPost(tx, invoiceValue)
+		if err != nil {
+			return err
+		}
+		if err := outboxService.QueueCreditLedgerPost(tx, user, post); err != nil {
+			return err
+		}
+
+		event, err = outboxService.Enqueue(tx, outboxService.OutboxMessage{
+			EventType:      OutboxInvoiceARHook,
+			AggregateType:  "INVOICE",
+			AggregateCode:  invoiceCodes[0],
+			IdempotencyKey: OutboxInvoiceARHook + ":" + invoiceCodes[0],
+			Payload: InvoiceHookPayload{
+				UrlHook:      urlHook,
+				InvoiceCodes: invoiceCodes,
+				RequestData:  requestData,
+			},
+			CreateBy: user,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":              invoiceIDs,
+		"interface_event": event.ID,
+		"status":          "success",
+		"message":         "Create Invoice Successfully",
+	}, nil
+}
+
+// dispatchInvoiceARHook calls the hook and stores the external ID it returns on the first invoice, then loads that
+// contact's deposits. An invoice that already has its external ID is not sent again, and deposits already loaded are skipped.
+func dispatchInvoiceARHook(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+	var payload InvoiceHookPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid invoice hook payload: %v", err)
+	}
+	if len(payload.InvoiceCodes) == 0 {
+		return nil, errors.New("invoice hook payload has no invoice")
+	}
+
+	var invoice models.Invoice
+	if err := gormx.Where("invoice_code = ?", payload.InvoiceCodes[0]).First(&invoice).Error; err != nil {
+		return nil, fmt.Errorf("failed to get invoice %s: %v", payload.InvoiceCodes[0], err)
+	}
+
+	externalID := invoice.ExternalID
+	if externalID == "" {
+		hookValue, err := interfaceService.HookInterface(interfaceService.HookInterfaceRequest{
+			RequestData: payload.RequestData,
+			UrlHook:     payload.UrlHook,
+		})
+		if err != nil {
+			return nil, err
+		}
+		externalID, _ = hookValue.(string)
+		if externalID == "" {
+			return nil, fmt.Errorf("interface hook returned no id: %v", hookValue)
+		}
+
+		if err := gormx.Model(&models.Invoice{}).Where("id = ?", invoice.ID).Update("external_id", externalID).Error; err != nil {
+			return nil, fmt.Errorf("failed to save external id %s on invoice %s: %v", externalID, invoice.InvoiceCode, err)
+		}
+	}
+
+	depositMapResult, err := interfaceService.GetDeposit(externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	deposits := []models.Deposit{}
+	for _, v := range depositMapResult {
+		depMap, _ := v.(map[string]interface{})
+		depositCode, _ := depMap["anchor"].(string)
+
+		var exists int64
+		if err := gormx.Model(&models.Deposit{}).Where("deposit_code = ?", depositCode).Count(&exists).Error; err != nil {
+			return nil, fmt.Errorf("failed to check deposit %s: %v", depositCode, err)
+		}
+		if exists > 0 {
+			continue
+		}
+
+		deposits = append(deposits, models.Deposit{
+			ID:           uuid.New(),
+			DepositCode:  depositCode,
+			CustomerCode: invoice.PartyCode,
+			AmountTotal:  parseDepositAmount(depMap["total"]),
+			AmountUsed:   parseDepositAmount(depMap["dr"]),
+			AmountRemain: parseDepositAmount(depMap["cr"]),
+			Status:       "PENDING",
+		})
+	}
+	if len(deposits) > 0 {
+		if err := gormx.Create(&deposits).Error; err != nil {
+			return nil, fmt.Errorf("failed to create deposits: %v", err)
+		}
+		depositCodes := make([]string, 0, len(deposits))
+		for _, deposit := range deposits {
+			depositCodes = append(depositCodes, deposit.DepositCode)
+		}
+		postings, err := repositoryCredit.DepositPostings(gormx, depositCodes, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if err := outboxService.QueueCreditLedgerPost(gormx, event.CreateBy, outboxService.CreditLedgerPost{
+			SourceType: models.CreditSourceDeposit,
+			SourceRef:  invoice.InvoiceCode,
+			Postings:   postings,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]interface{}{
+		"external_id": externalID,
+		"deposits":    len(deposits),
+	}, nil
+}
+
+func parseDepositAmount(value interface{}) float64 {
+	str, _ := value.(string)
+	amount, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
@@ -1,62 +1,84 @@
-package invoiceService
-
-import (
-	"encoding/json"
-	"errors"
-	models "prime-erp-core/internal/models"
-	repositoryInvoice "prime-erp-core/internal/repositories/invoice"
-	"strconv"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-func UpdateInvoice(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	var req []models.Invoice
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-	invoiceValue := []models.Invoice{}
-	invoiceItemValue := []models.InvoiceItem{}
-	for i, invoice := range req {
-
-		for o := range invoice.InvoiceItem {
-			invoiceItemID := uuid.New()
-			req[i].InvoiceItem[o].ID = invoiceItemID
-			req[i].InvoiceItem[o].InvoiceID = invoice.ID
-			req[i].InvoiceItem[o].InvoiceItem = strconv.Itoa(i)
-			invoiceItemValue = append(invoiceItemValue, req[i].InvoiceItem[o])
-		}
-		req[i].InvoiceItem = []models.InvoiceItem{}
-		req[i].InvoiceDeposit = []models.InvoiceDeposit{}
-		invoiceValue = append(invoiceValue, req[i])
-	}
-	invoiceId := []uuid.UUID{req[0].ID}
-	errDeleteInvoiceItem := repositoryInvoice.DeleteInvoiceItem(invoiceId)
-	if errDeleteInvoiceItem != nil {
-		return nil, errDeleteInvoiceItem
-	}
-	errCreateApproval := repositoryInvoice.CreateInvoice([]models.Invoice{}, invoiceItemValue, []models.InvoiceDeposit{})
-	if errCreateApproval != nil {
-		return nil, errCreateApproval
-	}
-
-	rowsAffected, errCreateApproval := repositoryInvoice.UpdateInvoice(invoiceValue, []models.InvoiceItem{})
-	if errCreateApproval != nil {
-		return nil, errCreateApproval
-	}
-
-	if rowsAffected > 0 {
-		return map[string]interface{}{
-			"status":  "success",
-			"message": "Approval updated successfully",
-		}, nil
-	} else {
-		return map[string]interface{}{
-			"status":  "success",
-			"message": "Approval Not Have Rows Affected ",
-		}, nil
-	}
-}
+package invoiceService
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	models "prime-erp-core/internal/models"
+	repositoryInvoice "prime-erp-core/internal/repositories/invoice"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func UpdateInvoice(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req []models.Invoice
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	invoiceValue := []models.Invoice{}
+	invoiceItemValue := []models.InvoiceItem{}
+	user := middleware.GetUserCode(ctx)
+	for i, invoice := range req {
+		req[i].UpdateBy = user
+
+		for o := range invoice.InvoiceItem {
+			invoiceItemID := uuid.New()
+			req[i].InvoiceItem[o].ID = invoiceItemID
+			req[i].InvoiceItem[o].InvoiceID = invoice.ID
+			req[i].InvoiceItem[o].InvoiceItem = strconv.Itoa(i)
+			req[i].InvoiceItem[o].CreateBy = user
+			req[i].InvoiceItem[o].UpdateBy = user
+			invoiceItemValue = append(invoiceItemValue, req[i].InvoiceItem[o])
+		}
+		req[i].InvoiceItem = []models.InvoiceItem{}
+		req[i].InvoiceDeposit = []models.InvoiceDeposit{}
+		invoiceValue = append(invoiceValue, req[i])
+	}
+	invoiceId := []uuid.UUID{req[0].ID}
+	errDeleteInvoiceItem := repositoryInvoice.DeleteInvoiceItem(invoiceId)
+	if errDeleteInvoiceItem != nil {
+		return nil, errDeleteInvoiceItem
+	}
+	errCreateApproval := repositoryInvoice.CreateInvoice([]models.Invoice{}, invoiceItemValue, []models.InvoiceDeposit{})
+	if errCreateApproval != nil {
+		return nil, errCreateApproval
+	}
+
+	rowsAffected, errCreateApproval := repositoryInvoice.UpdateInvoice(invoiceValue, []models.InvoiceItem{})
+	if errCreateApproval != nil {
+		return nil, errCreateApproval
+	}
+
+	// the request may carry only what changed, so take the type and customer from the saved invoices
+	invoiceIDs := []uuid.UUID{}
+	for _, invoice := range invoiceValue {
+		invoiceIDs = append(invoiceIDs, invoice.ID)
+	}
+	savedInvoices := []models.Invoice{}
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err == nil {
+		err = gormx.Select("invoice_code", "invoice_type", "party_code", "document_date").Where("id IN ?", invoiceIDs).Find(&savedInvoices).Error
+	}
+	if err != nil {
+		log.Printf("credit ledger post: failed to get invoices: %v\n", err)
+	}
+	queueInvoiceCreditLedgerPost(ctx, savedInvoices)
+
+	if rowsAffected > 0 {
+		return map[string]interface{}{
+			"status":  "success",
+			"message": "Approval updated successfully",
+		}, nil
+	} else {
+		return map[string]interface{}{
+			"status":  "success",
+			"message": "Approval Not Have Rows Affected ",
+		}, nil
+	}
+}
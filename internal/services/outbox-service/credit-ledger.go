@@ -0,0 +1,90 @@
+package outboxService
+
+import (
+	"log"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreditLedgerPostAggregate is the aggregate of credit ledger posts: one customer, whose posts go out in order.
+const CreditLedgerPostAggregate = "CUSTOMER_CREDIT"
+
+// CreditLedgerPost is a business event on sourceType/sourceRef for the credit ledger. Customers in CustomerCodes are
+// queued even without postings, so their credit holds are still settled; Replace lists the source types posted in full.
+type CreditLedgerPost struct {
+	SourceType    string
+	SourceRef     string
+	Postings      []models.CreditLedgerPosting
+	Replace       []string
+	CustomerCodes []string
+}
+
+// CreditLedgerPostings builds the postings of an event from its documents.
+type CreditLedgerPostings func(tx *gorm.DB) ([]models.CreditLedgerPosting, error)
+
+// QueueCreditLedgerPost queues the postings of a business event, one post per customer. Pass the transaction of the
+// event when there is one.
+func QueueCreditLedgerPost(tx *gorm.DB, user string, post CreditLedgerPost) error {
+	customerCodes := []string{}
+	byCustomer := map[string][]models.CreditLedgerPosting{}
+	add := func(customerCode string) {
+		if _, ok := byCustomer[customerCode]; !ok && customerCode != "" {
+			customerCodes = append(customerCodes, customerCode)
+			byCustomer[customerCode] = []models.CreditLedgerPosting{}
+		}
+	}
+	for _, customerCode := range post.CustomerCodes {
+		add(customerCode)
+	}
+	for _, posting := range post.Postings {
+		add(posting.CustomerCode)
+		if posting.CustomerCode != "" {
+			byCustomer[posting.CustomerCode] = append(byCustomer[posting.CustomerCode], posting)
+		}
+	}
+
+	for _, customerCode := range customerCodes {
+		if _, err := Enqueue(tx, OutboxMessage{
+			EventType:      models.OutboxCreditLedgerPost,
+			AggregateType:  CreditLedgerPostAggregate,
+			AggregateCode:  customerCode,
+			IdempotencyKey: models.OutboxCreditLedgerPost + ":" + customerCode + ":" + uuid.NewString(),
+			Payload: models.CreditLedgerPostPayload{
+				CustomerCode: customerCode,
+				SourceType:   post.SourceType,
+				SourceRef:    post.SourceRef,
+				Postings:     byCustomer[customerCode],
+				Replace:      post.Replace,
+			},
+			CreateBy: user,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueueCreditLedgerPostAfter queues the post of a change that was saved without a transaction, with the postings build
+// reads from its documents. The change stands either way, so a failure is only logged; the credit ledger
+// reconciliation reports the customers it leaves behind.
+func QueueCreditLedgerPostAfter(ctx *gin.Context, post CreditLedgerPost, build CreditLedgerPostings) {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err == nil && build != nil {
+		var postings []models.CreditLedgerPosting
+		if postings, err = build(gormx); err == nil {
+			post.Postings = append(post.Postings, postings...)
+		}
+	}
+	if err == nil {
+		err = QueueCreditLedgerPost(gormx, middleware.GetUserCode(ctx), post)
+	}
+	if err != nil {
+		log.Printf("credit ledger post %s %s: %v\n", post.SourceType, post.SourceRef, err)
+	}
+}
@@ -0,0 +1,117 @@
+package outboxService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GetDeadLettersRequest struct {
+	EventType     []string `json:"event_type"`
+	AggregateType []string `json:"aggregate_type"`
+	AggregateCode []string `json:"aggregate_code"`
+	Page          int      `json:"page"`
+	PageSize      int      `json:"page_size"`
+}
+
+type ResultDeadLetters struct {
+	Total      int                  `json:"total"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	TotalPages int                  `json:"total_pages"`
+	Events     []models.OutboxEvent `json:"events"`
+}
+
+// GetDeadLetters lists events that ran out of attempts, newest first.
+func GetDeadLetters(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req GetDeadLettersRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Model(&models.OutboxEvent{}).Where("status = ?", models.OutboxStatusDead)
+	if len(req.EventType) > 0 {
+		query = query.Where("event_type IN ?", req.EventType)
+	}
+	if len(req.AggregateType) > 0 {
+		query = query.Where("aggregate_type IN ?", req.AggregateType)
+	}
+	if len(req.AggregateCode) > 0 {
+		query = query.Where("aggregate_code IN ?", req.AggregateCode)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count dead letters: %v", err)
+	}
+
+	events := []models.OutboxEvent{}
+	if err := query.Order("update_dtm DESC").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %v", err)
+	}
+
+	return ResultDeadLetters{
+		Total:      int(total),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: (int(total) + req.PageSize - 1) / req.PageSize,
+		Events:     events,
+	}, nil
+}
+
+type ReplayDeadLettersRequest struct {
+	ID []uuid.UUID `json:"id"`
+}
+
+// ReplayDeadLetters puts dead events back in the queue with a fresh set of attempts.
+func ReplayDeadLetters(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req ReplayDeadLettersRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.ID) == 0 {
+		return nil, errors.New("id is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	result := gormx.Model(&models.OutboxEvent{}).
+		Where("id IN ? AND status = ?", req.ID, models.OutboxStatusDead).
+		Updates(map[string]interface{}{
+			"status":           models.OutboxStatusPending,
+			"attempts":         0,
+			"next_attempt_dtm": time.Now(),
+			"update_by":        middleware.GetUserCode(ctx),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to replay dead letters: %v", result.Error)
+	}
+
+	return map[string]interface{}{
+		"replayed": result.RowsAffected,
+		"status":   "success",
+		"message":  "Dead letters queued for replay",
+	}, nil
+}
@@ -0,0 +1,184 @@
+package outboxService
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"prime-erp-core/internal/cronjob"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	dispatchBatchSize = 50
+	// dispatchLease is how long a claimed event stays PROCESSING before another run may pick it up again,
+	// e.g. after the process died mid-call.
+	dispatchLease = 10 * time.Minute
+)
+
+func init() {
+	cronjob.RegisterJob("outbox-dispatch", runOutboxDispatch, "* * * * *")
+}
+
+type DispatchOutboxResponse struct {
+	Done    []uuid.UUID `json:"done"`
+	Retried []uuid.UUID `json:"retried"`
+	Dead    []uuid.UUID `json:"dead"`
+}
+
+// DispatchOutbox runs the dispatcher on demand.
+func DispatchOutbox(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	return dispatchOutbox(gormx, time.Now())
+}
+
+func runOutboxDispatch() {
+	gormx, err := db.DefaultRegistry().GORM(`prime_erp`)
+	if err != nil {
+		log.Printf("outbox dispatch: %v\n", err)
+		return
+	}
+
+	res, err := dispatchOutbox(gormx, time.Now())
+	if err != nil {
+		log.Printf("outbox dispatch: %v\n", err)
+		return
+	}
+	if len(res.Retried)+len(res.Dead) > 0 {
+		log.Printf("outbox dispatch: done %d, retried %v, dead %v\n", len(res.Done), res.Retried, res.Dead)
+	}
+}
+
+// claimOutboxEvents takes due events and leases them to this run. Events of one aggregate go out in the order they
+// were written: an event waits while an older one for the same aggregate is not DONE, including a dead one.
+func claimOutboxEvents(gormx *gorm.DB, now time.Time) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := gormx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(`
+			SELECT e.*
+			FROM outbox_event e
+			WHERE e.status IN ?
+			  AND e.next_attempt_dtm <= ?
+			  AND NOT EXISTS (
+			      SELECT 1 FROM outbox_event o
+			      WHERE o.aggregate_type = e.aggregate_type
+			        AND o.aggregate_code = e.aggregate_code
+			        AND o.create_dtm < e.create_dtm
+			        AND o.status <> ?)
+			ORDER BY e.next_attempt_dtm
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED`,
+			[]string{models.OutboxStatusPending, models.OutboxStatusProcessing},
+			now,
+			models.OutboxStatusDone,
+			dispatchBatchSize,
+		).Scan(&events).Error; err != nil {
+			return fmt.Errorf("failed to get outbox events: %v", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(events))
+		for i := range events {
+			ids = append(ids, events[i].ID)
+			events[i].Attempts++
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":           models.OutboxStatusProcessing,
+			"attempts":         gorm.Expr("attempts + 1"),
+			"next_attempt_dtm": now.Add(dispatchLease),
+		}).Error
+	})
+
+	return events, err
+}
+
+func dispatchOutbox(gormx *gorm.DB, now time.Time) (DispatchOutboxResponse, error) {
+	res := DispatchOutboxResponse{Done: []uuid.UUID{}, Retried: []uuid.UUID{}, Dead: []uuid.UUID{}}
+
+	events, err := claimOutboxEvents(gormx, now)
+	if err != nil {
+		return res, err
+	}
+
+	for _, event := range events {
+		response, err := deliverOutboxEvent(gormx, event)
+		if err == nil {
+			if err := markOutboxDone(gormx, event, response); err != nil {
+				log.Printf("outbox dispatch: %s: %v\n", event.ID, err)
+			}
+			res.Done = append(res.Done, event.ID)
+			continue
+		}
+
+		log.Printf("outbox dispatch: %s %s attempt %d: %v\n", event.EventType, event.AggregateCode, event.Attempts, err)
+		status, markErr := markOutboxFailed(gormx, event, err, time.Now())
+		if markErr != nil {
+			log.Printf("outbox dispatch: %s: %v\n", event.ID, markErr)
+		}
+		if status == models.OutboxStatusDead {
+			res.Dead = append(res.Dead, event.ID)
+		} else {
+			res.Retried = append(res.Retried, event.ID)
+		}
+	}
+
+	return res, nil
+}
+
+// deliverOutboxEvent runs the event's handler, turning a panic in the remote client into a failed attempt.
+func deliverOutboxEvent(gormx *gorm.DB, event models.OutboxEvent) (response interface{}, err error) {
+	handler, exists := handlerFor(event.EventType)
+	if !exists {
+		return nil, fmt.Errorf("no outbox handler for %s", event.EventType)
+	}
+
+	defer func() {
+		if rc := recover(); rc != nil {
+			err = fmt.Errorf("panic: %v", rc)
+		}
+	}()
+
+	return handler(gormx, event)
+}
+
+func markOutboxDone(gormx *gorm.DB, event models.OutboxEvent, response interface{}) error {
+	responseJSON, _ := json.Marshal(response)
+	now := time.Now()
+	return gormx.Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", event.ID, models.OutboxStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":        models.OutboxStatusDone,
+			"response":      responseJSON,
+			"last_error":    "",
+			"processed_dtm": now,
+		}).Error
+}
+
+func markOutboxFailed(gormx *gorm.DB, event models.OutboxEvent, cause error, now time.Time) (string, error) {
+	status := models.OutboxStatusPending
+	if event.Attempts >= event.MaxAttempts {
+		status = models.OutboxStatusDead
+	}
+
+	err := gormx.Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", event.ID, models.OutboxStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":           status,
+			"last_error":       cause.Error(),
+			"next_attempt_dtm": now.Add(outboxBackoff(event.Attempts)),
+		}).Error
+
+	return status, err
+}
@@ -0,0 +1,121 @@
+package outboxService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultMaxAttempts = 8
+	baseBackoff        = 30 * time.Second
+	maxBackoff         = 2 * time.Hour
+)
+
+// OutboxHandler delivers one event to the remote service. The returned value is kept on the event as its response.
+// Handlers can run more than once for the same event, so they must tolerate a call that already went through.
+type OutboxHandler func(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error)
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]OutboxHandler{}
+)
+
+// RegisterHandler sets the handler the dispatcher uses for eventType.
+func RegisterHandler(eventType string, handler OutboxHandler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+
+	handlers[eventType] = handler
+}
+
+func handlerFor(eventType string) (OutboxHandler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+
+	handler, exists := handlers[eventType]
+	return handler, exists
+}
+
+type OutboxMessage struct {
+	EventType      string
+	AggregateType  string
+	AggregateCode  string
+	IdempotencyKey string
+	Payload        interface{}
+	MaxAttempts    int
+	CreateBy       string
+}
+
+// Enqueue writes the message on tx, so it is only dispatched if the caller's transaction commits.
+// A message whose idempotency key is already queued is not written again; the existing event is returned instead.
+func Enqueue(tx *gorm.DB, message OutboxMessage) (models.OutboxEvent, error) {
+	if message.EventType == "" || message.IdempotencyKey == "" {
+		return models.OutboxEvent{}, errors.New("outbox event type and idempotency key are required")
+	}
+
+	payload, err := json.Marshal(message.Payload)
+	if err != nil {
+		return models.OutboxEvent{}, fmt.Errorf("failed to marshal outbox payload: %v", err)
+	}
+
+	maxAttempts := message.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	event := models.OutboxEvent{
+		ID:             uuid.New(),
+		EventType:      message.EventType,
+		AggregateType:  message.AggregateType,
+		AggregateCode:  message.AggregateCode,
+		IdempotencyKey: message.IdempotencyKey,
+		Payload:        payload,
+		Status:         models.OutboxStatusPending,
+		MaxAttempts:    maxAttempts,
+		NextAttemptDtm: time.Now(),
+		CreateBy:       message.CreateBy,
+		UpdateBy:       message.CreateBy,
+	}
+
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "idempotency_key"}},
+		DoNothing: true,
+	}).Create(&event)
+	if result.Error != nil {
+		return models.OutboxEvent{}, fmt.Errorf("failed to enqueue %s: %v", message.EventType, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var existing models.OutboxEvent
+		if err := tx.Where("idempotency_key = ?", message.IdempotencyKey).First(&existing).Error; err != nil {
+			return models.OutboxEvent{}, fmt.Errorf("failed to get outbox event %s: %v", message.IdempotencyKey, err)
+		}
+		return existing, nil
+	}
+
+	return event, nil
+}
+
+// outboxBackoff is the wait before retrying an event that has failed attempts times: 30s doubling up to 2h.
+func outboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := baseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
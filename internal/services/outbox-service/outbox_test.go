@@ -0,0 +1,45 @@
+package outboxService
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, outboxBackoff(0))
+	assert.Equal(t, 30*time.Second, outboxBackoff(1))
+	assert.Equal(t, time.Minute, outboxBackoff(2))
+	assert.Equal(t, 4*time.Minute, outboxBackoff(4))
+	assert.Equal(t, 2*time.Hour, outboxBackoff(9), "capped")
+	assert.Equal(t, 2*time.Hour, outboxBackoff(50))
+}
+
+func TestDeliverOutboxEvent(t *testing.T) {
+	_, err := deliverOutboxEvent(nil, models.OutboxEvent{EventType: "TEST_UNKNOWN"})
+	assert.ErrorContains(t, err, "no outbox handler")
+
+	RegisterHandler("TEST_OK", func(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+		return event.AggregateCode, nil
+	})
+	res, err := deliverOutboxEvent(nil, models.OutboxEvent{EventType: "TEST_OK", AggregateCode: "DO-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "DO-1", res)
+
+	RegisterHandler("TEST_FAIL", func(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+		return nil, errors.New("remote down")
+	})
+	_, err = deliverOutboxEvent(nil, models.OutboxEvent{EventType: "TEST_FAIL"})
+	assert.EqualError(t, err, "remote down")
+
+	RegisterHandler("TEST_PANIC", func(gormx *gorm.DB, event models.OutboxEvent) (interface{}, error) {
+		panic("request error")
+	})
+	_, err = deliverOutboxEvent(nil, models.OutboxEvent{EventType: "TEST_PANIC"})
+	assert.EqualError(t, err, "panic: request error", "a panicking client fails the attempt instead of the job")
+}
@@ -0,0 +1,422 @@
+package paymentService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	repositoryCredit "prime-erp-core/internal/repositories/credit"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// allocationTolerance is the smallest amount left open on an invoice or unapplied on a payment.
+const allocationTolerance = 0.005
+
+// openInvoice is an AR or DN invoice of a customer with what is already paid on it.
+type openInvoice struct {
+	InvoiceCode   string
+	InvoiceType   string
+	CustomerCode  string
+	DocumentRef   string
+	TaxInvoice    string
+	SaleCodes     string // comma separated document_ref of the items
+	DocumentDate  time.Time
+	DueDate       *time.Time
+	CreditTermDay float64
+	TotalAmount   float64
+	Paid          float64
+}
+
+func (i openInvoice) open() float64 {
+	return i.TotalAmount - i.Paid
+}
+
+// due is the invoice's due date, or else its date plus its credit term.
+func (i openInvoice) due() time.Time {
+	if i.DueDate != nil {
+		return *i.DueDate
+	}
+	return i.DocumentDate.AddDate(0, 0, int(i.CreditTermDay))
+}
+
+// refs are the codes a customer may quote to pay the invoice: its own, its tax invoice and its documents.
+func (i openInvoice) refs() []string {
+	refs := []string{i.InvoiceCode, i.TaxInvoice, i.DocumentRef}
+	if i.SaleCodes != "" {
+		refs = append(refs, strings.Split(i.SaleCodes, ",")...)
+	}
+	return refs
+}
+
+// matches says whether a payment reference names the invoice, its tax invoice or a document it was raised for.
+func (i openInvoice) matches(reference string) bool {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return false
+	}
+	for _, ref := range i.refs() {
+		if ref != "" && strings.EqualFold(ref, reference) {
+			return true
+		}
+	}
+	return false
+}
+
+type PaymentAllocation struct {
+	InvoiceCode string  `json:"invoice_code"`
+	Amount      float64 `json:"amount"`
+}
+
+// InvoicePaymentStatus derives an invoice's payment status from what is allocated to it.
+func InvoicePaymentStatus(totalAmount float64, paid float64) string {
+	switch {
+	case paid < allocationTolerance:
+		return models.InvoicePaymentUnpaid
+	case totalAmount-paid < allocationTolerance:
+		return models.InvoicePaymentPaid
+	default:
+		return models.InvoicePaymentPartial
+	}
+}
+
+// allocatePayment splits amount over the customer's open invoices: the manual allocations first, then with
+// OLDEST_DUE or REFERENCE the rest over the remaining open amounts, oldest due first. What is not allocated is
+// returned as unapplied.
+func allocatePayment(amount float64, invoices []openInvoice, mode string, reference string, manual []PaymentAllocation) ([]PaymentAllocation, float64, error) {
+	if mode == "" {
+		mode = models.PaymentAllocationManual
+	}
+	switch mode {
+	case models.PaymentAllocationManual, models.PaymentAllocationOldestDue:
+	case models.PaymentAllocationReference:
+		if strings.TrimSpace(reference) == "" {
+			return nil, 0, errors.New("allocation by reference needs a reference")
+		}
+	default:
+		return nil, 0, fmt.Errorf("invalid allocation %q", mode)
+	}
+	if amount < 0 {
+		return nil, 0, errors.New("payment amount cannot be negative")
+	}
+
+	open := map[string]float64{}
+	for _, invoice := range invoices {
+		open[invoice.InvoiceCode] = invoice.open()
+	}
+
+	allocations := []PaymentAllocation{}
+	index := map[string]int{}
+	remain := amount
+	add := func(invoiceCode string, allocated float64) {
+		open[invoiceCode] -= allocated
+		remain -= allocated
+		if i, ok := index[invoiceCode]; ok {
+			allocations[i].Amount += allocated
+			return
+		}
+		index[invoiceCode] = len(allocations)
+		allocations = append(allocations, PaymentAllocation{InvoiceCode: invoiceCode, Amount: allocated})
+	}
+
+	for _, m := range manual {
+		invoiceOpen, ok := open[m.InvoiceCode]
+		if !ok {
+			return nil, 0, fmt.Errorf("invoice %s is not an open AR or DN invoice of the customer", m.InvoiceCode)
+		}
+		if m.Amount <= 0 {
+			return nil, 0, fmt.Errorf("allocation to invoice %s must be greater than 0", m.InvoiceCode)
+		}
+		if m.Amount-invoiceOpen >= allocationTolerance {
+			return nil, 0, &utils.ConflictError{
+				Code:    "ALLOCATION_EXCEEDS_OPEN",
+				Message: fmt.Sprintf("allocation of %.2f to invoice %s exceeds its open amount %.2f", m.Amount, m.InvoiceCode, invoiceOpen),
+				Details: map[string]interface{}{"invoice_code": m.InvoiceCode, "amount": m.Amount, "open_amount": invoiceOpen},
+			}
+		}
+		if m.Amount-remain >= allocationTolerance {
+			return nil, 0, fmt.Errorf("allocations exceed the payment amount %.2f", amount)
+		}
+		add(m.InvoiceCode, m.Amount)
+	}
+
+	if mode != models.PaymentAllocationManual {
+		candidates := []openInvoice{}
+		for _, invoice := range invoices {
+			if mode == models.PaymentAllocationReference && !invoice.matches(reference) {
+				continue
+			}
+			candidates = append(candidates, invoice)
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if !candidates[i].due().Equal(candidates[j].due()) {
+				return candidates[i].due().Before(candidates[j].due())
+			}
+			if !candidates[i].DocumentDate.Equal(candidates[j].DocumentDate) {
+				return candidates[i].DocumentDate.Before(candidates[j].DocumentDate)
+			}
+			return candidates[i].InvoiceCode < candidates[j].InvoiceCode
+		})
+
+		for _, invoice := range candidates {
+			if remain < allocationTolerance {
+				break
+			}
+			invoiceOpen := open[invoice.InvoiceCode]
+			if invoiceOpen < allocationTolerance {
+				continue
+			}
+			add(invoice.InvoiceCode, math.Min(remain, invoiceOpen))
+		}
+	}
+
+	if remain < allocationTolerance {
+		remain = 0
+	}
+	return allocations, remain, nil
+}
+
+// lockCustomerAllocation serializes allocations to one customer's invoices until tx ends, so two payments cannot
+// both take the same open amount.
+func lockCustomerAllocation(tx *gorm.DB, customerCode string) error {
+	if err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext(?))`, "payment_allocation:"+customerCode).Error; err != nil {
+		return fmt.Errorf("failed to lock payments of %s: %v", customerCode, err)
+	}
+	return nil
+}
+
+// openInvoiceQuery reads active AR and DN invoices with what payments that are not cancelled paid on them.
+const openInvoiceQuery = `
+	select i.invoice_code, i.invoice_type, coalesce(i.party_code, '') customer_code
+		, coalesce(i.document_ref, '') document_ref, coalesce(i.tax_invoice, '') tax_invoice
+		, coalesce(si.sale_codes, '') sale_codes
+		, coalesce(i.invoice_date, i.document_date, i.create_dtm) document_date, i.due_date
+		, coalesce(i.credit_term_day, 0) credit_term_day, coalesce(i.total_amount, 0) total_amount
+		, coalesce(pa.amount, 0) paid
+	from invoice i
+	left join (
+		select pi.invoice_code, sum(pi.amount) amount
+		from payment_invoice pi
+		join payment p on p.id = pi.payment_id
+		where coalesce(p.status, '') <> 'CANCELLED'
+		group by pi.invoice_code
+	) pa on pa.invoice_code = i.invoice_code
+	left join lateral (
+		select string_agg(distinct ii.document_ref, ',') sale_codes
+		from invoice_item ii
+		where ii.invoice_id = i.id and coalesce(ii.document_ref, '') <> ''
+	) si on true
+	where i.invoice_type in ('AR', 'DN') and i.status in ('PENDING', 'COMPLETED')`
+
+// loadOpenInvoices reads the customer's invoices, settled ones included so manual allocations to them are reported
+// as exceeding.
+func loadOpenInvoices(tx *gorm.DB, customerCode string) ([]openInvoice, error) {
+	invoices := []openInvoice{}
+	if err := tx.Raw(openInvoiceQuery+`
+		and i.party_code = ?`, customerCode).Scan(&invoices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open invoices of %s: %v", customerCode, err)
+	}
+	return invoices, nil
+}
+
+// applyPaymentAllocation allocates a saved payment inside tx, adding to what it already allocated, and returns the
+// new payment_invoice rows with what is left unapplied.
+func applyPaymentAllocation(tx *gorm.DB, user string, payment models.Payment, mode string, manual []PaymentAllocation, applyDate time.Time) ([]models.PaymentInvoice, float64, error) {
+	if err := lockCustomerAllocation(tx, payment.CustomerCode); err != nil {
+		return nil, 0, err
+	}
+	invoices, err := loadOpenInvoices(tx, payment.CustomerCode)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var allocated float64
+	if err := tx.Model(&models.PaymentInvoice{}).Where("payment_id = ?", payment.ID).
+		Select("coalesce(sum(amount), 0)").Scan(&allocated).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get allocations of payment %s: %v", payment.PaymentCode, err)
+	}
+
+	allocations, unapplied, err := allocatePayment(payment.Amount-allocated, invoices, mode, payment.Reference, manual)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	rows := make([]models.PaymentInvoice, 0, len(allocations))
+	for _, allocation := range allocations {
+		rows = append(rows, models.PaymentInvoice{
+			ID:          uuid.New(),
+			PaymentID:   payment.ID,
+			InvoiceCode: allocation.InvoiceCode,
+			Amount:      allocation.Amount,
+			ApplyDate:   applyDate,
+			CreateBy:    user,
+			CreateDtm:   now,
+			UpdateBy:    user,
+			UpdateDate:  now,
+		})
+	}
+	if len(rows) > 0 {
+		if err := tx.Create(&rows).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to allocate payment %s: %v", payment.PaymentCode, err)
+		}
+	}
+
+	invoiceCodes := make([]string, 0, len(rows))
+	for _, row := range rows {
+		invoiceCodes = append(invoiceCodes, row.InvoiceCode)
+	}
+	if err := queuePaymentCreditLedgerPost(tx, user, payment, invoiceCodes, applyDate); err != nil {
+		return nil, 0, err
+	}
+	return rows, unapplied, nil
+}
+
+// queuePaymentCreditLedgerPost posts what a payment now leaves unpaid on invoices to their customers' credit, at the
+// date it was applied.
+func queuePaymentCreditLedgerPost(tx *gorm.DB, user string, payment models.Payment, invoiceCodes []string, at time.Time) error {
+	postings, err := repositoryCredit.InvoicePostings(tx, invoiceCodes, at)
+	if err != nil {
+		return err
+	}
+	return outboxService.QueueCreditLedgerPost(tx, user, outboxService.CreditLedgerPost{
+		SourceType: models.CreditSourcePayment,
+		SourceRef:  payment.PaymentCode,
+		Postings:   postings,
+	})
+}
+
+type AllocatePaymentRequest struct {
+	PaymentCode string              `json:"payment_code"`
+	Allocation  string              `json:"allocation"` // MANUAL (default), OLDEST_DUE or REFERENCE
+	Reference   string              `json:"reference"`  // replaces the payment's reference for REFERENCE
+	Allocations []PaymentAllocation `json:"allocations"`
+	Reset       bool                `json:"reset"` // release the payment's allocations first, to allocate it again
+}
+
+type AllocatePaymentResponse struct {
+	PaymentCode     string                  `json:"payment_code"`
+	Released        []models.PaymentInvoice `json:"released"`
+	Allocated       []models.PaymentInvoice `json:"allocated"`
+	UnappliedAmount float64                 `json:"unapplied_amount"`
+}
+
+// AllocatePayment applies a payment's unapplied credit to open invoices, or with reset re-allocates the payment.
+func AllocatePayment(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := AllocatePaymentRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if req.PaymentCode == "" {
+		return nil, errors.New("payment_code is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+	res := AllocatePaymentResponse{PaymentCode: req.PaymentCode, Released: []models.PaymentInvoice{}}
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("payment_code = ?", req.PaymentCode).Take(&payment).Error; err != nil {
+			return fmt.Errorf("failed to get payment %s: %v", req.PaymentCode, err)
+		}
+		if payment.Status == "CANCELLED" {
+			return &utils.ConflictError{Code: "PAYMENT_CANCELLED", Message: fmt.Sprintf("payment %s is cancelled", payment.PaymentCode)}
+		}
+		if req.Reference != "" && req.Reference != payment.Reference {
+			payment.Reference = req.Reference
+			if err := tx.Model(&models.Payment{}).Where("id = ?", payment.ID).
+				Updates(map[string]interface{}{"reference": payment.Reference, "update_by": user}).Error; err != nil {
+				return fmt.Errorf("failed to update payment %s: %v", payment.PaymentCode, err)
+			}
+		}
+
+		if req.Reset {
+			if err := tx.Clauses(clause.Returning{}).Where("payment_id = ?", payment.ID).Delete(&res.Released).Error; err != nil {
+				return fmt.Errorf("failed to release allocations of payment %s: %v", payment.PaymentCode, err)
+			}
+		}
+
+		allocated, unapplied, err := applyPaymentAllocation(tx, user, payment, req.Allocation, req.Allocations, time.Now())
+		if err != nil {
+			return err
+		}
+		res.Allocated = allocated
+		res.UnappliedAmount = unapplied
+
+		if len(res.Released) > 0 {
+			invoiceCodes := make([]string, 0, len(res.Released))
+			for _, released := range res.Released {
+				invoiceCodes = append(invoiceCodes, released.InvoiceCode)
+			}
+			return queuePaymentCreditLedgerPost(tx, user, payment, invoiceCodes, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type GetUnappliedCreditRequest struct {
+	CustomerCodes []string `json:"customer_codes"`
+}
+
+type UnappliedPayment struct {
+	PaymentID       uuid.UUID `json:"payment_id"`
+	PaymentCode     string    `json:"payment_code"`
+	CustomerCode    string    `json:"customer_code"`
+	PaymentDate     time.Time `json:"payment_date"`
+	Reference       string    `json:"reference"`
+	Amount          float64   `json:"amount"`
+	AllocatedAmount float64   `json:"allocated_amount"`
+	UnappliedAmount float64   `json:"unapplied_amount"`
+}
+
+// GetUnappliedCredit lists the payments of customers that still carry unapplied credit, oldest first.
+func GetUnappliedCredit(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetUnappliedCreditRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.CustomerCodes) == 0 {
+		return nil, errors.New("customer_codes is required")
+	}
+
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	qb := db.NewQueryBuilder(`
+		select p.id payment_id, p.payment_code, p.customer_code, p.payment_date, coalesce(p.reference, '') reference
+			, coalesce(p.amount, 0) amount, coalesce(sum(pi.amount), 0) allocated_amount
+			, coalesce(p.amount, 0) - coalesce(sum(pi.amount), 0) unapplied_amount
+		from payment p
+		left join payment_invoice pi on pi.payment_id = p.id
+		where coalesce(p.status, '') <> 'CANCELLED' and p.customer_code in `)
+	qb.Append(qb.InStrings(req.CustomerCodes))
+	qb.Append(`
+		group by p.id, p.payment_code, p.customer_code, p.payment_date, p.reference, p.amount
+		having coalesce(p.amount, 0) - coalesce(sum(pi.amount), 0) >= ?
+		order by p.customer_code, p.payment_date, p.payment_code`, allocationTolerance)
+
+	return db.SelectBuilder[UnappliedPayment](sqlx, qb)
+}
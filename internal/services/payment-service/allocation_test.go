@@ -0,0 +1,81 @@
+package paymentService
+
+import (
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocatePayment(t *testing.T) {
+	date := func(day int) time.Time { return time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC) }
+	due := date(20)
+	invoices := []openInvoice{
+		{InvoiceCode: "AR-3", DocumentDate: date(5), CreditTermDay: 30, TotalAmount: 300},
+		{InvoiceCode: "AR-1", DocumentDate: date(1), CreditTermDay: 30, TotalAmount: 100, Paid: 40},
+		{InvoiceCode: "AR-2", DocumentDate: date(10), DueDate: &due, TotalAmount: 200, TaxInvoice: "TX-2"},
+		{InvoiceCode: "AR-4", DocumentDate: date(2), TotalAmount: 50, Paid: 50},
+	}
+
+	t.Run("oldest due first with overpayment left unapplied", func(t *testing.T) {
+		allocations, unapplied, err := allocatePayment(600, invoices, models.PaymentAllocationOldestDue, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []PaymentAllocation{{"AR-2", 200}, {"AR-1", 60}, {"AR-3", 300}}, allocations)
+		assert.InDelta(t, 40, unapplied, 0.001)
+	})
+
+	t.Run("partial payment", func(t *testing.T) {
+		allocations, unapplied, err := allocatePayment(250, invoices, models.PaymentAllocationOldestDue, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []PaymentAllocation{{"AR-2", 200}, {"AR-1", 50}}, allocations)
+		assert.Zero(t, unapplied)
+	})
+
+	t.Run("manual first then the rest", func(t *testing.T) {
+		allocations, unapplied, err := allocatePayment(400, invoices, models.PaymentAllocationOldestDue, "", []PaymentAllocation{{"AR-3", 300}})
+		require.NoError(t, err)
+		assert.Equal(t, []PaymentAllocation{{"AR-3", 300}, {"AR-2", 100}}, allocations)
+		assert.Zero(t, unapplied)
+	})
+
+	t.Run("by reference", func(t *testing.T) {
+		allocations, unapplied, err := allocatePayment(250, invoices, models.PaymentAllocationReference, "tx-2", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []PaymentAllocation{{"AR-2", 200}}, allocations)
+		assert.InDelta(t, 50, unapplied, 0.001)
+	})
+
+	t.Run("manual leaves the rest unapplied", func(t *testing.T) {
+		allocations, unapplied, err := allocatePayment(100, invoices, "", "", []PaymentAllocation{{"AR-1", 60}})
+		require.NoError(t, err)
+		assert.Equal(t, []PaymentAllocation{{"AR-1", 60}}, allocations)
+		assert.InDelta(t, 40, unapplied, 0.001)
+	})
+
+	t.Run("manual over the open amount", func(t *testing.T) {
+		_, _, err := allocatePayment(100, invoices, "", "", []PaymentAllocation{{"AR-4", 10}})
+		var conflict *utils.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, "ALLOCATION_EXCEEDS_OPEN", conflict.Code)
+	})
+
+	t.Run("manual over the payment", func(t *testing.T) {
+		_, _, err := allocatePayment(100, invoices, "", "", []PaymentAllocation{{"AR-3", 80}, {"AR-2", 30}})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown invoice", func(t *testing.T) {
+		_, _, err := allocatePayment(100, invoices, "", "", []PaymentAllocation{{"AR-9", 10}})
+		assert.Error(t, err)
+	})
+}
+
+func TestInvoicePaymentStatus(t *testing.T) {
+	assert.Equal(t, models.InvoicePaymentUnpaid, InvoicePaymentStatus(100, 0))
+	assert.Equal(t, models.InvoicePaymentPartial, InvoicePaymentStatus(100, 40))
+	assert.Equal(t, models.InvoicePaymentPaid, InvoicePaymentStatus(100, 100))
+}
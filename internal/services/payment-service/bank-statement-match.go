@@ -0,0 +1,457 @@
+package paymentService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	systemConfigService "prime-erp-core/internal/services/system-config"
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scores of the evidence a statement line is paid by a customer. A line is PROPOSED when its best proposal reaches
+// bankMatchConfident and no other scores as high.
+const (
+	bankMatchReference = 50 // the line's reference quotes an invoice or sale code
+	bankMatchAmount    = 30 // the line pays exactly what the matched invoices leave open
+	bankMatchAccount   = 20 // the line comes from an account the customer paid from before
+
+	bankMatchConfident    = 50
+	bankMatchMaxProposals = 5
+	bankMatchMinRefLength = 4 // shorter codes match too much free text
+)
+
+// BankMatchProposal is a customer a statement line may be paid by, with how it would be allocated.
+type BankMatchProposal struct {
+	CustomerCode    string              `json:"customer_code"`
+	Allocations     []PaymentAllocation `json:"allocations"`
+	UnappliedAmount float64             `json:"unapplied_amount"`
+	Score           int                 `json:"score"`
+	Reasons         []string            `json:"reasons"` // REFERENCE, AMOUNT, ACCOUNT
+}
+
+// normalizeBankAccount keeps the letters and digits of an account or code, upper cased, so formatting does not
+// get in the way of comparing them.
+func normalizeBankAccount(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, value)
+}
+
+// matchBankLine proposes the customers a line may be paid by, best first, from the open invoices and the customers
+// known to pay from the line's account.
+func matchBankLine(line models.BankStatementLine, invoices []openInvoice, accountCustomers []string) []BankMatchProposal {
+	text := normalizeBankAccount(line.Reference)
+	isAccountCustomer := map[string]bool{}
+	for _, customerCode := range accountCustomers {
+		isAccountCustomer[customerCode] = true
+	}
+
+	byCustomer := map[string][]openInvoice{}
+	refHits := map[string][]openInvoice{}
+	amountHits := map[string][]openInvoice{}
+	for _, invoice := range invoices {
+		if invoice.open() < allocationTolerance {
+			continue
+		}
+		byCustomer[invoice.CustomerCode] = append(byCustomer[invoice.CustomerCode], invoice)
+		for _, ref := range invoice.refs() {
+			if ref = normalizeBankAccount(ref); len(ref) >= bankMatchMinRefLength && strings.Contains(text, ref) {
+				refHits[invoice.CustomerCode] = append(refHits[invoice.CustomerCode], invoice)
+				break
+			}
+		}
+		if math.Abs(invoice.open()-line.Amount) < allocationTolerance {
+			amountHits[invoice.CustomerCode] = append(amountHits[invoice.CustomerCode], invoice)
+		}
+	}
+
+	customers := map[string]bool{}
+	for _, set := range []map[string][]openInvoice{refHits, amountHits} {
+		for customerCode := range set {
+			customers[customerCode] = true
+		}
+	}
+	for customerCode := range isAccountCustomer {
+		customers[customerCode] = true
+	}
+
+	proposals := []BankMatchProposal{}
+	for customerCode := range customers {
+		proposal := BankMatchProposal{CustomerCode: customerCode, Reasons: []string{}}
+		var candidates []openInvoice
+		switch {
+		case len(refHits[customerCode]) > 0:
+			candidates = refHits[customerCode]
+			proposal.Score += bankMatchReference
+			proposal.Reasons = append(proposal.Reasons, "REFERENCE")
+			if math.Abs(sumOpen(candidates)-line.Amount) < allocationTolerance {
+				proposal.Score += bankMatchAmount
+				proposal.Reasons = append(proposal.Reasons, "AMOUNT")
+			}
+		case len(amountHits[customerCode]) > 0:
+			candidates = amountHits[customerCode]
+			proposal.Score += bankMatchAmount
+			proposal.Reasons = append(proposal.Reasons, "AMOUNT")
+		case isAccountCustomer[customerCode]:
+			candidates = byCustomer[customerCode]
+			if len(candidates) > 0 && math.Abs(sumOpen(candidates)-line.Amount) < allocationTolerance {
+				proposal.Score += bankMatchAmount
+				proposal.Reasons = append(proposal.Reasons, "AMOUNT")
+			}
+		}
+		if isAccountCustomer[customerCode] {
+			proposal.Score += bankMatchAccount
+			proposal.Reasons = append(proposal.Reasons, "ACCOUNT")
+		}
+
+		allocations, unapplied, err := allocatePayment(line.Amount, candidates, models.PaymentAllocationOldestDue, "", nil)
+		if err != nil {
+			continue
+		}
+		proposal.Allocations = allocations
+		proposal.UnappliedAmount = unapplied
+		proposals = append(proposals, proposal)
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].Score != proposals[j].Score {
+			return proposals[i].Score > proposals[j].Score
+		}
+		if proposals[i].UnappliedAmount != proposals[j].UnappliedAmount {
+			return proposals[i].UnappliedAmount < proposals[j].UnappliedAmount
+		}
+		return proposals[i].CustomerCode < proposals[j].CustomerCode
+	})
+	if len(proposals) > bankMatchMaxProposals {
+		proposals = proposals[:bankMatchMaxProposals]
+	}
+	return proposals
+}
+
+func sumOpen(invoices []openInvoice) float64 {
+	var sum float64
+	for _, invoice := range invoices {
+		sum += invoice.open()
+	}
+	return sum
+}
+
+// bankLineStatus is PROPOSED when the best proposal is confident and stands out, and UNMATCHED otherwise.
+func bankLineStatus(proposals []BankMatchProposal) string {
+	if len(proposals) == 0 || proposals[0].Score < bankMatchConfident {
+		return models.BankLineUnmatched
+	}
+	if len(proposals) > 1 && proposals[1].Score == proposals[0].Score {
+		return models.BankLineUnmatched
+	}
+	return models.BankLineProposed
+}
+
+// proposeBankMatches matches lines against the open invoices of all customers and saves the proposals.
+func proposeBankMatches(tx *gorm.DB, user string, lines []models.BankStatementLine) ([]models.BankStatementLine, error) {
+	if len(lines) == 0 {
+		return lines, nil
+	}
+
+	invoices := []openInvoice{}
+	if err := tx.Raw(openInvoiceQuery+`
+		and coalesce(i.total_amount, 0) - coalesce(pa.amount, 0) >= ?`, allocationTolerance).Scan(&invoices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open invoices: %v", err)
+	}
+
+	accountNos := []string{}
+	for _, line := range lines {
+		if accountNo := normalizeBankAccount(line.CounterpartyAccount); accountNo != "" {
+			accountNos = append(accountNos, accountNo)
+		}
+	}
+	accountCustomers := map[string][]string{}
+	if len(accountNos) > 0 {
+		accounts := []models.CustomerBankAccount{}
+		if err := tx.Where("account_no IN ?", accountNos).Find(&accounts).Error; err != nil {
+			return nil, fmt.Errorf("failed to get customer bank accounts: %v", err)
+		}
+		for _, account := range accounts {
+			accountCustomers[account.AccountNo] = append(accountCustomers[account.AccountNo], account.CustomerCode)
+		}
+	}
+
+	for i := range lines {
+		proposals := matchBankLine(lines[i], invoices, accountCustomers[normalizeBankAccount(lines[i].CounterpartyAccount)])
+		data, err := json.Marshal(proposals)
+		if err != nil {
+			return nil, err
+		}
+		lines[i].Proposals = data
+		lines[i].Status = bankLineStatus(proposals)
+		lines[i].UpdateBy = user
+		if err := tx.Model(&models.BankStatementLine{}).Where("id = ?", lines[i].ID).Updates(map[string]interface{}{
+			"proposals": lines[i].Proposals,
+			"status":    lines[i].Status,
+			"update_by": user,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to save proposals of bank statement line %d: %v", lines[i].LineNo, err)
+		}
+	}
+	return lines, nil
+}
+
+type MatchBankStatementLinesRequest struct {
+	StatementIDs []uuid.UUID `json:"statement_ids"`
+	LineIDs      []uuid.UUID `json:"line_ids"`
+}
+
+// MatchBankStatementLines proposes matches again for lines not yet reconciled, e.g. after new invoices were issued.
+func MatchBankStatementLines(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := MatchBankStatementLinesRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+	lines := []models.BankStatementLine{}
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status IN ?", []string{models.BankLineUnmatched, models.BankLineProposed})
+		if len(req.StatementIDs) > 0 {
+			query = query.Where("statement_id IN ?", req.StatementIDs)
+		}
+		if len(req.LineIDs) > 0 {
+			query = query.Where("id IN ?", req.LineIDs)
+		}
+		if err := query.Order("booking_date, line_no").Find(&lines).Error; err != nil {
+			return fmt.Errorf("failed to get bank statement lines: %v", err)
+		}
+
+		proposed, err := proposeBankMatches(tx, user, lines)
+		if err != nil {
+			return err
+		}
+		lines = proposed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+type GetBankStatementLinesRequest struct {
+	StatementIDs []uuid.UUID `json:"statement_ids"`
+	Statuses     []string    `json:"statuses"` // default UNMATCHED, the reconciliation queue
+}
+
+// GetBankStatementLines lists statement lines, by default those waiting in the reconciliation queue.
+func GetBankStatementLines(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetBankStatementLinesRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.Statuses) == 0 {
+		req.Statuses = []string{models.BankLineUnmatched}
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []models.BankStatementLine{}
+	query := gormx.Where("status IN ?", req.Statuses)
+	if len(req.StatementIDs) > 0 {
+		query = query.Where("statement_id IN ?", req.StatementIDs)
+	}
+	if err := query.Order("booking_date, statement_id, line_no").Find(&lines).Error; err != nil {
+		return nil, fmt.Errorf("failed to get bank statement lines: %v", err)
+	}
+	return lines, nil
+}
+
+type ConfirmBankStatementLine struct {
+	LineID       uuid.UUID           `json:"line_id"`
+	CustomerCode string              `json:"customer_code"` // empty confirms the line's proposal
+	Allocation   string              `json:"allocation"`
+	Allocations  []PaymentAllocation `json:"allocations"`
+}
+
+type ConfirmBankStatementLinesRequest struct {
+	Lines []ConfirmBankStatementLine `json:"lines"`
+}
+
+type ConfirmedBankStatementLine struct {
+	LineID          uuid.UUID               `json:"line_id"`
+	CustomerCode    string                  `json:"customer_code"`
+	PaymentCode     string                  `json:"payment_code"`
+	Allocated       []models.PaymentInvoice `json:"allocated"`
+	UnappliedAmount float64                 `json:"unapplied_amount"`
+}
+
+// ConfirmBankStatementLines turns statement lines into payments, either as proposed or to the customer and
+// allocations given, and remembers the payer's account for later matches.
+func ConfirmBankStatementLines(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := ConfirmBankStatementLinesRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.Lines) == 0 {
+		return nil, errors.New("lines is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := reservePaymentCodes(ctx, len(req.Lines))
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+	res := []ConfirmedBankStatementLine{}
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		for i, item := range req.Lines {
+			var line models.BankStatementLine
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", item.LineID).Take(&line).Error; err != nil {
+				return fmt.Errorf("failed to get bank statement line %s: %v", item.LineID, err)
+			}
+			if line.Status != models.BankLineUnmatched && line.Status != models.BankLineProposed {
+				return &utils.ConflictError{
+					Code:    "BANK_LINE_RECONCILED",
+					Message: fmt.Sprintf("bank statement line %s is already %s", line.ID, line.Status),
+					Details: map[string]interface{}{"line_id": line.ID, "status": line.Status, "payment_code": line.PaymentCode},
+				}
+			}
+
+			customerCode, mode, manual := item.CustomerCode, item.Allocation, item.Allocations
+			if customerCode == "" {
+				proposals := []BankMatchProposal{}
+				if err := json.Unmarshal(line.Proposals, &proposals); err != nil {
+					return fmt.Errorf("failed to read proposals of bank statement line %s: %v", line.ID, err)
+				}
+				if line.Status != models.BankLineProposed || len(proposals) == 0 {
+					return fmt.Errorf("bank statement line %s has no proposal to confirm, customer_code is required", line.ID)
+				}
+				customerCode, mode, manual = proposals[0].CustomerCode, models.PaymentAllocationManual, proposals[0].Allocations
+			}
+
+			payment := models.Payment{
+				ID:           uuid.New(),
+				PaymentCode:  codes.Data[i],
+				CustomerCode: customerCode,
+				PaymentDate:  line.BookingDate,
+				Amount:       line.Amount,
+				Method:       "BANK_TRANSFER",
+				Status:       "COMPLETED",
+				Remark:       strings.TrimSpace("bank " + line.BankRef),
+				Reference:    truncateRunes(line.Reference, 100),
+				CreateBy:     user,
+				UpdateBy:     user,
+			}
+			if err := tx.Create(&payment).Error; err != nil {
+				return fmt.Errorf("failed to create payment %s: %v", payment.PaymentCode, err)
+			}
+			allocated, unapplied, err := applyPaymentAllocation(tx, user, payment, mode, manual, line.BookingDate)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Model(&models.BankStatementLine{}).Where("id = ?", line.ID).Updates(map[string]interface{}{
+				"status":        models.BankLineMatched,
+				"customer_code": customerCode,
+				"payment_code":  payment.PaymentCode,
+				"update_by":     user,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update bank statement line %s: %v", line.ID, err)
+			}
+			if accountNo := normalizeBankAccount(line.CounterpartyAccount); accountNo != "" {
+				account := models.CustomerBankAccount{AccountNo: accountNo, CustomerCode: customerCode, CreateBy: user}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&account).Error; err != nil {
+					return fmt.Errorf("failed to save bank account of %s: %v", customerCode, err)
+				}
+			}
+
+			res = append(res, ConfirmedBankStatementLine{
+				LineID:          line.ID,
+				CustomerCode:    customerCode,
+				PaymentCode:     payment.PaymentCode,
+				Allocated:       allocated,
+				UnappliedAmount: unapplied,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		systemConfigService.VoidReservedNumbers(ctx, paymentRunningConfig, codes.Reserved, "confirm bank statement failed: "+err.Error())
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type IgnoreBankStatementLinesRequest struct {
+	LineIDs []uuid.UUID `json:"line_ids"`
+	Remark  string      `json:"remark"`
+}
+
+// IgnoreBankStatementLines takes credits that are not customer payments, e.g. interest or refunds, off the queue.
+func IgnoreBankStatementLines(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := IgnoreBankStatementLinesRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.LineIDs) == 0 {
+		return nil, errors.New("line_ids is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	result := gormx.Model(&models.BankStatementLine{}).
+		Where("id IN ? AND status IN ?", req.LineIDs, []string{models.BankLineUnmatched, models.BankLineProposed}).
+		Updates(map[string]interface{}{
+			"status":    models.BankLineIgnored,
+			"remark":    req.Remark,
+			"update_by": middleware.GetUserCode(ctx),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to ignore bank statement lines: %v", result.Error)
+	}
+
+	return map[string]interface{}{
+		"status":  "success",
+		"ignored": result.RowsAffected,
+	}, nil
+}
+
+func truncateRunes(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,471 @@
+package paymentService
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// bankStatementFile is what a parser reads out of a statement: its incoming credits, debits only counted.
+type bankStatementFile struct {
+	AccountNo     string
+	StatementRef  string
+	StatementDate *time.Time
+	Lines         []models.BankStatementLine
+	SkippedDebits int
+}
+
+type ImportBankStatementResponse struct {
+	Statement     models.BankStatement       `json:"statement"`
+	Lines         []models.BankStatementLine `json:"lines"`
+	Duplicates    int                        `json:"duplicates"`
+	SkippedDebits int                        `json:"skipped_debits"`
+	Proposed      int                        `json:"proposed"`
+	Unmatched     int                        `json:"unmatched"`
+}
+
+// ImportBankStatement reads an uploaded statement (form-data "file", CSV or camt.053 XML; "format" and, for CSV,
+// "account_no" optional), saves its new credit lines and proposes matches for them.
+func ImportBankStatement(ctx *gin.Context) (interface{}, error) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("missing file (form-data key: file): %v", err)
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	format := strings.ToUpper(strings.TrimSpace(ctx.PostForm("format")))
+	if format == "" {
+		format = detectBankStatementFormat(fileHeader.Filename, data)
+	}
+
+	var parsed *bankStatementFile
+	switch format {
+	case models.BankStatementFormatCSV:
+		parsed, err = parseBankStatementCSV(bytes.NewReader(data))
+	case models.BankStatementFormatCamt053:
+		parsed, err = parseCamt053(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("invalid format %q, expected CSV or CAMT053", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if accountNo := strings.TrimSpace(ctx.PostForm("account_no")); accountNo != "" {
+		parsed.AccountNo = accountNo
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+	statement := models.BankStatement{
+		ID:            uuid.New(),
+		FileName:      fileHeader.Filename,
+		Format:        format,
+		AccountNo:     parsed.AccountNo,
+		StatementRef:  parsed.StatementRef,
+		StatementDate: parsed.StatementDate,
+		CreateBy:      user,
+	}
+	stampBankStatementLines(&statement, parsed.Lines, user)
+
+	res := ImportBankStatementResponse{Lines: []models.BankStatementLine{}, SkippedDebits: parsed.SkippedDebits}
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&statement).Error; err != nil {
+			return fmt.Errorf("failed to save bank statement: %v", err)
+		}
+		for _, line := range parsed.Lines {
+			result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "line_hash"}}, DoNothing: true}).Create(&line)
+			if result.Error != nil {
+				return fmt.Errorf("failed to save bank statement line %d: %v", line.LineNo, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				res.Duplicates++
+				continue
+			}
+			res.Lines = append(res.Lines, line)
+		}
+		statement.LineCount = len(res.Lines)
+		if err := tx.Model(&statement).Update("line_count", statement.LineCount).Error; err != nil {
+			return fmt.Errorf("failed to save bank statement: %v", err)
+		}
+
+		lines, err := proposeBankMatches(tx, user, res.Lines)
+		if err != nil {
+			return err
+		}
+		res.Lines = lines
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.Statement = statement
+	for _, line := range res.Lines {
+		if line.Status == models.BankLineProposed {
+			res.Proposed++
+		} else {
+			res.Unmatched++
+		}
+	}
+	return res, nil
+}
+
+// stampBankStatementLines ties parsed lines to their statement. The hash identifies a transaction across imports;
+// identical transactions within one file are told apart by their occurrence.
+func stampBankStatementLines(statement *models.BankStatement, lines []models.BankStatementLine, user string) {
+	seen := map[string]int{}
+	for i := range lines {
+		key := strings.Join([]string{
+			normalizeBankAccount(statement.AccountNo),
+			lines[i].BookingDate.Format("2006-01-02"),
+			strconv.FormatFloat(lines[i].Amount, 'f', 2, 64),
+			lines[i].Currency,
+			lines[i].BankRef,
+			lines[i].Reference,
+			normalizeBankAccount(lines[i].CounterpartyAccount),
+		}, "|")
+		seen[key]++
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", key, seen[key])))
+
+		lines[i].ID = uuid.New()
+		lines[i].StatementID = statement.ID
+		lines[i].LineNo = i + 1
+		lines[i].LineHash = hex.EncodeToString(sum[:])
+		lines[i].Status = models.BankLineUnmatched
+		lines[i].Proposals = []byte("[]")
+		lines[i].CreateBy = user
+		lines[i].UpdateBy = user
+	}
+}
+
+func detectBankStatementFormat(fileName string, data []byte) string {
+	if strings.EqualFold(filepath.Ext(fileName), ".xml") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return models.BankStatementFormatCamt053
+	}
+	return models.BankStatementFormatCSV
+}
+
+// bankStatementCSVColumns maps the header names banks use to the fields of a line.
+var bankStatementCSVColumns = map[string]string{
+	"date":                 "booking_date",
+	"booking_date":         "booking_date",
+	"transaction_date":     "booking_date",
+	"posting_date":         "booking_date",
+	"value_date":           "value_date",
+	"amount":               "amount",
+	"credit":               "credit",
+	"deposit":              "credit",
+	"debit":                "debit",
+	"withdrawal":           "debit",
+	"currency":             "currency",
+	"reference":            "reference",
+	"description":          "reference",
+	"details":              "reference",
+	"remittance":           "reference",
+	"name":                 "counterparty_name",
+	"counterparty":         "counterparty_name",
+	"counterparty_name":    "counterparty_name",
+	"payer":                "counterparty_name",
+	"account":              "counterparty_account",
+	"counterparty_account": "counterparty_account",
+	"payer_account":        "counterparty_account",
+	"bank_ref":             "bank_ref",
+	"transaction_id":       "bank_ref",
+	"ref_no":               "bank_ref",
+}
+
+var bankStatementDateLayouts = []string{"2006-01-02", "02/01/2006", "2/1/2006", "02-01-2006", "20060102", "2006-01-02T15:04:05", time.RFC3339}
+
+// parseBankStatementCSV reads a statement with a header row. A line carries either a signed amount or separate
+// credit and debit columns; only credits are kept.
+func parseBankStatementCSV(r io.Reader) (*bankStatementFile, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("statement is empty")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.ReplaceAll(name, " ", "_")
+		if field, ok := bankStatementCSVColumns[name]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["booking_date"]; !ok {
+		return nil, errors.New("statement has no date column")
+	}
+	_, hasAmount := columns["amount"]
+	_, hasCredit := columns["credit"]
+	if !hasAmount && !hasCredit {
+		return nil, errors.New("statement has no amount or credit column")
+	}
+
+	parsed := &bankStatementFile{}
+	for n, record := range records[1:] {
+		rowNo := n + 2
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		var amount float64
+		if hasAmount && value("amount") != "" {
+			if amount, err = parseBankAmount(value("amount")); err != nil {
+				return nil, fmt.Errorf("row %d: %v", rowNo, err)
+			}
+		} else {
+			credit, err := parseBankAmount(value("credit"))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", rowNo, err)
+			}
+			debit, err := parseBankAmount(value("debit"))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", rowNo, err)
+			}
+			amount = credit - debit
+		}
+		if amount <= 0 {
+			parsed.SkippedDebits++
+			continue
+		}
+
+		bookingDate, err := parseBankDate(value("booking_date"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %v", rowNo, err)
+		}
+		line := models.BankStatementLine{
+			BookingDate:         bookingDate,
+			Amount:              amount,
+			Currency:            strings.ToUpper(value("currency")),
+			Reference:           value("reference"),
+			CounterpartyName:    value("counterparty_name"),
+			CounterpartyAccount: value("counterparty_account"),
+			BankRef:             value("bank_ref"),
+		}
+		if v := value("value_date"); v != "" {
+			valueDate, err := parseBankDate(v)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", rowNo, err)
+			}
+			line.ValueDate = &valueDate
+		}
+		parsed.Lines = append(parsed.Lines, line)
+	}
+	return parsed, nil
+}
+
+func parseBankAmount(value string) (float64, error) {
+	value = strings.NewReplacer(",", "", " ", "").Replace(value)
+	if value == "" {
+		return 0, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+func parseBankDate(value string) (time.Time, error) {
+	for _, layout := range bankStatementDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID         string      `xml:"Id"`
+	CreDtTm    string      `xml:"CreDtTm"`
+	IBAN       string      `xml:"Acct>Id>IBAN"`
+	OtherID    string      `xml:"Acct>Id>Othr>Id"`
+	Entries    []camtEntry `xml:"Ntry"`
+	ToDateTime string      `xml:"FrToDt>ToDtTm"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtEntry struct {
+	Amount         camtAmount     `xml:"Amt"`
+	CreditDebit    string         `xml:"CdtDbtInd"`
+	BookingDate    camtDate       `xml:"BookgDt"`
+	ValueDate      camtDate       `xml:"ValDt"`
+	AcctSvcrRef    string         `xml:"AcctSvcrRef"`
+	AdditionalInfo string         `xml:"AddtlNtryInf"`
+	Transactions   []camtTransfer `xml:"NtryDtls>TxDtls"`
+}
+
+// camtTransfer covers the transaction details of both camt.053.001.02 and the later versions.
+type camtTransfer struct {
+	Amount          *camtAmount `xml:"Amt"`
+	TxAmount        *camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	EndToEndID      string      `xml:"Refs>EndToEndId"`
+	AcctSvcrRef     string      `xml:"Refs>AcctSvcrRef"`
+	DebtorName      string      `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPartyName string      `xml:"RltdPties>Dbtr>Pty>Nm"`
+	DebtorIBAN      string      `xml:"RltdPties>DbtrAcct>Id>IBAN"`
+	DebtorOtherID   string      `xml:"RltdPties>DbtrAcct>Id>Othr>Id"`
+	Unstructured    []string    `xml:"RmtInf>Ustrd"`
+	CreditorRefs    []string    `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+}
+
+// parseCamt053 reads the credit entries of an ISO 20022 camt.053 statement. An entry batching several transfers
+// becomes a line per transfer.
+func parseCamt053(r io.Reader) (*bankStatementFile, error) {
+	doc := camtDocument{}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to read camt.053: %v", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, errors.New("camt.053 has no statement")
+	}
+
+	parsed := &bankStatementFile{}
+	for _, stmt := range doc.Statements {
+		if parsed.AccountNo == "" {
+			parsed.AccountNo = firstNonEmpty(stmt.IBAN, stmt.OtherID)
+			parsed.StatementRef = stmt.ID
+			if d, err := parseCamtDate(camtDate{DateTime: firstNonEmpty(stmt.ToDateTime, stmt.CreDtTm)}); err == nil {
+				parsed.StatementDate = &d
+			}
+		}
+
+		for _, entry := range stmt.Entries {
+			if entry.CreditDebit != "CRDT" {
+				parsed.SkippedDebits++
+				continue
+			}
+			bookingDate, err := parseCamtDate(entry.BookingDate)
+			if err != nil {
+				bookingDate, err = parseCamtDate(entry.ValueDate)
+				if err != nil {
+					return nil, fmt.Errorf("entry %s: %v", entry.AcctSvcrRef, err)
+				}
+			}
+			var valueDate *time.Time
+			if d, err := parseCamtDate(entry.ValueDate); err == nil {
+				valueDate = &d
+			}
+
+			transfers := entry.Transactions
+			if len(transfers) == 0 {
+				transfers = []camtTransfer{{}}
+			}
+			for _, transfer := range transfers {
+				amount := entry.Amount
+				if len(entry.Transactions) > 1 {
+					if transfer.Amount != nil {
+						amount = *transfer.Amount
+					} else if transfer.TxAmount != nil {
+						amount = *transfer.TxAmount
+					}
+				}
+				value, err := parseBankAmount(amount.Value)
+				if err != nil {
+					return nil, fmt.Errorf("entry %s: %v", entry.AcctSvcrRef, err)
+				}
+
+				reference := strings.Join(append(append([]string{}, transfer.CreditorRefs...), transfer.Unstructured...), " ")
+				if reference == "" {
+					reference = entry.AdditionalInfo
+				}
+				if transfer.EndToEndID != "" && transfer.EndToEndID != "NOTPROVIDED" {
+					reference = strings.TrimSpace(reference + " " + transfer.EndToEndID)
+				}
+				parsed.Lines = append(parsed.Lines, models.BankStatementLine{
+					BookingDate:         bookingDate,
+					ValueDate:           valueDate,
+					Amount:              value,
+					Currency:            amount.Currency,
+					Reference:           reference,
+					CounterpartyName:    firstNonEmpty(transfer.DebtorName, transfer.DebtorPartyName),
+					CounterpartyAccount: firstNonEmpty(transfer.DebtorIBAN, transfer.DebtorOtherID),
+					BankRef:             firstNonEmpty(transfer.AcctSvcrRef, entry.AcctSvcrRef),
+				})
+			}
+		}
+	}
+	return parsed, nil
+}
+
+func parseCamtDate(d camtDate) (time.Time, error) {
+	if d.Date != "" {
+		return parseBankDate(d.Date)
+	}
+	if d.DateTime != "" {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+			if t, err := time.Parse(layout, d.DateTime); err == nil {
+				return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid date %q", d.DateTime)
+	}
+	return time.Time{}, errors.New("missing date")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package paymentService
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBankStatementCSV(t *testing.T) {
+	csv := "\ufeffDate,Description,Credit,Debit,Payer Account,Name\n" +
+		"15/01/2026,INV2601-0001 thank you,\"1,070.00\",,123-4-56789-0,ACME\n" +
+		"15/01/2026,fee,,25.00,,\n" +
+		"\n" +
+		"2026-01-16,SO-0042,500,,,\n"
+
+	parsed, err := parseBankStatementCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Equal(t, 1, parsed.SkippedDebits)
+	require.Len(t, parsed.Lines, 2)
+	assert.Equal(t, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), parsed.Lines[0].BookingDate)
+	assert.Equal(t, 1070.0, parsed.Lines[0].Amount)
+	assert.Equal(t, "INV2601-0001 thank you", parsed.Lines[0].Reference)
+	assert.Equal(t, "123-4-56789-0", parsed.Lines[0].CounterpartyAccount)
+	assert.Equal(t, "ACME", parsed.Lines[0].CounterpartyName)
+	assert.Equal(t, 500.0, parsed.Lines[1].Amount)
+
+	_, err = parseBankStatementCSV(strings.NewReader("Description,Amount\nx,1\n"))
+	assert.Error(t, err)
+}
+
+func TestParseCamt053(t *testing.T) {
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Id>STMT-20260115</Id>
+      <CreDtTm>2026-01-15T23:00:00+07:00</CreDtTm>
+      <Acct><Id><Othr><Id>0011223344</Id></Othr></Id></Acct>
+      <Ntry>
+        <Amt Ccy="THB">1500.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2026-01-15</Dt></BookgDt>
+        <ValDt><Dt>2026-01-15</Dt></ValDt>
+        <AcctSvcrRef>B-1</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="THB">1000.00</Amt></TxAmt></AmtDtls>
+            <RltdPties><Dbtr><Nm>ACME</Nm></Dbtr><DbtrAcct><Id><IBAN>TH001</IBAN></Id></DbtrAcct></RltdPties>
+            <RmtInf><Ustrd>INV2601-0001</Ustrd></RmtInf>
+          </TxDtls>
+          <TxDtls>
+            <AmtDtls><TxAmt><Amt Ccy="THB">500.00</Amt></TxAmt></AmtDtls>
+            <RmtInf><Strd><CdtrRefInf><Ref>SO-0042</Ref></CdtrRefInf></Strd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="THB">20.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2026-01-15</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+	parsed, err := parseCamt053(strings.NewReader(xml))
+	require.NoError(t, err)
+	assert.Equal(t, "0011223344", parsed.AccountNo)
+	assert.Equal(t, "STMT-20260115", parsed.StatementRef)
+	assert.Equal(t, 1, parsed.SkippedDebits)
+	require.Len(t, parsed.Lines, 2)
+	assert.Equal(t, 1000.0, parsed.Lines[0].Amount)
+	assert.Equal(t, "THB", parsed.Lines[0].Currency)
+	assert.Equal(t, "INV2601-0001", parsed.Lines[0].Reference)
+	assert.Equal(t, "TH001", parsed.Lines[0].CounterpartyAccount)
+	assert.Equal(t, "ACME", parsed.Lines[0].CounterpartyName)
+	assert.Equal(t, "B-1", parsed.Lines[0].BankRef)
+	assert.Equal(t, 500.0, parsed.Lines[1].Amount)
+	assert.Equal(t, "SO-0042", parsed.Lines[1].Reference)
+}
+
+func TestStampBankStatementLines_Hash(t *testing.T) {
+	day := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	statement := &models.BankStatement{AccountNo: "001-122"}
+	lines := []models.BankStatementLine{{BookingDate: day, Amount: 100}, {BookingDate: day, Amount: 100}}
+	again := []models.BankStatementLine{{BookingDate: day, Amount: 100}}
+	stampBankStatementLines(statement, lines, "u")
+	stampBankStatementLines(&models.BankStatement{AccountNo: "001122"}, again, "u")
+
+	assert.NotEqual(t, lines[0].LineHash, lines[1].LineHash)
+	assert.Equal(t, lines[0].LineHash, again[0].LineHash)
+	assert.Equal(t, 2, lines[1].LineNo)
+}
+
+func TestMatchBankLine(t *testing.T) {
+	date := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	invoices := []openInvoice{
+		{InvoiceCode: "INV2601-0001", CustomerCode: "C1", DocumentDate: date, TotalAmount: 1000},
+		{InvoiceCode: "INV2601-0002", CustomerCode: "C1", DocumentDate: date.AddDate(0, 0, 1), TotalAmount: 300, SaleCodes: "SO-0042"},
+		{InvoiceCode: "INV2601-0003", CustomerCode: "C2", DocumentDate: date, TotalAmount: 500},
+		{InvoiceCode: "INV2601-0004", CustomerCode: "C3", DocumentDate: date, TotalAmount: 500, Paid: 500},
+	}
+
+	t.Run("reference and amount", func(t *testing.T) {
+		proposals := matchBankLine(models.BankStatementLine{Amount: 1000, Reference: "pay inv 2601-0001"}, invoices, nil)
+		require.NotEmpty(t, proposals)
+		assert.Equal(t, "C1", proposals[0].CustomerCode)
+		assert.Equal(t, bankMatchReference+bankMatchAmount, proposals[0].Score)
+		assert.Equal(t, []PaymentAllocation{{"INV2601-0001", 1000}}, proposals[0].Allocations)
+		assert.Equal(t, models.BankLineProposed, bankLineStatus(proposals))
+	})
+
+	t.Run("sale code with overpayment", func(t *testing.T) {
+		proposals := matchBankLine(models.BankStatementLine{Amount: 350, Reference: "so0042"}, invoices, nil)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, []PaymentAllocation{{"INV2601-0002", 300}}, proposals[0].Allocations)
+		assert.InDelta(t, 50, proposals[0].UnappliedAmount, 0.001)
+	})
+
+	t.Run("amount alone is not confident", func(t *testing.T) {
+		proposals := matchBankLine(models.BankStatementLine{Amount: 500}, invoices, nil)
+		require.Len(t, proposals, 1)
+		assert.Equal(t, "C2", proposals[0].CustomerCode)
+		assert.Equal(t, models.BankLineUnmatched, bankLineStatus(proposals))
+	})
+
+	t.Run("amount and account", func(t *testing.T) {
+		proposals := matchBankLine(models.BankStatementLine{Amount: 500, CounterpartyAccount: "x"}, invoices, []string{"C2"})
+		require.Len(t, proposals, 1)
+		assert.Equal(t, bankMatchAmount+bankMatchAccount, proposals[0].Score)
+		assert.Equal(t, models.BankLineProposed, bankLineStatus(proposals))
+	})
+
+	t.Run("account alone pays oldest due", func(t *testing.T) {
+		proposals := matchBankLine(models.BankStatementLine{Amount: 1100}, invoices, []string{"C1"})
+		require.Len(t, proposals, 1)
+		assert.Equal(t, []PaymentAllocation{{"INV2601-0001", 1000}, {"INV2601-0002", 100}}, proposals[0].Allocations)
+		assert.Equal(t, models.BankLineUnmatched, bankLineStatus(proposals))
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		proposals := matchBankLine(models.BankStatementLine{Amount: 77, Reference: "interest"}, invoices, nil)
+		assert.Empty(t, proposals)
+		assert.Equal(t, models.BankLineUnmatched, bankLineStatus(proposals))
+	})
+}
@@ -1,54 +1,121 @@
-package paymentService
-
-import (
-	"encoding/json"
-	"errors"
-	models "prime-erp-core/internal/models"
-	repositorypayment "prime-erp-core/internal/repositories/payment"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-func CreatePayment(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	var req []models.Payment
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-	paymentValue := []models.Payment{}
-	paymentInvoiceValue := []models.PaymentInvoice{}
-	paymentIDForReturn := []uuid.UUID{}
-	for i, payment := range req {
-		paymentID := uuid.New()
-		req[i].ID = paymentID
-
-		paymentIDForReturn = append(paymentIDForReturn, paymentID)
-
-		if req[i].PaymentCode == "" {
-			req[i].PaymentCode = uuid.New().String()
-		}
-
-		for o := range payment.PaymentInvoice {
-			paymentItemID := uuid.New()
-			req[i].PaymentInvoice[o].ID = paymentItemID
-			req[i].PaymentInvoice[o].PaymentID = paymentID
-			paymentInvoiceValue = append(paymentInvoiceValue, req[i].PaymentInvoice[o])
-		}
-
-		req[i].PaymentInvoice = []models.PaymentInvoice{}
-		paymentValue = append(paymentValue, req[i])
-	}
-
-	errCreateApproval := repositorypayment.CreatePayment(paymentValue, paymentInvoiceValue)
-	if errCreateApproval != nil {
-		return nil, errCreateApproval
-	}
-
-	return map[string]interface{}{
-		"id":      paymentIDForReturn,
-		"status":  "success",
-		"message": "Create payment Successfully",
-	}, nil
-}
+package paymentService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	models "prime-erp-core/internal/models"
+	systemConfigService "prime-erp-core/internal/services/system-config"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const paymentRunningConfig = "RUNNING_PM"
+
+type reservedPaymentCodes struct {
+	Data     []string
+	Reserved []string // the codes drawn from RUNNING_PM, to void when the payments are not saved
+}
+
+// reservePaymentCodes draws count payment codes from RUNNING_PM. Until migrations/0015_payment_allocation.sql seeds
+// that config, payments keep the uuid codes they had before.
+func reservePaymentCodes(ctx *gin.Context, count int) (reservedPaymentCodes, error) {
+	codes, err := systemConfigService.ReserveNumbers(ctx, systemConfigService.ReserveNumbersRequest{ConfigCode: paymentRunningConfig, Count: count})
+	if errors.Is(err, systemConfigService.ErrRunningConfigNotFound) {
+		res := reservedPaymentCodes{}
+		for i := 0; i < count; i++ {
+			res.Data = append(res.Data, uuid.New().String())
+		}
+		return res, nil
+	}
+	if err != nil {
+		return reservedPaymentCodes{}, fmt.Errorf("failed to generate payment code: %v", err)
+	}
+	return reservedPaymentCodes{Data: codes.Data, Reserved: codes.Data}, nil
+}
+
+// CreatePayment saves payments and allocates each: first its payment_invoice rows, then by its allocation the rest
+// to the customer's open invoices. What is left stays on the payment as unapplied credit.
+func CreatePayment(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var req []models.Payment
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	for _, payment := range req {
+		if payment.CustomerCode == "" {
+			return nil, errors.New("customer_code is required")
+		}
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	reserved := []string{}
+	for i := range req {
+		if req[i].PaymentCode != "" {
+			continue
+		}
+		codes, err := reservePaymentCodes(ctx, 1)
+		if err != nil {
+			return nil, err
+		}
+		req[i].PaymentCode = codes.Data[0]
+		reserved = append(reserved, codes.Reserved...)
+	}
+
+	paymentIDForReturn := []uuid.UUID{}
+	user := middleware.GetUserCode(ctx)
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		for i := range req {
+			manual := make([]PaymentAllocation, 0, len(req[i].PaymentInvoice))
+			for _, paymentInvoice := range req[i].PaymentInvoice {
+				manual = append(manual, PaymentAllocation{InvoiceCode: paymentInvoice.InvoiceCode, Amount: paymentInvoice.Amount})
+			}
+
+			req[i].ID = uuid.New()
+			req[i].CreateBy = user
+			req[i].UpdateBy = user
+			payment := req[i]
+			payment.PaymentInvoice = nil
+			if err := tx.Create(&payment).Error; err != nil {
+				return fmt.Errorf("failed to create payment %s: %v", payment.PaymentCode, err)
+			}
+
+			applyDate := req[i].PaymentDate
+			if applyDate.IsZero() {
+				applyDate = time.Now()
+			}
+			allocated, unapplied, err := applyPaymentAllocation(tx, user, payment, req[i].Allocation, manual, applyDate)
+			if err != nil {
+				return err
+			}
+			req[i].PaymentInvoice = allocated
+			req[i].AllocatedAmount = req[i].Amount - unapplied
+			req[i].UnappliedAmount = unapplied
+			paymentIDForReturn = append(paymentIDForReturn, req[i].ID)
+		}
+		return nil
+	})
+	if err != nil {
+		if len(reserved) > 0 {
+			systemConfigService.VoidReservedNumbers(ctx, paymentRunningConfig, reserved, "create payment failed: "+err.Error())
+		}
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":      paymentIDForReturn,
+		"payment": req,
+		"status":  "success",
+		"message": "Create payment Successfully",
+	}, nil
+}
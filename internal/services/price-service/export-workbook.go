@@ -0,0 +1,436 @@
+package priceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	// groupItemSheet is a hidden sheet holding the PG01..PG10 item codes the dropdowns point at
+	groupItemSheet = "group_items"
+	// templateRows is how far down the PG dropdowns reach
+	templateRows = 1000
+)
+
+// pricelistSheet is one sheet of the upload workbook read by parsePricelistWorkbook.
+type pricelistSheet struct {
+	Name    string
+	Columns []string
+	PGKeys  bool // PG01..PG10 follow the columns
+}
+
+var pricelistSheets = []pricelistSheet{
+	{
+		Name: "price_list_group",
+		Columns: []string{"company_code", "site_code", "group_code", "group_name", "currency", "effective_date",
+			"price_unit", "price_weight", "before_price_unit", "before_price_weight", "remark"},
+		PGKeys: true,
+	},
+	{
+		Name:    "price_list_group_term",
+		Columns: []string{"company_code", "site_code", "group_code", "term_code", "pdc", "pdc_percent", "due", "due_percent"},
+	},
+	{
+		Name: "price_list_group_extra",
+		Columns: []string{"company_code", "site_code", "group_code", "condition_code", "operator", "value_int",
+			"length_extra_key", "cond_range_min", "cond_range_max"},
+		PGKeys: true,
+	},
+	{
+		Name: "price_list_sub_group",
+		Columns: []string{"company_code", "site_code", "group_code", "subgroup_code", "is_trading",
+			"price_unit", "extra_price_unit", "total_net_price_unit",
+			"price_weight", "extra_price_weight", "term_price_weight", "total_net_price_weight",
+			"before_price_unit", "before_extra_price_unit", "before_term_price_unit", "before_total_net_price_unit",
+			"before_price_weight", "before_extra_price_weight", "before_term_price_weight", "before_total_net_price_weight",
+			"effective_date", "remark", "udf_json"},
+		PGKeys: true,
+	},
+	{
+		Name:    "formulas_map",
+		Columns: []string{"subgroup_code", "formula_code_default", "formula_code_convert"},
+	},
+}
+
+func (s pricelistSheet) header() []string {
+	if !s.PGKeys {
+		return s.Columns
+	}
+	return append(append([]string{}, s.Columns...), pgCols...)
+}
+
+// newPricelistWorkbook lays out the upload sheets with their headers and PG01..PG10 dropdowns fed from group_item.
+func newPricelistWorkbook(gormx *gorm.DB) (*excelize.File, error) {
+	var groups []models.Group
+	if err := gormx.Where("group_code IN ?", pgCols).
+		Preload("GroupItems", func(db *gorm.DB) *gorm.DB { return db.Order("item_code") }).
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to get group items: %w", err)
+	}
+	itemsByCode := map[string][]string{}
+	for _, g := range groups {
+		for _, item := range g.GroupItems {
+			itemsByCode[g.GroupCode] = append(itemsByCode[g.GroupCode], item.ItemCode)
+		}
+	}
+
+	return layoutPricelistWorkbook(itemsByCode)
+}
+
+// layoutPricelistWorkbook builds the empty workbook; itemsByCode holds the dropdown values per PG code.
+func layoutPricelistWorkbook(itemsByCode map[string][]string) (*excelize.File, error) {
+	f := excelize.NewFile()
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, sheet := range pricelistSheets {
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet.Name); err != nil {
+				return nil, err
+			}
+		} else if _, err := f.NewSheet(sheet.Name); err != nil {
+			return nil, err
+		}
+
+		header := sheet.header()
+		if err := writeWorkbookRow(f, sheet.Name, 1, toInterfaces(header)); err != nil {
+			return nil, err
+		}
+		if err := f.SetRowStyle(sheet.Name, 1, 1, headerStyle); err != nil {
+			return nil, err
+		}
+		if err := f.SetPanes(sheet.Name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return nil, err
+		}
+	}
+
+	// One list column per PG code on the hidden sheet, each data validation points at its column
+	if _, err := f.NewSheet(groupItemSheet); err != nil {
+		return nil, err
+	}
+	listRanges := map[string]string{}
+	for i, code := range pgCols {
+		items := itemsByCode[code]
+		col, _ := excelize.ColumnNumberToName(i + 1)
+		if err := f.SetCellStr(groupItemSheet, col+"1", code); err != nil {
+			return nil, err
+		}
+		for j, item := range items {
+			if err := f.SetCellStr(groupItemSheet, fmt.Sprintf("%s%d", col, j+2), item); err != nil {
+				return nil, err
+			}
+		}
+		if len(items) > 0 {
+			listRanges[code] = fmt.Sprintf("%s!$%s$2:$%s$%d", groupItemSheet, col, col, len(items)+1)
+		}
+	}
+	if err := f.SetSheetVisible(groupItemSheet, false); err != nil {
+		return nil, err
+	}
+
+	for _, sheet := range pricelistSheets {
+		if !sheet.PGKeys {
+			continue
+		}
+		for i, code := range pgCols {
+			listRange, exists := listRanges[code]
+			if !exists {
+				continue
+			}
+			col, _ := excelize.ColumnNumberToName(len(sheet.Columns) + i + 1)
+			dv := excelize.NewDataValidation(true)
+			dv.SetSqref(fmt.Sprintf("%s2:%s%d", col, col, templateRows))
+			dv.SetSqrefDropList(listRange)
+			dv.SetError(excelize.DataValidationErrorStyleStop, code, "เลือกค่าจากรายการ "+code)
+			if err := f.AddDataValidation(sheet.Name, dv); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return f, nil
+}
+
+func toInterfaces(values []string) []interface{} {
+	out := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+	}
+	return out
+}
+
+func writeWorkbookRow(f *excelize.File, sheet string, rowNum int, values []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, rowNum)
+	if err != nil {
+		return err
+	}
+	return f.SetSheetRow(sheet, cell, &values)
+}
+
+// writeSheetRows writes rows keyed by header name below the header of sheet.
+func writeSheetRows(f *excelize.File, sheet pricelistSheet, rows []map[string]interface{}) error {
+	header := sheet.header()
+	for i, row := range rows {
+		values := make([]interface{}, len(header))
+		for c, h := range header {
+			if v, exists := row[h]; exists {
+				values[c] = v
+			}
+		}
+		if err := writeWorkbookRow(f, sheet.Name, i+2, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func workbookFile(f *excelize.File, name string) (*utils.FileResponse, error) {
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return &utils.FileResponse{FileName: name, ContentType: xlsxContentType, Data: buf.Bytes()}, nil
+}
+
+func formatWorkbookDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// DownloadTemplate returns an empty upload workbook with one example row per sheet.
+func DownloadTemplate(ctx *gin.Context, jsonPayload string) (*utils.FileResponse, error) {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := newPricelistWorkbook(gormx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	// Keyed rows need at least one PG value; take the first PG01 item so the example passes validation
+	pg01, _ := f.GetCellValue(groupItemSheet, "A2")
+	if pg01 == "" {
+		pg01 = "ITEM01"
+	}
+
+	group := map[string]interface{}{"company_code": "COMPANY", "site_code": "SITE", "group_code": "GROUP01"}
+	example := map[string][]map[string]interface{}{
+		"price_list_group": {mergeRow(group, map[string]interface{}{
+			"group_name": "Example group", "currency": "THB", "effective_date": time.Now().Format("2006-01-02"),
+			"price_unit": 100, "price_weight": 25,
+		})},
+		"price_list_group_term": {mergeRow(group, map[string]interface{}{
+			"term_code": "CASH", "pdc": 0, "pdc_percent": 0, "due": 30, "due_percent": 0,
+		})},
+		"price_list_group_extra": {mergeRow(group, map[string]interface{}{
+			"condition_code": "LENGTH", "operator": "BETWEEN", "value_int": 50, "length_extra_key": 1,
+			"cond_range_min": 0, "cond_range_max": 6, "PG01": pg01,
+		})},
+		"price_list_sub_group": {mergeRow(group, map[string]interface{}{
+			"subgroup_code": "GROUP01-SG01", "is_trading": "false", "price_unit": 100, "price_weight": 25,
+			"effective_date": time.Now().Format("2006-01-02"), "PG01": pg01,
+		})},
+		"formulas_map": {{
+			"subgroup_code": "GROUP01-SG01", "formula_code_default": "FORMULA_DEFAULT", "formula_code_convert": "FORMULA_CONVERT",
+		}},
+	}
+	for _, sheet := range pricelistSheets {
+		if err := writeSheetRows(f, sheet, example[sheet.Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return workbookFile(f, "price_list_template.xlsx")
+}
+
+func mergeRow(base, extra map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
+
+type ExportWorkbookRequest struct {
+	CompanyCode string   `json:"company_code"`
+	SiteCodes   []string `json:"site_codes"`
+	GroupCodes  []string `json:"group_codes"`
+}
+
+type priceListGroupKey struct {
+	PriceListGroupID uuid.UUID `gorm:"column:price_list_group_id"`
+	Seq              int       `gorm:"column:seq"`
+	Code             string    `gorm:"column:code"`
+	Value            string    `gorm:"column:value"`
+}
+
+// ExportWorkbook exports the price lists of the given groups in the upload layout, so the file can be edited and
+// uploaded back as is.
+func ExportWorkbook(ctx *gin.Context, jsonPayload string) (*utils.FileResponse, error) {
+	var req ExportWorkbookRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.GroupCodes) == 0 {
+		return nil, errors.New("group_codes is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Where("group_code IN ?", req.GroupCodes)
+	if req.CompanyCode != "" {
+		query = query.Where("company_code = ?", req.CompanyCode)
+	}
+	if len(req.SiteCodes) > 0 {
+		query = query.Where("site_code IN ?", req.SiteCodes)
+	}
+	var groups []models.PriceListGroup
+	if err := query.
+		Preload("PriceListGroupTerms").
+		Preload("PriceListGroupExtras.PriceListGroupExtraKeys").
+		Preload("PriceListSubGroups.PriceListSubGroupKeys").
+		Order("company_code, site_code, group_code").
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price lists: %w", err)
+	}
+
+	groupIDs := []uuid.UUID{}
+	subGroupCodes := []string{}
+	for _, g := range groups {
+		groupIDs = append(groupIDs, g.ID)
+		for _, sg := range g.PriceListSubGroups {
+			subGroupCodes = append(subGroupCodes, sg.SubGroupCode)
+		}
+	}
+
+	groupKeys := map[uuid.UUID][]priceListGroupKey{}
+	if len(groupIDs) > 0 {
+		var keys []priceListGroupKey
+		if err := gormx.Table("price_list_group_key").Where("price_list_group_id IN ?", groupIDs).Find(&keys).Error; err != nil {
+			return nil, fmt.Errorf("failed to get price list group keys: %w", err)
+		}
+		for _, k := range keys {
+			groupKeys[k.PriceListGroupID] = append(groupKeys[k.PriceListGroupID], k)
+		}
+	}
+
+	formulas := map[string]map[string]interface{}{}
+	if len(subGroupCodes) > 0 {
+		var maps []models.PriceListSubGroupFormulasMap
+		if err := gormx.Where("price_list_subgroup_code IN ?", subGroupCodes).Find(&maps).Error; err != nil {
+			return nil, fmt.Errorf("failed to get formulas map: %w", err)
+		}
+		for _, m := range maps {
+			row, exists := formulas[m.PriceListSubGroupCode]
+			if !exists {
+				row = map[string]interface{}{"subgroup_code": m.PriceListSubGroupCode}
+				formulas[m.PriceListSubGroupCode] = row
+			}
+			if m.IsDefault {
+				row["formula_code_default"] = m.PriceListFormulasCode
+			} else {
+				row["formula_code_convert"] = m.PriceListFormulasCode
+			}
+		}
+	}
+
+	rows := map[string][]map[string]interface{}{}
+	for _, g := range groups {
+		group := map[string]interface{}{"company_code": g.CompanyCode, "site_code": g.SiteCode, "group_code": g.GroupCode}
+
+		groupRow := mergeRow(group, map[string]interface{}{
+			"group_name": g.GroupName, "currency": g.Currency, "effective_date": formatWorkbookDate(g.EffectiveDate),
+			"price_unit": g.PriceUnit, "price_weight": g.PriceWeight,
+			"before_price_unit": g.BeforePriceUnit, "before_price_weight": g.BeforePriceWeight, "remark": g.Remark,
+		})
+		for _, k := range groupKeys[g.ID] {
+			groupRow[k.Code] = k.Value
+		}
+		rows["price_list_group"] = append(rows["price_list_group"], groupRow)
+
+		for _, t := range g.PriceListGroupTerms {
+			rows["price_list_group_term"] = append(rows["price_list_group_term"], mergeRow(group, map[string]interface{}{
+				"term_code": t.TermCode, "pdc": t.Pdc, "pdc_percent": t.PdcPercent, "due": t.Due, "due_percent": t.DuePercent,
+			}))
+		}
+
+		for _, e := range g.PriceListGroupExtras {
+			extraRow := mergeRow(group, map[string]interface{}{
+				"condition_code": e.ConditionCode, "operator": e.Operator, "value_int": e.ValueInt,
+				"length_extra_key": e.LengthExtraKey, "cond_range_min": e.CondRangeMin, "cond_range_max": e.CondRangeMax,
+			})
+			for _, k := range e.PriceListGroupExtraKeys {
+				extraRow[k.Code] = k.Value
+			}
+			rows["price_list_group_extra"] = append(rows["price_list_group_extra"], extraRow)
+		}
+
+		sort.Slice(g.PriceListSubGroups, func(i, j int) bool {
+			return g.PriceListSubGroups[i].SubGroupCode < g.PriceListSubGroups[j].SubGroupCode
+		})
+		for _, sg := range g.PriceListSubGroups {
+			subRow := mergeRow(group, map[string]interface{}{
+				"subgroup_code": sg.SubGroupCode, "is_trading": fmt.Sprintf("%t", sg.IsTrading),
+				"price_unit": sg.PriceUnit, "extra_price_unit": sg.ExtraPriceUnit, "total_net_price_unit": sg.TotalNetPriceUnit,
+				"price_weight": sg.PriceWeight, "extra_price_weight": sg.ExtraPriceWeight,
+				"term_price_weight": sg.TermPriceWeight, "total_net_price_weight": sg.TotalNetPriceWeight,
+				"before_price_unit": sg.BeforePriceUnit, "before_extra_price_unit": sg.BeforeExtraPriceUnit,
+				"before_term_price_unit": sg.BeforeTermPriceUnit, "before_total_net_price_unit": sg.BeforeTotalNetPriceUnit,
+				"before_price_weight": sg.BeforePriceWeight, "before_extra_price_weight": sg.BeforeExtraPriceWeight,
+				"before_term_price_weight": sg.BeforeTermPriceWeight, "before_total_net_price_weight": sg.BeforeTotalNetPriceWeight,
+				"effective_date": formatWorkbookDate(sg.EffectiveDate), "remark": sg.Remark,
+			})
+			if len(sg.UdfJson) > 0 && string(sg.UdfJson) != "null" {
+				subRow["udf_json"] = string(sg.UdfJson)
+			}
+			for _, k := range sg.PriceListSubGroupKeys {
+				subRow[k.Code] = k.Value
+			}
+			rows["price_list_sub_group"] = append(rows["price_list_sub_group"], subRow)
+
+			if row, exists := formulas[sg.SubGroupCode]; exists {
+				rows["formulas_map"] = append(rows["formulas_map"], row)
+			}
+		}
+	}
+
+	f, err := newPricelistWorkbook(gormx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	for _, sheet := range pricelistSheets {
+		if err := writeSheetRows(f, sheet, rows[sheet.Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return workbookFile(f, fmt.Sprintf("price_list_%s.xlsx", time.Now().Format("20060102")))
+}
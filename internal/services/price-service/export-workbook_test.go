@@ -0,0 +1,57 @@
+package priceService
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricelistWorkbook_RoundTrip(t *testing.T) {
+	f, err := layoutPricelistWorkbook(map[string][]string{"PG01": {"STEEL", "WIRE"}})
+	assert.NoError(t, err)
+	defer f.Close()
+
+	group := map[string]interface{}{"company_code": "C1", "site_code": "S1", "group_code": "G1"}
+	rows := map[string][]map[string]interface{}{
+		"price_list_group": {mergeRow(group, map[string]interface{}{
+			"group_name": "Group 1", "currency": "THB", "effective_date": "2026-01-01", "price_unit": 1250.5, "PG01": "STEEL",
+		})},
+		"price_list_group_term": {mergeRow(group, map[string]interface{}{
+			"term_code": "CR30", "due": 30.0, "due_percent": 2.0,
+		})},
+		"price_list_sub_group": {mergeRow(group, map[string]interface{}{
+			"subgroup_code": "G1-WIRE", "is_trading": "true", "price_unit": 99.75, "udf_json": `{"is_highlight":true}`, "PG01": "WIRE",
+		})},
+		"formulas_map": {{"subgroup_code": "G1-WIRE", "formula_code_default": "F1", "formula_code_convert": "F2"}},
+	}
+	for _, sheet := range pricelistSheets {
+		assert.NoError(t, writeSheetRows(f, sheet, rows[sheet.Name]))
+	}
+	buf, err := f.WriteToBuffer()
+	assert.NoError(t, err)
+
+	req, report, err := parsePricelistWorkbook(buf)
+	assert.NoError(t, err)
+	assert.NoError(t, report.err())
+
+	if assert.Len(t, req.Groups, 1) {
+		assert.Equal(t, 1250.5, req.Groups[0].PriceUnit)
+		assert.Equal(t, "2026-01-01", formatWorkbookDate(req.Groups[0].EffectiveDate))
+	}
+	if assert.Len(t, req.Terms, 1) {
+		assert.Equal(t, 2, req.Terms[0].DuePercent)
+	}
+	if assert.Len(t, req.SubGroups, 1) {
+		assert.Equal(t, "WIRE", req.SubGroups[0].SubGroupKey)
+		assert.True(t, req.SubGroups[0].IsTrading)
+		assert.Equal(t, 99.75, req.SubGroups[0].PriceUnit)
+		assert.JSONEq(t, `{"is_highlight":true}`, string(req.SubGroups[0].UdfJson))
+	}
+	assert.Len(t, req.SubGroupFormulas, 2)
+
+	dvs, err := f.GetDataValidations("price_list_sub_group")
+	assert.NoError(t, err)
+	if assert.Len(t, dvs, 1, "a dropdown only for PG codes that have items") {
+		assert.Equal(t, "group_items!$A$2:$A$3", dvs[0].Formula1)
+	}
+}
@@ -0,0 +1,286 @@
+package priceService
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/models"
+	priceListRepository "prime-erp-core/internal/repositories/priceList"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// seam for unit testing: allow stubbing the group item lookup
+var getGroupItemValueIntsFunc = priceListRepository.GetGroupItemValueInts
+
+// groupItemValues is group_item.value_int by group code and item code.
+type groupItemValues map[string]map[string]float64
+
+func (v groupItemValues) lookup(groupCode, itemCode string) (float64, bool) {
+	value, ok := v[groupCode][itemCode]
+	return value, ok
+}
+
+// extraConditionEnv is what an extra's condition_expr can read, e.g. `key.PG01 in ["A1", "A2"] && cond >= min`.
+type extraConditionEnv struct {
+	Key      map[string]string  `expr:"key"`       // sub group key code -> value
+	ValueInt map[string]float64 `expr:"value_int"` // sub group key code -> group_item.value_int of its value
+	Cond     float64            `expr:"cond"`      // value_int of the condition_code key, 0 when it has none
+	HasCond  bool               `expr:"has_cond"`
+	Min      float64            `expr:"min"` // cond_range_min
+	Max      float64            `expr:"max"` // cond_range_max
+}
+
+func compileExtraCondition(source string) (*vm.Program, error) {
+	return expr.Compile(source, expr.Env(extraConditionEnv{}), expr.AsBool())
+}
+
+func isExtraStacking(stacking string) bool {
+	switch stacking {
+	case models.ExtraStackingLast, models.ExtraStackingSum, models.ExtraStackingMax, models.ExtraStackingPriority:
+		return true
+	}
+	return false
+}
+
+// loadExtraItemValues fetches, in one query, the group items every extra of the sub groups may look at.
+func loadExtraItemValues(subGroups []models.PriceListSubGroup) (groupItemValues, error) {
+	codes := map[string]bool{}
+	for _, subGroup := range subGroups {
+		if len(subGroup.PriceListGroup.PriceListGroupExtras) == 0 {
+			continue
+		}
+		for _, k := range subGroup.PriceListSubGroupKeys {
+			codes[k.Code] = true
+		}
+		for _, e := range subGroup.PriceListGroup.PriceListGroupExtras {
+			if e.ConditionCode != "" {
+				codes[e.ConditionCode] = true
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return groupItemValues{}, nil
+	}
+
+	values, err := getGroupItemValueIntsFunc(sortedKeys(codes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group item values: %w", err)
+	}
+	return groupItemValues(values), nil
+}
+
+// wildcardMatch matches value against a pattern where * stands for any run of characters and ? for exactly one.
+func wildcardMatch(pattern, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// extraKeysMatch checks the extra's keys against the sub group's; a key value may be a wildcard pattern.
+func extraKeysMatch(extraKeys []models.PriceListGroupExtraKey, keys map[string]string) (bool, string) {
+	for _, ek := range extraKeys {
+		v, ok := keys[ek.Code]
+		if !ok {
+			return false, fmt.Sprintf("sub group has no key %s", ek.Code)
+		}
+		if v == ek.Value {
+			continue
+		}
+		if strings.ContainsAny(ek.Value, "*?") && wildcardMatch(ek.Value, v) {
+			continue
+		}
+		return false, fmt.Sprintf("key %s is %s, extra wants %s", ek.Code, v, ek.Value)
+	}
+	return true, ""
+}
+
+// extraRuleEngine evaluates the extras of sub groups, compiling each distinct condition once.
+type extraRuleEngine struct {
+	itemValues groupItemValues
+	now        time.Time
+	programs   map[string]*vm.Program
+}
+
+func newExtraRuleEngine(itemValues groupItemValues, now time.Time) *extraRuleEngine {
+	return &extraRuleEngine{itemValues: itemValues, now: now, programs: map[string]*vm.Program{}}
+}
+
+// condition reports whether the extra's condition holds for the sub group, and why when it does not.
+func (r *extraRuleEngine) condition(e models.PriceListGroupExtra, keys map[string]string) (bool, string, error) {
+	var cond float64
+	hasCond := false
+	condReason := ""
+	if e.ConditionCode != "" {
+		if condValue, ok := keys[e.ConditionCode]; !ok {
+			condReason = fmt.Sprintf("sub group has no condition key %s", e.ConditionCode)
+		} else if cond, hasCond = r.itemValues.lookup(e.ConditionCode, condValue); !hasCond {
+			condReason = fmt.Sprintf("no group item %s in group %s", condValue, e.ConditionCode)
+		}
+	}
+
+	if e.ConditionExpr == "" {
+		// Without an expression an extra needs a condition_code and its operator, as before the rule engine
+		if e.ConditionCode == "" {
+			return false, "no condition_code or condition_expr", nil
+		}
+		if !hasCond {
+			return false, condReason, nil
+		}
+		if !extraConditionMatched(cond, e.Operator, e.CondRangeMin, e.CondRangeMax) {
+			return false, fmt.Sprintf("%v %s [%v, %v] is false", cond, e.Operator, e.CondRangeMin, e.CondRangeMax), nil
+		}
+		return true, "", nil
+	}
+
+	program, ok := r.programs[e.ConditionExpr]
+	if !ok {
+		var err error
+		if program, err = compileExtraCondition(e.ConditionExpr); err != nil {
+			return false, "", fmt.Errorf("extra %s: invalid condition_expr: %w", e.ExtraKey, err)
+		}
+		r.programs[e.ConditionExpr] = program
+	}
+
+	valueInts := make(map[string]float64, len(keys))
+	for code, value := range keys {
+		if v, found := r.itemValues.lookup(code, value); found {
+			valueInts[code] = v
+		}
+	}
+	out, err := expr.Run(program, extraConditionEnv{
+		Key:      keys,
+		ValueInt: valueInts,
+		Cond:     cond,
+		HasCond:  hasCond,
+		Min:      e.CondRangeMin,
+		Max:      e.CondRangeMax,
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("extra %s: condition_expr failed: %w", e.ExtraKey, err)
+	}
+	if fired, _ := out.(bool); !fired {
+		return false, "condition_expr is false", nil
+	}
+	return true, "", nil
+}
+
+// calculateExtraForSubGroup determines the Extra value (for weight and unit) of a sub group from its group's extras.
+// Every extra whose keys, condition and dates match fires; the group's extra_stacking decides how the fired
+// value_int make up the extra. When none fires the sub group keeps its current extra.
+func (r *extraRuleEngine) calculateExtraForSubGroup(subGroup *models.PriceListSubGroup) (float64, float64, models.ExtraExplanation, error) {
+	stacking := strings.ToUpper(subGroup.PriceListGroup.ExtraStacking)
+	if !isExtraStacking(stacking) {
+		stacking = models.ExtraStackingLast
+	}
+	explanation := models.ExtraExplanation{Stacking: stacking, Rules: []models.ExtraRuleResult{}}
+
+	// Build a quick lookup map from subgroup keys: code -> value
+	keys := make(map[string]string, len(subGroup.PriceListSubGroupKeys))
+	for _, k := range subGroup.PriceListSubGroupKeys {
+		keys[k.Code] = k.Value
+	}
+
+	fired := []int{}
+	for _, e := range subGroup.PriceListGroup.PriceListGroupExtras {
+		result := models.ExtraRuleResult{ExtraID: e.ID, ExtraKey: e.ExtraKey, Priority: e.Priority, ValueInt: e.ValueInt}
+
+		switch matched, reason := extraKeysMatch(e.PriceListGroupExtraKeys, keys); {
+		case e.EffectiveDate != nil && r.now.Before(*e.EffectiveDate):
+			result.Reason = "effective from " + e.EffectiveDate.Format(time.RFC3339)
+		case e.ExpiryDate != nil && !r.now.Before(*e.ExpiryDate):
+			result.Reason = "expired at " + e.ExpiryDate.Format(time.RFC3339)
+		case !matched:
+			result.Reason = reason
+		default:
+			ok, why, err := r.condition(e, keys)
+			if err != nil {
+				return 0, 0, explanation, err
+			}
+			result.Fired = ok
+			result.Reason = why
+		}
+
+		if result.Fired {
+			fired = append(fired, len(explanation.Rules))
+		}
+		explanation.Rules = append(explanation.Rules, result)
+	}
+
+	if len(fired) == 0 {
+		return subGroup.ExtraPriceWeight, subGroup.ExtraPriceUnit, explanation, nil
+	}
+	explanation.Matched = true
+
+	applied := fired[len(fired)-1]
+	switch stacking {
+	case models.ExtraStackingSum:
+		total := 0.0
+		for _, i := range fired {
+			explanation.Rules[i].Applied = true
+			total += explanation.Rules[i].ValueInt
+		}
+		return total, total, explanation, nil
+	case models.ExtraStackingMax:
+		applied = fired[0]
+		for _, i := range fired[1:] {
+			if explanation.Rules[i].ValueInt > explanation.Rules[applied].ValueInt {
+				applied = i
+			}
+		}
+	case models.ExtraStackingPriority:
+		// Ties go to the later extra, as under LAST
+		for _, i := range fired {
+			if explanation.Rules[i].Priority >= explanation.Rules[applied].Priority {
+				applied = i
+			}
+		}
+	}
+
+	explanation.Rules[applied].Applied = true
+	value := explanation.Rules[applied].ValueInt
+	return value, value, explanation, nil
+}
+
+// extraConditionMatched evaluates the operator and cond_range_min/max against the
+// group_item.value_int.
+func extraConditionMatched(val float64, operator string, min, max float64) bool {
+	switch operator {
+	case "=":
+		// Example from requirement: value_int must match cond_range_max
+		return val == max
+	case ">=":
+		return val >= min
+	case "<=":
+		return val <= max
+	case "<":
+		return val < max
+	case ">":
+		return val > min
+	case "<>":
+		return val >= min && val <= max
+	default:
+		return false
+	}
+}
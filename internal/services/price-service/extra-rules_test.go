@@ -0,0 +1,141 @@
+package priceService
+
+import (
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func extraSubGroup(stacking string, extras ...models.PriceListGroupExtra) *models.PriceListSubGroup {
+	return &models.PriceListSubGroup{
+		ExtraPriceWeight: 7,
+		ExtraPriceUnit:   7,
+		PriceListGroup:   models.PriceListGroup{ExtraStacking: stacking, PriceListGroupExtras: extras},
+		PriceListSubGroupKeys: []models.PriceListSubGroupKey{
+			{Code: "PG01", Value: "SD-295"},
+			{Code: "PG02", Value: "12MM"},
+		},
+	}
+}
+
+func extraRule(key string, value float64, extraKeys ...models.PriceListGroupExtraKey) models.PriceListGroupExtra {
+	return models.PriceListGroupExtra{
+		ID:                      uuid.New(),
+		ExtraKey:                key,
+		ValueInt:                value,
+		ConditionCode:           "PG02",
+		Operator:                ">=",
+		CondRangeMin:            10,
+		PriceListGroupExtraKeys: extraKeys,
+	}
+}
+
+var extraItemValues = groupItemValues{"PG02": {"12MM": 12, "9MM": 9}}
+
+func TestExtraRules_LegacyLastMatchWins(t *testing.T) {
+	engine := newExtraRuleEngine(extraItemValues, time.Now())
+	other := extraRule("E3", 300, models.PriceListGroupExtraKey{Code: "PG01", Value: "SD-390"})
+	subGroup := extraSubGroup("", extraRule("E1", 100), extraRule("E2", 200), other)
+
+	weight, unit, explanation, err := engine.calculateExtraForSubGroup(subGroup)
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, weight)
+	assert.Equal(t, 200.0, unit)
+	assert.Equal(t, models.ExtraStackingLast, explanation.Stacking)
+	assert.False(t, explanation.Rules[0].Applied)
+	assert.True(t, explanation.Rules[1].Applied)
+	assert.False(t, explanation.Rules[2].Fired)
+	assert.Equal(t, "key PG01 is SD-295, extra wants SD-390", explanation.Rules[2].Reason)
+
+	// Nothing fires: the current extra is kept
+	weight, _, explanation, err = engine.calculateExtraForSubGroup(extraSubGroup("", other))
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, weight)
+	assert.False(t, explanation.Matched)
+}
+
+func TestExtraRules_Stacking(t *testing.T) {
+	low := extraRule("E1", 100)
+	low.Priority = 1
+	high := extraRule("E2", 50)
+	high.Priority = 5
+	last := extraRule("E3", 80)
+	last.Priority = 1
+
+	cases := map[string]float64{
+		models.ExtraStackingLast:     80,
+		models.ExtraStackingSum:      230,
+		models.ExtraStackingMax:      100,
+		models.ExtraStackingPriority: 50,
+	}
+	for stacking, want := range cases {
+		weight, _, _, err := newExtraRuleEngine(extraItemValues, time.Now()).
+			calculateExtraForSubGroup(extraSubGroup(stacking, low, high, last))
+		require.NoError(t, err)
+		assert.Equal(t, want, weight, stacking)
+	}
+}
+
+func TestExtraRules_WildcardsExpressionsAndDates(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	later := now.AddDate(0, 1, 0)
+
+	wildcard := extraRule("WILD", 10, models.PriceListGroupExtraKey{Code: "PG01", Value: "SD-*"})
+	expression := extraRule("EXPR", 20)
+	expression.ConditionExpr = `key.PG01 startsWith "SD" && value_int.PG02 > 10 && cond == 12`
+	future := extraRule("FUTURE", 30)
+	future.EffectiveDate = &later
+	expired := extraRule("EXPIRED", 40)
+	expired.ExpiryDate = &now
+
+	engine := newExtraRuleEngine(extraItemValues, now)
+	weight, _, explanation, err := engine.calculateExtraForSubGroup(extraSubGroup(models.ExtraStackingSum, wildcard, expression, future, expired))
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, weight)
+	assert.True(t, explanation.Rules[0].Fired)
+	assert.True(t, explanation.Rules[1].Fired)
+	assert.Contains(t, explanation.Rules[2].Reason, "effective from")
+	assert.Contains(t, explanation.Rules[3].Reason, "expired at")
+
+	broken := extraRule("BROKEN", 1)
+	broken.ConditionExpr = "key.PG01 +"
+	_, _, _, err = engine.calculateExtraForSubGroup(extraSubGroup("", broken))
+	assert.ErrorContains(t, err, "BROKEN")
+}
+
+func TestWildcardMatch(t *testing.T) {
+	assert.True(t, wildcardMatch("SD-*", "SD-295"))
+	assert.True(t, wildcardMatch("*MM", "12MM"))
+	assert.True(t, wildcardMatch("1?MM", "12MM"))
+	assert.True(t, wildcardMatch("*", ""))
+	assert.False(t, wildcardMatch("SD-?", "SD-295"))
+	assert.False(t, wildcardMatch("*CM", "12MM"))
+}
+
+func TestLoadExtraItemValues_OneBatch(t *testing.T) {
+	calls := 0
+	var requested []string
+	original := getGroupItemValueIntsFunc
+	getGroupItemValueIntsFunc = func(codes []string) (map[string]map[string]float64, error) {
+		calls++
+		requested = codes
+		return extraItemValues, nil
+	}
+	defer func() { getGroupItemValueIntsFunc = original }()
+
+	// No extras, no lookup
+	_, err := loadExtraItemValues([]models.PriceListSubGroup{*extraSubGroup("")})
+	require.NoError(t, err)
+	assert.Equal(t, 0, calls)
+
+	values, err := loadExtraItemValues([]models.PriceListSubGroup{*extraSubGroup("", extraRule("E1", 1)), *extraSubGroup("", extraRule("E2", 2))})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"PG01", "PG02"}, requested)
+	assert.Equal(t, 12.0, values["PG02"]["12MM"])
+}
@@ -0,0 +1,458 @@
+package priceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+	priceDomain "prime-erp-core/internal/services/price-service/domain"
+	"prime-erp-core/internal/utils"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/file"
+	"github.com/expr-lang/expr/vm"
+	"github.com/gin-gonic/gin"
+)
+
+// formulaVariables are the price data variables every formula can use, in the order CalculatePrice sets them.
+var formulaVariables = []string{"base_price", "extra", "avg_kg_stock", "weight_spec", "pcs", "kg"}
+
+// sampleFormulaData is what Validate runs stored formulas with: neutral factors and a round base price.
+var sampleFormulaData = priceDomain.PriceData{BasePrice: 100, Extra: 0, AvgKgStock: 1, WeightSpec: 1, Pcs: 1, Kg: 1}
+
+// Formula issue kinds.
+const (
+	FormulaIssueParams  = "params"
+	FormulaIssueCompile = "compile"
+	FormulaIssueType    = "type"
+	FormulaIssueRun     = "run"
+)
+
+type FormulaIssue struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+type FormulaAstSummary struct {
+	ResultType string   `json:"result_type"`
+	NodeCount  int      `json:"node_count"`
+	Depth      int      `json:"depth"`
+	Variables  []string `json:"variables"`
+	Functions  []string `json:"functions"`
+	Operators  []string `json:"operators"`
+	Normalized string   `json:"normalized"` // the expression as the compiler reads it, fully parenthesized
+}
+
+type FormulaVariable struct {
+	Name   string      `json:"name"`
+	Source string      `json:"source"` // price_data or params
+	Value  interface{} `json:"value"`
+	Type   string      `json:"type"`
+	Used   bool        `json:"used"`
+}
+
+type FormulaStep struct {
+	Expression string      `json:"expression"`
+	Value      interface{} `json:"value"`
+	Type       string      `json:"type"`
+	Error      string      `json:"error,omitempty"`
+}
+
+type FormulaTrace struct {
+	Expression string             `json:"expression"`
+	Valid      bool               `json:"valid"`
+	Ast        *FormulaAstSummary `json:"ast"`
+	Variables  []FormulaVariable  `json:"variables"`
+	Steps      []FormulaStep      `json:"steps"`
+	RawResult  interface{}        `json:"raw_result"`
+	Rounding   int                `json:"rounding"`
+	Result     *float64           `json:"result"`  // before rounding
+	Rounded    *float64           `json:"rounded"` // what CalculatePrice returns
+	Errors     []FormulaIssue     `json:"errors"`
+	Warnings   []FormulaIssue     `json:"warnings"`
+}
+
+// traceFormula evaluates formula the way CalculatePrice does, keeping every step instead of only the result.
+func traceFormula(formula priceDomain.PriceFormula, priceData priceDomain.PriceData) FormulaTrace {
+	trace := FormulaTrace{
+		Expression: formula.Expression,
+		Rounding:   formula.Rounding,
+		Variables:  []FormulaVariable{},
+		Steps:      []FormulaStep{},
+		Errors:     []FormulaIssue{},
+		Warnings:   []FormulaIssue{},
+	}
+
+	env, err := formulaEnv(formula, priceData)
+	if err != nil {
+		trace.Errors = append(trace.Errors, FormulaIssue{Kind: FormulaIssueParams, Message: "invalid params: " + err.Error()})
+		return trace
+	}
+
+	builtIn := map[string]bool{}
+	for _, name := range formulaVariables {
+		builtIn[name] = true
+	}
+	params := map[string]interface{}{}
+	_ = json.Unmarshal(formula.Params, &params)
+	for name := range params {
+		if builtIn[name] {
+			trace.Warnings = append(trace.Warnings, FormulaIssue{
+				Kind:    FormulaIssueParams,
+				Message: fmt.Sprintf("param %s overrides the price data variable of the same name", name),
+			})
+		}
+	}
+
+	// Constant folding would hide the steps, so the trace compiles without it
+	program, err := expr.Compile(formula.Expression, expr.Env(env), expr.Optimize(false))
+	if err != nil {
+		trace.Errors = append(trace.Errors, compileIssue(err))
+		trace.Variables = formulaVariablesOf(env, params, nil)
+		return trace
+	}
+
+	summary, used := summarizeFormula(program)
+	trace.Ast = &summary
+	trace.Variables = formulaVariablesOf(env, params, used)
+
+	switch kind := program.Node().Type(); {
+	case kind == nil || kind.Kind() == reflect.Interface:
+		trace.Warnings = append(trace.Warnings, FormulaIssue{Kind: FormulaIssueType, Message: "result type is only known at run time"})
+	case kind.Kind() != reflect.Float64:
+		trace.Errors = append(trace.Errors, FormulaIssue{
+			Kind:    FormulaIssueType,
+			Message: fmt.Sprintf("expression returns %s, a price formula must return float64", kind),
+		})
+	}
+
+	trace.Steps = formulaSteps(program, env)
+
+	result, err := expr.Run(program, env)
+	if err != nil {
+		trace.Errors = append(trace.Errors, FormulaIssue{Kind: FormulaIssueRun, Message: err.Error()})
+		return trace
+	}
+	trace.RawResult = result
+
+	if num, ok := result.(float64); ok {
+		factor := math.Pow(10, float64(formula.Rounding))
+		rounded := math.Round(num*factor) / factor
+		trace.Result = &num
+		trace.Rounded = &rounded
+		if math.IsNaN(num) || math.IsInf(num, 0) {
+			trace.Warnings = append(trace.Warnings, FormulaIssue{Kind: FormulaIssueRun, Message: fmt.Sprintf("result is %v", num)})
+		}
+	} else if len(trace.Errors) == 0 {
+		trace.Errors = append(trace.Errors, FormulaIssue{
+			Kind:    FormulaIssueType,
+			Message: fmt.Sprintf("expression returned %T, a price formula must return float64", result),
+		})
+	}
+
+	trace.Valid = len(trace.Errors) == 0
+	return trace
+}
+
+func compileIssue(err error) FormulaIssue {
+	var fileErr *file.Error
+	if errors.As(err, &fileErr) {
+		return FormulaIssue{Kind: FormulaIssueCompile, Message: fileErr.Message, Line: fileErr.Line, Column: fileErr.Column}
+	}
+	return FormulaIssue{Kind: FormulaIssueCompile, Message: err.Error()}
+}
+
+type formulaSummaryVisitor struct {
+	nodes     int
+	variables map[string]bool
+	functions map[string]bool
+	operators map[string]bool
+}
+
+func (v *formulaSummaryVisitor) Visit(node *ast.Node) {
+	v.nodes++
+	switch n := (*node).(type) {
+	case *ast.IdentifierNode:
+		v.variables[n.Value] = true
+	case *ast.CallNode:
+		if callee, ok := n.Callee.(*ast.IdentifierNode); ok {
+			v.functions[callee.Value] = true
+			delete(v.variables, callee.Value)
+		}
+	case *ast.BuiltinNode:
+		v.functions[n.Name] = true
+	case *ast.BinaryNode:
+		v.operators[n.Operator] = true
+	case *ast.UnaryNode:
+		v.operators[n.Operator] = true
+	case *ast.ConditionalNode:
+		v.operators["?:"] = true
+	}
+}
+
+func summarizeFormula(program *vm.Program) (FormulaAstSummary, map[string]bool) {
+	root := program.Node()
+	v := &formulaSummaryVisitor{variables: map[string]bool{}, functions: map[string]bool{}, operators: map[string]bool{}}
+	ast.Walk(&root, v)
+
+	return FormulaAstSummary{
+		ResultType: typeName(root.Type()),
+		NodeCount:  v.nodes,
+		Depth:      formulaDepth(root),
+		Variables:  sortedKeys(v.variables),
+		Functions:  sortedKeys(v.functions),
+		Operators:  sortedKeys(v.operators),
+		Normalized: root.String(),
+	}, v.variables
+}
+
+// formulaDepth is the height of the tree below node, counting node itself.
+func formulaDepth(node ast.Node) int {
+	depth := 0
+	var children []ast.Node
+	switch n := node.(type) {
+	case *ast.UnaryNode:
+		children = []ast.Node{n.Node}
+	case *ast.BinaryNode:
+		children = []ast.Node{n.Left, n.Right}
+	case *ast.CallNode:
+		children = n.Arguments
+	case *ast.BuiltinNode:
+		children = n.Arguments
+	case *ast.ConditionalNode:
+		children = []ast.Node{n.Cond, n.Exp1, n.Exp2}
+	case *ast.MemberNode:
+		children = []ast.Node{n.Node, n.Property}
+	case *ast.ChainNode:
+		children = []ast.Node{n.Node}
+	case *ast.ArrayNode:
+		children = n.Nodes
+	}
+	for _, child := range children {
+		if d := formulaDepth(child); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+type formulaStepVisitor struct {
+	env   map[string]interface{}
+	steps []FormulaStep
+}
+
+// Visit runs each operation of the tree on its own, children before parents, so the steps read in evaluation order.
+func (v *formulaStepVisitor) Visit(node *ast.Node) {
+	switch (*node).(type) {
+	case *ast.UnaryNode, *ast.BinaryNode, *ast.CallNode, *ast.BuiltinNode, *ast.ConditionalNode:
+	default:
+		return
+	}
+
+	source := (*node).String()
+	step := FormulaStep{Expression: source}
+	value, err := expr.Eval(source, v.env)
+	if err != nil {
+		step.Error = err.Error()
+	} else {
+		step.Value = value
+		step.Type = fmt.Sprintf("%T", value)
+	}
+	v.steps = append(v.steps, step)
+}
+
+func formulaSteps(program *vm.Program, env map[string]interface{}) []FormulaStep {
+	root := program.Node()
+	v := &formulaStepVisitor{env: env, steps: []FormulaStep{}}
+	ast.Walk(&root, v)
+	return v.steps
+}
+
+func formulaVariablesOf(env, params map[string]interface{}, used map[string]bool) []FormulaVariable {
+	variables := []FormulaVariable{}
+	for _, name := range formulaVariables {
+		if _, isParam := params[name]; isParam {
+			continue
+		}
+		variables = append(variables, FormulaVariable{Name: name, Source: "price_data", Value: env[name], Type: fmt.Sprintf("%T", env[name]), Used: used[name]})
+	}
+	for _, name := range sortedKeys(params) {
+		variables = append(variables, FormulaVariable{Name: name, Source: "params", Value: env[name], Type: fmt.Sprintf("%T", env[name]), Used: used[name]})
+	}
+	return variables
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	return t.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type TestFormulaRequest struct {
+	FormulaCode string                `json:"formula_code"` // test a stored formula, or
+	Expression  string                `json:"expression"`   // test this expression with params and rounding
+	Params      json.RawMessage       `json:"params"`
+	Rounding    *int                  `json:"rounding"`
+	PriceData   priceDomain.PriceData `json:"price_data"`
+}
+
+// TestFormula runs a stored or draft formula on sample price data and returns its evaluation trace.
+func TestFormula(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req TestFormulaRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	formula := priceDomain.PriceFormula{Expression: req.Expression, Params: req.Params}
+	if req.FormulaCode != "" {
+		gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+		if err != nil {
+			return nil, err
+		}
+		var stored models.PriceListFormulas
+		if err := gormx.Where("formula_code = ?", req.FormulaCode).Take(&stored).Error; err != nil {
+			return nil, fmt.Errorf("formula %s not found: %w", req.FormulaCode, err)
+		}
+		formula = priceDomain.PriceFormula{Expression: stored.Expression, Params: stored.Params, Rounding: stored.Rounding}
+		// A draft expression or params in the request overrides the stored one, to try a fix before saving it
+		if req.Expression != "" {
+			formula.Expression = req.Expression
+		}
+		if len(req.Params) > 0 {
+			formula.Params = req.Params
+		}
+	}
+	if req.Rounding != nil {
+		formula.Rounding = *req.Rounding
+	}
+	if strings.TrimSpace(formula.Expression) == "" {
+		return nil, errors.New("formula_code or expression is required")
+	}
+
+	return traceFormula(formula, req.PriceData), nil
+}
+
+type FormulaValidation struct {
+	FormulaCode string         `json:"formula_code"`
+	Name        string         `json:"name"`
+	FormulaType string         `json:"formula_type"`
+	Uom         string         `json:"uom"`
+	Valid       bool           `json:"valid"`
+	Skipped     bool           `json:"skipped"` // input formulas are not evaluated
+	Errors      []FormulaIssue `json:"errors"`
+	Warnings    []FormulaIssue `json:"warnings"`
+}
+
+type ValidateFormulasRequest struct {
+	FormulaCodes []string `json:"formula_codes"` // all formulas when empty
+}
+
+type ValidateFormulasResponse struct {
+	Total    int                 `json:"total"`
+	Invalid  int                 `json:"invalid"`
+	Formulas []FormulaValidation `json:"formulas"`
+}
+
+// validateFormula type-checks a stored formula against the environment CalculatePrice gives it.
+func validateFormula(f models.PriceListFormulas) FormulaValidation {
+	res := FormulaValidation{
+		FormulaCode: f.FormulaCode,
+		Name:        f.Name,
+		FormulaType: f.FormulaType,
+		Uom:         f.Uom,
+		Valid:       true,
+		Errors:      []FormulaIssue{},
+		Warnings:    []FormulaIssue{},
+	}
+	if f.FormulaType == models.PriceFormulaTypeInput {
+		res.Skipped = true
+		return res
+	}
+
+	trace := traceFormula(priceDomain.PriceFormula{Expression: f.Expression, Params: f.Params, Rounding: f.Rounding}, sampleFormulaData)
+	res.Valid = trace.Valid
+	res.Errors = trace.Errors
+	res.Warnings = trace.Warnings
+	if f.Uom != "pcs" && f.Uom != "kg" {
+		res.Warnings = append(res.Warnings, FormulaIssue{Kind: FormulaIssueType, Message: fmt.Sprintf("uom %q is neither pcs nor kg, the formula is never applied", f.Uom)})
+	}
+	return res
+}
+
+// ValidateFormulas checks the stored formulas, so a broken one is found before UpdateLatestPriceListSubGroup uses it.
+func ValidateFormulas(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req ValidateFormulasRequest
+	if jsonPayload != "" {
+		if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+			return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+		}
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Order("formula_code")
+	if len(req.FormulaCodes) > 0 {
+		query = query.Where("formula_code IN ?", req.FormulaCodes)
+	}
+	var formulas []models.PriceListFormulas
+	if err := query.Find(&formulas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get formulas: %w", err)
+	}
+
+	res := ValidateFormulasResponse{Total: len(formulas), Formulas: []FormulaValidation{}}
+	for _, f := range formulas {
+		validation := validateFormula(f)
+		if !validation.Valid {
+			res.Invalid++
+		}
+		res.Formulas = append(res.Formulas, validation)
+	}
+
+	return res, nil
+}
+
+// checkSubGroupFormulas validates each distinct formula mapped to the sub groups once, before any price is calculated.
+func checkSubGroupFormulas(formulasMap map[string][]models.PriceListSubGroupFormulasMap) error {
+	checked := map[string]bool{}
+	invalid := []string{}
+	for _, subGroupCode := range sortedKeys(formulasMap) {
+		for _, mapping := range formulasMap[subGroupCode] {
+			formula := mapping.PriceListFormulas
+			if checked[formula.FormulaCode] {
+				continue
+			}
+			checked[formula.FormulaCode] = true
+
+			validation := validateFormula(formula)
+			for _, issue := range validation.Errors {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", formula.FormulaCode, issue.Message))
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return &utils.BindingError{Message: "invalid formulas: " + strings.Join(invalid, "; ")}
+	}
+	return nil
+}
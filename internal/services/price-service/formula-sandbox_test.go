@@ -0,0 +1,80 @@
+package priceService
+
+import (
+	"encoding/json"
+	"testing"
+
+	"prime-erp-core/internal/models"
+	priceDomain "prime-erp-core/internal/services/price-service/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceFormula_StepsAndRounding(t *testing.T) {
+	formula := priceDomain.PriceFormula{
+		Expression: "(base_price + extra) * markup",
+		Params:     json.RawMessage(`{"markup": 1.075}`),
+		Rounding:   2,
+	}
+
+	trace := traceFormula(formula, priceDomain.PriceData{BasePrice: 100, Extra: 3})
+
+	assert.True(t, trace.Valid)
+	assert.Empty(t, trace.Errors)
+	require.NotNil(t, trace.Ast)
+	assert.Equal(t, "float64", trace.Ast.ResultType)
+	assert.Equal(t, []string{"base_price", "extra", "markup"}, trace.Ast.Variables)
+	assert.Equal(t, []string{"*", "+"}, trace.Ast.Operators)
+
+	require.Len(t, trace.Steps, 2)
+	assert.Equal(t, "base_price + extra", trace.Steps[0].Expression)
+	assert.Equal(t, 103.0, trace.Steps[0].Value)
+	assert.InDelta(t, 110.725, trace.Steps[1].Value, 1e-9)
+
+	require.NotNil(t, trace.Result)
+	require.NotNil(t, trace.Rounded)
+	assert.InDelta(t, 110.725, *trace.Result, 1e-9)
+	assert.Equal(t, 110.73, *trace.Rounded)
+
+	var markup FormulaVariable
+	for _, v := range trace.Variables {
+		if v.Name == "markup" {
+			markup = v
+		}
+	}
+	assert.Equal(t, "params", markup.Source)
+	assert.True(t, markup.Used)
+}
+
+func TestTraceFormula_Errors(t *testing.T) {
+	unknown := traceFormula(priceDomain.PriceFormula{Expression: "base_price + surcharge"}, sampleFormulaData)
+	assert.False(t, unknown.Valid)
+	require.Len(t, unknown.Errors, 1)
+	assert.Equal(t, FormulaIssueCompile, unknown.Errors[0].Kind)
+	assert.Equal(t, 1, unknown.Errors[0].Line)
+
+	// CalculatePrice rejects anything but float64, a comparison compiles but can never be a price
+	boolean := traceFormula(priceDomain.PriceFormula{Expression: "base_price > 10"}, sampleFormulaData)
+	assert.False(t, boolean.Valid)
+	require.NotEmpty(t, boolean.Errors)
+	assert.Equal(t, FormulaIssueType, boolean.Errors[0].Kind)
+	assert.Nil(t, boolean.Rounded)
+}
+
+func TestCheckSubGroupFormulas(t *testing.T) {
+	valid := models.PriceListFormulas{FormulaCode: "F1", Uom: "kg", FormulaType: "calculation", Expression: "base_price + extra"}
+	input := models.PriceListFormulas{FormulaCode: "F2", Uom: "kg", FormulaType: "input"}
+	broken := models.PriceListFormulas{FormulaCode: "F3", Uom: "pcs", FormulaType: "calculation", Expression: "base_price +"}
+
+	formulasMap := map[string][]models.PriceListSubGroupFormulasMap{
+		"SG1": {{PriceListFormulas: valid}, {PriceListFormulas: input}},
+	}
+	assert.NoError(t, checkSubGroupFormulas(formulasMap))
+
+	formulasMap["SG2"] = []models.PriceListSubGroupFormulasMap{{PriceListFormulas: broken}, {PriceListFormulas: valid}}
+	err := checkSubGroupFormulas(formulasMap)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "F3")
+	assert.NotContains(t, err.Error(), "F1")
+}
@@ -0,0 +1,548 @@
+package priceService
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// formulaRef names the formula version a calculated total came from.
+func formulaRef(formula models.PriceListFormulas) *models.FormulaRef {
+	return &models.FormulaRef{FormulaCode: formula.FormulaCode, Version: formula.Version}
+}
+
+// FormulaRequest creates a formula, or edits one when only some fields are given.
+type FormulaRequest struct {
+	FormulaCode string          `json:"formula_code"`
+	Name        *string         `json:"name"`
+	Uom         *string         `json:"uom"`
+	FormulaType *string         `json:"formula_type"`
+	Expression  *string         `json:"expression"`
+	Params      json.RawMessage `json:"params"`
+	Rounding    *int            `json:"rounding"`
+	Remark      string          `json:"remark"` // why this version was made, kept on the version
+}
+
+func applyFormulaRequest(formula *models.PriceListFormulas, req FormulaRequest) {
+	if req.Name != nil {
+		formula.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Uom != nil {
+		formula.Uom = strings.ToLower(strings.TrimSpace(*req.Uom))
+	}
+	if req.FormulaType != nil {
+		formula.FormulaType = strings.TrimSpace(*req.FormulaType)
+	}
+	if req.Expression != nil {
+		formula.Expression = strings.TrimSpace(*req.Expression)
+	}
+	if len(req.Params) > 0 {
+		formula.Params = req.Params
+	}
+	if req.Rounding != nil {
+		formula.Rounding = *req.Rounding
+	}
+
+	if len(formula.Params) == 0 || string(formula.Params) == "null" {
+		formula.Params = json.RawMessage(`{}`)
+	}
+	if formula.FormulaType == models.PriceFormulaTypeInput {
+		formula.Expression = ""
+	}
+}
+
+// checkFormula rejects a formula that UpdateLatestPriceListSubGroup could not use.
+func checkFormula(formula models.PriceListFormulas) error {
+	if formula.Name == "" {
+		return errors.New("name is required")
+	}
+	if formula.Uom != "pcs" && formula.Uom != "kg" {
+		return fmt.Errorf("uom must be pcs or kg, got %q", formula.Uom)
+	}
+	if formula.FormulaType != models.PriceFormulaTypeInput && formula.FormulaType != models.PriceFormulaTypeCalc {
+		return fmt.Errorf("formula_type must be %s or %s, got %q", models.PriceFormulaTypeInput, models.PriceFormulaTypeCalc, formula.FormulaType)
+	}
+	if formula.Rounding < 0 || formula.Rounding > 6 {
+		return fmt.Errorf("rounding must be between 0 and 6, got %d", formula.Rounding)
+	}
+	if formula.FormulaType == models.PriceFormulaTypeInput {
+		return nil
+	}
+	if formula.Expression == "" {
+		return errors.New("expression is required")
+	}
+
+	validation := validateFormula(formula)
+	if !validation.Valid {
+		messages := make([]string, 0, len(validation.Errors))
+		for _, issue := range validation.Errors {
+			messages = append(messages, issue.Message)
+		}
+		return fmt.Errorf("invalid formula %s: %s", formula.FormulaCode, strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// sameFormula says whether an edit leaves everything the calculation depends on unchanged.
+func sameFormula(a, b models.PriceListFormulas) bool {
+	return a.Name == b.Name &&
+		a.Uom == b.Uom &&
+		a.FormulaType == b.FormulaType &&
+		a.Expression == b.Expression &&
+		a.Rounding == b.Rounding &&
+		bytes.Equal(compactJSON(a.Params), compactJSON(b.Params))
+}
+
+func compactJSON(raw json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+func formulaVersionOf(formula models.PriceListFormulas, remark, user string, now time.Time) models.PriceListFormulaVersion {
+	return models.PriceListFormulaVersion{
+		ID:          uuid.New(),
+		FormulaCode: formula.FormulaCode,
+		Version:     formula.Version,
+		Name:        formula.Name,
+		Uom:         formula.Uom,
+		FormulaType: formula.FormulaType,
+		Expression:  formula.Expression,
+		Params:      formula.Params,
+		Rounding:    formula.Rounding,
+		Remark:      remark,
+		CreateBy:    user,
+		CreateDtm:   now,
+	}
+}
+
+// snapshotFormulaVersion keeps the current version of a formula seeded before versioning, which has no version row yet.
+func snapshotFormulaVersion(tx *gorm.DB, formula models.PriceListFormulas) error {
+	version := formulaVersionOf(formula, "", formula.CreateBy, formula.CreateDtm)
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "formula_code"}, {Name: "version"}},
+		DoNothing: true,
+	}).Create(&version).Error
+}
+
+func lockFormula(tx *gorm.DB, formulaCode string) (models.PriceListFormulas, error) {
+	var formula models.PriceListFormulas
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("formula_code = ?", formulaCode).
+		Take(&formula).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return formula, fmt.Errorf("formula %s not found", formulaCode)
+		}
+		return formula, err
+	}
+	return formula, nil
+}
+
+func assignedSubGroupCodes(gormx *gorm.DB, formulaCode string) ([]string, error) {
+	codes := []string{}
+	err := gormx.Model(&models.PriceListSubGroupFormulasMap{}).
+		Where("price_list_formulas_code = ?", formulaCode).
+		Distinct().
+		Order("price_list_subgroup_code").
+		Pluck("price_list_subgroup_code", &codes).Error
+	return codes, err
+}
+
+type FormulaResponse struct {
+	Formula       models.PriceListFormulas `json:"formula"`
+	Changed       bool                     `json:"changed"`
+	SubGroupCodes []string                 `json:"subgroup_codes"` // sub groups to recalculate with UpdateLatest
+}
+
+// CreateFormula adds a formula at version 1.
+func CreateFormula(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req FormulaRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.FormulaCode))
+	if code == "" {
+		return nil, errors.New("formula_code is required")
+	}
+
+	user := middleware.GetUserCode(ctx)
+	now := time.Now().UTC()
+	formula := models.PriceListFormulas{
+		ID:          uuid.New(),
+		FormulaCode: code,
+		Version:     1,
+		Status:      models.PriceFormulaActive,
+		CreateBy:    user,
+		CreateDtm:   now,
+		UpdateBy:    user,
+		UpdateDtm:   &now,
+	}
+	applyFormulaRequest(&formula, req)
+	if err := checkFormula(formula); err != nil {
+		return nil, err
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.PriceListFormulas{}).Where("formula_code = ?", code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return &utils.ConflictError{Code: "FORMULA_EXISTS", Message: fmt.Sprintf("formula %s already exists", code)}
+		}
+
+		if err := tx.Create(&formula).Error; err != nil {
+			return fmt.Errorf("failed to create formula: %w", err)
+		}
+		version := formulaVersionOf(formula, req.Remark, user, now)
+		return tx.Create(&version).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return FormulaResponse{Formula: formula, Changed: true, SubGroupCodes: []string{}}, nil
+}
+
+// UpdateFormula saves an edit as the next version. Earlier versions stay as they were, so a sub group calculated with
+// one can still be explained.
+func UpdateFormula(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req FormulaRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.FormulaCode))
+	if code == "" {
+		return nil, errors.New("formula_code is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+	now := time.Now().UTC()
+	res := FormulaResponse{}
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		current, err := lockFormula(tx, code)
+		if err != nil {
+			return err
+		}
+		if current.Status == models.PriceFormulaDeprecated {
+			return &utils.ConflictError{Code: "FORMULA_DEPRECATED", Message: fmt.Sprintf("formula %s is deprecated", code)}
+		}
+		if err := snapshotFormulaVersion(tx, current); err != nil {
+			return fmt.Errorf("failed to keep formula version: %w", err)
+		}
+
+		updated := current
+		applyFormulaRequest(&updated, req)
+		if sameFormula(current, updated) {
+			res.Formula = current
+			return nil
+		}
+		if err := checkFormula(updated); err != nil {
+			return err
+		}
+
+		updated.Version = current.Version + 1
+		updated.UpdateBy = user
+		updated.UpdateDtm = &now
+		if err := tx.Model(&models.PriceListFormulas{}).Where("id = ?", current.ID).Updates(map[string]interface{}{
+			"name":         updated.Name,
+			"uom":          updated.Uom,
+			"formula_type": updated.FormulaType,
+			"expression":   updated.Expression,
+			"params":       updated.Params,
+			"rounding":     updated.Rounding,
+			"version":      updated.Version,
+			"update_by":    user,
+			"update_dtm":   now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update formula: %w", err)
+		}
+		version := formulaVersionOf(updated, req.Remark, user, now)
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("failed to create formula version: %w", err)
+		}
+
+		res.Formula = updated
+		res.Changed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.SubGroupCodes = []string{}
+	if res.Changed {
+		if res.SubGroupCodes, err = assignedSubGroupCodes(gormx, code); err != nil {
+			return nil, fmt.Errorf("failed to get sub groups of formula %s: %w", code, err)
+		}
+	}
+	return res, nil
+}
+
+type DeprecateFormulasRequest struct {
+	FormulaCodes []string `json:"formula_codes"`
+}
+
+// DeprecateFormulas retires formulas. Their versions and assignments are kept, but price calculation skips them.
+func DeprecateFormulas(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req DeprecateFormulasRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.FormulaCodes) == 0 {
+		return nil, errors.New("formula_codes is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := gormx.Model(&models.PriceListFormulas{}).
+		Where("formula_code IN ? AND status = ?", req.FormulaCodes, models.PriceFormulaActive).
+		Updates(map[string]interface{}{
+			"status":     models.PriceFormulaDeprecated,
+			"update_by":  middleware.GetUserCode(ctx),
+			"update_dtm": now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to deprecate formulas: %w", result.Error)
+	}
+
+	// Sub groups still assigned only these formulas keep their last calculated price
+	subGroupCodes := []string{}
+	if err := gormx.Model(&models.PriceListSubGroupFormulasMap{}).
+		Where("price_list_formulas_code IN ?", req.FormulaCodes).
+		Distinct().
+		Order("price_list_subgroup_code").
+		Pluck("price_list_subgroup_code", &subGroupCodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sub groups of formulas: %w", err)
+	}
+
+	return map[string]interface{}{
+		"deprecated":     result.RowsAffected,
+		"subgroup_codes": subGroupCodes,
+		"success":        true,
+		"message":        "Formulas deprecated successfully",
+	}, nil
+}
+
+type AssignFormulaRequest struct {
+	FormulaCode   string   `json:"formula_code"`
+	SubGroupCodes []string `json:"subgroup_codes"`
+	IsDefault     bool     `json:"is_default"` // makes it the only default among the sub group's formulas of the same uom
+	Unassign      bool     `json:"unassign"`   // removes the formula from the sub groups instead
+}
+
+// AssignFormula maps a formula to sub group codes, or removes it from them.
+func AssignFormula(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req AssignFormulaRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.FormulaCode))
+	if code == "" {
+		return nil, errors.New("formula_code is required")
+	}
+	if len(req.SubGroupCodes) == 0 {
+		return nil, errors.New("subgroup_codes is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		if req.Unassign {
+			return tx.Where("price_list_formulas_code = ? AND price_list_subgroup_code IN ?", code, req.SubGroupCodes).
+				Delete(&models.PriceListSubGroupFormulasMap{}).Error
+		}
+
+		formula, err := lockFormula(tx, code)
+		if err != nil {
+			return err
+		}
+		if formula.Status == models.PriceFormulaDeprecated {
+			return &utils.ConflictError{Code: "FORMULA_DEPRECATED", Message: fmt.Sprintf("formula %s is deprecated", code)}
+		}
+
+		if req.IsDefault {
+			sameUom := tx.Model(&models.PriceListFormulas{}).Select("formula_code").Where("uom = ?", formula.Uom)
+			if err := tx.Model(&models.PriceListSubGroupFormulasMap{}).
+				Where("price_list_subgroup_code IN ? AND price_list_formulas_code <> ?", req.SubGroupCodes, code).
+				Where("price_list_formulas_code IN (?)", sameUom).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, subGroupCode := range req.SubGroupCodes {
+			result := tx.Model(&models.PriceListSubGroupFormulasMap{}).
+				Where("price_list_subgroup_code = ? AND price_list_formulas_code = ?", subGroupCode, code).
+				Update("is_default", req.IsDefault)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				continue
+			}
+			if err := tx.Create(&models.PriceListSubGroupFormulasMap{
+				ID:                    uuid.New(),
+				PriceListSubGroupCode: subGroupCode,
+				PriceListFormulasCode: code,
+				IsDefault:             req.IsDefault,
+				CreateDtm:             time.Now().UTC(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"message": "Formula assignment updated successfully",
+	}, nil
+}
+
+type GetFormulasRequest struct {
+	FormulaCodes []string `json:"formula_codes"`
+	Status       []string `json:"status"`
+}
+
+type FormulaWithAssignments struct {
+	models.PriceListFormulas
+	SubGroupCodes []string `json:"subgroup_codes"`
+}
+
+// GetFormulas lists formulas with the sub group codes each is assigned to.
+func GetFormulas(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req GetFormulasRequest
+	if jsonPayload != "" {
+		if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+			return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+		}
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Order("formula_code")
+	if len(req.FormulaCodes) > 0 {
+		query = query.Where("formula_code IN ?", req.FormulaCodes)
+	}
+	if len(req.Status) > 0 {
+		query = query.Where("status IN ?", req.Status)
+	}
+	var formulas []models.PriceListFormulas
+	if err := query.Find(&formulas).Error; err != nil {
+		return nil, fmt.Errorf("failed to get formulas: %w", err)
+	}
+
+	codes := make([]string, 0, len(formulas))
+	for _, formula := range formulas {
+		codes = append(codes, formula.FormulaCode)
+	}
+	var maps []models.PriceListSubGroupFormulasMap
+	if len(codes) > 0 {
+		if err := gormx.Where("price_list_formulas_code IN ?", codes).
+			Order("price_list_subgroup_code").
+			Find(&maps).Error; err != nil {
+			return nil, fmt.Errorf("failed to get formula assignments: %w", err)
+		}
+	}
+	subGroupCodes := map[string][]string{}
+	for _, m := range maps {
+		subGroupCodes[m.PriceListFormulasCode] = append(subGroupCodes[m.PriceListFormulasCode], m.PriceListSubGroupCode)
+	}
+
+	res := make([]FormulaWithAssignments, 0, len(formulas))
+	for _, formula := range formulas {
+		assigned := subGroupCodes[formula.FormulaCode]
+		if assigned == nil {
+			assigned = []string{}
+		}
+		res = append(res, FormulaWithAssignments{PriceListFormulas: formula, SubGroupCodes: assigned})
+	}
+	return res, nil
+}
+
+type GetFormulaVersionsRequest struct {
+	FormulaCode string `json:"formula_code"`
+	Version     int    `json:"version"` // all versions when 0
+}
+
+// GetFormulaVersions returns the versions of a formula, newest first, e.g. the one a sub group's
+// weight_formula_version points at.
+func GetFormulaVersions(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req GetFormulaVersionsRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.FormulaCode))
+	if code == "" {
+		return nil, errors.New("formula_code is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Where("formula_code = ?", code)
+	if req.Version > 0 {
+		query = query.Where("version = ?", req.Version)
+	}
+	versions := []models.PriceListFormulaVersion{}
+	if err := query.Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get formula versions: %w", err)
+	}
+	if len(versions) > 0 {
+		return versions, nil
+	}
+
+	// A formula that was seeded and never edited has only its live row
+	var formula models.PriceListFormulas
+	if err := gormx.Where("formula_code = ?", code).Take(&formula).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("formula %s not found", code)
+		}
+		return nil, err
+	}
+	if req.Version > 0 && req.Version != formula.Version {
+		return nil, fmt.Errorf("formula %s has no version %d", code, req.Version)
+	}
+	return []models.PriceListFormulaVersion{formulaVersionOf(formula, "", formula.CreateBy, formula.CreateDtm)}, nil
+}
@@ -0,0 +1,66 @@
+package priceService
+
+import (
+	"encoding/json"
+	"testing"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFormulaRequest_EditsAndChecks(t *testing.T) {
+	current := models.PriceListFormulas{
+		FormulaCode: "FM-1",
+		Name:        "kg = base + extra",
+		Uom:         "kg",
+		FormulaType: models.PriceFormulaTypeCalc,
+		Expression:  "base_price + extra",
+		Params:      json.RawMessage(`{"description": "kg"}`),
+		Rounding:    2,
+		Version:     3,
+	}
+
+	// Reformatted params are not a new version
+	same := current
+	applyFormulaRequest(&same, FormulaRequest{Params: json.RawMessage(`{ "description":"kg" }`)})
+	assert.True(t, sameFormula(current, same))
+
+	expression := "base_price + extra * 1.07"
+	edited := current
+	applyFormulaRequest(&edited, FormulaRequest{Expression: &expression})
+	assert.False(t, sameFormula(current, edited))
+	assert.NoError(t, checkFormula(edited))
+	assert.Equal(t, 3, edited.Version, "the version is bumped when saved, not when applied")
+
+	broken := "base_price + surcharge"
+	invalid := current
+	applyFormulaRequest(&invalid, FormulaRequest{Expression: &broken})
+	err := checkFormula(invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "FM-1")
+
+	input := models.PriceFormulaTypeInput
+	uom := "PCS"
+	toInput := current
+	applyFormulaRequest(&toInput, FormulaRequest{FormulaType: &input, Uom: &uom})
+	assert.Equal(t, "pcs", toInput.Uom)
+	assert.Empty(t, toInput.Expression, "input formulas have no expression")
+	assert.NoError(t, checkFormula(toInput))
+
+	created := models.PriceListFormulas{FormulaCode: "FM-2"}
+	applyFormulaRequest(&created, FormulaRequest{})
+	assert.JSONEq(t, `{}`, string(created.Params))
+	assert.EqualError(t, checkFormula(created), "name is required")
+}
+
+func TestFormulaVersionOf(t *testing.T) {
+	formula := models.PriceListFormulas{FormulaCode: "FM-1", Version: 4, Expression: "base_price", Uom: "kg"}
+
+	version := formulaVersionOf(formula, "raise rounding", "u1", formula.CreateDtm)
+	assert.Equal(t, "FM-1", version.FormulaCode)
+	assert.Equal(t, 4, version.Version)
+	assert.Equal(t, "raise rounding", version.Remark)
+	assert.Equal(t, &models.FormulaRef{FormulaCode: "FM-1", Version: 4}, formulaRef(formula))
+}
@@ -0,0 +1,481 @@
+package priceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	externalService "prime-erp-core/external/warehouse-service"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// seam for unit testing: allow stubbing the product lookup of sub groups
+var getSubGroupProductsFunc = getSubGroupProducts
+
+// getSubGroupProducts asks the inventory service which products each sub group's keys stand for.
+// Returns a map of sub group id -> product codes.
+func getSubGroupProducts(subGroups []models.PriceListSubGroup) (map[uuid.UUID][]string, error) {
+	keyValues := map[string][]externalService.InventoryByProductCodeKeyValue{}
+	siteCodes := map[string]map[string]bool{}
+	for _, subGroup := range subGroups {
+		companyCode := subGroup.PriceListGroup.CompanyCode
+		if siteCodes[companyCode] == nil {
+			siteCodes[companyCode] = map[string]bool{}
+		}
+		if subGroup.PriceListGroup.SiteCode != "" {
+			siteCodes[companyCode][subGroup.PriceListGroup.SiteCode] = true
+		}
+		for _, sgk := range subGroup.PriceListSubGroupKeys {
+			keyValues[companyCode] = append(keyValues[companyCode], externalService.InventoryByProductCodeKeyValue{
+				ID:         subGroup.ID.String(),
+				GroupCode:  sgk.Code,
+				GroupValue: sgk.Value,
+				Seq:        sgk.Seq,
+			})
+		}
+	}
+
+	products := map[uuid.UUID][]string{}
+	for _, companyCode := range sortedKeys(keyValues) {
+		inventory, err := externalService.GetInventoryByProductCode(companyCode, sortedKeys(siteCodes[companyCode]), keyValues[companyCode])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get products of sub groups: %w", err)
+		}
+		for _, inv := range inventory {
+			id, err := uuid.Parse(inv.ID)
+			if err != nil || inv.ProductCode == "" {
+				continue
+			}
+			products[id] = append(products[id], inv.ProductCode)
+		}
+	}
+	return products, nil
+}
+
+type ImpactAnalysisRequest struct {
+	Changes    []models.UpdatePriceListSubGroupItem `json:"changes"`     // a proposed change set, as for UpdatePriceListSubGroup
+	VersionIDs []uuid.UUID                          `json:"version_ids"` // staged sub group versions
+}
+
+type ImpactLine struct {
+	DocType        string  `json:"doc_type"` // QUOTATION or SALE
+	DocCode        string  `json:"doc_code"`
+	ItemRef        string  `json:"item_ref"`
+	CustomerCode   string  `json:"customer_code"`
+	ProductCode    string  `json:"product_code"`
+	SubGroupID     string  `json:"subgroup_id"`
+	SaleUnit       string  `json:"sale_unit"`
+	SaleQty        float64 `json:"sale_qty"` // qty, or total weight when sold by weight
+	NetPrice       float64 `json:"net_price"`
+	PriceListUnit  float64 `json:"price_list_unit"`
+	NewPriceList   float64 `json:"new_price_list"`
+	MarginGap      float64 `json:"margin_gap"` // (net_price - new_price_list) * sale_qty, negative below the new floor
+	IsBelowNewList bool    `json:"is_below_new_list"`
+}
+
+type ImpactDocument struct {
+	DocType          string  `json:"doc_type"`
+	DocCode          string  `json:"doc_code"`
+	CustomerCode     string  `json:"customer_code"`
+	ImpactedLines    int     `json:"impacted_lines"`
+	MarginGap        float64 `json:"margin_gap"`
+	IsPassPriceNow   bool    `json:"is_pass_price_now"`
+	IsPassPriceAfter bool    `json:"is_pass_price_after"`
+	WouldFailPrice   bool    `json:"would_fail_price"` // passes VerifyApprove's price check today but not after the change
+}
+
+type ImpactCustomer struct {
+	CustomerCode  string  `json:"customer_code"`
+	Documents     int     `json:"documents"`
+	ImpactedLines int     `json:"impacted_lines"`
+	MarginGap     float64 `json:"margin_gap"`
+}
+
+type ImpactAnalysisResponse struct {
+	SubGroups         int              `json:"subgroups"`
+	ImpactedLines     int              `json:"impacted_lines"`
+	WouldFailPrice    int              `json:"would_fail_price"`
+	Lines             []ImpactLine     `json:"lines"`
+	Documents         []ImpactDocument `json:"documents"`
+	Customers         []ImpactCustomer `json:"customers"`
+	UnmappedSubGroups []string         `json:"unmapped_subgroups"` // sub groups no product could be found for
+}
+
+// proposedPrice is a sub group's price list after the change.
+type proposedPrice struct {
+	SubGroupID  uuid.UUID
+	PriceUnit   float64
+	PriceWeight float64
+}
+
+// impactDocument is an open quotation or sale reduced to what VerifyApprove's price check reads.
+type impactDocument struct {
+	DocType       string
+	DocCode       string
+	CompanyCode   string
+	SiteCode      string
+	CustomerCode  string
+	TransportType string
+	TransportCost float64
+	TotalAmount   float64
+	TotalWeight   float64
+	Items         []impactItem
+}
+
+type impactItem struct {
+	ItemRef        string
+	ProductCode    string
+	Qty            float64
+	Unit           string
+	TotalWeight    float64
+	TotalAmount    float64
+	PriceUnit      float64
+	PriceListUnit  float64
+	SaleUnit       string
+	SaleUnitType   string
+	NetPriceUnit   float64
+	NetPriceWeight float64
+}
+
+func impactProductKey(companyCode, siteCode, productCode string) string {
+	return companyCode + "|" + siteCode + "|" + productCode
+}
+
+// comparePriceRequest builds the request VerifyApprove would check the document with.
+func (d impactDocument) comparePriceRequest(priceList func(impactItem) float64) GetComparePriceRequest {
+	req := GetComparePriceRequest{
+		UnitCode:           `PC`, // as VerifyApprove
+		UnitCodeWeight:     `KG`,
+		TransportType:      d.TransportType,
+		TotalTransportCost: d.TransportCost,
+		TotalAmount:        d.TotalAmount,
+		TotalWeight:        d.TotalWeight,
+	}
+	for _, item := range d.Items {
+		req.Items = append(req.Items, ItemComparePrice{
+			RefItem:       item.ItemRef,
+			ProductCode:   item.ProductCode,
+			Qty:           item.Qty,
+			Unit:          item.Unit,
+			PriceUnit:     item.PriceUnit,
+			PriceListUnit: priceList(item),
+			TotalAmount:   item.TotalAmount,
+			TotalWeight:   item.TotalWeight,
+			SaleUnit:      item.SaleUnit,
+			SaleUnitType:  item.SaleUnitType,
+		})
+	}
+	return req
+}
+
+// analyzeImpact compares every line of the documents with the proposed prices of its product. prices is keyed by
+// impactProductKey.
+func analyzeImpact(documents []impactDocument, prices map[string]proposedPrice) (ImpactAnalysisResponse, error) {
+	res := ImpactAnalysisResponse{Lines: []ImpactLine{}, Documents: []ImpactDocument{}, Customers: []ImpactCustomer{}, UnmappedSubGroups: []string{}}
+	customers := map[string]*ImpactCustomer{}
+
+	for _, doc := range documents {
+		newPrices := map[string]float64{}
+		docImpact := ImpactDocument{DocType: doc.DocType, DocCode: doc.DocCode, CustomerCode: doc.CustomerCode}
+
+		for _, item := range doc.Items {
+			price, ok := prices[impactProductKey(doc.CompanyCode, doc.SiteCode, item.ProductCode)]
+			if !ok {
+				// A price list group without a site prices every site of its company
+				if price, ok = prices[impactProductKey(doc.CompanyCode, "", item.ProductCode)]; !ok {
+					continue
+				}
+			}
+
+			line := ImpactLine{
+				DocType:       doc.DocType,
+				DocCode:       doc.DocCode,
+				ItemRef:       item.ItemRef,
+				CustomerCode:  doc.CustomerCode,
+				ProductCode:   item.ProductCode,
+				SubGroupID:    price.SubGroupID.String(),
+				SaleUnit:      item.SaleUnit,
+				PriceListUnit: item.PriceListUnit,
+			}
+			switch item.SaleUnit {
+			case `PC`:
+				line.SaleQty, line.NetPrice, line.NewPriceList = item.Qty, item.NetPriceUnit, price.PriceUnit
+			case `KG`:
+				line.SaleQty, line.NetPrice, line.NewPriceList = item.TotalWeight, item.NetPriceWeight, price.PriceWeight
+			default:
+				continue
+			}
+			if round2(line.NewPriceList) == round2(item.PriceListUnit) {
+				continue
+			}
+
+			line.MarginGap = round2((line.NetPrice - line.NewPriceList) * line.SaleQty)
+			line.IsBelowNewList = round2(line.NetPrice) < round2(line.NewPriceList)
+			newPrices[item.ItemRef] = line.NewPriceList
+			docImpact.ImpactedLines++
+			docImpact.MarginGap = round2(docImpact.MarginGap + line.MarginGap)
+			res.Lines = append(res.Lines, line)
+		}
+		if docImpact.ImpactedLines == 0 {
+			continue
+		}
+
+		before, err := ComparePrice(doc.comparePriceRequest(func(item impactItem) float64 { return item.PriceListUnit }))
+		if err != nil {
+			return res, fmt.Errorf("%s %s: %w", doc.DocType, doc.DocCode, err)
+		}
+		after, err := ComparePrice(doc.comparePriceRequest(func(item impactItem) float64 {
+			if price, ok := newPrices[item.ItemRef]; ok {
+				return price
+			}
+			return item.PriceListUnit
+		}))
+		if err != nil {
+			return res, fmt.Errorf("%s %s: %w", doc.DocType, doc.DocCode, err)
+		}
+		docImpact.IsPassPriceNow = before.IsPassPriceUnitAll && before.IsPassPriceWeightAll
+		docImpact.IsPassPriceAfter = after.IsPassPriceUnitAll && after.IsPassPriceWeightAll
+		docImpact.WouldFailPrice = docImpact.IsPassPriceNow && !docImpact.IsPassPriceAfter
+		if docImpact.WouldFailPrice {
+			res.WouldFailPrice++
+		}
+		res.ImpactedLines += docImpact.ImpactedLines
+		res.Documents = append(res.Documents, docImpact)
+
+		customer, ok := customers[doc.CustomerCode]
+		if !ok {
+			customer = &ImpactCustomer{CustomerCode: doc.CustomerCode}
+			customers[doc.CustomerCode] = customer
+		}
+		customer.Documents++
+		customer.ImpactedLines += docImpact.ImpactedLines
+		customer.MarginGap = round2(customer.MarginGap + docImpact.MarginGap)
+	}
+
+	for _, code := range sortedKeys(customers) {
+		res.Customers = append(res.Customers, *customers[code])
+	}
+	// Biggest shortfall first
+	sort.SliceStable(res.Customers, func(i, j int) bool { return res.Customers[i].MarginGap < res.Customers[j].MarginGap })
+	return res, nil
+}
+
+// proposedChanges turns the request, or the staged versions it names, into sub group changes.
+func proposedChanges(gormx *gorm.DB, req ImpactAnalysisRequest) ([]models.UpdatePriceListSubGroupItem, error) {
+	changes := append([]models.UpdatePriceListSubGroupItem{}, req.Changes...)
+	if len(req.VersionIDs) == 0 {
+		return changes, nil
+	}
+
+	var versions []models.PriceListVersion
+	if err := gormx.Where("id IN ? AND status = ?", req.VersionIDs, models.PriceVersionStaged).
+		Order("effective_date, create_dtm").
+		Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price versions: %w", err)
+	}
+	if len(versions) != len(req.VersionIDs) {
+		return nil, errors.New("version_ids must all be staged price versions")
+	}
+	for _, version := range versions {
+		if version.TargetType != models.PriceVersionTargetSubGroup {
+			return nil, fmt.Errorf("price version %s is a group change; analyze the sub group prices it leads to instead", version.ID)
+		}
+		var item models.UpdatePriceListSubGroupItem
+		if err := json.Unmarshal(version.Changes, &item); err != nil {
+			return nil, fmt.Errorf("invalid sub group price version %s: %w", version.ID, err)
+		}
+		item.SubGroupID = version.TargetID
+		changes = append(changes, item)
+	}
+	return changes, nil
+}
+
+// loadImpactDocuments reads the open quotations and PENDING sales of the given companies that have a line for one of
+// the products.
+func loadImpactDocuments(gormx *gorm.DB, companyCodes, productCodes []string, now time.Time) ([]impactDocument, error) {
+	documents := []impactDocument{}
+
+	var saleIDs []uuid.UUID
+	if err := gormx.Model(&models.SaleItem{}).
+		Joins("JOIN sale ON sale.id = sale_item.sale_id").
+		Where("sale.status = ? AND sale.company_code IN ?", models.SaleStatusPending, companyCodes).
+		Where("sale_item.product_code IN ? AND COALESCE(sale_item.status, '') <> ?", productCodes, models.SaleStatusCanceled).
+		Distinct().
+		Pluck("sale_item.sale_id", &saleIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open sales: %w", err)
+	}
+	if len(saleIDs) > 0 {
+		var sales []models.Sale
+		if err := gormx.Preload("SaleItem", "COALESCE(status, '') <> ?", models.SaleStatusCanceled).
+			Where("id IN ?", saleIDs).
+			Order("sale_code").
+			Find(&sales).Error; err != nil {
+			return nil, fmt.Errorf("failed to get open sales: %w", err)
+		}
+		for _, sale := range sales {
+			doc := impactDocument{
+				DocType: "SALE", DocCode: sale.SaleCode, CompanyCode: sale.CompanyCode, SiteCode: sale.SiteCode,
+				CustomerCode: sale.CustomerCode, TransportType: sale.TransportCostType, TransportCost: sale.TotalTransportCost,
+				TotalAmount: sale.SubtotalExclVat, TotalWeight: sale.TotalWeight,
+			}
+			for _, item := range sale.SaleItem {
+				doc.Items = append(doc.Items, impactItem{
+					ItemRef: item.SaleItem, ProductCode: item.ProductCode, Qty: item.Qty, Unit: item.Unit,
+					TotalWeight: item.TotalWeight, TotalAmount: item.SubtotalExclVat, PriceUnit: item.PriceUnit,
+					PriceListUnit: item.PriceListUnit, SaleUnit: item.SaleUnit, SaleUnitType: item.SaleUnitType,
+					NetPriceUnit: item.NetPriceUnitExclTransport, NetPriceWeight: item.NetPricePerWeightExclTransport,
+				})
+			}
+			documents = append(documents, doc)
+		}
+	}
+
+	// Open quotations: not converted or canceled, and still within their price validity
+	var quotations []models.Quotation
+	if err := gormx.Where("company_code IN ? AND status IN ?", companyCodes, []string{"PENDING", models.QuotationStatusPartiallyConverted}).
+		Where("expire_price_date IS NULL OR expire_price_date >= ?", now).
+		Where("id IN (?)", gormx.Model(&models.QuotationItem{}).Select("quotation_id").Where("product_code IN ?", productCodes)).
+		Order("quotation_code").
+		Find(&quotations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open quotations: %w", err)
+	}
+	if len(quotations) == 0 {
+		return documents, nil
+	}
+
+	quotationIDs := make([]uuid.UUID, 0, len(quotations))
+	for _, quotation := range quotations {
+		quotationIDs = append(quotationIDs, quotation.ID)
+	}
+	var quotationItems []models.QuotationItem
+	if err := gormx.Where("quotation_id IN ? AND COALESCE(status, '') NOT IN ?", quotationIDs, []string{"CANCELED", models.QuotationStatusConverted}).
+		Order("quotation_item").
+		Find(&quotationItems).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open quotation items: %w", err)
+	}
+	itemsByQuotation := map[uuid.UUID][]models.QuotationItem{}
+	for _, item := range quotationItems {
+		itemsByQuotation[item.QuotationID] = append(itemsByQuotation[item.QuotationID], item)
+	}
+
+	for _, quotation := range quotations {
+		doc := impactDocument{
+			DocType: "QUOTATION", DocCode: quotation.QuotationCode, CompanyCode: quotation.CompanyCode, SiteCode: quotation.SiteCode,
+			CustomerCode: quotation.CustomerCode, TransportType: quotation.TransportCostType, TransportCost: quotation.TotalTransportCost,
+			TotalAmount: quotation.SubtotalExclVat, TotalWeight: quotation.TotalWeight,
+		}
+		for _, item := range itemsByQuotation[quotation.ID] {
+			doc.Items = append(doc.Items, impactItem{
+				ItemRef: item.QuotationItem, ProductCode: item.ProductCode, Qty: item.Qty, Unit: item.Unit,
+				TotalWeight: item.TotalWeight, TotalAmount: item.SubtotalExclVat, PriceUnit: item.PriceUnit,
+				PriceListUnit: item.PriceListUnit, SaleUnit: item.SaleUnit, SaleUnitType: item.SaleUnitType,
+				NetPriceUnit: item.NetPriceUnitExclTransport, NetPriceWeight: item.NetPricePerWeightExclTransport,
+			})
+		}
+		if len(doc.Items) > 0 {
+			documents = append(documents, doc)
+		}
+	}
+	return documents, nil
+}
+
+// ImpactAnalysis shows, before a price change is saved or a staged version activates, which open quotation and
+// PENDING sale lines were priced on a different list price, the margin gap per customer, and which documents would
+// then fail VerifyApprove's price check. Products are matched to sub groups through the inventory service.
+func ImpactAnalysis(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req ImpactAnalysisRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.Changes) == 0 && len(req.VersionIDs) == 0 {
+		return nil, errors.New("changes or version_ids is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := proposedChanges(gormx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	subGroupIDs := make([]uuid.UUID, 0, len(changes))
+	for _, change := range changes {
+		subGroupIDs = append(subGroupIDs, change.SubGroupID)
+	}
+	subGroups, err := getPriceListSubGroupsByIDsFunc(subGroupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch price list sub groups: %w", err)
+	}
+	subGroupMap := make(map[uuid.UUID]*models.PriceListSubGroup, len(subGroups))
+	for i := range subGroups {
+		subGroupMap[subGroups[i].ID] = &subGroups[i]
+	}
+
+	// Later changes of the same sub group win, as they would when applied in order
+	proposed := map[uuid.UUID]proposedPrice{}
+	for _, change := range changes {
+		subGroup, ok := subGroupMap[change.SubGroupID]
+		if !ok {
+			return nil, fmt.Errorf("price list sub group %s not found", change.SubGroupID)
+		}
+		price, ok := proposed[change.SubGroupID]
+		if !ok {
+			price = proposedPrice{SubGroupID: subGroup.ID, PriceUnit: subGroup.TotalNetPriceUnit, PriceWeight: subGroup.TotalNetPriceWeight}
+		}
+		if change.TotalNetPriceUnit != nil {
+			price.PriceUnit = *change.TotalNetPriceUnit
+		}
+		if change.TotalNetPriceWeight != nil {
+			price.PriceWeight = *change.TotalNetPriceWeight
+		}
+		proposed[change.SubGroupID] = price
+	}
+
+	products, err := getSubGroupProductsFunc(subGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := map[string]proposedPrice{}
+	companyCodes := map[string]bool{}
+	productCodes := map[string]bool{}
+	unmapped := []string{}
+	for _, subGroup := range subGroups {
+		if len(products[subGroup.ID]) == 0 {
+			unmapped = append(unmapped, subGroup.ID.String())
+			continue
+		}
+		group := subGroup.PriceListGroup
+		companyCodes[group.CompanyCode] = true
+		for _, productCode := range products[subGroup.ID] {
+			productCodes[productCode] = true
+			prices[impactProductKey(group.CompanyCode, group.SiteCode, productCode)] = proposed[subGroup.ID]
+		}
+	}
+
+	documents := []impactDocument{}
+	if len(productCodes) > 0 {
+		if documents, err = loadImpactDocuments(gormx, sortedKeys(companyCodes), sortedKeys(productCodes), time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := analyzeImpact(documents, prices)
+	if err != nil {
+		return nil, err
+	}
+	res.SubGroups = len(subGroups)
+	sort.Strings(unmapped)
+	res.UnmappedSubGroups = unmapped
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package priceService
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeImpact(t *testing.T) {
+	subGroupID := uuid.New()
+	prices := map[string]proposedPrice{
+		impactProductKey("C1", "", "P1"):   {SubGroupID: subGroupID, PriceUnit: 110, PriceWeight: 30},
+		impactProductKey("C1", "S1", "P2"): {SubGroupID: subGroupID, PriceUnit: 100, PriceWeight: 25},
+	}
+	item := func(ref, product, saleUnit string, priceList, net float64) impactItem {
+		return impactItem{
+			ItemRef: ref, ProductCode: product, Qty: 10, TotalWeight: 40, TotalAmount: net * 10, SaleUnit: saleUnit,
+			PriceListUnit: priceList, NetPriceUnit: net, NetPriceWeight: net,
+		}
+	}
+	documents := []impactDocument{
+		{
+			DocType: "SALE", DocCode: "SO1", CompanyCode: "C1", SiteCode: "S1", CustomerCode: "CUST1", TransportType: "EXCL",
+			TotalAmount: 2050,
+			Items: []impactItem{
+				item("1", "P1", "PC", 100, 105), // new floor 110: 5 short on 10 pcs
+				item("2", "P2", "PC", 100, 100), // unchanged
+				item("3", "P3", "PC", 50, 50),   // not in the change set
+			},
+		},
+		{
+			DocType: "QUOTATION", DocCode: "QT1", CompanyCode: "C1", SiteCode: "S2", CustomerCode: "CUST2", TransportType: "EXCL",
+			TotalAmount: 300,
+			Items: []impactItem{
+				{ItemRef: "1", ProductCode: "P1", Qty: 1, TotalWeight: 10, TotalAmount: 300, SaleUnit: "KG",
+					PriceListUnit: 32, NetPriceWeight: 30}, // floor drops to 30
+			},
+		},
+	}
+
+	res, err := analyzeImpact(documents, prices)
+	require.NoError(t, err)
+
+	require.Len(t, res.Lines, 2)
+	assert.Equal(t, "SO1", res.Lines[0].DocCode)
+	assert.Equal(t, 110.0, res.Lines[0].NewPriceList)
+	assert.Equal(t, -50.0, res.Lines[0].MarginGap)
+	assert.True(t, res.Lines[0].IsBelowNewList)
+	assert.Equal(t, 0.0, res.Lines[1].MarginGap)
+	assert.False(t, res.Lines[1].IsBelowNewList)
+
+	require.Len(t, res.Documents, 2)
+	assert.True(t, res.Documents[0].IsPassPriceNow)
+	assert.False(t, res.Documents[0].IsPassPriceAfter)
+	assert.True(t, res.Documents[0].WouldFailPrice)
+	assert.False(t, res.Documents[1].WouldFailPrice)
+	assert.Equal(t, 1, res.WouldFailPrice)
+
+	require.Len(t, res.Customers, 2)
+	assert.Equal(t, "CUST1", res.Customers[0].CustomerCode, "biggest shortfall first")
+	assert.Equal(t, -50.0, res.Customers[0].MarginGap)
+}
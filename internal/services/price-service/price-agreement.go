@@ -0,0 +1,436 @@
+package priceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	customerService "prime-erp-core/internal/services/customer-service"
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// seam for unit testing: allow stubbing the customer master lookup
+var getCustomerGroupsFunc = getCustomerGroups
+
+// getCustomerGroups reads the customer's active groups from the customer master.
+func getCustomerGroups(customerCode string) ([]models.CustomerGroupRef, error) {
+	customers, err := customerService.GetCustomers(map[string]interface{}{
+		"customer_code": []string{customerCode},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer %s: %w", customerCode, err)
+	}
+
+	groups := []models.CustomerGroupRef{}
+	for _, customer := range customers.Customers {
+		if customer.CustomerCode != customerCode {
+			continue
+		}
+		for _, g := range customer.CustomerGroup {
+			if g.ActiveFlg {
+				groups = append(groups, models.CustomerGroupRef{GroupCode: g.GroupCode, GroupValue: g.GroupValue})
+			}
+		}
+	}
+	return groups, nil
+}
+
+type PriceAgreementLineRequest struct {
+	GroupCode    string  `json:"group_code"`
+	SubGroupCode string  `json:"subgroup_code"`
+	ProductCode  string  `json:"product_code"`
+	PricingType  string  `json:"pricing_type"`
+	PriceUnit    float64 `json:"price_unit"`
+	PriceWeight  float64 `json:"price_weight"`
+	Percent      float64 `json:"percent"`
+	Amount       float64 `json:"amount"`
+	MinQty       float64 `json:"min_qty"`
+	MinWeight    float64 `json:"min_weight"`
+}
+
+type CreatePriceAgreementRequest struct {
+	AgreementCode      string                      `json:"agreement_code"`
+	CompanyCode        string                      `json:"company_code"`
+	SiteCode           string                      `json:"site_code"`
+	CustomerCode       string                      `json:"customer_code"`
+	CustomerGroupCode  string                      `json:"customer_group_code"`
+	CustomerGroupValue string                      `json:"customer_group_value"`
+	ValidFrom          time.Time                   `json:"valid_from"`
+	ValidTo            *time.Time                  `json:"valid_to"`
+	Remark             string                      `json:"remark"`
+	Lines              []PriceAgreementLineRequest `json:"lines"`
+}
+
+// checkPriceAgreementLine rejects a line the resolver could not price.
+func checkPriceAgreementLine(line models.PriceAgreementLine) error {
+	if line.MinQty < 0 || line.MinWeight < 0 {
+		return errors.New("min_qty and min_weight cannot be negative")
+	}
+	switch line.PricingType {
+	case models.PriceAgreementFixed:
+		if line.PriceUnit < 0 || line.PriceWeight < 0 || line.PriceUnit+line.PriceWeight == 0 {
+			return errors.New("a FIXED line needs a positive price_unit or price_weight")
+		}
+	case models.PriceAgreementDiscount:
+		if line.Percent < 0 || line.Percent > 100 || line.Amount < 0 || line.Percent+line.Amount == 0 {
+			return errors.New("a DISCOUNT line needs a percent between 0 and 100 or a positive amount")
+		}
+	case models.PriceAgreementMarkup:
+		if line.Percent < 0 || line.Amount < 0 || line.Percent+line.Amount == 0 {
+			return errors.New("a MARKUP line needs a positive percent or amount")
+		}
+	default:
+		return fmt.Errorf("pricing_type must be %s, %s or %s, got %q",
+			models.PriceAgreementFixed, models.PriceAgreementDiscount, models.PriceAgreementMarkup, line.PricingType)
+	}
+	return nil
+}
+
+func CreatePriceAgreement(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req CreatePriceAgreementRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.AgreementCode))
+	switch {
+	case code == "":
+		return nil, errors.New("agreement_code is required")
+	case req.CompanyCode == "":
+		return nil, errors.New("company_code is required")
+	case req.CustomerCode == "" && (req.CustomerGroupCode == "" || req.CustomerGroupValue == ""):
+		return nil, errors.New("customer_code, or customer_group_code and customer_group_value, is required")
+	case req.CustomerCode != "" && req.CustomerGroupCode != "":
+		return nil, errors.New("an agreement is for a customer_code or a customer_group_code, not both")
+	case req.ValidFrom.IsZero():
+		return nil, errors.New("valid_from is required")
+	case req.ValidTo != nil && !req.ValidTo.After(req.ValidFrom):
+		return nil, errors.New("valid_to must be after valid_from")
+	case len(req.Lines) == 0:
+		return nil, errors.New("require at least one line")
+	}
+
+	user := middleware.GetUserCode(ctx)
+	now := time.Now().UTC()
+	agreement := models.PriceAgreement{
+		ID:                 uuid.New(),
+		AgreementCode:      code,
+		CompanyCode:        req.CompanyCode,
+		SiteCode:           req.SiteCode,
+		CustomerCode:       req.CustomerCode,
+		CustomerGroupCode:  req.CustomerGroupCode,
+		CustomerGroupValue: req.CustomerGroupValue,
+		ValidFrom:          req.ValidFrom,
+		ValidTo:            req.ValidTo,
+		Status:             models.PriceAgreementActive,
+		Remark:             req.Remark,
+		CreateBy:           user,
+		CreateDtm:          now,
+		UpdateBy:           user,
+		UpdateDtm:          now,
+	}
+	for i, l := range req.Lines {
+		line := models.PriceAgreementLine{
+			ID:           uuid.New(),
+			AgreementID:  agreement.ID,
+			GroupCode:    l.GroupCode,
+			SubGroupCode: l.SubGroupCode,
+			ProductCode:  l.ProductCode,
+			PricingType:  strings.ToUpper(strings.TrimSpace(l.PricingType)),
+			PriceUnit:    l.PriceUnit,
+			PriceWeight:  l.PriceWeight,
+			Percent:      l.Percent,
+			Amount:       l.Amount,
+			MinQty:       l.MinQty,
+			MinWeight:    l.MinWeight,
+			Seq:          i + 1,
+		}
+		if err := checkPriceAgreementLine(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		agreement.Lines = append(agreement.Lines, line)
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.PriceAgreement{}).Where("agreement_code = ?", code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return &utils.ConflictError{Code: "AGREEMENT_EXISTS", Message: fmt.Sprintf("price agreement %s already exists", code)}
+		}
+		if err := tx.Create(&agreement).Error; err != nil {
+			return fmt.Errorf("failed to create price agreement: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return agreement, nil
+}
+
+type GetPriceAgreementsRequest struct {
+	CompanyCode       string     `json:"company_code"`
+	AgreementCodes    []string   `json:"agreement_codes"`
+	CustomerCodes     []string   `json:"customer_codes"`
+	CustomerGroupCode string     `json:"customer_group_code"`
+	Status            []string   `json:"status"`
+	ValidAt           *time.Time `json:"valid_at"` // only agreements valid at this moment
+}
+
+func GetPriceAgreements(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req GetPriceAgreementsRequest
+	if jsonPayload != "" {
+		if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+			return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+		}
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Preload("Lines", func(tx *gorm.DB) *gorm.DB { return tx.Order("seq") }).
+		Order("agreement_code")
+	if req.CompanyCode != "" {
+		query = query.Where("company_code = ?", req.CompanyCode)
+	}
+	if len(req.AgreementCodes) > 0 {
+		query = query.Where("agreement_code IN ?", req.AgreementCodes)
+	}
+	if len(req.CustomerCodes) > 0 {
+		query = query.Where("customer_code IN ?", req.CustomerCodes)
+	}
+	if req.CustomerGroupCode != "" {
+		query = query.Where("customer_group_code = ?", req.CustomerGroupCode)
+	}
+	if len(req.Status) > 0 {
+		query = query.Where("status IN ?", req.Status)
+	}
+	if req.ValidAt != nil {
+		query = query.Where("valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", *req.ValidAt, *req.ValidAt)
+	}
+
+	agreements := []models.PriceAgreement{}
+	if err := query.Find(&agreements).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price agreements: %w", err)
+	}
+	return agreements, nil
+}
+
+type CancelPriceAgreementsRequest struct {
+	AgreementCodes []string `json:"agreement_codes"`
+}
+
+// CancelPriceAgreements ends agreements early. They are kept, so documents priced with them can still be explained.
+func CancelPriceAgreements(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req CancelPriceAgreementsRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.AgreementCodes) == 0 {
+		return nil, errors.New("agreement_codes is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	result := gormx.Model(&models.PriceAgreement{}).
+		Where("agreement_code IN ? AND status = ?", req.AgreementCodes, models.PriceAgreementActive).
+		Updates(map[string]interface{}{
+			"status":     models.PriceAgreementCanceled,
+			"update_by":  middleware.GetUserCode(ctx),
+			"update_dtm": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel price agreements: %w", result.Error)
+	}
+
+	return map[string]interface{}{
+		"canceled": result.RowsAffected,
+		"success":  true,
+		"message":  "Price agreements canceled successfully",
+	}, nil
+}
+
+// PriceAgreements are the agreements of one customer valid at one moment, ready to resolve prices with.
+type PriceAgreements struct {
+	CustomerCode string
+	Agreements   []models.PriceAgreement
+}
+
+// LoadPriceAgreements fetches the customer's own agreements and those of its customer groups valid at the given
+// moment. groups may be nil, then the customer master is asked, but only when group agreements exist.
+func LoadPriceAgreements(gormx *gorm.DB, companyCode, siteCode, customerCode string, groups []models.CustomerGroupRef, at time.Time) (PriceAgreements, error) {
+	result := PriceAgreements{CustomerCode: customerCode}
+	if customerCode == "" {
+		return result, nil
+	}
+
+	var agreements []models.PriceAgreement
+	if err := gormx.Preload("Lines", func(tx *gorm.DB) *gorm.DB { return tx.Order("seq") }).
+		Where("company_code = ? AND site_code IN ? AND status = ?", companyCode, []string{"", siteCode}, models.PriceAgreementActive).
+		Where("valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)", at, at).
+		Where("customer_code = ? OR (customer_code = '' AND customer_group_code <> '')", customerCode).
+		Find(&agreements).Error; err != nil {
+		return result, fmt.Errorf("failed to get price agreements: %w", err)
+	}
+
+	hasGroupAgreement := false
+	for _, a := range agreements {
+		if a.CustomerCode == "" {
+			hasGroupAgreement = true
+			break
+		}
+	}
+	if hasGroupAgreement && groups == nil {
+		var err error
+		if groups, err = getCustomerGroupsFunc(customerCode); err != nil {
+			return result, err
+		}
+	}
+
+	member := map[models.CustomerGroupRef]bool{}
+	for _, g := range groups {
+		member[g] = true
+	}
+	for _, a := range agreements {
+		if a.CustomerCode == customerCode || member[models.CustomerGroupRef{GroupCode: a.CustomerGroupCode, GroupValue: a.CustomerGroupValue}] {
+			result.Agreements = append(result.Agreements, a)
+		}
+	}
+	return result, nil
+}
+
+// PriceAgreementTarget is what is being priced: where it sits in the price list, how much of it, and the prices an
+// agreement line may be relative to. Empty codes only match lines that leave that target open.
+type PriceAgreementTarget struct {
+	GroupCode       string
+	SubGroupCode    string
+	ProductCode     string
+	Qty             float64
+	Weight          float64
+	ListPriceUnit   float64 // base of DISCOUNT lines
+	ListPriceWeight float64
+	CostUnit        float64 // base of MARKUP lines
+	CostWeight      float64
+}
+
+// lineSpecificity ranks a line matching the target by how narrowly it names it, or -1 when it does not match.
+func lineSpecificity(line models.PriceAgreementLine, target PriceAgreementTarget) int {
+	score := 0
+	for _, f := range []struct {
+		want, got string
+		weight    int
+	}{
+		{line.ProductCode, target.ProductCode, 4},
+		{line.SubGroupCode, target.SubGroupCode, 2},
+		{line.GroupCode, target.GroupCode, 1},
+	} {
+		if f.want == "" {
+			continue
+		}
+		if f.want != f.got {
+			return -1
+		}
+		score += f.weight
+	}
+	return score
+}
+
+// agreementLinePrice prices the target with a line; a price whose base is unknown stays 0.
+func agreementLinePrice(line models.PriceAgreementLine, target PriceAgreementTarget) (float64, float64) {
+	adjust := func(base, sign float64) float64 {
+		if base <= 0 {
+			return 0
+		}
+		return round2(base*(1+sign*line.Percent/100) + sign*line.Amount)
+	}
+	switch line.PricingType {
+	case models.PriceAgreementFixed:
+		return line.PriceUnit, line.PriceWeight
+	case models.PriceAgreementDiscount:
+		return adjust(target.ListPriceUnit, -1), adjust(target.ListPriceWeight, -1)
+	case models.PriceAgreementMarkup:
+		return adjust(target.CostUnit, 1), adjust(target.CostWeight, 1)
+	}
+	return 0, 0
+}
+
+// Resolve returns the agreement price of the target, or nil when no agreement covers it. A customer's own agreement
+// wins over a customer group's, then the line naming the target most narrowly, then the highest tier the quantity
+// and weight reach, then the agreement that started last.
+func (p PriceAgreements) Resolve(target PriceAgreementTarget) *models.AgreementPrice {
+	type candidate struct {
+		own         bool
+		specificity int
+		line        models.PriceAgreementLine
+		agreement   *models.PriceAgreement
+	}
+	better := func(a, b candidate) bool {
+		switch {
+		case a.own != b.own:
+			return a.own
+		case a.specificity != b.specificity:
+			return a.specificity > b.specificity
+		case a.line.MinQty != b.line.MinQty:
+			return a.line.MinQty > b.line.MinQty
+		case a.line.MinWeight != b.line.MinWeight:
+			return a.line.MinWeight > b.line.MinWeight
+		}
+		return a.agreement.ValidFrom.After(b.agreement.ValidFrom)
+	}
+
+	var best *candidate
+	var bestUnit, bestWeight float64
+	for i := range p.Agreements {
+		agreement := &p.Agreements[i]
+		for _, line := range agreement.Lines {
+			specificity := lineSpecificity(line, target)
+			if specificity < 0 || target.Qty < line.MinQty || target.Weight < line.MinWeight {
+				continue
+			}
+			unit, weight := agreementLinePrice(line, target)
+			if unit <= 0 && weight <= 0 {
+				continue
+			}
+			c := candidate{own: agreement.CustomerCode != "", specificity: specificity, line: line, agreement: agreement}
+			if best == nil || better(c, *best) {
+				best, bestUnit, bestWeight = &c, unit, weight
+			}
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	return &models.AgreementPrice{
+		AgreementID:   best.agreement.ID,
+		AgreementCode: best.agreement.AgreementCode,
+		LineID:        best.line.ID,
+		PricingType:   best.line.PricingType,
+		PriceUnit:     bestUnit,
+		PriceWeight:   bestWeight,
+		MinQty:        best.line.MinQty,
+		MinWeight:     best.line.MinWeight,
+	}
+}
@@ -0,0 +1,105 @@
+package priceService
+
+import (
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func agreement(code, customerCode string, validFrom time.Time, lines ...models.PriceAgreementLine) models.PriceAgreement {
+	a := models.PriceAgreement{ID: uuid.New(), AgreementCode: code, CustomerCode: customerCode, ValidFrom: validFrom}
+	if customerCode == "" {
+		a.CustomerGroupCode, a.CustomerGroupValue = "CG01", "DEALER"
+	}
+	for _, line := range lines {
+		line.ID = uuid.New()
+		a.Lines = append(a.Lines, line)
+	}
+	return a
+}
+
+func TestResolvePriceAgreement_Precedence(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := jan.AddDate(0, 1, 0)
+	target := PriceAgreementTarget{GroupCode: "G1", SubGroupCode: "G1-A", ProductCode: "P1", Qty: 50, ListPriceUnit: 100, CostUnit: 80}
+
+	fixed := func(price float64) models.PriceAgreementLine {
+		return models.PriceAgreementLine{PricingType: models.PriceAgreementFixed, PriceUnit: price}
+	}
+
+	t.Run("customer wins over group", func(t *testing.T) {
+		group := fixed(70)
+		group.ProductCode = "P1"
+		p := PriceAgreements{Agreements: []models.PriceAgreement{
+			agreement("GRP", "", feb, group),
+			agreement("OWN", "C1", jan, fixed(90)),
+		}}
+		got := p.Resolve(target)
+		require.NotNil(t, got)
+		assert.Equal(t, "OWN", got.AgreementCode)
+		assert.Equal(t, 90.0, got.PriceUnit)
+	})
+
+	t.Run("narrower target wins", func(t *testing.T) {
+		sub := fixed(85)
+		sub.SubGroupCode = "G1-A"
+		other := fixed(60)
+		other.ProductCode = "P2"
+		p := PriceAgreements{Agreements: []models.PriceAgreement{agreement("A1", "C1", jan, fixed(90), sub, other)}}
+		got := p.Resolve(target)
+		require.NotNil(t, got)
+		assert.Equal(t, 85.0, got.PriceUnit)
+	})
+
+	t.Run("highest tier reached wins", func(t *testing.T) {
+		tier10, tier100 := fixed(88), fixed(80)
+		tier10.MinQty, tier100.MinQty = 10, 100
+		p := PriceAgreements{Agreements: []models.PriceAgreement{agreement("A1", "C1", jan, fixed(90), tier10, tier100)}}
+		got := p.Resolve(target)
+		require.NotNil(t, got)
+		assert.Equal(t, 88.0, got.PriceUnit)
+		assert.Equal(t, 10.0, got.MinQty)
+	})
+
+	t.Run("later agreement wins a tie", func(t *testing.T) {
+		p := PriceAgreements{Agreements: []models.PriceAgreement{
+			agreement("NEW", "C1", feb, fixed(92)),
+			agreement("OLD", "C1", jan, fixed(90)),
+		}}
+		assert.Equal(t, "NEW", p.Resolve(target).AgreementCode)
+	})
+
+	t.Run("no matching line", func(t *testing.T) {
+		other := fixed(60)
+		other.GroupCode = "G2"
+		p := PriceAgreements{Agreements: []models.PriceAgreement{agreement("A1", "C1", jan, other)}}
+		assert.Nil(t, p.Resolve(target))
+	})
+}
+
+func TestResolvePriceAgreement_DiscountAndMarkup(t *testing.T) {
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	target := PriceAgreementTarget{ListPriceUnit: 100, ListPriceWeight: 40, CostUnit: 80}
+
+	discount := PriceAgreements{Agreements: []models.PriceAgreement{agreement("D", "C1", jan,
+		models.PriceAgreementLine{PricingType: models.PriceAgreementDiscount, Percent: 5, Amount: 1})}}
+	got := discount.Resolve(target)
+	require.NotNil(t, got)
+	assert.Equal(t, 94.0, got.PriceUnit)
+	assert.Equal(t, 37.0, got.PriceWeight)
+
+	markup := PriceAgreements{Agreements: []models.PriceAgreement{agreement("M", "C1", jan,
+		models.PriceAgreementLine{PricingType: models.PriceAgreementMarkup, Percent: 10})}}
+	got = markup.Resolve(target)
+	require.NotNil(t, got)
+	assert.Equal(t, 88.0, got.PriceUnit)
+	assert.Equal(t, 0.0, got.PriceWeight, "no cost per weight, no markup price per weight")
+
+	target.CostUnit = 0
+	assert.Nil(t, markup.Resolve(target))
+}
@@ -0,0 +1,388 @@
+package priceService
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// priceAsOf holds what is needed to turn live price rows into the rows valid at asOf: for a past moment the
+// history row that was replaced after it, for a future moment the staged versions due by then.
+type priceAsOf struct {
+	asOf             time.Time
+	groupHistory     map[string]models.PriceListGroupHistory
+	subGroupHistory  map[string]models.PriceListSubGroupHistory
+	groupVersions    map[uuid.UUID][]models.UpdatePriceListBaseRequest
+	subGroupVersions map[uuid.UUID][]models.UpdatePriceListSubGroupItem
+}
+
+func groupHistoryKey(companyCode, siteCode, groupCode string) string {
+	return companyCode + "|" + siteCode + "|" + groupCode
+}
+
+func subGroupHistoryKey(groupID uuid.UUID, subGroupKey string) string {
+	return groupID.String() + "|" + subGroupKey
+}
+
+func loadPriceAsOf(gormx *gorm.DB, asOf, now time.Time, groupIDs []uuid.UUID, groupCodes []string, subGroupIDs []uuid.UUID) (*priceAsOf, error) {
+	p := &priceAsOf{
+		asOf:             asOf,
+		groupHistory:     map[string]models.PriceListGroupHistory{},
+		subGroupHistory:  map[string]models.PriceListSubGroupHistory{},
+		groupVersions:    map[uuid.UUID][]models.UpdatePriceListBaseRequest{},
+		subGroupVersions: map[uuid.UUID][]models.UpdatePriceListSubGroupItem{},
+	}
+	if len(groupIDs) == 0 {
+		return p, nil
+	}
+
+	if !asOf.After(now) {
+		// The row live at asOf is the first one replaced after it
+		groupHistory := []models.PriceListGroupHistory{}
+		if err := gormx.Where("group_code IN ? AND expiry_date > ?", groupCodes, asOf).
+			Order("expiry_date").
+			Find(&groupHistory).Error; err != nil {
+			return nil, fmt.Errorf("failed to get price list group history: %w", err)
+		}
+		for _, h := range groupHistory {
+			key := groupHistoryKey(h.CompanyCode, h.SiteCode, h.GroupCode)
+			if _, exists := p.groupHistory[key]; !exists {
+				p.groupHistory[key] = h
+			}
+		}
+
+		subGroupHistory := []models.PriceListSubGroupHistory{}
+		if err := gormx.Where("price_list_group_id IN ? AND expiry_date > ?", groupIDs, asOf).
+			Order("expiry_date").
+			Find(&subGroupHistory).Error; err != nil {
+			return nil, fmt.Errorf("failed to get price list sub group history: %w", err)
+		}
+		for _, h := range subGroupHistory {
+			key := subGroupHistoryKey(h.PriceListGroupID, h.SubgroupKey)
+			if _, exists := p.subGroupHistory[key]; !exists {
+				p.subGroupHistory[key] = h
+			}
+		}
+
+		return p, nil
+	}
+
+	targetIDs := append(append([]uuid.UUID{}, groupIDs...), subGroupIDs...)
+	versions := []models.PriceListVersion{}
+	if err := gormx.Where("status = ? AND effective_date <= ? AND target_id IN ?", models.PriceVersionStaged, asOf, targetIDs).
+		Order("effective_date, create_dtm").
+		Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get staged price versions: %w", err)
+	}
+	for _, version := range versions {
+		effectiveDate := version.EffectiveDate
+		switch version.TargetType {
+		case models.PriceVersionTargetGroup:
+			var change models.UpdatePriceListBaseRequest
+			if err := json.Unmarshal(version.Changes, &change); err != nil {
+				return nil, fmt.Errorf("invalid group price version %s: %w", version.ID, err)
+			}
+			change.EffectiveDate = &effectiveDate
+			p.groupVersions[version.TargetID] = append(p.groupVersions[version.TargetID], change)
+		case models.PriceVersionTargetSubGroup:
+			var change models.UpdatePriceListSubGroupItem
+			if err := json.Unmarshal(version.Changes, &change); err != nil {
+				return nil, fmt.Errorf("invalid sub group price version %s: %w", version.ID, err)
+			}
+			change.EffectiveDate = &effectiveDate
+			p.subGroupVersions[version.TargetID] = append(p.subGroupVersions[version.TargetID], change)
+		}
+	}
+
+	return p, nil
+}
+
+// effectiveAt reports whether a row with this effective date is already valid at asOf.
+func effectiveAt(effectiveDate *time.Time, asOf time.Time) bool {
+	return effectiveDate == nil || !effectiveDate.After(asOf)
+}
+
+// group resolves a live group, given in history form, to its values at asOf. False means it was not yet valid.
+func (p *priceAsOf) group(id uuid.UUID, live models.PriceListGroupHistory) (models.PriceListGroupHistory, bool) {
+	resolved := live
+	if h, exists := p.groupHistory[groupHistoryKey(live.CompanyCode, live.SiteCode, live.GroupCode)]; exists {
+		resolved = h
+	}
+	for _, change := range p.groupVersions[id] {
+		resolved = applyGroupChange(resolved, change)
+	}
+
+	return resolved, effectiveAt(resolved.EffectiveDate, p.asOf)
+}
+
+// subGroup resolves a live sub group, given in history form, to its values at asOf. False means it was not yet valid.
+func (p *priceAsOf) subGroup(id uuid.UUID, live models.PriceListSubGroupHistory) (models.PriceListSubGroupHistory, bool) {
+	resolved := live
+	if h, exists := p.subGroupHistory[subGroupHistoryKey(live.PriceListGroupID, live.SubgroupKey)]; exists {
+		resolved = h
+	}
+	for _, change := range p.subGroupVersions[id] {
+		resolved = applySubGroupChange(resolved, change)
+	}
+
+	return resolved, effectiveAt(resolved.EffectiveDate, p.asOf)
+}
+
+// applyGroupChange mirrors what the repository writes when the change is activated.
+func applyGroupChange(g models.PriceListGroupHistory, change models.UpdatePriceListBaseRequest) models.PriceListGroupHistory {
+	g.BeforePriceUnit = g.PriceUnit
+	g.BeforePriceWeight = g.PriceWeight
+	g.PriceUnit = change.PriceUnit
+	g.PriceWeight = change.PriceWeight
+	g.Currency = change.Currency
+	g.EffectiveDate = change.EffectiveDate
+	g.Remark = change.Remark
+	return g
+}
+
+// applySubGroupChange mirrors what the repository writes when the change is activated.
+func applySubGroupChange(s models.PriceListSubGroupHistory, change models.UpdatePriceListSubGroupItem) models.PriceListSubGroupHistory {
+	if change.IsTrading != nil {
+		s.IsTrading = *change.IsTrading
+	}
+	if change.PriceUnit != nil {
+		s.BeforePriceUnit, s.PriceUnit = s.PriceUnit, *change.PriceUnit
+	}
+	if change.ExtraPriceUnit != nil {
+		s.BeforeExtraPriceUnit, s.ExtraPriceUnit = s.ExtraPriceUnit, *change.ExtraPriceUnit
+	}
+	if change.TotalNetPriceUnit != nil {
+		s.BeforeTotalNetPriceUnit, s.TotalNetPriceUnit = s.TotalNetPriceUnit, *change.TotalNetPriceUnit
+	}
+	if change.PriceWeight != nil {
+		s.BeforePriceWeight, s.PriceWeight = s.PriceWeight, *change.PriceWeight
+	}
+	if change.ExtraPriceWeight != nil {
+		s.BeforeExtraPriceWeight, s.ExtraPriceWeight = s.ExtraPriceWeight, *change.ExtraPriceWeight
+	}
+	if change.TermPriceWeight != nil {
+		s.BeforeTermPriceWeight, s.TermPriceWeight = s.TermPriceWeight, *change.TermPriceWeight
+	}
+	if change.TotalNetPriceWeight != nil {
+		s.BeforeTotalNetPriceWeight, s.TotalNetPriceWeight = s.TotalNetPriceWeight, *change.TotalNetPriceWeight
+	}
+	if change.EffectiveDate != nil {
+		s.EffectiveDate = change.EffectiveDate
+	}
+	if change.Remark != nil {
+		s.Remark = *change.Remark
+	}
+	return s
+}
+
+// resolvePriceListGroupsAsOf replaces the live values of groups with those valid at asOf, dropping groups and
+// sub groups that were not valid yet.
+func resolvePriceListGroupsAsOf(gormx *gorm.DB, asOf time.Time, groups []models.PriceListGroup) ([]models.PriceListGroup, error) {
+	groupIDs, groupCodes, subGroupIDs := []uuid.UUID{}, []string{}, []uuid.UUID{}
+	for _, g := range groups {
+		groupIDs = append(groupIDs, g.ID)
+		groupCodes = append(groupCodes, g.GroupCode)
+		for _, sg := range g.PriceListSubGroups {
+			subGroupIDs = append(subGroupIDs, sg.ID)
+		}
+	}
+
+	p, err := loadPriceAsOf(gormx, asOf, time.Now().UTC(), groupIDs, groupCodes, subGroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []models.PriceListGroup{}
+	for _, g := range groups {
+		resolved, ok := p.group(g.ID, models.PriceListGroupHistory{
+			CompanyCode:       g.CompanyCode,
+			SiteCode:          g.SiteCode,
+			GroupCode:         g.GroupCode,
+			PriceUnit:         g.PriceUnit,
+			PriceWeight:       g.PriceWeight,
+			BeforePriceUnit:   g.BeforePriceUnit,
+			BeforePriceWeight: g.BeforePriceWeight,
+			Currency:          g.Currency,
+			EffectiveDate:     g.EffectiveDate,
+			Remark:            g.Remark,
+		})
+		if !ok {
+			continue
+		}
+		g.PriceUnit = resolved.PriceUnit
+		g.PriceWeight = resolved.PriceWeight
+		g.BeforePriceUnit = resolved.BeforePriceUnit
+		g.BeforePriceWeight = resolved.BeforePriceWeight
+		g.Currency = resolved.Currency
+		g.EffectiveDate = resolved.EffectiveDate
+		g.Remark = resolved.Remark
+
+		subGroups := []models.PriceListSubGroup{}
+		for _, sg := range g.PriceListSubGroups {
+			resolvedSub, ok := p.subGroup(sg.ID, models.PriceListSubGroupHistory{
+				PriceListGroupID:          sg.PriceListGroupID,
+				SubgroupKey:               sg.SubgroupKey,
+				IsTrading:                 sg.IsTrading,
+				PriceUnit:                 sg.PriceUnit,
+				ExtraPriceUnit:            sg.ExtraPriceUnit,
+				TotalNetPriceUnit:         sg.TotalNetPriceUnit,
+				PriceWeight:               sg.PriceWeight,
+				ExtraPriceWeight:          sg.ExtraPriceWeight,
+				TermPriceWeight:           sg.TermPriceWeight,
+				TotalNetPriceWeight:       sg.TotalNetPriceWeight,
+				BeforePriceUnit:           sg.BeforePriceUnit,
+				BeforeExtraPriceUnit:      sg.BeforeExtraPriceUnit,
+				BeforeTermPriceUnit:       sg.BeforeTermPriceUnit,
+				BeforeTotalNetPriceUnit:   sg.BeforeTotalNetPriceUnit,
+				BeforePriceWeight:         sg.BeforePriceWeight,
+				BeforeExtraPriceWeight:    sg.BeforeExtraPriceWeight,
+				BeforeTermPriceWeight:     sg.BeforeTermPriceWeight,
+				BeforeTotalNetPriceWeight: sg.BeforeTotalNetPriceWeight,
+				EffectiveDate:             sg.EffectiveDate,
+				Remark:                    sg.Remark,
+			})
+			if !ok {
+				continue
+			}
+			sg.IsTrading = resolvedSub.IsTrading
+			sg.PriceUnit = resolvedSub.PriceUnit
+			sg.ExtraPriceUnit = resolvedSub.ExtraPriceUnit
+			sg.TotalNetPriceUnit = resolvedSub.TotalNetPriceUnit
+			sg.PriceWeight = resolvedSub.PriceWeight
+			sg.ExtraPriceWeight = resolvedSub.ExtraPriceWeight
+			sg.TermPriceWeight = resolvedSub.TermPriceWeight
+			sg.TotalNetPriceWeight = resolvedSub.TotalNetPriceWeight
+			sg.BeforePriceUnit = resolvedSub.BeforePriceUnit
+			sg.BeforeExtraPriceUnit = resolvedSub.BeforeExtraPriceUnit
+			sg.BeforeTermPriceUnit = resolvedSub.BeforeTermPriceUnit
+			sg.BeforeTotalNetPriceUnit = resolvedSub.BeforeTotalNetPriceUnit
+			sg.BeforePriceWeight = resolvedSub.BeforePriceWeight
+			sg.BeforeExtraPriceWeight = resolvedSub.BeforeExtraPriceWeight
+			sg.BeforeTermPriceWeight = resolvedSub.BeforeTermPriceWeight
+			sg.BeforeTotalNetPriceWeight = resolvedSub.BeforeTotalNetPriceWeight
+			sg.EffectiveDate = resolvedSub.EffectiveDate
+			sg.Remark = resolvedSub.Remark
+			subGroups = append(subGroups, sg)
+		}
+		g.PriceListSubGroups = subGroups
+
+		res = append(res, g)
+	}
+
+	return res, nil
+}
+
+// resolveGroupSubGroupAsOf is resolvePriceListGroupsAsOf for the rows read by getGroupSubGroup.
+func resolveGroupSubGroupAsOf(gormx *gorm.DB, asOf time.Time, groups []GetPriceListGroupResponse) ([]GetPriceListGroupResponse, error) {
+	groupIDs, groupCodes, subGroupIDs := []uuid.UUID{}, []string{}, []uuid.UUID{}
+	for _, g := range groups {
+		groupIDs = append(groupIDs, g.ID)
+		groupCodes = append(groupCodes, g.GroupCode)
+		for _, sg := range g.SubGroups {
+			subGroupIDs = append(subGroupIDs, sg.ID)
+		}
+	}
+
+	p, err := loadPriceAsOf(gormx, asOf, time.Now().UTC(), groupIDs, groupCodes, subGroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []GetPriceListGroupResponse{}
+	for _, g := range groups {
+		resolved, ok := p.group(g.ID, models.PriceListGroupHistory{
+			CompanyCode:       g.CompanyCode,
+			SiteCode:          g.SiteCode,
+			GroupCode:         g.GroupCode,
+			PriceUnit:         g.PriceUnit,
+			PriceWeight:       g.PriceWeight,
+			BeforePriceUnit:   g.BeforePriceUnit,
+			BeforePriceWeight: g.BeforePriceWeight,
+			Currency:          g.Currency,
+			EffectiveDate:     optionalTime(g.EffectiveDate),
+			Remark:            g.Remark,
+		})
+		if !ok {
+			continue
+		}
+		g.PriceUnit = resolved.PriceUnit
+		g.PriceWeight = resolved.PriceWeight
+		g.BeforePriceUnit = resolved.BeforePriceUnit
+		g.BeforePriceWeight = resolved.BeforePriceWeight
+		g.Currency = resolved.Currency
+		g.EffectiveDate = valueTime(resolved.EffectiveDate)
+		g.Remark = resolved.Remark
+
+		subGroups := []SubGroup{}
+		for _, sg := range g.SubGroups {
+			resolvedSub, ok := p.subGroup(sg.ID, models.PriceListSubGroupHistory{
+				PriceListGroupID:          g.ID,
+				SubgroupKey:               sg.SubGroupKey,
+				IsTrading:                 sg.IsTrading,
+				PriceUnit:                 sg.PriceUnit,
+				ExtraPriceUnit:            sg.ExtraPriceUnit,
+				TotalNetPriceUnit:         sg.TotalNetPriceUnit,
+				PriceWeight:               sg.PriceWeight,
+				ExtraPriceWeight:          sg.ExtraPriceWeight,
+				TermPriceWeight:           sg.TermPriceWeight,
+				TotalNetPriceWeight:       sg.TotalNetPriceWeight,
+				BeforePriceUnit:           sg.BeforePriceUnit,
+				BeforeExtraPriceUnit:      sg.BeforeExtraPriceUnit,
+				BeforeTermPriceUnit:       sg.BeforeTermPriceUnit,
+				BeforeTotalNetPriceUnit:   sg.BeforeTotalNetPriceUnit,
+				BeforePriceWeight:         sg.BeforePriceWeight,
+				BeforeExtraPriceWeight:    sg.BeforeExtraPriceWeight,
+				BeforeTermPriceWeight:     sg.BeforeTermPriceWeight,
+				BeforeTotalNetPriceWeight: sg.BeforeTotalNetPriceWeight,
+				EffectiveDate:             optionalTime(sg.EffectiveDate),
+				Remark:                    sg.Remark,
+			})
+			if !ok {
+				continue
+			}
+			sg.IsTrading = resolvedSub.IsTrading
+			sg.PriceUnit = resolvedSub.PriceUnit
+			sg.ExtraPriceUnit = resolvedSub.ExtraPriceUnit
+			sg.TotalNetPriceUnit = resolvedSub.TotalNetPriceUnit
+			sg.PriceWeight = resolvedSub.PriceWeight
+			sg.ExtraPriceWeight = resolvedSub.ExtraPriceWeight
+			sg.TermPriceWeight = resolvedSub.TermPriceWeight
+			sg.TotalNetPriceWeight = resolvedSub.TotalNetPriceWeight
+			sg.BeforePriceUnit = resolvedSub.BeforePriceUnit
+			sg.BeforeExtraPriceUnit = resolvedSub.BeforeExtraPriceUnit
+			sg.BeforeTermPriceUnit = resolvedSub.BeforeTermPriceUnit
+			sg.BeforeTotalNetPriceUnit = resolvedSub.BeforeTotalNetPriceUnit
+			sg.BeforePriceWeight = resolvedSub.BeforePriceWeight
+			sg.BeforeExtraPriceWeight = resolvedSub.BeforeExtraPriceWeight
+			sg.BeforeTermPriceWeight = resolvedSub.BeforeTermPriceWeight
+			sg.BeforeTotalNetPriceWeight = resolvedSub.BeforeTotalNetPriceWeight
+			sg.EffectiveDate = valueTime(resolvedSub.EffectiveDate)
+			sg.Remark = resolvedSub.Remark
+			subGroups = append(subGroups, sg)
+		}
+		g.SubGroups = subGroups
+
+		res = append(res, g)
+	}
+
+	return res, nil
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func valueTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,243 @@
+package priceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	priceListRepository "prime-erp-core/internal/repositories/priceList"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// seam for unit testing: allow stubbing the break table lookup
+var getPriceListTiersFunc = priceListRepository.GetPriceListTiers
+
+// PriceTierTables are break tables by company, group and sub group.
+type PriceTierTables map[string][]models.PriceListTier
+
+func priceTierKey(companyCode, groupCode, subGroupCode string) string {
+	return companyCode + "|" + groupCode + "|" + subGroupCode
+}
+
+func priceTierKeyOf(tier models.PriceListTier) string {
+	return priceTierKey(tier.CompanyCode, tier.GroupCode, tier.SubGroupCode)
+}
+
+// LoadPriceTiers fetches, in one query, the break tables of the groups and of their sub groups.
+func LoadPriceTiers(companyCodes, groupCodes []string) (PriceTierTables, error) {
+	tables := PriceTierTables{}
+	if len(groupCodes) == 0 {
+		return tables, nil
+	}
+
+	tiers, err := getPriceListTiersFunc(companyCodes, groupCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price tiers: %w", err)
+	}
+	for _, tier := range tiers {
+		key := priceTierKeyOf(tier)
+		tables[key] = append(tables[key], tier)
+	}
+	for _, table := range tables {
+		sort.SliceStable(table, func(i, j int) bool { return table[i].MinValue < table[j].MinValue })
+	}
+	return tables, nil
+}
+
+// For returns the break table of a sub group: its own, or else its group's.
+func (t PriceTierTables) For(companyCode, groupCode, subGroupCode string) []models.PriceListTier {
+	if groupCode == "" {
+		return nil
+	}
+	if subGroupCode != "" {
+		if table, ok := t[priceTierKey(companyCode, groupCode, subGroupCode)]; ok {
+			return table
+		}
+	}
+	return t[priceTierKey(companyCode, groupCode, "")]
+}
+
+// pickPriceTier returns the tier a quantity and weight fall in, or nil when they are below the first break.
+func pickPriceTier(tiers []models.PriceListTier, qty, weight float64) *models.PriceListTier {
+	for i := range tiers {
+		value := qty
+		if tiers[i].Basis == models.PriceTierWeight {
+			value = weight
+		}
+		if value >= tiers[i].MinValue && (tiers[i].MaxValue == nil || value < *tiers[i].MaxValue) {
+			return &tiers[i]
+		}
+	}
+	return nil
+}
+
+// applyPriceTier takes the tier's discount off a price per piece, or per kg when perWeight.
+func applyPriceTier(price float64, tier *models.PriceListTier, perWeight bool) float64 {
+	if tier == nil || price <= 0 {
+		return price
+	}
+	amount := tier.DiscountUnit
+	if perWeight {
+		amount = tier.DiscountWeight
+	}
+	return math.Max(round2(price*(1-tier.DiscountPercent/100)-amount), 0)
+}
+
+// priceTierPrices prices a sub group in every tier of its break table, marking the one qty and weight fall in.
+func priceTierPrices(tiers []models.PriceListTier, priceUnit, priceWeight, qty, weight float64) []models.PriceTierPrice {
+	applied := pickPriceTier(tiers, qty, weight)
+	prices := make([]models.PriceTierPrice, 0, len(tiers))
+	for i := range tiers {
+		tier := &tiers[i]
+		prices = append(prices, models.PriceTierPrice{
+			TierID:      tier.ID,
+			Basis:       tier.Basis,
+			MinValue:    tier.MinValue,
+			MaxValue:    tier.MaxValue,
+			PriceUnit:   applyPriceTier(priceUnit, tier, false),
+			PriceWeight: applyPriceTier(priceWeight, tier, true),
+			Applied:     tier == applied,
+		})
+	}
+	return prices
+}
+
+type PriceTierRequest struct {
+	MinValue        float64  `json:"min_value"`
+	MaxValue        *float64 `json:"max_value"`
+	DiscountPercent float64  `json:"discount_percent"`
+	DiscountUnit    float64  `json:"discount_unit"`
+	DiscountWeight  float64  `json:"discount_weight"`
+}
+
+type SavePriceTiersRequest struct {
+	CompanyCode  string             `json:"company_code"`
+	GroupCode    string             `json:"group_code"`
+	SubGroupCode string             `json:"subgroup_code"` // empty for the group's table
+	Basis        string             `json:"basis"`
+	Tiers        []PriceTierRequest `json:"tiers"` // empty removes the table
+}
+
+// checkPriceTiers rejects a break table whose tiers are out of order or overlap.
+func checkPriceTiers(basis string, tiers []PriceTierRequest) error {
+	if basis != models.PriceTierQty && basis != models.PriceTierWeight {
+		return fmt.Errorf("basis must be %s or %s, got %q", models.PriceTierQty, models.PriceTierWeight, basis)
+	}
+	for i, tier := range tiers {
+		switch {
+		case tier.MinValue < 0:
+			return fmt.Errorf("tier %d: min_value cannot be negative", i+1)
+		case tier.MaxValue != nil && *tier.MaxValue <= tier.MinValue:
+			return fmt.Errorf("tier %d: max_value must be above min_value", i+1)
+		case tier.DiscountPercent < 0 || tier.DiscountPercent > 100:
+			return fmt.Errorf("tier %d: discount_percent must be between 0 and 100", i+1)
+		case tier.DiscountUnit < 0 || tier.DiscountWeight < 0:
+			return fmt.Errorf("tier %d: discount_unit and discount_weight cannot be negative", i+1)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := tiers[i-1]
+		if tier.MinValue <= prev.MinValue {
+			return fmt.Errorf("tier %d: tiers must be in ascending min_value", i+1)
+		}
+		if prev.MaxValue == nil || *prev.MaxValue > tier.MinValue {
+			return fmt.Errorf("tier %d overlaps tier %d", i+1, i)
+		}
+	}
+	return nil
+}
+
+// SavePriceTiers replaces the break table of a group or sub group.
+func SavePriceTiers(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req SavePriceTiersRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	req.Basis = strings.ToUpper(strings.TrimSpace(req.Basis))
+	if req.CompanyCode == "" || req.GroupCode == "" {
+		return nil, errors.New("company_code and group_code are required")
+	}
+	if len(req.Tiers) > 0 {
+		if err := checkPriceTiers(req.Basis, req.Tiers); err != nil {
+			return nil, err
+		}
+	}
+
+	user := middleware.GetUserCode(ctx)
+	now := time.Now().UTC()
+	tiers := make([]models.PriceListTier, 0, len(req.Tiers))
+	for _, t := range req.Tiers {
+		tiers = append(tiers, models.PriceListTier{
+			ID:              uuid.New(),
+			CompanyCode:     req.CompanyCode,
+			GroupCode:       req.GroupCode,
+			SubGroupCode:    req.SubGroupCode,
+			Basis:           req.Basis,
+			MinValue:        t.MinValue,
+			MaxValue:        t.MaxValue,
+			DiscountPercent: t.DiscountPercent,
+			DiscountUnit:    t.DiscountUnit,
+			DiscountWeight:  t.DiscountWeight,
+			CreateBy:        user,
+			CreateDtm:       now,
+			UpdateBy:        user,
+			UpdateDtm:       now,
+		})
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("company_code = ? AND group_code = ? AND subgroup_code = ?", req.CompanyCode, req.GroupCode, req.SubGroupCode).
+			Delete(&models.PriceListTier{}).Error; err != nil {
+			return fmt.Errorf("failed to delete price tiers: %w", err)
+		}
+		if len(tiers) == 0 {
+			return nil
+		}
+		if err := tx.Create(&tiers).Error; err != nil {
+			return fmt.Errorf("failed to create price tiers: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tiers, nil
+}
+
+type GetPriceTiersRequest struct {
+	CompanyCode string   `json:"company_code"`
+	GroupCodes  []string `json:"group_codes"`
+}
+
+func GetPriceTiers(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req GetPriceTiersRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.GroupCodes) == 0 {
+		return nil, errors.New("group_codes is required")
+	}
+
+	companyCodes := []string{}
+	if req.CompanyCode != "" {
+		companyCodes = append(companyCodes, req.CompanyCode)
+	}
+	return getPriceListTiersFunc(companyCodes, req.GroupCodes)
+}
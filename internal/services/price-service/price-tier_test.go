@@ -0,0 +1,82 @@
+package priceService
+
+import (
+	"testing"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func weightTiers() []models.PriceListTier {
+	five, twenty := 5000.0, 20000.0
+	return []models.PriceListTier{
+		{ID: uuid.New(), CompanyCode: "C1", GroupCode: "G1", Basis: models.PriceTierWeight, MinValue: 0, MaxValue: &five},
+		{ID: uuid.New(), CompanyCode: "C1", GroupCode: "G1", Basis: models.PriceTierWeight, MinValue: 5000, MaxValue: &twenty, DiscountWeight: 1},
+		{ID: uuid.New(), CompanyCode: "C1", GroupCode: "G1", Basis: models.PriceTierWeight, MinValue: 20000, DiscountPercent: 5},
+	}
+}
+
+func TestComparePrice_TierFromWholeOrder(t *testing.T) {
+	tiers := weightTiers()
+	item := func(ref string, weight float64) ItemComparePrice {
+		return ItemComparePrice{
+			RefItem: ref, Qty: 1, TotalWeight: weight, SaleUnit: "KG",
+			PriceListUnit: 30, TotalAmount: 29 * weight, PriceTiers: tiers,
+		}
+	}
+
+	// Each item alone is in the first tier, together they reach 5-20 t
+	res, err := ComparePrice(GetComparePriceRequest{
+		UnitCode: "PC", UnitCodeWeight: "KG", TransportType: "EXCL",
+		Items: []ItemComparePrice{item("1", 3000), item("2", 4000)},
+	})
+	require.NoError(t, err)
+	for _, it := range res.Items {
+		require.NotNil(t, it.PriceTier)
+		assert.Equal(t, 5000.0, it.PriceTier.MinValue)
+		assert.Equal(t, 29.0, it.TierPriceList)
+		assert.True(t, it.IsPassPriceUnit)
+	}
+	assert.True(t, res.IsPassPriceUnitAll)
+
+	// Without tiers the list price is the floor
+	plain := item("1", 3000)
+	plain.PriceTiers = nil
+	res, err = ComparePrice(GetComparePriceRequest{
+		UnitCode: "PC", UnitCodeWeight: "KG", TransportType: "EXCL", Items: []ItemComparePrice{plain},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, res.Items[0].PriceTier)
+	assert.Equal(t, 30.0, res.Items[0].TierPriceList)
+	assert.False(t, res.IsPassPriceUnitAll)
+}
+
+func TestPriceTierTables_For(t *testing.T) {
+	sub := models.PriceListTier{CompanyCode: "C1", GroupCode: "G1", SubGroupCode: "G1-A", Basis: models.PriceTierQty}
+	tables := PriceTierTables{
+		priceTierKey("C1", "G1", ""):     weightTiers(),
+		priceTierKey("C1", "G1", "G1-A"): {sub},
+	}
+
+	assert.Equal(t, []models.PriceListTier{sub}, tables.For("C1", "G1", "G1-A"))
+	assert.Len(t, tables.For("C1", "G1", "G1-B"), 3, "a sub group without a table uses its group's")
+	assert.Empty(t, tables.For("C1", "", ""))
+
+	prices := priceTierPrices(weightTiers(), 0, 30, 0, 25000)
+	require.Len(t, prices, 3)
+	assert.Equal(t, []float64{30, 29, 28.5}, []float64{prices[0].PriceWeight, prices[1].PriceWeight, prices[2].PriceWeight})
+	assert.True(t, prices[2].Applied)
+	assert.False(t, prices[0].Applied)
+}
+
+func TestCheckPriceTiers(t *testing.T) {
+	five, ten := 5.0, 10.0
+	assert.NoError(t, checkPriceTiers(models.PriceTierQty, []PriceTierRequest{{MinValue: 0, MaxValue: &five}, {MinValue: 5}}))
+	assert.Error(t, checkPriceTiers("TON", []PriceTierRequest{{MinValue: 0}}))
+	assert.ErrorContains(t, checkPriceTiers(models.PriceTierQty, []PriceTierRequest{{MinValue: 0, MaxValue: &ten}, {MinValue: 5}}), "overlaps")
+	assert.ErrorContains(t, checkPriceTiers(models.PriceTierQty, []PriceTierRequest{{MinValue: 0}, {MinValue: 5}}), "overlaps")
+	assert.ErrorContains(t, checkPriceTiers(models.PriceTierQty, []PriceTierRequest{{MinValue: 0, DiscountPercent: 120}}), "discount_percent")
+}
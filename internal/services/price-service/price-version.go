@@ -0,0 +1,278 @@
+package priceService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"prime-erp-core/internal/cronjob"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	priceListRepository "prime-erp-core/internal/repositories/priceList"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seam for unit testing: allow stubbing version persistence
+var stagePriceVersionsFunc = stagePriceVersions
+
+var errPriceVersionTaken = errors.New("price version is no longer staged")
+
+func init() {
+	cronjob.RegisterJob("price-version-activation", runPriceVersionActivation, "*/15 * * * *")
+}
+
+// isFutureEffective says whether a change with this effective date must be staged rather than applied now.
+func isFutureEffective(effectiveDate *time.Time, now time.Time) bool {
+	return effectiveDate != nil && effectiveDate.After(now)
+}
+
+func newPriceListVersion(targetType string, targetID uuid.UUID, effectiveDate time.Time, changes interface{}, user string) (models.PriceListVersion, error) {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return models.PriceListVersion{}, fmt.Errorf("failed to marshal price version: %w", err)
+	}
+
+	return models.PriceListVersion{
+		ID:            uuid.New(),
+		TargetType:    targetType,
+		TargetID:      targetID,
+		EffectiveDate: effectiveDate,
+		Changes:       changesJSON,
+		Status:        models.PriceVersionStaged,
+		CreateBy:      user,
+		UpdateBy:      user,
+	}, nil
+}
+
+func priceVersionIDs(versions []models.PriceListVersion) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(versions))
+	for _, version := range versions {
+		ids = append(ids, version.ID)
+	}
+	return ids
+}
+
+func stagePriceVersions(ctx *gin.Context, versions []models.PriceListVersion) error {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return err
+	}
+
+	// Staging against a missing group or sub group would only fail later, at activation
+	for _, version := range versions {
+		var count int64
+		target := gormx.Model(&models.PriceListGroup{})
+		if version.TargetType == models.PriceVersionTargetSubGroup {
+			target = gormx.Model(&models.PriceListSubGroup{})
+		}
+		if err := target.Where("id = ?", version.TargetID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("price list %s %s not found", version.TargetType, version.TargetID)
+		}
+	}
+
+	return gormx.Create(&versions).Error
+}
+
+type ActivatePriceVersionsResponse struct {
+	Activated []uuid.UUID `json:"activated"`
+	Failed    []uuid.UUID `json:"failed"`
+}
+
+// ActivatePriceVersions runs the activation job on demand.
+func ActivatePriceVersions(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	return activatePriceVersions(gormx, time.Now().UTC())
+}
+
+func runPriceVersionActivation() {
+	gormx, err := db.DefaultRegistry().GORM(`prime_erp`)
+	if err != nil {
+		log.Printf("price version activation: %v\n", err)
+		return
+	}
+
+	res, err := activatePriceVersions(gormx, time.Now().UTC())
+	if err != nil {
+		log.Printf("price version activation: %v\n", err)
+		return
+	}
+	if len(res.Activated)+len(res.Failed) > 0 {
+		log.Printf("price version activation: activated %v, failed %v\n", res.Activated, res.Failed)
+	}
+}
+
+// activatePriceVersions applies every staged version that has reached its effective date, oldest first, so the last
+// version staged for a date wins. The replaced live values go to history expiring at the version's effective date.
+func activatePriceVersions(gormx *gorm.DB, now time.Time) (ActivatePriceVersionsResponse, error) {
+	res := ActivatePriceVersionsResponse{Activated: []uuid.UUID{}, Failed: []uuid.UUID{}}
+
+	var due []models.PriceListVersion
+	if err := gormx.Where("status = ? AND effective_date <= ?", models.PriceVersionStaged, now).
+		Order("effective_date, create_dtm").
+		Find(&due).Error; err != nil {
+		return res, fmt.Errorf("failed to get staged price versions: %w", err)
+	}
+
+	for _, version := range due {
+		err := gormx.Transaction(func(tx *gorm.DB) error {
+			var locked models.PriceListVersion
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ?", version.ID, models.PriceVersionStaged).
+				Take(&locked).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errPriceVersionTaken
+				}
+				return err
+			}
+
+			if err := applyPriceListVersion(tx, locked, now); err != nil {
+				return err
+			}
+
+			return tx.Model(&models.PriceListVersion{}).Where("id = ?", locked.ID).Updates(map[string]interface{}{
+				"status":        models.PriceVersionActive,
+				"activated_dtm": now,
+				"last_error":    "",
+			}).Error
+		})
+		if errors.Is(err, errPriceVersionTaken) {
+			continue
+		}
+		if err != nil {
+			log.Printf("price version activation: %s: %v\n", version.ID, err)
+			if markErr := gormx.Model(&models.PriceListVersion{}).Where("id = ?", version.ID).Updates(map[string]interface{}{
+				"status":     models.PriceVersionFailed,
+				"last_error": err.Error(),
+			}).Error; markErr != nil {
+				log.Printf("price version activation: %s: %v\n", version.ID, markErr)
+			}
+			res.Failed = append(res.Failed, version.ID)
+			continue
+		}
+		res.Activated = append(res.Activated, version.ID)
+	}
+
+	return res, nil
+}
+
+func applyPriceListVersion(tx *gorm.DB, version models.PriceListVersion, now time.Time) error {
+	effectiveDate := version.EffectiveDate
+
+	switch version.TargetType {
+	case models.PriceVersionTargetGroup:
+		var req models.UpdatePriceListBaseRequest
+		if err := json.Unmarshal(version.Changes, &req); err != nil {
+			return fmt.Errorf("invalid group price version: %w", err)
+		}
+		req.ID = version.TargetID
+		req.EffectiveDate = &effectiveDate
+
+		group := priceListGroupFromRequest(req, version.CreateBy, now)
+		return priceListRepository.UpdatePriceListBaseTx(tx, []models.PriceListGroup{group}, effectiveDate)
+	case models.PriceVersionTargetSubGroup:
+		var item models.UpdatePriceListSubGroupItem
+		if err := json.Unmarshal(version.Changes, &item); err != nil {
+			return fmt.Errorf("invalid sub group price version: %w", err)
+		}
+		item.SubGroupID = version.TargetID
+		item.EffectiveDate = &effectiveDate
+
+		return priceListRepository.UpdatePriceListSubGroupsTx(tx, models.UpdatePriceListSubGroupRequest{
+			Changes:  []models.UpdatePriceListSubGroupItem{item},
+			UpdateBy: version.CreateBy,
+		}, effectiveDate)
+	default:
+		return fmt.Errorf("unknown price version target %s", version.TargetType)
+	}
+}
+
+type GetPriceVersionsRequest struct {
+	TargetType string      `json:"target_type"`
+	TargetID   []uuid.UUID `json:"target_id"`
+	Status     []string    `json:"status"` // defaults to STAGED
+}
+
+// GetPriceVersions lists price versions by effective date, staged ones unless a status is given.
+func GetPriceVersions(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req GetPriceVersionsRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.Status) == 0 {
+		req.Status = []string{models.PriceVersionStaged}
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Where("status IN ?", req.Status)
+	if req.TargetType != "" {
+		query = query.Where("target_type = ?", req.TargetType)
+	}
+	if len(req.TargetID) > 0 {
+		query = query.Where("target_id IN ?", req.TargetID)
+	}
+
+	versions := []models.PriceListVersion{}
+	if err := query.Order("effective_date, create_dtm").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get price versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+type CancelPriceVersionsRequest struct {
+	ID []uuid.UUID `json:"id"`
+}
+
+// CancelPriceVersions withdraws staged versions that have not been activated yet.
+func CancelPriceVersions(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	var req CancelPriceVersionsRequest
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if len(req.ID) == 0 {
+		return nil, errors.New("id is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	result := gormx.Model(&models.PriceListVersion{}).
+		Where("id IN ? AND status = ?", req.ID, models.PriceVersionStaged).
+		Updates(map[string]interface{}{
+			"status":    models.PriceVersionCanceled,
+			"update_by": middleware.GetUserCode(ctx),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel price versions: %w", result.Error)
+	}
+
+	return map[string]interface{}{
+		"canceled": result.RowsAffected,
+		"success":  true,
+		"message":  "Price versions canceled successfully",
+	}, nil
+}
@@ -0,0 +1,111 @@
+package priceService
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdatePriceListSubGroup_StagesFutureChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var applied models.UpdatePriceListSubGroupRequest
+	originalUpdate := updateSubGroupFunc
+	updateSubGroupFunc = func(req models.UpdatePriceListSubGroupRequest) error {
+		applied = req
+		return nil
+	}
+	defer func() { updateSubGroupFunc = originalUpdate }()
+
+	var staged []models.PriceListVersion
+	originalStage := stagePriceVersionsFunc
+	stagePriceVersionsFunc = func(ctx *gin.Context, versions []models.PriceListVersion) error {
+		staged = versions
+		return nil
+	}
+	defer func() { stagePriceVersionsFunc = originalStage }()
+
+	nowID, laterID := uuid.New(), uuid.New()
+	later := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+	payload := map[string]interface{}{
+		"changes": []map[string]interface{}{
+			{"subgroup_id": nowID.String(), "price_unit": 10.0},
+			{"subgroup_id": laterID.String(), "price_unit": 12.0, "effective_date": later},
+		},
+	}
+	body, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/price/UpdatePriceListSubGroup", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	resp, err := UpdatePriceListSubGroup(c)
+	assert.NoError(t, err)
+
+	if assert.Len(t, applied.Changes, 1) {
+		assert.Equal(t, nowID, applied.Changes[0].SubGroupID)
+	}
+	if assert.Len(t, staged, 1) {
+		assert.Equal(t, models.PriceVersionTargetSubGroup, staged[0].TargetType)
+		assert.Equal(t, laterID, staged[0].TargetID)
+		assert.True(t, later.Equal(staged[0].EffectiveDate))
+		assert.Equal(t, models.PriceVersionStaged, staged[0].Status)
+		assert.Equal(t, []uuid.UUID{staged[0].ID}, resp.(map[string]interface{})["staged_versions"])
+	}
+}
+
+func TestPriceAsOf_PastUsesReplacedRow(t *testing.T) {
+	groupID, subGroupID := uuid.New(), uuid.New()
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	p := &priceAsOf{
+		asOf: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		groupHistory: map[string]models.PriceListGroupHistory{
+			groupHistoryKey("C1", "S1", "G1"): {CompanyCode: "C1", SiteCode: "S1", GroupCode: "G1", PriceUnit: 100, EffectiveDate: &jan, ExpiryDate: &mar},
+		},
+		subGroupHistory: map[string]models.PriceListSubGroupHistory{},
+	}
+
+	g, ok := p.group(groupID, models.PriceListGroupHistory{CompanyCode: "C1", SiteCode: "S1", GroupCode: "G1", PriceUnit: 120, EffectiveDate: &mar})
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, g.PriceUnit, "the row replaced in March was the one live in February")
+
+	// A sub group created in March did not exist yet in February
+	_, ok = p.subGroup(subGroupID, models.PriceListSubGroupHistory{PriceListGroupID: groupID, SubgroupKey: "K1", EffectiveDate: &mar})
+	assert.False(t, ok)
+}
+
+func TestPriceAsOf_FutureAppliesStagedVersions(t *testing.T) {
+	subGroupID := uuid.New()
+	jan := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	apr := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	first, second := 110.0, 130.0
+
+	p := &priceAsOf{
+		asOf: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		subGroupVersions: map[uuid.UUID][]models.UpdatePriceListSubGroupItem{
+			subGroupID: {
+				{SubGroupID: subGroupID, PriceUnit: &first, EffectiveDate: &mar},
+				{SubGroupID: subGroupID, PriceUnit: &second, EffectiveDate: &apr},
+			},
+		},
+	}
+
+	s, ok := p.subGroup(subGroupID, models.PriceListSubGroupHistory{SubgroupKey: "K1", PriceUnit: 100, PriceWeight: 5, EffectiveDate: &jan})
+	assert.True(t, ok)
+	assert.Equal(t, 130.0, s.PriceUnit)
+	assert.Equal(t, 110.0, s.BeforePriceUnit, "before values follow the repository: the value replaced last")
+	assert.Equal(t, 5.0, s.PriceWeight, "fields left out of a version keep their value")
+	assert.True(t, apr.Equal(*s.EffectiveDate))
+}
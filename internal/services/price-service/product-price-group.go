@@ -0,0 +1,71 @@
+package priceService
+
+import (
+	"fmt"
+	"sort"
+
+	"prime-erp-core/internal/models"
+	priceListRepository "prime-erp-core/internal/repositories/priceList"
+)
+
+// seam for unit testing: allow stubbing the price list lookup of a site
+var getPriceListGroupFunc = priceListRepository.GetPriceListGroup
+
+// ProductPriceGroup is the price list group and sub group a product is priced under.
+type ProductPriceGroup struct {
+	GroupCode    string `json:"group_code"`
+	SubGroupCode string `json:"subgroup_code"`
+}
+
+// ResolveProductPriceGroups finds the price list group and sub group of each product of a site, by asking the
+// inventory service which products the sub groups' keys stand for. A product under more than one sub group takes
+// the one with the most keys. Products under none are left out.
+func ResolveProductPriceGroups(companyCode, siteCode string, productCodes []string) (map[string]ProductPriceGroup, error) {
+	resolved := map[string]ProductPriceGroup{}
+	if len(productCodes) == 0 {
+		return resolved, nil
+	}
+
+	groups, err := getPriceListGroupFunc(companyCode, siteCode, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price list groups: %w", err)
+	}
+
+	subGroups := []models.PriceListSubGroup{}
+	for _, group := range groups {
+		for _, subGroup := range group.PriceListSubGroups {
+			subGroup.PriceListGroup = models.PriceListGroup{CompanyCode: group.CompanyCode, SiteCode: group.SiteCode, GroupCode: group.GroupCode}
+			subGroups = append(subGroups, subGroup)
+		}
+	}
+	if len(subGroups) == 0 {
+		return resolved, nil
+	}
+
+	// Most specific first, so the first sub group a product is found under wins
+	sort.SliceStable(subGroups, func(i, j int) bool {
+		if len(subGroups[i].PriceListSubGroupKeys) != len(subGroups[j].PriceListSubGroupKeys) {
+			return len(subGroups[i].PriceListSubGroupKeys) > len(subGroups[j].PriceListSubGroupKeys)
+		}
+		return subGroups[i].SubGroupCode < subGroups[j].SubGroupCode
+	})
+
+	products, err := getSubGroupProductsFunc(subGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(productCodes))
+	for _, productCode := range productCodes {
+		wanted[productCode] = true
+	}
+	for _, subGroup := range subGroups {
+		for _, productCode := range products[subGroup.ID] {
+			if _, ok := resolved[productCode]; ok || !wanted[productCode] {
+				continue
+			}
+			resolved[productCode] = ProductPriceGroup{GroupCode: subGroup.PriceListGroup.GroupCode, SubGroupCode: subGroup.SubGroupCode}
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,36 @@
+package priceService
+
+import (
+	"testing"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveProductPriceGroups(t *testing.T) {
+	wide := models.PriceListSubGroup{ID: uuid.New(), SubGroupCode: "G1-ALL", PriceListSubGroupKeys: []models.PriceListSubGroupKey{{Code: "PG1", Value: "A"}}}
+	narrow := models.PriceListSubGroup{ID: uuid.New(), SubGroupCode: "G1-A1", PriceListSubGroupKeys: []models.PriceListSubGroupKey{{Code: "PG1", Value: "A"}, {Code: "PG2", Value: "1"}}}
+
+	originalGroups, originalProducts := getPriceListGroupFunc, getSubGroupProductsFunc
+	defer func() { getPriceListGroupFunc, getSubGroupProductsFunc = originalGroups, originalProducts }()
+	getPriceListGroupFunc = func(companyCode, siteCode string, groupCodes []string) ([]models.PriceListGroup, error) {
+		assert.Equal(t, "C1", companyCode)
+		assert.Equal(t, "S1", siteCode)
+		return []models.PriceListGroup{{CompanyCode: "C1", SiteCode: "S1", GroupCode: "G1", PriceListSubGroups: []models.PriceListSubGroup{wide, narrow}}}, nil
+	}
+	getSubGroupProductsFunc = func(subGroups []models.PriceListSubGroup) (map[uuid.UUID][]string, error) {
+		require.Len(t, subGroups, 2)
+		assert.Equal(t, "C1", subGroups[0].PriceListGroup.CompanyCode)
+		return map[uuid.UUID][]string{wide.ID: {"P1", "P2", "P9"}, narrow.ID: {"P1"}}, nil
+	}
+
+	resolved, err := ResolveProductPriceGroups("C1", "S1", []string{"P1", "P2", "P3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ProductPriceGroup{
+		"P1": {GroupCode: "G1", SubGroupCode: "G1-A1"},
+		"P2": {GroupCode: "G1", SubGroupCode: "G1-ALL"},
+	}, resolved)
+}
@@ -1,125 +1,179 @@
-package priceService
-
-import (
-	"encoding/json"
-	"prime-erp-core/internal/models"
-	priceListRepository "prime-erp-core/internal/repositories/priceList"
-	"time"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-func UpdatePriceListBase(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-	req := []models.UpdatePriceListBaseRequest{}
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, err
-	}
-
-	priceListGroup := []models.PriceListGroup{}
-	for _, r := range req {
-
-		now := time.Now().UTC()
-
-		priceListGroupTerm := []models.PriceListGroupTerm{}
-		if len(r.Terms) > 0 {
-			termNow := time.Now().UTC()
-			for _, term := range r.Terms {
-				priceListGroupTerm = append(priceListGroupTerm, models.PriceListGroupTerm{
-					ID:               term.ID,
-					PriceListGroupID: r.ID,
-					TermCode:         term.TermCode,
-					Pdc:              term.Pdc,
-					PdcPercent:       term.PdcPercent,
-					Due:              term.Due,
-					DuePercent:       term.DuePercent,
-					CreateBy:         term.CreateBy,
-					CreateDtm:        term.CreateDtm,
-					UpdateBy:         "system", // TODO: get user from auth
-					UpdateDtm:        &termNow,
-				})
-			}
-		}
-
-		priceListGroup = append(priceListGroup, models.PriceListGroup{
-			ID:                  r.ID,
-			PriceUnit:           r.PriceUnit,
-			PriceWeight:         r.PriceWeight,
-			Currency:            r.Currency,
-			EffectiveDate:       r.EffectiveDate,
-			Remark:              r.Remark,
-			UpdateBy:            "system", // TODO: get user from auth
-			UpdateDtm:           now,
-			PriceListGroupTerms: priceListGroupTerm,
-		})
-
-	}
-
-	if err := priceListRepository.UpdatePriceListBase(priceListGroup); err != nil {
-		return nil, err
-	}
-
-	return nil, nil
-}
-
-func UpdateExtras(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-	req := []models.UpdatePriceListExtraRequest{}
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, err
-	}
-
-	extras := []models.PriceListGroupExtra{}
-	for _, r := range req {
-		now := time.Now().UTC()
-
-		var id uuid.UUID
-		if r.ID == nil {
-			id = uuid.New()
-		} else {
-			id = *r.ID
-		}
-
-		extraKeys := []models.PriceListGroupExtraKey{}
-		for _, extraKey := range r.PriceListGroupExtraKeys {
-			var keyId uuid.UUID
-			if extraKey.ID == nil {
-				keyId = uuid.New()
-			} else {
-				keyId = *extraKey.ID
-			}
-
-			extraKeys = append(extraKeys, models.PriceListGroupExtraKey{
-				ID:           keyId,
-				GroupExtraID: id,
-				Code:         extraKey.Code,
-				Value:        extraKey.Value,
-				Seq:          extraKey.Seq,
-			})
-		}
-
-		extras = append(extras, models.PriceListGroupExtra{
-			ID:                      id,
-			PriceListGroupID:        r.PriceListGroupID,
-			ExtraKey:                r.ExtraKey,
-			ConditionCode:           r.ConditionCode,
-			ValueInt:                r.ValueInt,
-			LengthExtraKey:          r.LengthExtraKey,
-			Operator:                r.Operator,
-			CondRangeMin:            r.CondRangeMin,
-			CondRangeMax:            r.CondRangeMax,
-			CreateBy:                r.CreateBy,
-			CreateDtm:               &r.CreateDtm,
-			UpdateBy:                "system", // TODO: get user from auth
-			UpdateDtm:               &now,
-			PriceListGroupExtraKeys: extraKeys,
-		})
-	}
-
-	if err := priceListRepository.UpdateExtra(extras); err != nil {
-		return nil, err
-	}
-
-	return nil, nil
-}
+package priceService
+
+import (
+	"encoding/json"
+	"fmt"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+	priceListRepository "prime-erp-core/internal/repositories/priceList"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UpdatePriceListBase applies group changes right away, or stages them as a price list version when their
+// effective date is still ahead.
+func UpdatePriceListBase(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := []models.UpdatePriceListBaseRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+	now := time.Now().UTC()
+
+	priceListGroup := []models.PriceListGroup{}
+	versions := []models.PriceListVersion{}
+	for _, r := range req {
+		if r.ExtraStacking != nil && !isExtraStacking(strings.ToUpper(*r.ExtraStacking)) {
+			return nil, fmt.Errorf("invalid extra_stacking %q for price list group %s", *r.ExtraStacking, r.ID)
+		}
+		if isFutureEffective(r.EffectiveDate, now) {
+			version, err := newPriceListVersion(models.PriceVersionTargetGroup, r.ID, *r.EffectiveDate, r, user)
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, version)
+			continue
+		}
+
+		priceListGroup = append(priceListGroup, priceListGroupFromRequest(r, user, now))
+	}
+
+	if err := stagePriceVersionsFunc(ctx, versions); err != nil {
+		return nil, fmt.Errorf("failed to stage price versions: %w", err)
+	}
+
+	if len(priceListGroup) > 0 {
+		if err := priceListRepository.UpdatePriceListBase(priceListGroup); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(versions) > 0 {
+		return map[string]interface{}{
+			"staged_versions": priceVersionIDs(versions),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func priceListGroupFromRequest(r models.UpdatePriceListBaseRequest, user string, now time.Time) models.PriceListGroup {
+	priceListGroupTerm := []models.PriceListGroupTerm{}
+	for _, term := range r.Terms {
+		termNow := now
+		priceListGroupTerm = append(priceListGroupTerm, models.PriceListGroupTerm{
+			ID:               term.ID,
+			PriceListGroupID: r.ID,
+			TermCode:         term.TermCode,
+			Pdc:              term.Pdc,
+			PdcPercent:       term.PdcPercent,
+			Due:              term.Due,
+			DuePercent:       term.DuePercent,
+			CreateBy:         term.CreateBy,
+			CreateDtm:        term.CreateDtm,
+			UpdateBy:         user,
+			UpdateDtm:        &termNow,
+		})
+	}
+
+	extraStacking := ""
+	if r.ExtraStacking != nil {
+		extraStacking = strings.ToUpper(*r.ExtraStacking)
+	}
+
+	return models.PriceListGroup{
+		ID:                  r.ID,
+		PriceUnit:           r.PriceUnit,
+		PriceWeight:         r.PriceWeight,
+		Currency:            r.Currency,
+		EffectiveDate:       r.EffectiveDate,
+		Remark:              r.Remark,
+		ExtraStacking:       extraStacking,
+		UpdateBy:            user,
+		UpdateDtm:           now,
+		PriceListGroupTerms: priceListGroupTerm,
+	}
+}
+
+func UpdateExtras(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := []models.UpdatePriceListExtraRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, err
+	}
+
+	user := middleware.GetUserCode(ctx)
+
+	extras := []models.PriceListGroupExtra{}
+	for _, r := range req {
+		now := time.Now().UTC()
+
+		var id uuid.UUID
+		createBy := r.CreateBy
+		if r.ID == nil {
+			id = uuid.New()
+			createBy = user
+		} else {
+			id = *r.ID
+		}
+
+		if r.ConditionExpr != "" {
+			if _, err := compileExtraCondition(r.ConditionExpr); err != nil {
+				return nil, fmt.Errorf("invalid condition_expr of extra %s: %w", r.ExtraKey, err)
+			}
+		}
+		if r.EffectiveDate != nil && r.ExpiryDate != nil && !r.ExpiryDate.After(*r.EffectiveDate) {
+			return nil, fmt.Errorf("expiry_date of extra %s must be after its effective_date", r.ExtraKey)
+		}
+
+		extraKeys := []models.PriceListGroupExtraKey{}
+		for _, extraKey := range r.PriceListGroupExtraKeys {
+			var keyId uuid.UUID
+			if extraKey.ID == nil {
+				keyId = uuid.New()
+			} else {
+				keyId = *extraKey.ID
+			}
+
+			extraKeys = append(extraKeys, models.PriceListGroupExtraKey{
+				ID:           keyId,
+				GroupExtraID: id,
+				Code:         extraKey.Code,
+				Value:        extraKey.Value,
+				Seq:          extraKey.Seq,
+			})
+		}
+
+		extras = append(extras, models.PriceListGroupExtra{
+			ID:                      id,
+			PriceListGroupID:        r.PriceListGroupID,
+			ExtraKey:                r.ExtraKey,
+			ConditionCode:           r.ConditionCode,
+			ValueInt:                r.ValueInt,
+			LengthExtraKey:          r.LengthExtraKey,
+			Operator:                r.Operator,
+			CondRangeMin:            r.CondRangeMin,
+			CondRangeMax:            r.CondRangeMax,
+			ConditionExpr:           strings.TrimSpace(r.ConditionExpr),
+			Priority:                r.Priority,
+			EffectiveDate:           r.EffectiveDate,
+			ExpiryDate:              r.ExpiryDate,
+			CreateBy:                createBy,
+			CreateDtm:               &r.CreateDtm,
+			UpdateBy:                user,
+			UpdateDtm:               &now,
+			PriceListGroupExtraKeys: extraKeys,
+		})
+	}
+
+	if err := priceListRepository.UpdateExtra(extras); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,441 @@
+package priceService
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UploadIssue struct {
+	Sheet   string `json:"sheet"`
+	Row     int    `json:"row"` // worksheet row number, 0 for the sheet itself
+	Column  string `json:"column"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
+
+type UploadSheetReport struct {
+	Sheet    string        `json:"sheet"`
+	Rows     int           `json:"rows"`
+	Errors   []UploadIssue `json:"errors"`
+	Warnings []UploadIssue `json:"warnings"`
+}
+
+// uploadCellRef is a cell whose value has to exist in another table.
+type uploadCellRef struct {
+	Sheet  string
+	Row    int
+	Column string
+	Value  string
+}
+
+type uploadReport struct {
+	sheets      map[string]*UploadSheetReport
+	order       []string
+	itemRefs    []uploadCellRef
+	formulaRefs []uploadCellRef
+	groupRefs   []uploadCellRef // Value is groupKey(company, site, group)
+}
+
+func newUploadReport() *uploadReport {
+	return &uploadReport{sheets: map[string]*UploadSheetReport{}}
+}
+
+func (r *uploadReport) sheet(name string) *UploadSheetReport {
+	s, exists := r.sheets[name]
+	if !exists {
+		s = &UploadSheetReport{Sheet: name, Errors: []UploadIssue{}, Warnings: []UploadIssue{}}
+		r.sheets[name] = s
+		r.order = append(r.order, name)
+	}
+	return s
+}
+
+func (r *uploadReport) addError(sheet string, row int, column, value, message string) {
+	s := r.sheet(sheet)
+	s.Errors = append(s.Errors, UploadIssue{Sheet: sheet, Row: row, Column: column, Value: value, Message: message})
+}
+
+func (r *uploadReport) addWarning(sheet string, row int, column, value, message string) {
+	s := r.sheet(sheet)
+	s.Warnings = append(s.Warnings, UploadIssue{Sheet: sheet, Row: row, Column: column, Value: value, Message: message})
+}
+
+// Sheets returns the sheet reports in the order the sheets were read.
+func (r *uploadReport) Sheets() []UploadSheetReport {
+	out := make([]UploadSheetReport, 0, len(r.order))
+	for _, name := range r.order {
+		out = append(out, *r.sheets[name])
+	}
+	return out
+}
+
+func (r *uploadReport) counts() (errs int, warnings int) {
+	for _, s := range r.sheets {
+		errs += len(s.Errors)
+		warnings += len(s.Warnings)
+	}
+	return errs, warnings
+}
+
+// err sums the report up as one error for the upload that cannot go ahead, or nil when there is no error.
+func (r *uploadReport) err() error {
+	errs, _ := r.counts()
+	if errs == 0 {
+		return nil
+	}
+
+	var first UploadIssue
+	for _, name := range r.order {
+		if s := r.sheets[name]; len(s.Errors) > 0 {
+			first = s.Errors[0]
+			break
+		}
+	}
+
+	msg := first.Sheet
+	if first.Row > 0 {
+		msg += fmt.Sprintf(" row %d", first.Row)
+	}
+	if first.Column != "" {
+		msg += " " + first.Column
+	}
+	msg += ": " + first.Message
+	if errs > 1 {
+		msg += fmt.Sprintf(" (and %d more errors)", errs-1)
+	}
+	return errors.New(msg)
+}
+
+// uploadRow is one worksheet row. Its parse helpers record bad cells against the row and mark it failed.
+type uploadRow struct {
+	report *uploadReport
+	sheet  string
+	num    int
+	cells  map[string]string
+	failed bool
+}
+
+func (u *uploadRow) addError(column, value, message string) {
+	u.failed = true
+	u.report.addError(u.sheet, u.num, column, value, message)
+}
+
+func (u *uploadRow) addWarning(column, value, message string) {
+	u.report.addWarning(u.sheet, u.num, column, value, message)
+}
+
+func (u *uploadRow) required(columns ...string) bool {
+	ok := true
+	for _, c := range columns {
+		if u.cells[c] == "" {
+			u.addError(c, "", c+" is required")
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (u *uploadRow) float(column string) float64 {
+	s := strings.ReplaceAll(strings.TrimSpace(u.cells[column]), ",", "")
+	if s == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		u.addError(column, u.cells[column], "invalid number")
+		return 0
+	}
+	return v
+}
+
+func (u *uploadRow) int(column string) int {
+	v := u.float(column)
+	if v != math.Trunc(v) {
+		u.addError(column, u.cells[column], "must be a whole number")
+		return 0
+	}
+	return int(v)
+}
+
+func (u *uploadRow) bool(column string) bool {
+	switch strings.TrimSpace(strings.ToLower(u.cells[column])) {
+	case "true", "1", "yes", "y":
+		return true
+	case "", "false", "0", "no", "n":
+		return false
+	default:
+		u.addError(column, u.cells[column], "invalid boolean")
+		return false
+	}
+}
+
+func (u *uploadRow) time(column string) *time.Time {
+	s := strings.TrimSpace(u.cells[column])
+	if s == "" {
+		return nil
+	}
+	layouts := []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+	for _, ly := range layouts {
+		if t, err := time.Parse(ly, s); err == nil {
+			return &t
+		}
+	}
+	u.addError(column, s, "invalid date format")
+	return nil
+}
+
+func (u *uploadRow) itemRefs(keys []genKeyPart) {
+	for _, k := range keys {
+		u.report.itemRefs = append(u.report.itemRefs, uploadCellRef{Sheet: u.sheet, Row: u.num, Column: k.Code, Value: k.Value})
+	}
+}
+
+func (u *uploadRow) groupRef() {
+	key := u.cells["company_code"] + "|" + u.cells["site_code"] + "|" + u.cells["group_code"]
+	u.report.groupRefs = append(u.report.groupRefs, uploadCellRef{Sheet: u.sheet, Row: u.num, Column: "group_code", Value: key})
+}
+
+func (u *uploadRow) formulaRef(column string) {
+	u.report.formulaRefs = append(u.report.formulaRefs, uploadCellRef{Sheet: u.sheet, Row: u.num, Column: column, Value: u.cells[column]})
+}
+
+// checkPricelistReferences reports cells that point at group items, formulas or groups that do not exist.
+func checkPricelistReferences(gormx *gorm.DB, req *CreatePricelistRequest, report *uploadReport) error {
+	if len(report.itemRefs) > 0 {
+		var known []string
+		if err := gormx.Model(&models.GroupItem{}).
+			Where("item_code IN ?", distinctRefValues(report.itemRefs)).
+			Distinct().Pluck("item_code", &known).Error; err != nil {
+			return fmt.Errorf("failed to check group items: %w", err)
+		}
+		reportUnknownRefs(report, report.itemRefs, known, "unknown group item")
+	}
+
+	if len(report.formulaRefs) > 0 {
+		var known []string
+		if err := gormx.Model(&models.PriceListFormulas{}).
+			Where("formula_code IN ?", distinctRefValues(report.formulaRefs)).
+			Distinct().Pluck("formula_code", &known).Error; err != nil {
+			return fmt.Errorf("failed to check formulas: %w", err)
+		}
+		reportUnknownRefs(report, report.formulaRefs, known, "unknown formula")
+	}
+
+	// A child row may point at a group outside the file as long as it already exists
+	inFile := map[string]bool{}
+	for _, g := range req.Groups {
+		inFile[g.CompanyCode+"|"+g.SiteCode+"|"+g.GroupCode] = true
+	}
+	outside := []uploadCellRef{}
+	for _, ref := range report.groupRefs {
+		if !inFile[ref.Value] {
+			outside = append(outside, ref)
+		}
+	}
+	if len(outside) > 0 {
+		query := gormx.Model(&models.PriceListGroup{})
+		conditions := []string{}
+		args := []interface{}{}
+		for _, key := range distinctRefValues(outside) {
+			parts := strings.SplitN(key, "|", 3)
+			conditions = append(conditions, "(company_code = ? AND site_code = ? AND group_code = ?)")
+			args = append(args, parts[0], parts[1], parts[2])
+		}
+		var existing []models.PriceListGroup
+		if err := query.Select("company_code, site_code, group_code").
+			Where(strings.Join(conditions, " OR "), args...).
+			Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check price list groups: %w", err)
+		}
+		known := []string{}
+		for _, g := range existing {
+			known = append(known, g.CompanyCode+"|"+g.SiteCode+"|"+g.GroupCode)
+		}
+		reportUnknownRefs(report, outside, known, "group is neither in price_list_group nor in the price list")
+	}
+
+	return nil
+}
+
+func distinctRefValues(refs []uploadCellRef) []string {
+	seen := map[string]bool{}
+	values := []string{}
+	for _, ref := range refs {
+		if !seen[ref.Value] {
+			seen[ref.Value] = true
+			values = append(values, ref.Value)
+		}
+	}
+	return values
+}
+
+func reportUnknownRefs(report *uploadReport, refs []uploadCellRef, known []string, message string) {
+	knownSet := map[string]bool{}
+	for _, k := range known {
+		knownSet[k] = true
+	}
+	for _, ref := range refs {
+		if !knownSet[ref.Value] {
+			report.addError(ref.Sheet, ref.Row, ref.Column, ref.Value, message)
+		}
+	}
+}
+
+// Preview statuses of an uploaded sub group.
+const (
+	UploadDiffNew       = "NEW"
+	UploadDiffChanged   = "CHANGED"
+	UploadDiffUnchanged = "UNCHANGED"
+)
+
+type UploadPriceDelta struct {
+	Field string  `json:"field"`
+	Old   float64 `json:"old"`
+	New   float64 `json:"new"`
+	Delta float64 `json:"delta"`
+}
+
+type UploadSubGroupDiff struct {
+	SubGroupCode string             `json:"subgroup_code"`
+	GroupCode    string             `json:"group_code"`
+	SubGroupKey  string             `json:"subgroup_key"`
+	Status       string             `json:"status"`
+	Changes      []UploadPriceDelta `json:"changes"`
+}
+
+type UploadPreview struct {
+	New       int                  `json:"new"`
+	Changed   int                  `json:"changed"`
+	Unchanged int                  `json:"unchanged"`
+	SubGroups []UploadSubGroupDiff `json:"sub_groups"`
+}
+
+// previewPricelistUpload compares the uploaded sub groups with the stored ones by subgroup_code, the key the upload
+// upserts on. Like the upsert, a zero price in the file keeps the stored price.
+func previewPricelistUpload(gormx *gorm.DB, req *CreatePricelistRequest) (UploadPreview, error) {
+	preview := UploadPreview{SubGroups: []UploadSubGroupDiff{}}
+
+	// The last row for a code is the one the upload keeps
+	latest := map[string]PriceListSubGroupCreateDTO{}
+	codes := []string{}
+	for _, s := range req.SubGroups {
+		if _, exists := latest[s.SubGroupCode]; !exists {
+			codes = append(codes, s.SubGroupCode)
+		}
+		latest[s.SubGroupCode] = s
+	}
+	if len(codes) == 0 {
+		return preview, nil
+	}
+
+	var stored []models.PriceListSubGroup
+	if err := gormx.Where("subgroup_code IN ?", codes).Find(&stored).Error; err != nil {
+		return preview, fmt.Errorf("failed to get price list sub groups: %w", err)
+	}
+	storedByCode := map[string]models.PriceListSubGroup{}
+	for _, s := range stored {
+		storedByCode[s.SubGroupCode] = s
+	}
+
+	sort.Strings(codes)
+	for _, code := range codes {
+		s := latest[code]
+		diff := UploadSubGroupDiff{
+			SubGroupCode: code,
+			GroupCode:    s.GroupCode,
+			SubGroupKey:  s.SubGroupKey,
+			Changes:      []UploadPriceDelta{},
+		}
+
+		old, exists := storedByCode[code]
+		if !exists {
+			diff.Status = UploadDiffNew
+			preview.New++
+			preview.SubGroups = append(preview.SubGroups, diff)
+			continue
+		}
+
+		fields := []struct {
+			name     string
+			old, new float64
+		}{
+			{"price_unit", old.PriceUnit, s.PriceUnit},
+			{"extra_price_unit", old.ExtraPriceUnit, s.ExtraPriceUnit},
+			{"total_net_price_unit", old.TotalNetPriceUnit, s.TotalNetPriceUnit},
+			{"price_weight", old.PriceWeight, s.PriceWeight},
+			{"extra_price_weight", old.ExtraPriceWeight, s.ExtraPriceWeight},
+			{"term_price_weight", old.TermPriceWeight, s.TermPriceWeight},
+			{"total_net_price_weight", old.TotalNetPriceWeight, s.TotalNetPriceWeight},
+		}
+		for _, field := range fields {
+			if field.new == 0 || field.new == field.old {
+				continue
+			}
+			diff.Changes = append(diff.Changes, UploadPriceDelta{
+				Field: field.name,
+				Old:   field.old,
+				New:   field.new,
+				Delta: field.new - field.old,
+			})
+		}
+
+		if len(diff.Changes) > 0 || old.IsTrading != s.IsTrading {
+			diff.Status = UploadDiffChanged
+			preview.Changed++
+		} else {
+			diff.Status = UploadDiffUnchanged
+			preview.Unchanged++
+		}
+		preview.SubGroups = append(preview.SubGroups, diff)
+	}
+
+	return preview, nil
+}
+
+type UploadPricelistDryRunResponse struct {
+	ResponseCode int                 `json:"response_code"`
+	Message      string              `json:"message"`
+	DryRun       bool                `json:"dry_run"`
+	Valid        bool                `json:"valid"`
+	ErrorCount   int                 `json:"error_count"`
+	WarningCount int                 `json:"warning_count"`
+	Sheets       []UploadSheetReport `json:"sheets"`
+	Preview      UploadPreview       `json:"preview"`
+}
+
+// dryRunPricelistUpload validates the workbook against the database and previews its effect without writing.
+func dryRunPricelistUpload(gormx *gorm.DB, req *CreatePricelistRequest, report *uploadReport) (*UploadPricelistDryRunResponse, error) {
+	if err := checkPricelistReferences(gormx, req, report); err != nil {
+		return nil, err
+	}
+
+	preview, err := previewPricelistUpload(gormx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, warnings := report.counts()
+	res := &UploadPricelistDryRunResponse{
+		ResponseCode: 0,
+		Message:      "dry run, nothing was saved",
+		DryRun:       true,
+		Valid:        errs == 0,
+		ErrorCount:   errs,
+		WarningCount: warnings,
+		Sheets:       report.Sheets(),
+		Preview:      preview,
+	}
+	if errs > 0 {
+		res.ResponseCode = 1
+	}
+	return res, nil
+}
@@ -0,0 +1,84 @@
+package priceService
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func newUploadWorkbook(t *testing.T, sheets map[string][][]interface{}) *bytes.Buffer {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+	for name, rows := range sheets {
+		_, err := f.NewSheet(name)
+		assert.NoError(t, err)
+		for i, row := range rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			assert.NoError(t, f.SetSheetRow(name, cell, &row))
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	assert.NoError(t, err)
+	return buf
+}
+
+func TestParsePricelistWorkbook_ReportsEveryBadCell(t *testing.T) {
+	buf := newUploadWorkbook(t, map[string][][]interface{}{
+		"price_list_group": {
+			{"company_code", "site_code", "group_code", "price_unit", "effective_date", "PG01"},
+			{"C1", "S1", "G1", "1,250.50", "2026-01-01", "STEEL"},
+			{"C1", "S1", "", "abc", "01/01/2026", ""},
+		},
+		"price_list_sub_group": {
+			{"company_code", "site_code", "group_code", "subgroup_code", "price_unit", "is_trading", "PG01"},
+			{"C1", "S1", "G1", "SG1", "10", "y", "STEEL"},
+			{"C1", "S1", "G1", "SG2", "ten", "maybe", "STEEL"},
+			{"C1", "S1", "G1", "SG1", "12", "n", "STEEL"},
+		},
+		"formulas_map": {
+			{"subgroup_code", "formula_code_default", "formula_code_convert"},
+			{"SG1", "F1", "F2"},
+		},
+	})
+
+	req, report, err := parsePricelistWorkbook(buf)
+	assert.NoError(t, err)
+
+	if assert.Len(t, req.Groups, 1) {
+		assert.Equal(t, 1250.5, req.Groups[0].PriceUnit, "thousand separators are accepted")
+	}
+	assert.Len(t, req.SubGroups, 2, "the row with bad cells is left out")
+
+	sheets := map[string]UploadSheetReport{}
+	for _, s := range report.Sheets() {
+		sheets[s.Sheet] = s
+	}
+
+	groupErrors := sheets["price_list_group"].Errors
+	if assert.Len(t, groupErrors, 3) {
+		assert.Equal(t, UploadIssue{Sheet: "price_list_group", Row: 3, Column: "group_code", Message: "group_code is required"}, groupErrors[0])
+		assert.Equal(t, "effective_date", groupErrors[1].Column)
+		assert.Equal(t, "price_unit", groupErrors[2].Column)
+	}
+
+	subErrors := sheets["price_list_sub_group"].Errors
+	if assert.Len(t, subErrors, 2) {
+		assert.Equal(t, 3, subErrors[0].Row)
+		assert.Equal(t, "is_trading", subErrors[0].Column)
+		assert.Equal(t, "price_unit", subErrors[1].Column)
+		assert.Equal(t, "ten", subErrors[1].Value)
+	}
+	if assert.Len(t, sheets["price_list_sub_group"].Warnings, 1) {
+		assert.Equal(t, 4, sheets["price_list_sub_group"].Warnings[0].Row, "duplicate subgroup_code")
+	}
+	assert.Len(t, sheets["price_list_group_term"].Warnings, 1, "optional sheet missing")
+
+	assert.EqualError(t, report.err(), "price_list_group row 3 group_code: group_code is required (and 4 more errors)")
+	assert.Len(t, report.itemRefs, 4)
+	assert.Len(t, report.formulaRefs, 2)
+}
@@ -0,0 +1,352 @@
+package quotationService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+	saleService "prime-erp-core/internal/services/sale-service"
+	verifyService "prime-erp-core/internal/services/verify-service"
+	"prime-erp-core/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ConvertToSaleRequest struct {
+	QuotationID  uuid.UUID           `json:"quotation_id"`
+	Status       string              `json:"status"`     // APPROVED or WAIT_FOR_APPROVED, as for CreateSale
+	IsReprice    bool                `json:"is_reprice"` // allow an expired quotation when its prices still pass today's price list
+	Items        []ConvertToSaleItem `json:"items"`      // empty converts every open line in full
+	DeliveryDate *time.Time          `json:"delivery_date"`
+	RefPoDoc     string              `json:"ref_po_doc"`
+	Remark       string              `json:"remark"`
+}
+
+type ConvertToSaleItem struct {
+	QuotationItem string  `json:"quotation_item"`
+	Qty           float64 `json:"qty"` // 0 takes the remaining qty
+}
+
+type ConvertToSaleResponse struct {
+	Sales           interface{}                  `json:"sales"`
+	QuotationCode   string                       `json:"quotation_code"`
+	QuotationStatus string                       `json:"quotation_status"`
+	Items           []ConvertToSaleRemainingItem `json:"items"`
+}
+
+type ConvertToSaleRemainingItem struct {
+	QuotationItem string  `json:"quotation_item"`
+	Qty           float64 `json:"qty"`
+	ConvertedQty  float64 `json:"converted_qty"`
+	RemainingQty  float64 `json:"remaining_qty"`
+	Status        string  `json:"status"`
+}
+
+// ConvertToSale creates a sale order from the selected lines and quantities of a quotation.
+// The quotation item bookkeeping (converted/remaining qty and status) is done by CreateSale in the same transaction as the sale.
+func ConvertToSale(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := ConvertToSaleRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	if req.QuotationID == uuid.Nil {
+		return nil, fmt.Errorf("quotation_id is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotation models.Quotation
+	if err := gormx.Where("id = ?", req.QuotationID).First(&quotation).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quotation: %v", err)
+	}
+
+	var quotationItems []models.QuotationItem
+	if err := gormx.Where("quotation_id = ?", req.QuotationID).Order("quotation_item").Find(&quotationItems).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quotation items: %v", err)
+	}
+
+	saleItems, err := buildConvertedSaleItems(quotation, quotationItems, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	sale := buildConvertedSale(quotation, saleItems)
+	if req.DeliveryDate != nil {
+		sale.DeliveryDate = req.DeliveryDate
+	}
+	if req.RefPoDoc != "" {
+		sale.RefPoDoc = req.RefPoDoc
+	}
+	if req.Remark != "" {
+		sale.Remark = req.Remark
+	}
+
+	if isQuotationPriceExpired(quotation, today) {
+		if !req.IsReprice {
+			return nil, &utils.ConflictError{
+				Code:    "QUOTATION_PRICE_EXPIRED",
+				Message: fmt.Sprintf("quotation %s price expired on %s; re-price it before converting", quotation.QuotationCode, quotation.ExpirePriceDate.Format("2006-01-02")),
+			}
+		}
+
+		if err := verifyConvertedPrice(ctx, quotation, sale, saleItems, today); err != nil {
+			return nil, err
+		}
+		sale.EffectiveDatePrice = &today
+	}
+
+	createReq := saleService.CreateSaleRequest{
+		QuotationID: req.QuotationID.String(),
+		Status:      req.Status,
+		Sales: []saleService.SaleDocument{{
+			Sale:  sale,
+			Items: saleItems,
+		}},
+	}
+	createPayload, err := json.Marshal(createReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal create sale request: %v", err)
+	}
+
+	createRes, err := saleService.CreateSale(ctx, string(createPayload))
+	if err != nil {
+		return nil, err
+	}
+
+	res := ConvertToSaleResponse{
+		Sales:         createRes,
+		QuotationCode: quotation.QuotationCode,
+		Items:         []ConvertToSaleRemainingItem{},
+	}
+
+	if err := gormx.Where("id = ?", req.QuotationID).First(&quotation).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quotation: %v", err)
+	}
+	res.QuotationStatus = quotation.Status
+
+	if err := gormx.Where("quotation_id = ?", req.QuotationID).Order("quotation_item").Find(&quotationItems).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quotation items: %v", err)
+	}
+	for _, item := range quotationItems {
+		res.Items = append(res.Items, ConvertToSaleRemainingItem{
+			QuotationItem: item.QuotationItem,
+			Qty:           item.Qty,
+			ConvertedQty:  item.ConvertedQty,
+			RemainingQty:  item.RemainingQty,
+			Status:        item.Status,
+		})
+	}
+
+	return res, nil
+}
+
+func isQuotationPriceExpired(quotation models.Quotation, today time.Time) bool {
+	if quotation.ExpirePriceDate == nil {
+		return false
+	}
+	expire := *quotation.ExpirePriceDate
+	expireDate := time.Date(expire.Year(), expire.Month(), expire.Day(), 0, 0, 0, 0, today.Location())
+	return expireDate.Before(today)
+}
+
+// buildConvertedSaleItems turns the selected quotation lines into sale items for the requested qty,
+// scaling the line amounts and weights by the share of the quotation qty being ordered.
+func buildConvertedSaleItems(quotation models.Quotation, quotationItems []models.QuotationItem, selected []ConvertToSaleItem) ([]models.SaleItem, error) {
+	itemsByCode := map[string]models.QuotationItem{}
+	for _, item := range quotationItems {
+		itemsByCode[item.QuotationItem] = item
+	}
+
+	if len(selected) == 0 {
+		for _, item := range quotationItems {
+			if item.Status != "CANCELED" && item.Qty-item.ConvertedQty > 0 {
+				selected = append(selected, ConvertToSaleItem{QuotationItem: item.QuotationItem})
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, &utils.ConflictError{
+			Code:    "QUOTATION_NOT_CONVERTIBLE",
+			Message: fmt.Sprintf("quotation %s has no open quantity left to convert", quotation.QuotationCode),
+		}
+	}
+
+	saleItems := []models.SaleItem{}
+	for _, line := range selected {
+		item, exists := itemsByCode[line.QuotationItem]
+		if !exists {
+			return nil, fmt.Errorf("quotation %s has no item %s", quotation.QuotationCode, line.QuotationItem)
+		}
+		if line.Qty < 0 {
+			return nil, fmt.Errorf("qty of quotation item %s must not be negative", line.QuotationItem)
+		}
+
+		remaining := item.Qty - item.ConvertedQty
+		qty := line.Qty
+		if qty == 0 {
+			qty = remaining
+		}
+		if qty <= 0 || item.Qty <= 0 {
+			continue
+		}
+
+		ratio := qty / item.Qty
+		saleItems = append(saleItems, models.SaleItem{
+			ProductCode:                    item.ProductCode,
+			ProductDesc:                    item.ProductDesc,
+			DocumentRef:                    quotation.QuotationCode,
+			DocumentRefItem:                item.QuotationItem,
+			Qty:                            qty,
+			OriginQty:                      qty,
+			Unit:                           item.Unit,
+			PriceListUnit:                  item.PriceListUnit,
+			SaleQty:                        item.SaleQty * ratio,
+			SaleUnit:                       item.SaleUnit,
+			SaleUnitType:                   item.SaleUnitType,
+			PassPriceUnit:                  item.PassPrice,
+			PassPriceWeight:                item.PassWeight,
+			PriceUnit:                      item.PriceUnit,
+			TotalAmount:                    item.TotalAmount * ratio,
+			TransportCostUnit:              item.TransportCostUnit,
+			SubtotalExclTransport:          item.SubtotalExclTransport * ratio,
+			NetPriceUnitExclTransport:      item.NetPriceUnitExclTransport,
+			WeightUnit:                     item.WeightUnit,
+			AvgWeightUnit:                  item.AvgWeightUnit,
+			TotalWeight:                    item.TotalWeight * ratio,
+			TransportCostWeightUnit:        item.TransportCostWeightUnit,
+			SubtotalWeightExclTransport:    item.SubtotalWeightExclTransport * ratio,
+			NetPricePerWeightExclTransport: item.NetPricePerWeightExclTransport,
+			Status:                         "PENDING",
+			Remark:                         item.Remark,
+			SubtotalExclVat:                item.SubtotalExclVat * ratio,
+			TotalVat:                       item.TotalVat * ratio,
+			UnitUom:                        item.UnitUom,
+			TotalDiscount:                  item.TotalDiscount * ratio,
+			TotalDiscountPercent:           item.TotalDiscountPercent,
+		})
+	}
+
+	return saleItems, nil
+}
+
+// buildConvertedSale copies the quotation header and totals the converted lines.
+// Transport cost is shared out by the weight being ordered.
+func buildConvertedSale(quotation models.Quotation, saleItems []models.SaleItem) models.Sale {
+	sale := models.Sale{
+		CompanyCode:        quotation.CompanyCode,
+		SiteCode:           quotation.SiteCode,
+		CustomerCode:       quotation.CustomerCode,
+		CustomerName:       quotation.CustomerName,
+		DeliveryDate:       quotation.DeliveryDate,
+		SoldToCode:         quotation.SoldToCode,
+		SoldToAddress:      quotation.SoldToAddress,
+		BillToCode:         quotation.BillToCode,
+		BillToAddress:      quotation.BillToAddress,
+		ShipToCode:         quotation.ShipToCode,
+		ShipToType:         quotation.ShipToType,
+		ShipToAddress:      quotation.ShipToAddress,
+		DeliveryMethod:     quotation.DeliveryMethod,
+		TransportCostType:  quotation.TransportCostType,
+		PaymentMethod:      quotation.PaymentMethod,
+		PeymentTermCode:    quotation.PeymentTermCode,
+		SalePersonCode:     quotation.SalePersonCode,
+		EffectiveDatePrice: quotation.EffectiveDatePrice,
+		ExpirePriceDay:     quotation.ExpirePriceDay,
+		ExpirePriceDate:    quotation.ExpirePriceDate,
+		Remark:             quotation.Remark,
+		CreditTerm:         quotation.CreditTerm,
+		PayerTerm:          quotation.PayerTerm,
+	}
+
+	for _, item := range saleItems {
+		sale.TotalAmount += item.TotalAmount
+		sale.TotalWeight += item.TotalWeight
+		sale.SubtotalExclTransport += item.SubtotalExclTransport
+		sale.SubtotalWeightExclTransport += item.SubtotalWeightExclTransport
+		sale.SubtotalExclVat += item.SubtotalExclVat
+		sale.TotalVat += item.TotalVat
+		sale.TotalDiscount += item.TotalDiscount
+	}
+
+	if quotation.TotalWeight > 0 {
+		share := sale.TotalWeight / quotation.TotalWeight
+		sale.TotalTransportCost = quotation.TotalTransportCost * share
+		sale.TotalTransportCostVat = quotation.TotalTransportCostVat * share
+	}
+
+	return sale
+}
+
+// verifyConvertedPrice re-prices an expired quotation against today's price list; the conversion is refused if the quoted prices no longer pass.
+func verifyConvertedPrice(ctx *gin.Context, quotation models.Quotation, sale models.Sale, saleItems []models.SaleItem, today time.Time) error {
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return err
+	}
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return err
+	}
+
+	saleDate := today
+	if sale.DeliveryDate != nil {
+		saleDate = *sale.DeliveryDate
+	}
+
+	doc := verifyService.VerifyApproveDocument{
+		DocRef:             quotation.QuotationCode,
+		CustomerCode:       quotation.CustomerCode,
+		EffectiveDatePrice: today,
+		TransportCost:      sale.TotalTransportCost,
+		TransportType:      sale.TransportCostType,
+		TotalAmount:        sale.SubtotalExclVat,
+		TotalWeight:        sale.TotalWeight,
+	}
+	for _, item := range saleItems {
+		doc.Items = append(doc.Items, verifyService.VerifyApproveItem{
+			ItemRef:       item.DocumentRefItem,
+			ProductCode:   item.ProductCode,
+			Qty:           item.Qty,
+			Unit:          item.Unit,
+			TotalWeight:   item.TotalWeight,
+			PriceUnit:     item.PriceUnit,
+			PriceListUnit: item.PriceListUnit,
+			TotalAmount:   item.SubtotalExclVat,
+			SaleUnit:      item.SaleUnit,
+			SaleUnitType:  item.SaleUnitType,
+		})
+	}
+
+	verifyRes, err := verifyService.VerifyApproveLogic(gormx, sqlx, verifyService.VerifyApproveRequest{
+		IsVerifyPrice: true,
+		CompanyCode:   quotation.CompanyCode,
+		SiteCode:      quotation.SiteCode,
+		StorageType:   []string{`NORMAL`},
+		SaleDate:      saleDate,
+		Documents:     []verifyService.VerifyApproveDocument{doc},
+	})
+	if err != nil {
+		return err
+	}
+	if !verifyRes.IsPassPrice {
+		return &utils.ConflictError{
+			Code:    "QUOTATION_REPRICE_FAILED",
+			Message: fmt.Sprintf("quotation %s prices do not pass the current price list", quotation.QuotationCode),
+			Details: verifyRes.Documents,
+		}
+	}
+
+	return nil
+}
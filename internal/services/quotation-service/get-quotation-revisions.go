@@ -0,0 +1,139 @@
+package quotationService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"prime-erp-core/internal/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetRevisionsRequest struct {
+	QuotationCode string `json:"quotation_code"`
+}
+
+type GetRevisionsResponse struct {
+	QuotationCode string              `json:"quotation_code"`
+	Revisions     []quotationRevision `json:"revisions"`
+}
+
+type QuotationRevisionRef struct {
+	QuotationCode string   `json:"quotation_code"`
+	Revision      *float64 `json:"revision"` // nil = the live quotation
+}
+
+type DiffRevisionsRequest struct {
+	QuotationCode string               `json:"quotation_code"`
+	From          QuotationRevisionRef `json:"from"` // empty = the revision right before To
+	To            QuotationRevisionRef `json:"to"`   // empty = the live quotation_code
+}
+
+type DiffRevisionsResponse struct {
+	From QuotationRevisionRef `json:"from"`
+	To   QuotationRevisionRef `json:"to"`
+	QuotationRevisionDiff
+}
+
+// GetRevisions lists every revision of the quotation chain the quotation belongs to, oldest first.
+func GetRevisions(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetRevisionsRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	if req.QuotationCode == "" {
+		return nil, fmt.Errorf("quotation_code is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := loadQuotationRevisions(gormx, req.QuotationCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetRevisionsResponse{
+		QuotationCode: req.QuotationCode,
+		Revisions:     revisions,
+	}, nil
+}
+
+// DiffRevisions compares two revisions of a quotation chain: header field changes, added/removed/changed items and amount deltas.
+func DiffRevisions(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := DiffRevisionsRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	if req.To.QuotationCode == "" {
+		req.To.QuotationCode = req.QuotationCode
+	}
+	if req.To.QuotationCode == "" {
+		return nil, fmt.Errorf("quotation_code is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := loadQuotationRevisions(gormx, req.To.QuotationCode)
+	if err != nil {
+		return nil, err
+	}
+
+	toIndex := findQuotationRevision(revisions, req.To)
+	if toIndex < 0 {
+		return nil, fmt.Errorf("quotation %s has no revision %s", req.To.QuotationCode, formatRevisionRef(req.To))
+	}
+
+	fromIndex := toIndex - 1
+	if req.From.QuotationCode != "" {
+		fromIndex = findQuotationRevision(revisions, req.From)
+		if fromIndex < 0 {
+			return nil, fmt.Errorf("quotation %s has no revision %s", req.From.QuotationCode, formatRevisionRef(req.From))
+		}
+	}
+	if fromIndex < 0 {
+		return nil, fmt.Errorf("quotation %s revision %s has no earlier revision to compare with", req.To.QuotationCode, formatRevisionRef(req.To))
+	}
+
+	from := revisions[fromIndex]
+	to := revisions[toIndex]
+
+	return DiffRevisionsResponse{
+		From:                  QuotationRevisionRef{QuotationCode: from.QuotationCode, Revision: &from.Revision},
+		To:                    QuotationRevisionRef{QuotationCode: to.QuotationCode, Revision: &to.Revision},
+		QuotationRevisionDiff: diffQuotationRevisions(from, to),
+	}, nil
+}
+
+// findQuotationRevision returns the index of ref in revisions; a ref without a revision points at the live row.
+func findQuotationRevision(revisions []quotationRevision, ref QuotationRevisionRef) int {
+	for i, revision := range revisions {
+		if revision.QuotationCode != ref.QuotationCode {
+			continue
+		}
+		if ref.Revision == nil && revision.IsCurrent {
+			return i
+		}
+		if ref.Revision != nil && *ref.Revision == revision.Revision {
+			return i
+		}
+	}
+	return -1
+}
+
+func formatRevisionRef(ref QuotationRevisionRef) string {
+	if ref.Revision == nil {
+		return "current"
+	}
+	return fmt.Sprintf("%v", *ref.Revision)
+}
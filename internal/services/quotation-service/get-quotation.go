@@ -1,436 +1,441 @@
-package quotationService
-
-import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"math"
-	"net/http"
-	externalService "prime-erp-core/external/customer-service"
-	"prime-erp-core/internal/db"
-	"strings"
-	"time"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-type GetQuotationRequest struct {
-	ID                   []string   `json:"id"`
-	QuotationCode        []string   `json:"quotation_code"`
-	SiteCode             []string   `json:"site_code"`
-	CompanyCode          []string   `json:"company_code"`
-	CustomerCode         []string   `json:"customer_code"`
-	ProductCode          []string   `json:"product_code"`
-	QuotationCodeLike    string     `json:"quotation_code_like"`
-	CustomerCodeLike     string     `json:"customer_code_like"`
-	CustomerNameLike     string     `json:"customer_name_like"`
-	CreateDateStart      *time.Time `json:"create_date_start"`
-	CreateDateEnd        *time.Time `json:"create_date_end"`
-	ExpirePriceDateStart *time.Time `json:"expire_price_date_start"`
-	ExpirePriceDateEnd   *time.Time `json:"expire_price_date_end"`
-	DeliveryDateStart    *time.Time `json:"delivery_date_start"`
-	DeliveryDateEnd      *time.Time `json:"delivery_date_end"`
-	CompletedDateStart   *time.Time `json:"completed_date_start"`
-	CompletedDateEnd     *time.Time `json:"completed_date_end"`
-	Status               []string   `json:"status"`
-	StatusFilter         []string   `json:"status_filter"` // สำหรับกรองตาม status ที่แสดงใน UI
-	Page                 int        `json:"page"`
-	PageSize             int        `json:"page_size"`
-}
-
-func (GetQuotationResponse) TableName() string { return "quotation" }
-
-func (GetQuotationItemResponse) TableName() string { return "quotation_item" }
-
-type GetQuotationResponse struct {
-	ID                          uuid.UUID                  `gorm:"type:uuid;primary_key" json:"id"`
-	QuotationCode               string                     `gorm:"type:varchar(50)" json:"quotation_code"`
-	CompanyCode                 string                     `gorm:"type:varchar(50)" json:"company_code"`
-	SiteCode                    string                     `gorm:"type:varchar(50)" json:"site_code"`
-	CustomerCode                string                     `gorm:"type:varchar(50)" json:"customer_code"`
-	CustomerName                string                     `gorm:"type:varchar(255)" json:"customer_name"`
-	DeliveryDate                *time.Time                 `gorm:"type:date" json:"delivery_date"`
-	SoldToCode                  string                     `gorm:"type:varchar(50)" json:"sold_to_code"`
-	SoldToAddress               string                     `gorm:"type:varchar(255)" json:"sold_to_address"`
-	BillToCode                  string                     `gorm:"type:varchar(50)" json:"bill_to_code"`
-	BillToAddress               string                     `gorm:"type:varchar(255)" json:"bill_to_address"`
-	ShipToCode                  string                     `gorm:"type:varchar(50)" json:"ship_to_code"`
-	ShipToType                  string                     `gorm:"type:varchar(50)" json:"ship_to_type"`
-	ShipToAddress               string                     `gorm:"type:varchar(255)" json:"ship_to_address"`
-	DeliveryMethod              string                     `gorm:"type:varchar(50)" json:"delivery_method"`
-	TransportCostType           string                     `gorm:"type:varchar(50)" json:"transport_cost_type"`
-	TotalTransportCost          float64                    `gorm:"type:numeric" json:"total_transport_cost"`
-	PassPrice                   bool                       `gorm:"type:boolean" json:"pass_price"`
-	TotalAmount                 float64                    `gorm:"type:numeric" json:"total_amount"` //TotalPrice
-	TotalWeight                 float64                    `gorm:"type:numeric" json:"total_weight"`
-	SubtotalExclTransport       float64                    `gorm:"type:numeric" json:"subtotal_excl_transport"`        //TotalNetPrice
-	SubtotalWeightExclTransport float64                    `gorm:"type:numeric" json:"subtotal_weight_excl_transport"` //TotalNetPriceWeight
-	PaymentMethod               string                     `gorm:"type:varchar(50)" json:"payment_method"`
-	PeymentTermCode             string                     `gorm:"type:varchar(50)" json:"peyment_term_code"`
-	SalePersonCode              string                     `gorm:"type:varchar(50)" json:"sale_person_code"`
-	EffectiveDatePrice          *time.Time                 `gorm:"type:date" json:"effective_date_price"`
-	ExpirePriceDay              int                        `gorm:"type:int" json:"expire_price_day"`
-	ExpirePriceDate             *time.Time                 `gorm:"type:date" json:"expire_price_date"`
-	PassPriceList               string                     `gorm:"type:varchar(50)" json:"pass_price_list"`
-	PassAtpCheck                string                     `gorm:"type:varchar(50)" json:"pass_atp_check"`
-	PassCreditLimit             string                     `gorm:"type:varchar(50)" json:"pass_credit_limit"`
-	PassPriceExpire             string                     `gorm:"type:varchar(50)" json:"pass_price_expire"`
-	Status                      string                     `gorm:"type:varchar(50)" json:"status"`
-	Remark                      string                     `gorm:"type:varchar(255)" json:"remark"`
-	IsApproved                  bool                       `gorm:"type:boolean" json:"is_approved"`
-	StatusApprove               string                     `gorm:"type:varchar(50)" json:"status_approve"`
-	TotalVat                    float64                    `gorm:"type:numeric" json:"total_vat"`
-	TotalDiscount               float64                    `gorm:"type:numeric" json:"total_discount"`
-	SubtotalExclVat             float64                    `gorm:"type:numeric" json:"subtotal_excl_vat"`
-	TotalTransportCostVat       float64                    `gorm:"type:numeric" json:"total_transport_cost_vat"`
-	RemarkApproval              string                     `gorm:"type:varchar(255)" json:"remark_approval"`
-	Revision                    float64                    `gorm:"type:numeric" json:"revision"`
-	QuotationCodeRef            string                     `gorm:"type:varchar(50)" json:"quotation_code_ref"`
-	CreditTerm                  string                     `gorm:"type:varchar(50)" json:"credit_term"`
-	PayerTerm                   string                     `gorm:"type:varchar(50)" json:"payer_term"`
-	CreateDate                  *time.Time                 `gorm:"type:date" json:"create_date"`
-	CreateBy                    string                     `gorm:"type:varchar(50)" json:"create_by"`
-	UpdateDate                  *time.Time                 `gorm:"type:date" json:"update_date"`
-	UpdateBy                    string                     `gorm:"type:varchar(50)" json:"update_by"`
-	Items                       []GetQuotationItemResponse `gorm:"foreignKey:QuotationID" json:"items"`
-}
-
-type GetQuotationItemResponse struct {
-	ID                             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
-	QuotationID                    uuid.UUID  `gorm:"type:uuid" json:"quotation_id"`
-	QuotationItem                  string     `gorm:"type:varchar(50)" json:"quotation_item"`
-	ProductCode                    string     `gorm:"type:varchar(50)" json:"product_code"`
-	ProductDesc                    string     `gorm:"type:varchar(255)" json:"product_desc"`
-	Qty                            float64    `gorm:"type:numeric" json:"qty"`
-	Unit                           string     `gorm:"type:varchar(50)" json:"unit"`
-	PriceListUnit                  float64    `gorm:"type:numeric" json:"price_list_unit"`
-	SaleQty                        float64    `gorm:"type:numeric" json:"sale_qty"`
-	SaleUnit                       string     `gorm:"type:varchar(50)" json:"sale_unit"`
-	SaleUnitType                   string     `gorm:"type:varchar(50)" json:"sale_unit_type"`
-	PassPrice                      string     `gorm:"type:varchar(50)" json:"pass_price"`
-	PassWeight                     string     `gorm:"type:varchar(50)" json:"pass_weight"`
-	PriceUnit                      float64    `gorm:"type:numeric" json:"price_unit"`
-	TotalAmount                    float64    `gorm:"type:numeric" json:"total_amount"` //TotalPrice
-	TransportCostUnit              float64    `gorm:"type:numeric" json:"transport_cost_unit"`
-	SubtotalExclTransport          float64    `gorm:"type:numeric" json:"subtotal_excl_transport"`       //TotalNetPrice
-	NetPriceUnitExclTransport      float64    `gorm:"type:numeric" json:"net_price_unit_excl_transport"` //NetPriceUnit
-	WeightUnit                     float64    `gorm:"type:numeric" json:"weight_unit"`
-	AvgWeightUnit                  float64    `gorm:"type:numeric" json:"avg_weight_unit"`
-	TotalWeight                    float64    `gorm:"type:numeric" json:"total_weight"`
-	TransportCostWeightUnit        float64    `gorm:"type:numeric" json:"transport_cost_weight_unit"`
-	SubtotalWeightExclTransport    float64    `gorm:"type:numeric" json:"subtotal_weight_excl_transport"`      //TotalNetPriceWeight
-	NetPricePerWeightExclTransport float64    `gorm:"type:numeric" json:"net_price_per_weight_excl_transport"` //NetPriceWeightUnit
-	Status                         string     `gorm:"type:varchar(50)" json:"status"`
-	Remark                         string     `gorm:"type:varchar(255)" json:"remark"`
-	SubtotalExclVat                float64    `gorm:"type:numeric" json:"subtotal_excl_vat"`
-	TotalVat                       float64    `gorm:"type:numeric" json:"total_vat"`
-	UnitUom                        string     `gorm:"type:varchar(50)" json:"unit_uom"`
-	TotalDiscount                  float64    `gorm:"type:numeric" json:"total_discount"`
-	TotalDiscountPercent           float64    `gorm:"type:numeric" json:"total_discount_percent"`
-	CreateDate                     *time.Time `gorm:"type:date" json:"create_date"`
-	CreateBy                       string     `gorm:"type:varchar(50)" json:"create_by"`
-	UpdateDate                     *time.Time `gorm:"type:date" json:"update_date"`
-	UpdateBy                       string     `gorm:"type:varchar(50)" json:"update_by"`
-}
-
-type ResultQuotationResponse struct {
-	Total      int                    `json:"total"`
-	Page       int                    `json:"page"`
-	PageSize   int                    `json:"page_size"`
-	TotalPages int                    `json:"total_pages"`
-	Quotations []GetQuotationResponse `json:"quotations"`
-}
-
-// buildStatusConditions สร้างเงื่อนไขการกรองตาม status ที่ซับซ้อน
-func buildStatusConditions(statusFilters []string) ([]string, []interface{}) {
-	var conditions []string
-	var args []interface{}
-
-	for _, statusFilter := range statusFilters {
-		switch strings.ToLower(statusFilter) {
-		case "new":
-			// status='PENDING' และ status_approve='PENDING' (ใน edit mode)
-			conditions = append(conditions, "(status = ? AND status_approve = ?)")
-			args = append(args, "PENDING", "PENDING")
-		case "create":
-			// status='PENDING' และ status_approve='PENDING' (ไม่ใช่ edit mode)
-			conditions = append(conditions, "(status = ? AND status_approve = ?)")
-			args = append(args, "PENDING", "PENDING")
-		case "pending":
-			// รวมทุกสถานะ pending
-			conditions = append(conditions, "(status = ? AND status_approve = ?)")
-			args = append(args, "PENDING", "PENDING")
-		case "wait for approve", "waitapprove":
-			// status='PENDING' และ status_approve='PROCESS'
-			conditions = append(conditions, "(status = ? AND status_approve = ?)")
-			args = append(args, "PENDING", "PROCESS")
-		case "approved":
-			// status='PENDING' และ status_approve='COMPLETED' และยังไม่ overdue
-			conditions = append(conditions, "(status = ? AND status_approve = ? AND (expire_price_date IS NULL OR expire_price_date >= CURRENT_DATE))")
-			args = append(args, "PENDING", "COMPLETED")
-		case "overdue":
-			// status='PENDING' และ status_approve='COMPLETED' และ overdue
-			conditions = append(conditions, "(status = ? AND status_approve = ? AND expire_price_date IS NOT NULL AND expire_price_date < CURRENT_DATE)")
-			args = append(args, "PENDING", "COMPLETED")
-		case "reject":
-			// status='PENDING' และ status_approve='REJECT'
-			conditions = append(conditions, "(status = ? AND status_approve = ?)")
-			args = append(args, "PENDING", "REJECT")
-		case "review":
-			// status='PENDING' และ status_approve='REVIEW'
-			conditions = append(conditions, "(status = ? AND status_approve = ?)")
-			args = append(args, "PENDING", "REVIEW")
-		case "canceled", "cancelled":
-			// status='CANCELED'
-			conditions = append(conditions, "status = ?")
-			args = append(args, "CANCELED")
-		case "draft":
-			// status='TEMP'
-			conditions = append(conditions, "status = ?")
-			args = append(args, "TEMP")
-		case "converted":
-			// status='COMPLETED'
-			conditions = append(conditions, "status = ?")
-			args = append(args, "COMPLETED")
-		}
-	}
-
-	return conditions, args
-}
-
-// getCustomerCodesByName ค้นหา customer codes จาก customer service โดยใช้ customer name
-func getCustomerCodesByName(customerNameLike string) ([]string, error) {
-	if len(customerNameLike) == 0 {
-		return nil, nil
-	}
-
-	getCustomerByNameRequest := externalService.GetCustomerRequest{
-		CustomerNameLike: customerNameLike,
-		Page:             1,
-		PageSize:         1000, // เอาเยอะๆ เพื่อให้ได้ customerCode ทั้งหมดที่ match
-	}
-
-	customerByNameData, err := externalService.GetCustomer(getCustomerByNameRequest)
-	if err != nil {
-		fmt.Println("failed to fetch customers by name:", err)
-		return nil, errors.New("failed to fetch customers by name: " + err.Error())
-	}
-
-	fmt.Printf("Found %d customers matching name like '%s'\n", len(customerByNameData.Customers), customerNameLike)
-
-	// เก็บ customerCode ทั้งหมดที่ได้จากการค้นหาด้วย name
-	var customerCodes []string
-	for _, customer := range customerByNameData.Customers {
-		customerCodes = append(customerCodes, customer.CustomerCode)
-	}
-
-	fmt.Println("Customer codes from name search:", customerCodes)
-	return customerCodes, nil
-}
-
-func GetQuotation(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-
-	var res []GetQuotationResponse
-	var req GetQuotationRequest
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-
-	gormx, err := db.ConnectGORM("prime_erp")
-	if err != nil {
-		fmt.Println(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to connect to database"})
-		return nil, err
-	}
-	defer db.CloseGORM(gormx)
-
-	// ถ้ามี CustomerNameLike ให้ไปค้นหา customerCode จาก customer service ก่อน
-	customerCodesFromName, err := getCustomerCodesByName(req.CustomerNameLike)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return nil, err
-	}
-
-	query := gormx.Preload("Items").
-		Order("quotation.update_date DESC")
-
-	if len(req.ID) > 0 {
-		query = query.Where("id IN ?", req.ID)
-	}
-
-	if len(req.QuotationCode) > 0 {
-		query = query.Where("quotation_code IN ?", req.QuotationCode)
-	}
-
-	if len(req.SiteCode) > 0 {
-		query = query.Where("site_code IN ?", req.SiteCode)
-	}
-
-	if len(req.CompanyCode) > 0 {
-		query = query.Where("company_code IN ?", req.CompanyCode)
-	}
-
-	if len(req.CustomerCode) > 0 {
-		query = query.Where("customer_code IN ?", req.CustomerCode)
-	}
-
-	if len(req.ProductCode) > 0 {
-		query = query.Joins("JOIN quotation_item ON quotation_item.quotation_id = quotation.id").
-			Where("quotation_item.product_code IN ?", req.ProductCode)
-	}
-
-	if len(req.QuotationCodeLike) > 0 {
-		query = query.Where("quotation_code ILIKE ?", "%"+req.QuotationCodeLike+"%")
-	}
-
-	if len(req.CustomerCodeLike) > 0 {
-		query = query.Where("customer_code ILIKE ?", "%"+req.CustomerCodeLike+"%")
-	}
-
-	if len(req.CustomerNameLike) > 0 {
-		// ใช้ customerCode ที่ได้จากการค้นหาด้วย customer name แทนการค้นหาตรงๆ ด้วย customer_name
-		if len(customerCodesFromName) > 0 {
-			query = query.Where("customer_code IN ?", customerCodesFromName)
-		} else {
-			// ถ้าไม่เจอ customer ใดๆ ที่ match กับ name ให้ return ข้อมูลว่างเปล่า
-			query = query.Where("1 = 0") // condition ที่จะไม่ match อะไรเลย
-		}
-	}
-
-	if req.CreateDateStart != nil && req.CreateDateEnd != nil {
-		query = query.Where("create_date BETWEEN ? AND ?", req.CreateDateStart, req.CreateDateEnd)
-	}
-
-	if req.ExpirePriceDateStart != nil && req.ExpirePriceDateEnd != nil {
-		query = query.Where("expire_price_date BETWEEN ? AND ?", req.ExpirePriceDateStart, req.ExpirePriceDateEnd)
-	}
-
-	if req.DeliveryDateStart != nil && req.DeliveryDateEnd != nil {
-		query = query.Where("delivery_date BETWEEN ? AND ?", req.DeliveryDateStart, req.DeliveryDateEnd)
-	}
-
-	if req.CompletedDateStart != nil && req.CompletedDateEnd != nil {
-		query = query.Where("update_date BETWEEN ? AND ? AND status = ?", req.CompletedDateStart, req.CompletedDateEnd, "COMPLETED")
-	}
-
-	if len(req.Status) > 0 {
-		query = query.Where("status IN ?", req.Status)
-	}
-
-	// Apply status filter conditions
-	if len(req.StatusFilter) > 0 {
-		conditions, args := buildStatusConditions(req.StatusFilter)
-		if len(conditions) > 0 {
-			// Join conditions with OR
-			combinedCondition := "(" + strings.Join(conditions, " OR ") + ")"
-			query = query.Where(combinedCondition, args...)
-		}
-	}
-
-	// Build base query for counting
-	countQuery := gormx.Model(&GetQuotationResponse{})
-
-	if len(req.ID) > 0 {
-		countQuery = countQuery.Where("id IN ?", req.ID)
-	}
-
-	if len(req.QuotationCode) > 0 {
-		countQuery = countQuery.Where("quotation_code IN ?", req.QuotationCode)
-	}
-
-	if len(req.SiteCode) > 0 {
-		countQuery = countQuery.Where("site_code IN ?", req.SiteCode)
-	}
-
-	if len(req.CompanyCode) > 0 {
-		countQuery = countQuery.Where("company_code IN ?", req.CompanyCode)
-	}
-
-	if len(req.CustomerCode) > 0 {
-		countQuery = countQuery.Where("customer_code IN ?", req.CustomerCode)
-	}
-
-	if len(req.ProductCode) > 0 {
-		countQuery = countQuery.Joins("JOIN quotation_item ON quotation_item.quotation_id = quotation.id").
-			Where("quotation_item.product_code IN ?", req.ProductCode)
-	}
-	// Apply same filters to count query
-	if len(req.QuotationCodeLike) > 0 {
-		countQuery = countQuery.Where("quotation_code ILIKE ?", "%"+req.QuotationCodeLike+"%")
-	}
-
-	if len(req.CustomerCodeLike) > 0 {
-		countQuery = countQuery.Where("customer_code ILIKE ?", "%"+req.CustomerCodeLike+"%")
-	}
-
-	if len(req.CustomerNameLike) > 0 {
-		if len(customerCodesFromName) > 0 {
-			countQuery = countQuery.Where("customer_code IN ?", customerCodesFromName)
-		} else {
-			countQuery = countQuery.Where("1 = 0")
-		}
-	}
-
-	if req.CreateDateStart != nil && req.CreateDateEnd != nil {
-		countQuery = countQuery.Where("create_date BETWEEN ? AND ?", req.CreateDateStart, req.CreateDateEnd)
-	}
-
-	if req.ExpirePriceDateStart != nil && req.ExpirePriceDateEnd != nil {
-		countQuery = countQuery.Where("expire_price_date BETWEEN ? AND ?", req.ExpirePriceDateStart, req.ExpirePriceDateEnd)
-	}
-
-	if req.DeliveryDateStart != nil && req.DeliveryDateEnd != nil {
-		countQuery = countQuery.Where("delivery_date BETWEEN ? AND ?", req.DeliveryDateStart, req.DeliveryDateEnd)
-	}
-
-	if req.CompletedDateStart != nil && req.CompletedDateEnd != nil {
-		countQuery = countQuery.Where("update_date BETWEEN ? AND ? AND status = ?", req.CompletedDateStart, req.CompletedDateEnd, "COMPLETED")
-	}
-
-	if len(req.Status) > 0 {
-		countQuery = countQuery.Where("status IN ?", req.Status)
-	}
-
-	// Apply status filter conditions to count query
-	if len(req.StatusFilter) > 0 {
-		conditions, args := buildStatusConditions(req.StatusFilter)
-		if len(conditions) > 0 {
-			combinedCondition := "(" + strings.Join(conditions, " OR ") + ")"
-			countQuery = countQuery.Where(combinedCondition, args...)
-		}
-	}
-
-	var count int64
-	countQuery.Count(&count)
-
-	totalRecords := count
-	totalPages := 0
-	offset := (req.Page - 1) * req.PageSize
-	if totalRecords > 0 {
-		if req.PageSize > 0 && req.Page > 0 {
-			query = query.Limit(req.PageSize).Offset(offset)
-			totalPages = int(math.Ceil(float64(totalRecords) / float64(req.PageSize)))
-		} else {
-			query = query.Limit(int(totalRecords)).Offset(offset)
-			totalPages = (int(totalRecords) / 1)
-		}
-	}
-
-	if err := query.Find(&res).Error; err != nil {
-		fmt.Println(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve data"})
-		return nil, err
-	}
-
-	resultQuotation := ResultQuotationResponse{
-		Total:      int(totalRecords),
-		Page:       req.Page,
-		PageSize:   req.PageSize,
-		TotalPages: totalPages,
-		Quotations: res,
-	}
-
-	return resultQuotation, nil
-}
+package quotationService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	externalService "prime-erp-core/external/customer-service"
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GetQuotationRequest struct {
+	ID                   []string   `json:"id"`
+	QuotationCode        []string   `json:"quotation_code"`
+	SiteCode             []string   `json:"site_code"`
+	CompanyCode          []string   `json:"company_code"`
+	CustomerCode         []string   `json:"customer_code"`
+	ProductCode          []string   `json:"product_code"`
+	QuotationCodeLike    string     `json:"quotation_code_like"`
+	CustomerCodeLike     string     `json:"customer_code_like"`
+	CustomerNameLike     string     `json:"customer_name_like"`
+	CreateDateStart      *time.Time `json:"create_date_start"`
+	CreateDateEnd        *time.Time `json:"create_date_end"`
+	ExpirePriceDateStart *time.Time `json:"expire_price_date_start"`
+	ExpirePriceDateEnd   *time.Time `json:"expire_price_date_end"`
+	DeliveryDateStart    *time.Time `json:"delivery_date_start"`
+	DeliveryDateEnd      *time.Time `json:"delivery_date_end"`
+	CompletedDateStart   *time.Time `json:"completed_date_start"`
+	CompletedDateEnd     *time.Time `json:"completed_date_end"`
+	Status               []string   `json:"status"`
+	StatusFilter         []string   `json:"status_filter"` // สำหรับกรองตาม status ที่แสดงใน UI
+	Page                 int        `json:"page"`
+	PageSize             int        `json:"page_size"`
+}
+
+func (GetQuotationResponse) TableName() string { return "quotation" }
+
+func (GetQuotationItemResponse) TableName() string { return "quotation_item" }
+
+type GetQuotationResponse struct {
+	ID                          uuid.UUID                  `gorm:"type:uuid;primary_key" json:"id"`
+	QuotationCode               string                     `gorm:"type:varchar(50)" json:"quotation_code"`
+	CompanyCode                 string                     `gorm:"type:varchar(50)" json:"company_code"`
+	SiteCode                    string                     `gorm:"type:varchar(50)" json:"site_code"`
+	CustomerCode                string                     `gorm:"type:varchar(50)" json:"customer_code"`
+	CustomerName                string                     `gorm:"type:varchar(255)" json:"customer_name"`
+	DeliveryDate                *time.Time                 `gorm:"type:date" json:"delivery_date"`
+	SoldToCode                  string                     `gorm:"type:varchar(50)" json:"sold_to_code"`
+	SoldToAddress               string                     `gorm:"type:varchar(255)" json:"sold_to_address"`
+	BillToCode                  string                     `gorm:"type:varchar(50)" json:"bill_to_code"`
+	BillToAddress               string                     `gorm:"type:varchar(255)" json:"bill_to_address"`
+	ShipToCode                  string                     `gorm:"type:varchar(50)" json:"ship_to_code"`
+	ShipToType                  string                     `gorm:"type:varchar(50)" json:"ship_to_type"`
+	ShipToAddress               string                     `gorm:"type:varchar(255)" json:"ship_to_address"`
+	DeliveryMethod              string                     `gorm:"type:varchar(50)" json:"delivery_method"`
+	TransportCostType           string                     `gorm:"type:varchar(50)" json:"transport_cost_type"`
+	TotalTransportCost          float64                    `gorm:"type:numeric" json:"total_transport_cost"`
+	PassPrice                   bool                       `gorm:"type:boolean" json:"pass_price"`
+	TotalAmount                 float64                    `gorm:"type:numeric" json:"total_amount"` //TotalPrice
+	TotalWeight                 float64                    `gorm:"type:numeric" json:"total_weight"`
+	SubtotalExclTransport       float64                    `gorm:"type:numeric" json:"subtotal_excl_transport"`        //TotalNetPrice
+	SubtotalWeightExclTransport float64                    `gorm:"type:numeric" json:"subtotal_weight_excl_transport"` //TotalNetPriceWeight
+	PaymentMethod               string                     `gorm:"type:varchar(50)" json:"payment_method"`
+	PeymentTermCode             string                     `gorm:"type:varchar(50)" json:"peyment_term_code"`
+	SalePersonCode              string                     `gorm:"type:varchar(50)" json:"sale_person_code"`
+	EffectiveDatePrice          *time.Time                 `gorm:"type:date" json:"effective_date_price"`
+	ExpirePriceDay              int                        `gorm:"type:int" json:"expire_price_day"`
+	ExpirePriceDate             *time.Time                 `gorm:"type:date" json:"expire_price_date"`
+	PassPriceList               string                     `gorm:"type:varchar(50)" json:"pass_price_list"`
+	PassAtpCheck                string                     `gorm:"type:varchar(50)" json:"pass_atp_check"`
+	PassCreditLimit             string                     `gorm:"type:varchar(50)" json:"pass_credit_limit"`
+	PassPriceExpire             string                     `gorm:"type:varchar(50)" json:"pass_price_expire"`
+	Status                      string                     `gorm:"type:varchar(50)" json:"status"`
+	Remark                      string                     `gorm:"type:varchar(255)" json:"remark"`
+	IsApproved                  bool                       `gorm:"type:boolean" json:"is_approved"`
+	StatusApprove               string                     `gorm:"type:varchar(50)" json:"status_approve"`
+	TotalVat                    float64                    `gorm:"type:numeric" json:"total_vat"`
+	TotalDiscount               float64                    `gorm:"type:numeric" json:"total_discount"`
+	SubtotalExclVat             float64                    `gorm:"type:numeric" json:"subtotal_excl_vat"`
+	TotalTransportCostVat       float64                    `gorm:"type:numeric" json:"total_transport_cost_vat"`
+	RemarkApproval              string                     `gorm:"type:varchar(255)" json:"remark_approval"`
+	Revision                    float64                    `gorm:"type:numeric" json:"revision"`
+	QuotationCodeRef            string                     `gorm:"type:varchar(50)" json:"quotation_code_ref"`
+	CreditTerm                  string                     `gorm:"type:varchar(50)" json:"credit_term"`
+	PayerTerm                   string                     `gorm:"type:varchar(50)" json:"payer_term"`
+	CreateDate                  *time.Time                 `gorm:"type:date" json:"create_date"`
+	CreateBy                    string                     `gorm:"type:varchar(50)" json:"create_by"`
+	UpdateDate                  *time.Time                 `gorm:"type:date" json:"update_date"`
+	UpdateBy                    string                     `gorm:"type:varchar(50)" json:"update_by"`
+	Items                       []GetQuotationItemResponse `gorm:"foreignKey:QuotationID" json:"items"`
+}
+
+type GetQuotationItemResponse struct {
+	ID                             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	QuotationID                    uuid.UUID  `gorm:"type:uuid" json:"quotation_id"`
+	QuotationItem                  string     `gorm:"type:varchar(50)" json:"quotation_item"`
+	ProductCode                    string     `gorm:"type:varchar(50)" json:"product_code"`
+	ProductDesc                    string     `gorm:"type:varchar(255)" json:"product_desc"`
+	Qty                            float64    `gorm:"type:numeric" json:"qty"`
+	Unit                           string     `gorm:"type:varchar(50)" json:"unit"`
+	PriceListUnit                  float64    `gorm:"type:numeric" json:"price_list_unit"`
+	SaleQty                        float64    `gorm:"type:numeric" json:"sale_qty"`
+	SaleUnit                       string     `gorm:"type:varchar(50)" json:"sale_unit"`
+	SaleUnitType                   string     `gorm:"type:varchar(50)" json:"sale_unit_type"`
+	PassPrice                      string     `gorm:"type:varchar(50)" json:"pass_price"`
+	PassWeight                     string     `gorm:"type:varchar(50)" json:"pass_weight"`
+	PriceUnit                      float64    `gorm:"type:numeric" json:"price_unit"`
+	TotalAmount                    float64    `gorm:"type:numeric" json:"total_amount"` //TotalPrice
+	TransportCostUnit              float64    `gorm:"type:numeric" json:"transport_cost_unit"`
+	SubtotalExclTransport          float64    `gorm:"type:numeric" json:"subtotal_excl_transport"`       //TotalNetPrice
+	NetPriceUnitExclTransport      float64    `gorm:"type:numeric" json:"net_price_unit_excl_transport"` //NetPriceUnit
+	WeightUnit                     float64    `gorm:"type:numeric" json:"weight_unit"`
+	AvgWeightUnit                  float64    `gorm:"type:numeric" json:"avg_weight_unit"`
+	TotalWeight                    float64    `gorm:"type:numeric" json:"total_weight"`
+	TransportCostWeightUnit        float64    `gorm:"type:numeric" json:"transport_cost_weight_unit"`
+	SubtotalWeightExclTransport    float64    `gorm:"type:numeric" json:"subtotal_weight_excl_transport"`      //TotalNetPriceWeight
+	NetPricePerWeightExclTransport float64    `gorm:"type:numeric" json:"net_price_per_weight_excl_transport"` //NetPriceWeightUnit
+	Status                         string     `gorm:"type:varchar(50)" json:"status"`
+	Remark                         string     `gorm:"type:varchar(255)" json:"remark"`
+	SubtotalExclVat                float64    `gorm:"type:numeric" json:"subtotal_excl_vat"`
+	TotalVat                       float64    `gorm:"type:numeric" json:"total_vat"`
+	UnitUom                        string     `gorm:"type:varchar(50)" json:"unit_uom"`
+	TotalDiscount                  float64    `gorm:"type:numeric" json:"total_discount"`
+	TotalDiscountPercent           float64    `gorm:"type:numeric" json:"total_discount_percent"`
+	ConvertedQty                   float64    `gorm:"type:numeric" json:"converted_qty"`
+	RemainingQty                   float64    `gorm:"type:numeric" json:"remaining_qty"`
+	CreateDate                     *time.Time `gorm:"type:date" json:"create_date"`
+	CreateBy                       string     `gorm:"type:varchar(50)" json:"create_by"`
+	UpdateDate                     *time.Time `gorm:"type:date" json:"update_date"`
+	UpdateBy                       string     `gorm:"type:varchar(50)" json:"update_by"`
+}
+
+type ResultQuotationResponse struct {
+	Total      int                    `json:"total"`
+	Page       int                    `json:"page"`
+	PageSize   int                    `json:"page_size"`
+	TotalPages int                    `json:"total_pages"`
+	Quotations []GetQuotationResponse `json:"quotations"`
+}
+
+// quotationConvertedStatuses คือ status ของใบเสนอราคาที่แปลงเป็นใบสั่งขายครบแล้ว ('COMPLETED' เป็นค่าของข้อมูลเดิม)
+var quotationConvertedStatuses = []string{models.QuotationStatusConverted, "COMPLETED"}
+
+// buildStatusConditions สร้างเงื่อนไขการกรองตาม status ที่ซับซ้อน
+func buildStatusConditions(statusFilters []string) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	for _, statusFilter := range statusFilters {
+		switch strings.ToLower(statusFilter) {
+		case "new":
+			// status='PENDING' และ status_approve='PENDING' (ใน edit mode)
+			conditions = append(conditions, "(status = ? AND status_approve = ?)")
+			args = append(args, "PENDING", "PENDING")
+		case "create":
+			// status='PENDING' และ status_approve='PENDING' (ไม่ใช่ edit mode)
+			conditions = append(conditions, "(status = ? AND status_approve = ?)")
+			args = append(args, "PENDING", "PENDING")
+		case "pending":
+			// รวมทุกสถานะ pending
+			conditions = append(conditions, "(status = ? AND status_approve = ?)")
+			args = append(args, "PENDING", "PENDING")
+		case "wait for approve", "waitapprove":
+			// status='PENDING' และ status_approve='PROCESS'
+			conditions = append(conditions, "(status = ? AND status_approve = ?)")
+			args = append(args, "PENDING", "PROCESS")
+		case "approved":
+			// status='PENDING' หรือ 'PARTIALLY_CONVERTED' และ status_approve='COMPLETED' และยังไม่ overdue
+			conditions = append(conditions, "(status IN (?, ?) AND status_approve = ? AND (expire_price_date IS NULL OR expire_price_date >= CURRENT_DATE))")
+			args = append(args, "PENDING", models.QuotationStatusPartiallyConverted, "COMPLETED")
+		case "overdue":
+			// status='PENDING' หรือ 'PARTIALLY_CONVERTED' และ status_approve='COMPLETED' และ overdue
+			conditions = append(conditions, "(status IN (?, ?) AND status_approve = ? AND expire_price_date IS NOT NULL AND expire_price_date < CURRENT_DATE)")
+			args = append(args, "PENDING", models.QuotationStatusPartiallyConverted, "COMPLETED")
+		case "reject":
+			// status='PENDING' และ status_approve='REJECT'
+			conditions = append(conditions, "(status = ? AND status_approve = ?)")
+			args = append(args, "PENDING", "REJECT")
+		case "review":
+			// status='PENDING' และ status_approve='REVIEW'
+			conditions = append(conditions, "(status = ? AND status_approve = ?)")
+			args = append(args, "PENDING", "REVIEW")
+		case "canceled", "cancelled":
+			// status='CANCELED'
+			conditions = append(conditions, "status = ?")
+			args = append(args, "CANCELED")
+		case "draft":
+			// status='TEMP'
+			conditions = append(conditions, "status = ?")
+			args = append(args, "TEMP")
+		case "converted":
+			// status='CONVERTED' (หรือ 'COMPLETED' ของข้อมูลเดิม)
+			conditions = append(conditions, "status IN (?, ?)")
+			args = append(args, models.QuotationStatusConverted, "COMPLETED")
+		}
+	}
+
+	return conditions, args
+}
+
+// getCustomerCodesByName ค้นหา customer codes จาก customer service โดยใช้ customer name
+func getCustomerCodesByName(customerNameLike string) ([]string, error) {
+	if len(customerNameLike) == 0 {
+		return nil, nil
+	}
+
+	getCustomerByNameRequest := externalService.GetCustomerRequest{
+		CustomerNameLike: customerNameLike,
+		Page:             1,
+		PageSize:         1000, // เอาเยอะๆ เพื่อให้ได้ customerCode ทั้งหมดที่ match
+	}
+
+	customerByNameData, err := externalService.GetCustomer(getCustomerByNameRequest)
+	if err != nil {
+		fmt.Println("failed to fetch customers by name:", err)
+		return nil, errors.New("failed to fetch customers by name: " + err.Error())
+	}
+
+	fmt.Printf("Found %d customers matching name like '%s'\n", len(customerByNameData.Customers), customerNameLike)
+
+	// เก็บ customerCode ทั้งหมดที่ได้จากการค้นหาด้วย name
+	var customerCodes []string
+	for _, customer := range customerByNameData.Customers {
+		customerCodes = append(customerCodes, customer.CustomerCode)
+	}
+
+	fmt.Println("Customer codes from name search:", customerCodes)
+	return customerCodes, nil
+}
+
+func GetQuotation(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+
+	var res []GetQuotationResponse
+	var req GetQuotationRequest
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	gormx, err := db.FromContext(ctx).GORM("prime_erp")
+	if err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to connect to database"})
+		return nil, err
+	}
+
+	// ถ้ามี CustomerNameLike ให้ไปค้นหา customerCode จาก customer service ก่อน
+	customerCodesFromName, err := getCustomerCodesByName(req.CustomerNameLike)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, err
+	}
+
+	query := gormx.Preload("Items").
+		Order("quotation.update_date DESC")
+
+	if len(req.ID) > 0 {
+		query = query.Where("id IN ?", req.ID)
+	}
+
+	if len(req.QuotationCode) > 0 {
+		query = query.Where("quotation_code IN ?", req.QuotationCode)
+	}
+
+	if len(req.SiteCode) > 0 {
+		query = query.Where("site_code IN ?", req.SiteCode)
+	}
+
+	if len(req.CompanyCode) > 0 {
+		query = query.Where("company_code IN ?", req.CompanyCode)
+	}
+
+	if len(req.CustomerCode) > 0 {
+		query = query.Where("customer_code IN ?", req.CustomerCode)
+	}
+
+	if len(req.ProductCode) > 0 {
+		query = query.Joins("JOIN quotation_item ON quotation_item.quotation_id = quotation.id").
+			Where("quotation_item.product_code IN ?", req.ProductCode)
+	}
+
+	if len(req.QuotationCodeLike) > 0 {
+		query = query.Where("quotation_code ILIKE ?", "%"+req.QuotationCodeLike+"%")
+	}
+
+	if len(req.CustomerCodeLike) > 0 {
+		query = query.Where("customer_code ILIKE ?", "%"+req.CustomerCodeLike+"%")
+	}
+
+	if len(req.CustomerNameLike) > 0 {
+		// ใช้ customerCode ที่ได้จากการค้นหาด้วย customer name แทนการค้นหาตรงๆ ด้วย customer_name
+		if len(customerCodesFromName) > 0 {
+			query = query.Where("customer_code IN ?", customerCodesFromName)
+		} else {
+			// ถ้าไม่เจอ customer ใดๆ ที่ match กับ name ให้ return ข้อมูลว่างเปล่า
+			query = query.Where("1 = 0") // condition ที่จะไม่ match อะไรเลย
+		}
+	}
+
+	if req.CreateDateStart != nil && req.CreateDateEnd != nil {
+		query = query.Where("create_date BETWEEN ? AND ?", req.CreateDateStart, req.CreateDateEnd)
+	}
+
+	if req.ExpirePriceDateStart != nil && req.ExpirePriceDateEnd != nil {
+		query = query.Where("expire_price_date BETWEEN ? AND ?", req.ExpirePriceDateStart, req.ExpirePriceDateEnd)
+	}
+
+	if req.DeliveryDateStart != nil && req.DeliveryDateEnd != nil {
+		query = query.Where("delivery_date BETWEEN ? AND ?", req.DeliveryDateStart, req.DeliveryDateEnd)
+	}
+
+	if req.CompletedDateStart != nil && req.CompletedDateEnd != nil {
+		query = query.Where("update_date BETWEEN ? AND ? AND status IN ?", req.CompletedDateStart, req.CompletedDateEnd, quotationConvertedStatuses)
+	}
+
+	if len(req.Status) > 0 {
+		query = query.Where("status IN ?", req.Status)
+	}
+
+	// Apply status filter conditions
+	if len(req.StatusFilter) > 0 {
+		conditions, args := buildStatusConditions(req.StatusFilter)
+		if len(conditions) > 0 {
+			// Join conditions with OR
+			combinedCondition := "(" + strings.Join(conditions, " OR ") + ")"
+			query = query.Where(combinedCondition, args...)
+		}
+	}
+
+	// Build base query for counting
+	countQuery := gormx.Model(&GetQuotationResponse{})
+
+	if len(req.ID) > 0 {
+		countQuery = countQuery.Where("id IN ?", req.ID)
+	}
+
+	if len(req.QuotationCode) > 0 {
+		countQuery = countQuery.Where("quotation_code IN ?", req.QuotationCode)
+	}
+
+	if len(req.SiteCode) > 0 {
+		countQuery = countQuery.Where("site_code IN ?", req.SiteCode)
+	}
+
+	if len(req.CompanyCode) > 0 {
+		countQuery = countQuery.Where("company_code IN ?", req.CompanyCode)
+	}
+
+	if len(req.CustomerCode) > 0 {
+		countQuery = countQuery.Where("customer_code IN ?", req.CustomerCode)
+	}
+
+	if len(req.ProductCode) > 0 {
+		countQuery = countQuery.Joins("JOIN quotation_item ON quotation_item.quotation_id = quotation.id").
+			Where("quotation_item.product_code IN ?", req.ProductCode)
+	}
+	// Apply same filters to count query
+	if len(req.QuotationCodeLike) > 0 {
+		countQuery = countQuery.Where("quotation_code ILIKE ?", "%"+req.QuotationCodeLike+"%")
+	}
+
+	if len(req.CustomerCodeLike) > 0 {
+		countQuery = countQuery.Where("customer_code ILIKE ?", "%"+req.CustomerCodeLike+"%")
+	}
+
+	if len(req.CustomerNameLike) > 0 {
+		if len(customerCodesFromName) > 0 {
+			countQuery = countQuery.Where("customer_code IN ?", customerCodesFromName)
+		} else {
+			countQuery = countQuery.Where("1 = 0")
+		}
+	}
+
+	if req.CreateDateStart != nil && req.CreateDateEnd != nil {
+		countQuery = countQuery.Where("create_date BETWEEN ? AND ?", req.CreateDateStart, req.CreateDateEnd)
+	}
+
+	if req.ExpirePriceDateStart != nil && req.ExpirePriceDateEnd != nil {
+		countQuery = countQuery.Where("expire_price_date BETWEEN ? AND ?", req.ExpirePriceDateStart, req.ExpirePriceDateEnd)
+	}
+
+	if req.DeliveryDateStart != nil && req.DeliveryDateEnd != nil {
+		countQuery = countQuery.Where("delivery_date BETWEEN ? AND ?", req.DeliveryDateStart, req.DeliveryDateEnd)
+	}
+
+	if req.CompletedDateStart != nil && req.CompletedDateEnd != nil {
+		countQuery = countQuery.Where("update_date BETWEEN ? AND ? AND status IN ?", req.CompletedDateStart, req.CompletedDateEnd, quotationConvertedStatuses)
+	}
+
+	if len(req.Status) > 0 {
+		countQuery = countQuery.Where("status IN ?", req.Status)
+	}
+
+	// Apply status filter conditions to count query
+	if len(req.StatusFilter) > 0 {
+		conditions, args := buildStatusConditions(req.StatusFilter)
+		if len(conditions) > 0 {
+			combinedCondition := "(" + strings.Join(conditions, " OR ") + ")"
+			countQuery = countQuery.Where(combinedCondition, args...)
+		}
+	}
+
+	var count int64
+	countQuery.Count(&count)
+
+	totalRecords := count
+	totalPages := 0
+	offset := (req.Page - 1) * req.PageSize
+	if totalRecords > 0 {
+		if req.PageSize > 0 && req.Page > 0 {
+			query = query.Limit(req.PageSize).Offset(offset)
+			totalPages = int(math.Ceil(float64(totalRecords) / float64(req.PageSize)))
+		} else {
+			query = query.Limit(int(totalRecords)).Offset(offset)
+			totalPages = (int(totalRecords) / 1)
+		}
+	}
+
+	if err := query.Find(&res).Error; err != nil {
+		fmt.Println(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retrieve data"})
+		return nil, err
+	}
+
+	resultQuotation := ResultQuotationResponse{
+		Total:      int(totalRecords),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+		Quotations: res,
+	}
+
+	return resultQuotation, nil
+}
@@ -0,0 +1,20 @@
+package quotationService
+
+import (
+	"testing"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildStatusConditions_ConvertedStatuses(t *testing.T) {
+	conditions, args := buildStatusConditions([]string{"converted"})
+	assert.Equal(t, []string{"status IN (?, ?)"}, conditions)
+	assert.Equal(t, []interface{}{models.QuotationStatusConverted, "COMPLETED"}, args)
+
+	conditions, args = buildStatusConditions([]string{"approved"})
+	assert.Len(t, conditions, 1)
+	assert.Contains(t, args, models.QuotationStatusPartiallyConverted)
+	assert.NotContains(t, args, models.QuotationStatusConverted)
+}
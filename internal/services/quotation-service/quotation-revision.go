@@ -0,0 +1,345 @@
+package quotationService
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// quotationRevision is one revision of a quotation chain, either a saved snapshot or the live quotation row.
+type quotationRevision struct {
+	QuotationCode string                 `json:"quotation_code"`
+	Revision      float64                `json:"revision"`
+	IsCurrent     bool                   `json:"is_current"`
+	CreateBy      string                 `json:"create_by"`
+	CreateDtm     *time.Time             `json:"create_dtm"`
+	Quotation     models.Quotation       `json:"quotation"`
+	Items         []models.QuotationItem `json:"items"`
+}
+
+// Header and item fields that identify or audit a row rather than describe the offer; they are left out of diffs.
+var revisionDiffSkipFields = map[string]bool{
+	"id":                 true,
+	"quotation_id":       true,
+	"quotation_code":     true,
+	"quotation_code_ref": true,
+	"quotation_item":     true,
+	"revision":           true,
+	"status":             true,
+	"status_approve":     true,
+	"is_approved":        true,
+	"remark_approval":    true,
+	"converted_qty":      true,
+	"remaining_qty":      true,
+	"create_date":        true,
+	"create_by":          true,
+	"update_date":        true,
+	"update_by":          true,
+}
+
+// snapshotQuotationRevision freezes the quotation and its items under the current revision number
+// and moves the live quotation on to the next revision.
+func snapshotQuotationRevision(tx *gorm.DB, quotation models.Quotation, user string) error {
+	var items []models.QuotationItem
+	if err := tx.Where("quotation_id = ?", quotation.ID).Order("quotation_item").Find(&items).Error; err != nil {
+		return fmt.Errorf("failed to get quotation items: %v", err)
+	}
+
+	quotationJSON, err := json.Marshal(quotation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quotation: %v", err)
+	}
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quotation items: %v", err)
+	}
+
+	revision := models.QuotationRevision{
+		ID:            uuid.New(),
+		QuotationID:   quotation.ID,
+		QuotationCode: quotation.QuotationCode,
+		Revision:      quotation.Revision,
+		Quotation:     quotationJSON,
+		Items:         itemsJSON,
+		CreateBy:      user,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return fmt.Errorf("failed to save quotation revision: %v", err)
+	}
+
+	if err := tx.Model(&models.Quotation{}).
+		Where("id = ?", quotation.ID).
+		Update("revision", quotation.Revision+1).Error; err != nil {
+		return fmt.Errorf("failed to update quotation revision: %v", err)
+	}
+
+	return nil
+}
+
+// quotationChainCodes follows quotation_code_ref back to the first quotation and then forward to every
+// quotation copied from it, returning the codes oldest first.
+func quotationChainCodes(gormx *gorm.DB, quotationCode string) ([]string, error) {
+	seen := map[string]bool{quotationCode: true}
+	chain := []string{quotationCode}
+
+	code := quotationCode
+	for {
+		var ref []string
+		if err := gormx.Model(&models.Quotation{}).Where("quotation_code = ?", code).Pluck("quotation_code_ref", &ref).Error; err != nil {
+			return nil, fmt.Errorf("failed to get quotation %s: %v", code, err)
+		}
+		if len(ref) == 0 || ref[0] == "" || seen[ref[0]] {
+			break
+		}
+		code = ref[0]
+		seen[code] = true
+		chain = append([]string{code}, chain...)
+	}
+
+	next := chain
+	for len(next) > 0 {
+		var refs []string
+		if err := gormx.Model(&models.Quotation{}).Where("quotation_code_ref IN ?", next).Order("create_date, quotation_code").Pluck("quotation_code", &refs).Error; err != nil {
+			return nil, fmt.Errorf("failed to get quotation revisions: %v", err)
+		}
+		next = []string{}
+		for _, ref := range refs {
+			if !seen[ref] {
+				seen[ref] = true
+				chain = append(chain, ref)
+				next = append(next, ref)
+			}
+		}
+	}
+
+	return chain, nil
+}
+
+// loadQuotationRevisions returns every snapshot and live revision of the chain containing quotationCode, oldest first.
+func loadQuotationRevisions(gormx *gorm.DB, quotationCode string) ([]quotationRevision, error) {
+	chain, err := quotationChainCodes(gormx, quotationCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotations []models.Quotation
+	if err := gormx.Where("quotation_code IN ?", chain).Find(&quotations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quotations: %v", err)
+	}
+	if len(quotations) == 0 {
+		return nil, fmt.Errorf("quotation %s not found", quotationCode)
+	}
+
+	quotationIDs := []uuid.UUID{}
+	for _, quotation := range quotations {
+		quotationIDs = append(quotationIDs, quotation.ID)
+	}
+	var items []models.QuotationItem
+	if err := gormx.Where("quotation_id IN ?", quotationIDs).Order("quotation_item").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quotation items: %v", err)
+	}
+	itemsByQuotation := map[uuid.UUID][]models.QuotationItem{}
+	for _, item := range items {
+		itemsByQuotation[item.QuotationID] = append(itemsByQuotation[item.QuotationID], item)
+	}
+
+	var snapshots []models.QuotationRevision
+	if err := gormx.Where("quotation_code IN ?", chain).Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get quotation revisions: %v", err)
+	}
+
+	revisions := []quotationRevision{}
+	for _, snapshot := range snapshots {
+		revision := quotationRevision{
+			QuotationCode: snapshot.QuotationCode,
+			Revision:      snapshot.Revision,
+			CreateBy:      snapshot.CreateBy,
+			Items:         []models.QuotationItem{},
+		}
+		createDtm := snapshot.CreateDtm
+		revision.CreateDtm = &createDtm
+		if err := json.Unmarshal(snapshot.Quotation, &revision.Quotation); err != nil {
+			return nil, fmt.Errorf("failed to read quotation %s revision %v: %v", snapshot.QuotationCode, snapshot.Revision, err)
+		}
+		if err := json.Unmarshal(snapshot.Items, &revision.Items); err != nil {
+			return nil, fmt.Errorf("failed to read quotation %s revision %v items: %v", snapshot.QuotationCode, snapshot.Revision, err)
+		}
+		revisions = append(revisions, revision)
+	}
+	for _, quotation := range quotations {
+		revision := quotationRevision{
+			QuotationCode: quotation.QuotationCode,
+			Revision:      quotation.Revision,
+			IsCurrent:     true,
+			CreateBy:      quotation.UpdateBy,
+			CreateDtm:     quotation.UpdateDate,
+			Quotation:     quotation,
+			Items:         itemsByQuotation[quotation.ID],
+		}
+		if revision.Items == nil {
+			revision.Items = []models.QuotationItem{}
+		}
+		revisions = append(revisions, revision)
+	}
+
+	chainOrder := map[string]int{}
+	for i, code := range chain {
+		chainOrder[code] = i
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		if revisions[i].QuotationCode != revisions[j].QuotationCode {
+			return chainOrder[revisions[i].QuotationCode] < chainOrder[revisions[j].QuotationCode]
+		}
+		if revisions[i].Revision != revisions[j].Revision {
+			return revisions[i].Revision < revisions[j].Revision
+		}
+		return !revisions[i].IsCurrent && revisions[j].IsCurrent
+	})
+
+	return revisions, nil
+}
+
+type QuotationFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type QuotationItemDiff struct {
+	QuotationItem        string                 `json:"quotation_item"`
+	FromQuotationItem    string                 `json:"from_quotation_item"`
+	ProductCode          string                 `json:"product_code"`
+	QtyDelta             float64                `json:"qty_delta"`
+	PriceUnitDelta       float64                `json:"price_unit_delta"`
+	TotalAmountDelta     float64                `json:"total_amount_delta"`
+	SubtotalExclVatDelta float64                `json:"subtotal_excl_vat_delta"`
+	Changes              []QuotationFieldChange `json:"changes"`
+}
+
+type QuotationRevisionDiff struct {
+	HeaderChanges        []QuotationFieldChange `json:"header_changes"`
+	AddedItems           []models.QuotationItem `json:"added_items"`
+	RemovedItems         []models.QuotationItem `json:"removed_items"`
+	ChangedItems         []QuotationItemDiff    `json:"changed_items"`
+	TotalAmountDelta     float64                `json:"total_amount_delta"`
+	SubtotalExclVatDelta float64                `json:"subtotal_excl_vat_delta"`
+	TotalWeightDelta     float64                `json:"total_weight_delta"`
+	TotalDiscountDelta   float64                `json:"total_discount_delta"`
+}
+
+// diffQuotationRevisions compares two revisions. Items are paired by quotation_item first; leftovers are
+// paired by product code, since a quotation copied into a new code gets new item numbers.
+func diffQuotationRevisions(from, to quotationRevision) QuotationRevisionDiff {
+	diff := QuotationRevisionDiff{
+		HeaderChanges:        diffRevisionFields(from.Quotation, to.Quotation),
+		AddedItems:           []models.QuotationItem{},
+		RemovedItems:         []models.QuotationItem{},
+		ChangedItems:         []QuotationItemDiff{},
+		TotalAmountDelta:     to.Quotation.TotalAmount - from.Quotation.TotalAmount,
+		SubtotalExclVatDelta: to.Quotation.SubtotalExclVat - from.Quotation.SubtotalExclVat,
+		TotalWeightDelta:     to.Quotation.TotalWeight - from.Quotation.TotalWeight,
+		TotalDiscountDelta:   to.Quotation.TotalDiscount - from.Quotation.TotalDiscount,
+	}
+
+	fromByCode := map[string]int{}
+	for i, item := range from.Items {
+		fromByCode[item.QuotationItem] = i
+	}
+
+	matched := make([]bool, len(from.Items))
+	pairs := make([]int, len(to.Items))
+	for i, item := range to.Items {
+		pairs[i] = -1
+		if j, exists := fromByCode[item.QuotationItem]; exists && !matched[j] {
+			pairs[i] = j
+			matched[j] = true
+		}
+	}
+	for i, item := range to.Items {
+		if pairs[i] >= 0 {
+			continue
+		}
+		for j, fromItem := range from.Items {
+			if !matched[j] && fromItem.ProductCode == item.ProductCode {
+				pairs[i] = j
+				matched[j] = true
+				break
+			}
+		}
+	}
+
+	for i, item := range to.Items {
+		if pairs[i] < 0 {
+			diff.AddedItems = append(diff.AddedItems, item)
+			continue
+		}
+
+		fromItem := from.Items[pairs[i]]
+		changes := diffRevisionFields(fromItem, item)
+		if len(changes) == 0 {
+			continue
+		}
+		diff.ChangedItems = append(diff.ChangedItems, QuotationItemDiff{
+			QuotationItem:        item.QuotationItem,
+			FromQuotationItem:    fromItem.QuotationItem,
+			ProductCode:          item.ProductCode,
+			QtyDelta:             item.Qty - fromItem.Qty,
+			PriceUnitDelta:       item.PriceUnit - fromItem.PriceUnit,
+			TotalAmountDelta:     item.TotalAmount - fromItem.TotalAmount,
+			SubtotalExclVatDelta: item.SubtotalExclVat - fromItem.SubtotalExclVat,
+			Changes:              changes,
+		})
+	}
+	for j, item := range from.Items {
+		if !matched[j] {
+			diff.RemovedItems = append(diff.RemovedItems, item)
+		}
+	}
+
+	return diff
+}
+
+// diffRevisionFields lists the json fields of two structs of the same type whose values differ.
+func diffRevisionFields(from, to interface{}) []QuotationFieldChange {
+	changes := []QuotationFieldChange{}
+
+	fromValue := reflect.ValueOf(from)
+	toValue := reflect.ValueOf(to)
+	for i := 0; i < fromValue.NumField(); i++ {
+		field := fromValue.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || revisionDiffSkipFields[name] {
+			continue
+		}
+
+		fromField := revisionFieldValue(fromValue.Field(i))
+		toField := revisionFieldValue(toValue.Field(i))
+		if !reflect.DeepEqual(fromField, toField) {
+			changes = append(changes, QuotationFieldChange{Field: name, From: fromField, To: toField})
+		}
+	}
+
+	return changes
+}
+
+// revisionFieldValue flattens pointers and compares dates by calendar day, which is how quotations store them.
+func revisionFieldValue(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if t, ok := value.Interface().(time.Time); ok {
+		return t.Format("2006-01-02")
+	}
+	return value.Interface()
+}
@@ -0,0 +1,76 @@
+package quotationService
+
+import (
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffQuotationRevisions(t *testing.T) {
+	delivery := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	newDelivery := delivery.AddDate(0, 0, 5)
+
+	from := quotationRevision{
+		Quotation: models.Quotation{QuotationCode: "QT1", Revision: 0, TotalAmount: 1000, DeliveryDate: &delivery, PaymentMethod: "CASH", UpdateBy: "rep1"},
+		Items: []models.QuotationItem{
+			{QuotationItem: "10", ProductCode: "P1", Qty: 10, PriceUnit: 50, TotalAmount: 500},
+			{QuotationItem: "20", ProductCode: "P2", Qty: 5, PriceUnit: 100, TotalAmount: 500},
+		},
+	}
+	to := quotationRevision{
+		Quotation: models.Quotation{QuotationCode: "QT1", Revision: 1, TotalAmount: 1140, DeliveryDate: &newDelivery, PaymentMethod: "CASH", UpdateBy: "rep2"},
+		Items: []models.QuotationItem{
+			{QuotationItem: "10", ProductCode: "P1", Qty: 12, PriceUnit: 45, TotalAmount: 540, UpdateBy: "rep2"},
+			{QuotationItem: "30", ProductCode: "P3", Qty: 2, PriceUnit: 300, TotalAmount: 600},
+		},
+	}
+
+	diff := diffQuotationRevisions(from, to)
+
+	assert.Equal(t, 140.0, diff.TotalAmountDelta)
+	assert.Equal(t, []QuotationFieldChange{
+		{Field: "delivery_date", From: "2025-01-10", To: "2025-01-15"},
+		{Field: "total_amount", From: 1000.0, To: 1140.0},
+	}, diff.HeaderChanges, "audit fields and unchanged fields are not reported")
+
+	assert.Len(t, diff.ChangedItems, 1)
+	assert.Equal(t, "10", diff.ChangedItems[0].QuotationItem)
+	assert.Equal(t, 2.0, diff.ChangedItems[0].QtyDelta)
+	assert.Equal(t, -5.0, diff.ChangedItems[0].PriceUnitDelta)
+	assert.Equal(t, 40.0, diff.ChangedItems[0].TotalAmountDelta)
+
+	assert.Len(t, diff.AddedItems, 1)
+	assert.Equal(t, "30", diff.AddedItems[0].QuotationItem)
+	assert.Len(t, diff.RemovedItems, 1)
+	assert.Equal(t, "20", diff.RemovedItems[0].QuotationItem)
+}
+
+func TestDiffQuotationRevisions_PairsCopiedItemsByProduct(t *testing.T) {
+	from := quotationRevision{Items: []models.QuotationItem{{QuotationItem: "a1", ProductCode: "P1", Qty: 10}}}
+	to := quotationRevision{Items: []models.QuotationItem{{QuotationItem: "b1", ProductCode: "P1", Qty: 8}}}
+
+	diff := diffQuotationRevisions(from, to)
+
+	assert.Empty(t, diff.AddedItems)
+	assert.Empty(t, diff.RemovedItems)
+	assert.Len(t, diff.ChangedItems, 1)
+	assert.Equal(t, "a1", diff.ChangedItems[0].FromQuotationItem)
+	assert.Equal(t, -2.0, diff.ChangedItems[0].QtyDelta)
+}
+
+func TestFindQuotationRevision(t *testing.T) {
+	revisions := []quotationRevision{
+		{QuotationCode: "QT1", Revision: 0},
+		{QuotationCode: "QT1", Revision: 1, IsCurrent: true},
+		{QuotationCode: "QT2", Revision: 0, IsCurrent: true},
+	}
+	zero := 0.0
+
+	assert.Equal(t, 1, findQuotationRevision(revisions, QuotationRevisionRef{QuotationCode: "QT1"}))
+	assert.Equal(t, 0, findQuotationRevision(revisions, QuotationRevisionRef{QuotationCode: "QT1", Revision: &zero}))
+	assert.Equal(t, 2, findQuotationRevision(revisions, QuotationRevisionRef{QuotationCode: "QT2", Revision: &zero}))
+	assert.Equal(t, -1, findQuotationRevision(revisions, QuotationRevisionRef{QuotationCode: "QT3"}))
+}
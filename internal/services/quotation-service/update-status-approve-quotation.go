@@ -1,152 +1,229 @@
-package quotationService
-
-import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"strconv"
-	"time"
-
-	"prime-erp-core/internal/db"
-	"prime-erp-core/internal/models"
-	approvalService "prime-erp-core/internal/services/approval-service"
-	verifyService "prime-erp-core/internal/services/verify-service"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-type UpdateStatusApproveQuotationRequest struct {
-	ID         uuid.UUID `json:"id"`
-	ApprovalID uuid.UUID `json:"approval_id"`
-	Status     string    `json:"status"` // REVIEW, REJECT, COMPLETED
-	Remark     string    `json:"remark"`
-}
-
-func UpdateStatusApproveQuotation(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-	req := UpdateStatusApproveQuotationRequest{}
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-
-	sqlx, err := db.ConnectSqlx(`prime_erp`)
-	if err != nil {
-		return nil, err
-	}
-	defer sqlx.Close()
-
-	gormx, err := db.ConnectGORM(`prime_erp`)
-	if err != nil {
-		return nil, err
-	}
-	defer db.CloseGORM(gormx)
-
-	updateApprovalReq := []struct {
-		ID     uuid.UUID `json:"id"`
-		Status string    `json:"status"`
-		Remark string    `json:"remark"`
-	}{
-		{
-			ID:     req.ApprovalID,
-			Status: req.Status,
-			Remark: req.Remark,
-		},
-	}
-
-	updateApprovalPayload, _ := json.Marshal(updateApprovalReq)
-	approvalResult, err := approvalService.UpdateApproval(ctx, string(updateApprovalPayload))
-	if err != nil {
-		return nil, fmt.Errorf("failed to update approval: %v", err)
-	}
-
-	// Update quotation based on approval status
-	var quotationStatus, quotationStatusApprove string
-
-	switch req.Status {
-	case "REVIEW":
-		quotationStatus = "PENDING"
-		quotationStatusApprove = "REVIEW"
-	case "REJECT":
-		quotationStatus = "CANCELED"
-		quotationStatusApprove = "REJECT"
-	case "COMPLETED":
-		quotationStatus = "PENDING"
-		quotationStatusApprove = "COMPLETED"
-	default:
-		return nil, fmt.Errorf("invalid status: %s", req.Status)
-	}
-
-	now := time.Now()
-	nowDateOnly := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-
-	updateFields := map[string]interface{}{
-		"status":          quotationStatus,
-		"status_approve":  quotationStatusApprove,
-		"remark_approval": req.Remark,
-		"update_date":     nowDateOnly,
-	}
-	if req.Status == "COMPLETED" {
-		// Query existing quotation to check expire_price_date
-		var existingQuotation models.Quotation
-		if err := gormx.Where("id = ?", req.ID).First(&existingQuotation).Error; err != nil {
-			return nil, fmt.Errorf("failed to get existing quotation: %v", err)
-		}
-
-		// Check if expire_price_date has expired
-		now := time.Now()
-		isPriceExpired := existingQuotation.ExpirePriceDate == nil || existingQuotation.ExpirePriceDate.Before(now)
-
-		if isPriceExpired {
-			//get config
-			topic := `PRICE`
-			configCodes := []string{`EXPIRY_PRICE_DAYS`}
-			configMap, err := verifyService.GetConfigSystem(gormx, topic, configCodes)
-			if err != nil {
-				return nil, err
-			}
-
-			expiryDaysConfig, exists := configMap[fmt.Sprintf(`%s|%s`, topic, `EXPIRY_PRICE_DAYS`)]
-			if !exists {
-				return nil, errors.New("missing configuration for expiry price days")
-			}
-
-			expiryDays, err := strconv.ParseInt(expiryDaysConfig.Value, 10, 64)
-			if err != nil {
-				return nil, errors.New("failed to convert expiry days to int64: " + err.Error())
-			}
-
-			expiryDateTemp := time.Now().AddDate(0, 0, int(expiryDays))
-			expiryDate := time.Date(expiryDateTemp.Year(), expiryDateTemp.Month(), expiryDateTemp.Day(), 0, 0, 0, 0, expiryDateTemp.Location())
-
-			updateFields["effective_date_price"] = nowDateOnly
-			updateFields["expire_price_day"] = int(expiryDays)
-			updateFields["expire_price_date"] = &expiryDate
-		}
-		updateFields["is_approved"] = true
-	}
-
-	if err := gormx.Model(&models.Quotation{}).
-		Where("id = ?", req.ID).
-		Updates(updateFields).Error; err != nil {
-		return nil, fmt.Errorf("failed to update quotation status: %v", err)
-	}
-
-	// If status is REJECT, also update quotation_item status to CANCELED
-	if req.Status == "REJECT" {
-		if err := gormx.Model(&models.QuotationItem{}).
-			Where("quotation_id = ?", req.ID).
-			Updates(map[string]interface{}{
-				"status":      "CANCELED",
-				"update_date": nowDateOnly,
-			}).Error; err != nil {
-			return nil, fmt.Errorf("failed to update quotation items status: %v", err)
-		}
-	}
-
-	return map[string]interface{}{
-		"external_response": approvalResult,
-		"status":            "success",
-		"message":           "Approval updated successfully",
-	}, nil
-}
+package quotationService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prime-erp-core/internal/middleware"
+	"strconv"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+	repositoryApproval "prime-erp-core/internal/repositories/approval"
+	approvalService "prime-erp-core/internal/services/approval-service"
+	verifyService "prime-erp-core/internal/services/verify-service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func init() {
+	approvalService.RegisterRejectHandler("QUOTATION", rejectQuotationApproval)
+}
+
+type UpdateStatusApproveQuotationRequest struct {
+	ID            uuid.UUID `json:"id"`
+	ApprovalID    uuid.UUID `json:"approval_id"`
+	Status        string    `json:"status"` // REVIEW, REJECT, COMPLETED
+	Remark        string    `json:"remark"`
+	IsFinalReject bool      `json:"is_final_reject"` // reject the quotation instead of returning the approval to its previous step
+}
+
+func UpdateStatusApproveQuotation(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := UpdateStatusApproveQuotationRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Status {
+	case "REVIEW", "REJECT", "COMPLETED":
+	default:
+		return nil, fmt.Errorf("invalid status: %s", req.Status)
+	}
+
+	// Approve and reject go through the approval workflow; the quotation only follows once it reaches its final step,
+	// in the same transaction so the approval is rolled back when the quotation cannot be updated.
+	user := middleware.GetUserCode(ctx)
+	var approvalResult interface{}
+	var actResult *approvalService.ApprovalActResult
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		if req.Status == "REVIEW" {
+			rowsAffected, err := repositoryApproval.UpdateApprovalTx(tx, []models.Approval{{
+				ID:       req.ApprovalID,
+				Status:   req.Status,
+				Remark:   req.Remark,
+				UpdateBy: user,
+			}})
+			if err != nil {
+				return fmt.Errorf("failed to update approval: %v", err)
+			}
+			message := "Approval updated successfully"
+			if rowsAffected == 0 {
+				message = "Approval Not Have Rows Affected"
+			}
+			approvalResult = map[string]interface{}{"status": "success", "message": message}
+			return updateQuotationApproval(tx, user, req)
+		}
+
+		result, err := approvalService.ApplyApprovalDecision(tx, req.ApprovalID, user, req.Status, req.Remark, req.IsFinalReject)
+		if err != nil {
+			return err
+		}
+		actResult = result
+		approvalResult = result
+		if !result.IsFinal {
+			return nil
+		}
+		return updateQuotationApproval(tx, user, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if actResult != nil && !actResult.IsFinal {
+		return map[string]interface{}{
+			"external_response": actResult,
+			"status":            "success",
+			"message":           fmt.Sprintf("Approval moved to step %d", actResult.CurentStepSeq),
+		}, nil
+	}
+
+	return map[string]interface{}{
+		"external_response": approvalResult,
+		"status":            "success",
+		"message":           "Approval updated successfully",
+	}, nil
+}
+
+// updateQuotationApproval moves the quotation to where its approval ended.
+func updateQuotationApproval(tx *gorm.DB, user string, req UpdateStatusApproveQuotationRequest) error {
+	// Update quotation based on approval status
+	var quotationStatus, quotationStatusApprove string
+
+	switch req.Status {
+	case "REVIEW":
+		quotationStatus = "PENDING"
+		quotationStatusApprove = "REVIEW"
+	case "REJECT":
+		quotationStatus = "CANCELED"
+		quotationStatusApprove = "REJECT"
+	case "COMPLETED":
+		quotationStatus = "PENDING"
+		quotationStatusApprove = "COMPLETED"
+	default:
+		return fmt.Errorf("invalid status: %s", req.Status)
+	}
+
+	now := time.Now()
+	nowDateOnly := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	updateFields := map[string]interface{}{
+		"status":          quotationStatus,
+		"status_approve":  quotationStatusApprove,
+		"remark_approval": req.Remark,
+		"update_date":     nowDateOnly,
+		"update_by":       user,
+	}
+	if req.Status == "COMPLETED" {
+		// Query existing quotation to check expire_price_date
+		var existingQuotation models.Quotation
+		if err := tx.Where("id = ?", req.ID).First(&existingQuotation).Error; err != nil {
+			return fmt.Errorf("failed to get existing quotation: %v", err)
+		}
+
+		// Check if expire_price_date has expired
+		now := time.Now()
+		isPriceExpired := existingQuotation.ExpirePriceDate == nil || existingQuotation.ExpirePriceDate.Before(now)
+
+		if isPriceExpired {
+			//get config
+			topic := `PRICE`
+			configCodes := []string{`EXPIRY_PRICE_DAYS`}
+			configMap, err := verifyService.GetConfigSystem(tx, topic, configCodes)
+			if err != nil {
+				return err
+			}
+
+			expiryDaysConfig, exists := configMap[fmt.Sprintf(`%s|%s`, topic, `EXPIRY_PRICE_DAYS`)]
+			if !exists {
+				return errors.New("missing configuration for expiry price days")
+			}
+
+			expiryDays, err := strconv.ParseInt(expiryDaysConfig.Value, 10, 64)
+			if err != nil {
+				return errors.New("failed to convert expiry days to int64: " + err.Error())
+			}
+
+			expiryDateTemp := time.Now().AddDate(0, 0, int(expiryDays))
+			expiryDate := time.Date(expiryDateTemp.Year(), expiryDateTemp.Month(), expiryDateTemp.Day(), 0, 0, 0, 0, expiryDateTemp.Location())
+
+			updateFields["effective_date_price"] = nowDateOnly
+			updateFields["expire_price_day"] = int(expiryDays)
+			updateFields["expire_price_date"] = &expiryDate
+		}
+		updateFields["is_approved"] = true
+	}
+
+	if err := tx.Model(&models.Quotation{}).
+		Where("id = ?", req.ID).
+		Updates(updateFields).Error; err != nil {
+		return fmt.Errorf("failed to update quotation status: %v", err)
+	}
+
+	// If status is REJECT, also update quotation_item status to CANCELED
+	if req.Status == "REJECT" {
+		if err := tx.Model(&models.QuotationItem{}).
+			Where("quotation_id = ?", req.ID).
+			Updates(map[string]interface{}{
+				"status":      "CANCELED",
+				"update_date": nowDateOnly,
+				"update_by":   user,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to update quotation items status: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// rejectQuotationApproval cancels the quotation of an approval the escalation job auto-rejected.
+func rejectQuotationApproval(tx *gorm.DB, approval models.Approval, remark string) error {
+	var quotation models.Quotation
+	if err := tx.Where("quotation_code = ?", approval.DocumentCode).First(&quotation).Error; err != nil {
+		return fmt.Errorf("failed to get quotation %s: %v", approval.DocumentCode, err)
+	}
+
+	now := time.Now()
+	nowDateOnly := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if err := tx.Model(&models.Quotation{}).
+		Where("id = ?", quotation.ID).
+		Updates(map[string]interface{}{
+			"status":          "CANCELED",
+			"status_approve":  "REJECT",
+			"remark_approval": remark,
+			"update_date":     nowDateOnly,
+			"update_by":       middleware.SystemUser,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update quotation status: %v", err)
+	}
+
+	if err := tx.Model(&models.QuotationItem{}).
+		Where("quotation_id = ?", quotation.ID).
+		Updates(map[string]interface{}{
+			"status":      "CANCELED",
+			"update_date": nowDateOnly,
+			"update_by":   middleware.SystemUser,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update quotation items status: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package saleService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GetSaleStatusHistoryRequest struct {
+	SaleID   []uuid.UUID `json:"sale_id"`
+	SaleCode []string    `json:"sale_code"`
+}
+
+func GetSaleStatusHistory(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetSaleStatusHistoryRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	if len(req.SaleID) == 0 && len(req.SaleCode) == 0 {
+		return nil, fmt.Errorf("sale_id or sale_code is required")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	query := gormx.Model(&models.SaleStatusHistory{})
+	if len(req.SaleID) > 0 {
+		query = query.Where("sale_id IN ?", req.SaleID)
+	}
+	if len(req.SaleCode) > 0 {
+		query = query.Where("sale_code IN ?", req.SaleCode)
+	}
+
+	histories := []models.SaleStatusHistory{}
+	if err := query.Order("sale_code, create_dtm").Find(&histories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sale status history: %v", err)
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,157 @@
+package saleService
+
+import (
+	"fmt"
+
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// qtyTolerance absorbs float rounding when comparing ordered and open quantities.
+const qtyTolerance = 0.000001
+
+type quotationItemConversion struct {
+	Item         models.QuotationItem
+	ConvertedQty float64
+	RemainingQty float64
+	Status       string
+}
+
+// planQuotationConversion works out the converted and remaining qty of every quotation item touched by saleItems.
+// Sale items point at quotation lines through document_ref/document_ref_item; when none do, the whole
+// quotation is treated as ordered, which is how sales were created from quotations before line tracking.
+func planQuotationConversion(quotation models.Quotation, items []models.QuotationItem, saleItems []models.SaleItem) ([]quotationItemConversion, string, error) {
+	switch quotation.Status {
+	case "CANCELED", "COMPLETED", models.QuotationStatusConverted: // COMPLETED is how fully ordered quotations were marked before
+		return nil, "", &utils.ConflictError{
+			Code:    "QUOTATION_NOT_CONVERTIBLE",
+			Message: fmt.Sprintf("quotation %s is %s and cannot be converted", quotation.QuotationCode, quotation.Status),
+		}
+	}
+
+	itemsByCode := map[string]models.QuotationItem{}
+	for _, item := range items {
+		itemsByCode[item.QuotationItem] = item
+	}
+
+	ordered := map[string]float64{}
+	for _, saleItem := range saleItems {
+		if saleItem.DocumentRefItem == "" || (saleItem.DocumentRef != "" && saleItem.DocumentRef != quotation.QuotationCode) {
+			continue
+		}
+		if _, exists := itemsByCode[saleItem.DocumentRefItem]; !exists {
+			return nil, "", &utils.ConflictError{
+				Code:    "QUOTATION_ITEM_NOT_FOUND",
+				Message: fmt.Sprintf("quotation %s has no item %s", quotation.QuotationCode, saleItem.DocumentRefItem),
+			}
+		}
+		ordered[saleItem.DocumentRefItem] += saleItem.Qty
+	}
+	if len(ordered) == 0 {
+		for _, item := range items {
+			if item.Status != "CANCELED" {
+				ordered[item.QuotationItem] = item.Qty - item.ConvertedQty
+			}
+		}
+	}
+
+	conversions := []quotationItemConversion{}
+	allConverted := true
+	for _, item := range items {
+		qty, touched := ordered[item.QuotationItem]
+		remaining := item.Qty - item.ConvertedQty
+		if !touched {
+			if remaining > qtyTolerance && item.Status != "CANCELED" {
+				allConverted = false
+			}
+			continue
+		}
+
+		if item.Status == "CANCELED" {
+			return nil, "", &utils.ConflictError{
+				Code:    "QUOTATION_NOT_CONVERTIBLE",
+				Message: fmt.Sprintf("quotation %s item %s is cancelled", quotation.QuotationCode, item.QuotationItem),
+			}
+		}
+		if qty > remaining+qtyTolerance {
+			return nil, "", &utils.ConflictError{
+				Code:    "QUOTATION_OVER_CONVERTED",
+				Message: fmt.Sprintf("quotation %s item %s has %v open but %v was ordered", quotation.QuotationCode, item.QuotationItem, remaining, qty),
+				Details: map[string]interface{}{
+					"quotation_item": item.QuotationItem,
+					"remaining_qty":  remaining,
+					"ordered_qty":    qty,
+				},
+			}
+		}
+
+		conversion := quotationItemConversion{
+			Item:         item,
+			ConvertedQty: item.ConvertedQty + qty,
+			RemainingQty: remaining - qty,
+			Status:       models.QuotationStatusPartiallyConverted,
+		}
+		if conversion.RemainingQty <= qtyTolerance {
+			conversion.RemainingQty = 0
+			conversion.Status = models.QuotationStatusConverted
+		} else {
+			allConverted = false
+		}
+		conversions = append(conversions, conversion)
+	}
+
+	if allConverted {
+		return conversions, models.QuotationStatusConverted, nil
+	}
+	return conversions, models.QuotationStatusPartiallyConverted, nil
+}
+
+// applyQuotationConversion records the sale items ordered from a quotation on its items and moves the
+// quotation to PARTIALLY_CONVERTED or CONVERTED. The quotation row is locked so concurrent orders cannot over-convert it.
+func applyQuotationConversion(tx *gorm.DB, user string, quotationID uuid.UUID, saleItems []models.SaleItem) error {
+	var quotation models.Quotation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", quotationID).First(&quotation).Error; err != nil {
+		return fmt.Errorf("failed to get quotation %v: %v", quotationID, err)
+	}
+
+	var items []models.QuotationItem
+	if err := tx.Where("quotation_id = ?", quotationID).Find(&items).Error; err != nil {
+		return fmt.Errorf("failed to get quotation items: %v", err)
+	}
+
+	conversions, status, err := planQuotationConversion(quotation, items, saleItems)
+	if err != nil {
+		return err
+	}
+
+	now := tx.NowFunc()
+	for _, conversion := range conversions {
+		if err := tx.Model(&models.QuotationItem{}).
+			Where("id = ?", conversion.Item.ID).
+			Updates(map[string]interface{}{
+				"converted_qty": conversion.ConvertedQty,
+				"remaining_qty": conversion.RemainingQty,
+				"status":        conversion.Status,
+				"update_date":   now,
+				"update_by":     user,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to update quotation item %s: %v", conversion.Item.QuotationItem, err)
+		}
+	}
+
+	if err := tx.Model(&models.Quotation{}).
+		Where("id = ?", quotationID).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"update_date": now,
+			"update_by":   user,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update quotation status: %v", err)
+	}
+
+	return nil
+}
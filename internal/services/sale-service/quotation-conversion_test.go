@@ -0,0 +1,91 @@
+package saleService
+
+import (
+	"errors"
+	"testing"
+
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func conversionQuotation() (models.Quotation, []models.QuotationItem) {
+	quotation := models.Quotation{QuotationCode: "QT1", Status: "PENDING"}
+	items := []models.QuotationItem{
+		{QuotationItem: "10", Qty: 10, RemainingQty: 10, Status: "PENDING"},
+		{QuotationItem: "20", Qty: 5, RemainingQty: 5, Status: "PENDING"},
+	}
+	return quotation, items
+}
+
+func TestPlanQuotationConversion_Partial(t *testing.T) {
+	quotation, items := conversionQuotation()
+	saleItems := []models.SaleItem{{DocumentRef: "QT1", DocumentRefItem: "10", Qty: 4}}
+
+	conversions, status, err := planQuotationConversion(quotation, items, saleItems)
+	assert.NoError(t, err)
+	assert.Equal(t, models.QuotationStatusPartiallyConverted, status)
+	assert.Len(t, conversions, 1)
+	assert.Equal(t, 4.0, conversions[0].ConvertedQty)
+	assert.Equal(t, 6.0, conversions[0].RemainingQty)
+	assert.Equal(t, models.QuotationStatusPartiallyConverted, conversions[0].Status)
+}
+
+func TestPlanQuotationConversion_CompletesPartiallyConverted(t *testing.T) {
+	quotation, items := conversionQuotation()
+	quotation.Status = models.QuotationStatusPartiallyConverted
+	items[0].ConvertedQty, items[0].RemainingQty = 4, 6
+	saleItems := []models.SaleItem{
+		{DocumentRef: "QT1", DocumentRefItem: "10", Qty: 6},
+		{DocumentRef: "QT1", DocumentRefItem: "20", Qty: 5},
+	}
+
+	conversions, status, err := planQuotationConversion(quotation, items, saleItems)
+	assert.NoError(t, err)
+	assert.Equal(t, models.QuotationStatusConverted, status)
+	for _, conversion := range conversions {
+		assert.Equal(t, models.QuotationStatusConverted, conversion.Status)
+		assert.Zero(t, conversion.RemainingQty)
+	}
+}
+
+func TestPlanQuotationConversion_OverConverted(t *testing.T) {
+	quotation, items := conversionQuotation()
+	items[1].ConvertedQty, items[1].RemainingQty = 3, 2
+	saleItems := []models.SaleItem{{DocumentRef: "QT1", DocumentRefItem: "20", Qty: 3}}
+
+	_, _, err := planQuotationConversion(quotation, items, saleItems)
+	var conflictErr *utils.ConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "QUOTATION_OVER_CONVERTED", conflictErr.Code)
+}
+
+func TestPlanQuotationConversion_LegacyWholeQuotation(t *testing.T) {
+	quotation, items := conversionQuotation()
+	items[1].Status = "CANCELED"
+
+	conversions, status, err := planQuotationConversion(quotation, items, []models.SaleItem{{ProductCode: "P1", Qty: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, models.QuotationStatusConverted, status)
+	assert.Len(t, conversions, 1, "canceled lines are not converted")
+	assert.Equal(t, 10.0, conversions[0].ConvertedQty)
+}
+
+func TestPlanQuotationConversion_NotConvertible(t *testing.T) {
+	for _, status := range []string{"CANCELED", "COMPLETED", models.QuotationStatusConverted} {
+		quotation, items := conversionQuotation()
+		quotation.Status = status
+
+		_, _, err := planQuotationConversion(quotation, items, nil)
+		var conflictErr *utils.ConflictError
+		assert.True(t, errors.As(err, &conflictErr), status)
+		assert.Equal(t, "QUOTATION_NOT_CONVERTIBLE", conflictErr.Code)
+	}
+
+	quotation, items := conversionQuotation()
+	_, _, err := planQuotationConversion(quotation, items, []models.SaleItem{{DocumentRef: "QT1", DocumentRefItem: "99", Qty: 1}})
+	var conflictErr *utils.ConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "QUOTATION_ITEM_NOT_FOUND", conflictErr.Code)
+}
@@ -0,0 +1,428 @@
+package saleService
+
+import (
+	"fmt"
+	"time"
+
+	"prime-erp-core/internal/models"
+	repositoryCredit "prime-erp-core/internal/repositories/credit"
+	creditService "prime-erp-core/internal/services/credit-service"
+	outboxService "prime-erp-core/internal/services/outbox-service"
+	"prime-erp-core/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	saleHistoryFieldStatus  = "status"
+	saleHistoryFieldApprove = "status_approve"
+)
+
+// queueSaleCreditLedgerPost posts what sales now use of their customers' credit, with their invoices, in tx.
+func queueSaleCreditLedgerPost(tx *gorm.DB, user string, sales ...models.Sale) error {
+	saleCodes := make([]string, 0, len(sales))
+	customerCodes := make([]string, 0, len(sales))
+	for _, sale := range sales {
+		saleCodes = append(saleCodes, sale.SaleCode)
+		customerCodes = append(customerCodes, sale.CustomerCode)
+	}
+	postings, err := repositoryCredit.SalePostings(tx, saleCodes, time.Now())
+	if err != nil {
+		return err
+	}
+
+	post := outboxService.CreditLedgerPost{SourceType: models.CreditSourceSale, Postings: postings, CustomerCodes: customerCodes}
+	if len(sales) == 1 {
+		post.SourceRef = sales[0].SaleCode
+	}
+	return outboxService.QueueCreditLedgerPost(tx, user, post)
+}
+
+// saleTransitions lists the statuses a sale may move to from each status; COMPLETED and CANCELED are final.
+var saleTransitions = map[string][]string{
+	models.SaleStatusPending:          {models.SaleStatusApproved, models.SaleStatusCanceled},
+	models.SaleStatusApproved:         {models.SaleStatusPending, models.SaleStatusPartialDelivered, models.SaleStatusDelivered, models.SaleStatusCompleted, models.SaleStatusCanceled},
+	models.SaleStatusPartialDelivered: {models.SaleStatusDelivered, models.SaleStatusCompleted},
+	models.SaleStatusDelivered:        {models.SaleStatusCompleted},
+}
+
+// saleItemTransitions is the same lifecycle for items, which follow the approval of their sale instead of having their own.
+var saleItemTransitions = map[string][]string{
+	models.SaleStatusPending:          {models.SaleStatusPartialDelivered, models.SaleStatusDelivered, models.SaleStatusCompleted, models.SaleStatusCanceled},
+	models.SaleStatusApproved:         {models.SaleStatusPartialDelivered, models.SaleStatusDelivered, models.SaleStatusCompleted, models.SaleStatusCanceled},
+	models.SaleStatusPartialDelivered: {models.SaleStatusDelivered, models.SaleStatusCompleted},
+	models.SaleStatusDelivered:        {models.SaleStatusCompleted},
+}
+
+// saleApproveTransitions covers the approval sub-states in status_approve. Editing a sale sends it back to PENDING.
+var saleApproveTransitions = map[string][]string{
+	models.SaleApprovePending:   {models.SaleApproveProcess, models.SaleApproveCompleted},
+	models.SaleApproveProcess:   {models.SaleApprovePending, models.SaleApproveReview, models.SaleApproveReject, models.SaleApproveCompleted},
+	models.SaleApproveReview:    {models.SaleApprovePending, models.SaleApproveProcess, models.SaleApproveReject, models.SaleApproveCompleted},
+	models.SaleApproveReject:    {models.SaleApprovePending},
+	models.SaleApproveCompleted: {models.SaleApprovePending},
+}
+
+// CanTransitionSale reports whether a sale may move from one status to another.
+func CanTransitionSale(from string, to string) bool {
+	return canTransition(saleTransitions, from, to)
+}
+
+func canTransition(transitions map[string][]string, from string, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func newInvalidTransitionError(saleCode string, field string, from string, to string, reason string) *utils.ConflictError {
+	message := fmt.Sprintf("sale %s cannot change %s from %s to %s", saleCode, field, from, to)
+	if reason != "" {
+		message += ": " + reason
+	}
+
+	return &utils.ConflictError{
+		Code:    "INVALID_STATUS_TRANSITION",
+		Message: message,
+		Details: map[string]string{
+			"sale_code": saleCode,
+			"field":     field,
+			"from":      from,
+			"to":        to,
+		},
+	}
+}
+
+// effectiveSaleStatus maps rows written before APPROVED existed (PENDING with a completed approval) onto APPROVED.
+func effectiveSaleStatus(sale models.Sale) string {
+	if sale.Status == "" {
+		return models.SaleStatusPending
+	}
+	if sale.Status == models.SaleStatusPending && sale.StatusApprove == models.SaleApproveCompleted {
+		return models.SaleStatusApproved
+	}
+	return sale.Status
+}
+
+func effectiveSaleApprove(sale models.Sale) string {
+	if sale.StatusApprove == "" {
+		return models.SaleApprovePending
+	}
+	return sale.StatusApprove
+}
+
+// lockSale loads a sale with a row lock so concurrent status changes are applied one after another.
+func lockSale(tx *gorm.DB, saleID uuid.UUID) (models.Sale, error) {
+	var sale models.Sale
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", saleID).First(&sale).Error; err != nil {
+		return sale, fmt.Errorf("failed to get sale %v: %v", saleID, err)
+	}
+	return sale, nil
+}
+
+// hasActiveARInvoice reports whether a non-cancelled AR invoice references the sale, or one of its items when saleItem is set.
+func hasActiveARInvoice(tx *gorm.DB, saleCode string, saleItem string) (bool, error) {
+	query := tx.Model(&models.InvoiceItem{}).
+		Joins("JOIN invoice ON invoice_item.invoice_id = invoice.id").
+		Where("invoice.status IN ? AND invoice.invoice_type = ? AND invoice_item.document_ref = ?",
+			[]string{"PENDING", "COMPLETED"}, "AR", saleCode)
+	if saleItem != "" {
+		query = query.Where("invoice_item.document_ref_item = ?", saleItem)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check AR invoices of sale %s: %v", saleCode, err)
+	}
+	return count > 0, nil
+}
+
+func writeSaleHistory(tx *gorm.DB, history models.SaleStatusHistory) error {
+	history.ID = uuid.New()
+	if err := tx.Create(&history).Error; err != nil {
+		return fmt.Errorf("failed to write sale status history: %v", err)
+	}
+	return nil
+}
+
+// transitionSale moves a sale to status to, checking the allowed transitions and guards, and records the change.
+// Cancelling a sale also cancels its open items.
+func transitionSale(tx *gorm.DB, user string, sale *models.Sale, to string, remark string) error {
+	if sale.Status == to {
+		return nil
+	}
+
+	from := effectiveSaleStatus(*sale)
+	if from != to && !CanTransitionSale(from, to) {
+		return newInvalidTransitionError(sale.SaleCode, saleHistoryFieldStatus, from, to, "")
+	}
+
+	switch to {
+	case models.SaleStatusApproved:
+		if sale.StatusApprove != models.SaleApproveCompleted {
+			return newInvalidTransitionError(sale.SaleCode, saleHistoryFieldStatus, from, to, "approval is not completed")
+		}
+	case models.SaleStatusCanceled:
+		invoiced, err := hasActiveARInvoice(tx, sale.SaleCode, "")
+		if err != nil {
+			return err
+		}
+		if invoiced {
+			return newInvalidTransitionError(sale.SaleCode, saleHistoryFieldStatus, from, to, "an AR invoice already exists")
+		}
+	}
+
+	result := tx.Model(&models.Sale{}).
+		Where("id = ? AND status = ?", sale.ID, sale.Status).
+		Updates(map[string]interface{}{
+			"status":      to,
+			"update_date": tx.NowFunc(),
+			"update_by":   user,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update sale status: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return newInvalidTransitionError(sale.SaleCode, saleHistoryFieldStatus, from, to, "the sale was changed by another request")
+	}
+
+	if err := writeSaleHistory(tx, models.SaleStatusHistory{
+		SaleID:     sale.ID,
+		SaleCode:   sale.SaleCode,
+		Field:      saleHistoryFieldStatus,
+		FromStatus: sale.Status,
+		ToStatus:   to,
+		Remark:     remark,
+		CreateBy:   user,
+	}); err != nil {
+		return err
+	}
+	sale.Status = to
+
+	if err := queueSaleCreditLedgerPost(tx, user, *sale); err != nil {
+		return err
+	}
+
+	if to == models.SaleStatusCanceled {
+		var openItems []models.SaleItem
+		if err := tx.Where("sale_id = ? AND status NOT IN ?", sale.ID, []string{models.SaleStatusCompleted, models.SaleStatusCanceled}).
+			Find(&openItems).Error; err != nil {
+			return fmt.Errorf("failed to get sale items: %v", err)
+		}
+		if err := transitionSaleItems(tx, user, *sale, openItems, models.SaleStatusCanceled, remark); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSaleApproval validates an approval transition without writing it, so callers can fail before side effects.
+func checkSaleApproval(sale models.Sale, to string) error {
+	from := effectiveSaleApprove(sale)
+	if from == to {
+		return nil
+	}
+	if !canTransition(saleApproveTransitions, from, to) {
+		return newInvalidTransitionError(sale.SaleCode, saleHistoryFieldApprove, from, to, "")
+	}
+
+	status := effectiveSaleStatus(sale)
+	if status != models.SaleStatusPending && status != models.SaleStatusApproved {
+		return newInvalidTransitionError(sale.SaleCode, saleHistoryFieldApprove, from, to, "the sale is already "+status)
+	}
+	return nil
+}
+
+// transitionSaleApproval moves the approval sub-state of a sale and applies its effect on the sale status:
+// COMPLETED approves the sale, REJECT cancels it and PENDING (after an edit) sends an approved sale back.
+// Approving holds the sale's credit, which the customer's balance must cover; that needs sqlx to read the balance.
+func transitionSaleApproval(tx *gorm.DB, sqlx *sqlx.DB, user string, sale *models.Sale, to string, remark string) error {
+	from := effectiveSaleApprove(*sale)
+	if from == to {
+		return nil
+	}
+	if err := checkSaleApproval(*sale, to); err != nil {
+		return err
+	}
+
+	updateFields := map[string]interface{}{
+		"status_approve": to,
+		"is_approved":    to == models.SaleApproveCompleted,
+		"update_date":    tx.NowFunc(),
+		"update_by":      user,
+	}
+	if remark != "" {
+		updateFields["remark_approval"] = remark
+	}
+
+	result := tx.Model(&models.Sale{}).
+		Where("id = ? AND status_approve = ?", sale.ID, sale.StatusApprove).
+		Updates(updateFields)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update sale approval status: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return newInvalidTransitionError(sale.SaleCode, saleHistoryFieldApprove, from, to, "the sale was changed by another request")
+	}
+
+	if err := writeSaleHistory(tx, models.SaleStatusHistory{
+		SaleID:     sale.ID,
+		SaleCode:   sale.SaleCode,
+		Field:      saleHistoryFieldApprove,
+		FromStatus: sale.StatusApprove,
+		ToStatus:   to,
+		Remark:     remark,
+		CreateBy:   user,
+	}); err != nil {
+		return err
+	}
+	sale.StatusApprove = to
+	sale.IsApproved = to == models.SaleApproveCompleted
+
+	if err := queueSaleCreditLedgerPost(tx, user, *sale); err != nil {
+		return err
+	}
+
+	switch to {
+	case models.SaleApproveCompleted:
+		if err := holdSaleCredit(tx, sqlx, user, []models.Sale{*sale}, true); err != nil {
+			return err
+		}
+		return transitionSale(tx, user, sale, models.SaleStatusApproved, remark)
+	case models.SaleApproveReject:
+		return transitionSale(tx, user, sale, models.SaleStatusCanceled, remark)
+	case models.SaleApprovePending:
+		return transitionSale(tx, user, sale, models.SaleStatusPending, remark)
+	}
+
+	return nil
+}
+
+// saleCreditAmount is what a sale takes from its customer's credit: its total, with the transport cost when it is
+// charged on top.
+func saleCreditAmount(sale models.Sale) float64 {
+	if sale.TransportCostType == "EXCL" {
+		return sale.TotalAmount + sale.TotalTransportCost
+	}
+	return sale.TotalAmount
+}
+
+func saleCreditHoldRequest(sale models.Sale, enforce bool) creditService.CreditHoldRequest {
+	return creditService.CreditHoldRequest{
+		CustomerCode: sale.CustomerCode,
+		SaleCode:     sale.SaleCode,
+		Amount:       saleCreditAmount(sale),
+		Enforce:      enforce,
+	}
+}
+
+// holdSaleCredit places or refreshes the credit holds of open sales. With enforce the customers' balance must cover
+// them; that needs sqlx to read the balance.
+func holdSaleCredit(tx *gorm.DB, sqlx *sqlx.DB, user string, sales []models.Sale, enforce bool) error {
+	reqs := make([]creditService.CreditHoldRequest, 0, len(sales))
+	for _, sale := range sales {
+		if sale.Status == models.SaleStatusCompleted || sale.Status == models.SaleStatusCanceled {
+			continue
+		}
+		reqs = append(reqs, saleCreditHoldRequest(sale, enforce))
+	}
+	_, err := creditService.HoldCredit(tx, sqlx, user, reqs)
+	return err
+}
+
+// transitionSaleItems moves items of one sale to status to. Items already in that status are skipped.
+func transitionSaleItems(tx *gorm.DB, user string, sale models.Sale, items []models.SaleItem, to string, remark string) error {
+	for _, item := range items {
+		from := item.Status
+		if from == "" {
+			from = models.SaleStatusPending
+		}
+		if from == to {
+			continue
+		}
+		if !canTransition(saleItemTransitions, from, to) {
+			return newInvalidTransitionError(sale.SaleCode+"/"+item.SaleItem, saleHistoryFieldStatus, from, to, "")
+		}
+
+		if to == models.SaleStatusCanceled {
+			invoiced, err := hasActiveARInvoice(tx, sale.SaleCode, item.SaleItem)
+			if err != nil {
+				return err
+			}
+			if invoiced {
+				return newInvalidTransitionError(sale.SaleCode+"/"+item.SaleItem, saleHistoryFieldStatus, from, to, "an AR invoice already exists")
+			}
+		}
+
+		result := tx.Model(&models.SaleItem{}).
+			Where("id = ? AND status = ?", item.ID, item.Status).
+			Updates(map[string]interface{}{
+				"status":      to,
+				"update_date": tx.NowFunc(),
+				"update_by":   user,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update sale item status: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return newInvalidTransitionError(sale.SaleCode+"/"+item.SaleItem, saleHistoryFieldStatus, from, to, "the item was changed by another request")
+		}
+
+		itemID := item.ID
+		if err := writeSaleHistory(tx, models.SaleStatusHistory{
+			SaleID:     sale.ID,
+			SaleCode:   sale.SaleCode,
+			SaleItemID: &itemID,
+			SaleItem:   item.SaleItem,
+			Field:      saleHistoryFieldStatus,
+			FromStatus: item.Status,
+			ToStatus:   to,
+			Remark:     remark,
+			CreateBy:   user,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollupSaleStatus derives the sale status from its items, ignoring cancelled ones.
+// It returns "" when the items do not imply a change (nothing delivered yet).
+func rollupSaleStatus(items []models.SaleItem) string {
+	active, completed, delivered, started := 0, 0, 0, 0
+	for _, item := range items {
+		switch item.Status {
+		case models.SaleStatusCanceled:
+			continue
+		case models.SaleStatusCompleted:
+			completed++
+			delivered++
+			started++
+		case models.SaleStatusDelivered:
+			delivered++
+			started++
+		case models.SaleStatusPartialDelivered:
+			started++
+		}
+		active++
+	}
+
+	switch {
+	case active == 0 && len(items) > 0:
+		return models.SaleStatusCanceled
+	case active > 0 && completed == active:
+		return models.SaleStatusCompleted
+	case active > 0 && delivered == active:
+		return models.SaleStatusDelivered
+	case started > 0:
+		return models.SaleStatusPartialDelivered
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package saleService
+
+import (
+	"errors"
+	"testing"
+
+	"prime-erp-core/internal/models"
+	"prime-erp-core/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionSale(t *testing.T) {
+	assert.True(t, CanTransitionSale(models.SaleStatusPending, models.SaleStatusApproved))
+	assert.True(t, CanTransitionSale(models.SaleStatusApproved, models.SaleStatusPartialDelivered))
+	assert.True(t, CanTransitionSale(models.SaleStatusPartialDelivered, models.SaleStatusDelivered))
+	assert.True(t, CanTransitionSale(models.SaleStatusDelivered, models.SaleStatusCompleted))
+
+	assert.False(t, CanTransitionSale(models.SaleStatusPending, models.SaleStatusDelivered), "must be approved first")
+	assert.False(t, CanTransitionSale(models.SaleStatusDelivered, models.SaleStatusCanceled))
+	assert.False(t, CanTransitionSale(models.SaleStatusCompleted, models.SaleStatusPending))
+	assert.False(t, CanTransitionSale(models.SaleStatusCanceled, models.SaleStatusApproved))
+}
+
+func TestEffectiveSaleStatus_LegacyApprovedRows(t *testing.T) {
+	assert.Equal(t, models.SaleStatusApproved, effectiveSaleStatus(models.Sale{Status: "PENDING", StatusApprove: "COMPLETED"}))
+	assert.Equal(t, models.SaleStatusPending, effectiveSaleStatus(models.Sale{Status: "PENDING", StatusApprove: "PROCESS"}))
+	assert.Equal(t, models.SaleStatusPending, effectiveSaleStatus(models.Sale{}))
+}
+
+func TestCheckSaleApproval(t *testing.T) {
+	sale := models.Sale{SaleCode: "SO1", Status: models.SaleStatusPending, StatusApprove: models.SaleApproveProcess}
+	assert.NoError(t, checkSaleApproval(sale, models.SaleApproveCompleted))
+	assert.NoError(t, checkSaleApproval(sale, models.SaleApproveProcess), "same state is a no-op")
+
+	sale.StatusApprove = models.SaleApproveReject
+	err := checkSaleApproval(sale, models.SaleApproveCompleted)
+	var conflictErr *utils.ConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "INVALID_STATUS_TRANSITION", conflictErr.Code)
+
+	delivered := models.Sale{SaleCode: "SO2", Status: models.SaleStatusDelivered, StatusApprove: models.SaleApproveCompleted}
+	assert.Error(t, checkSaleApproval(delivered, models.SaleApprovePending), "delivered sales cannot be sent back for approval")
+}
+
+func TestRollupSaleStatus(t *testing.T) {
+	items := func(statuses ...string) []models.SaleItem {
+		result := []models.SaleItem{}
+		for _, status := range statuses {
+			result = append(result, models.SaleItem{Status: status})
+		}
+		return result
+	}
+
+	assert.Equal(t, "", rollupSaleStatus(items("PENDING", "PENDING")))
+	assert.Equal(t, models.SaleStatusPartialDelivered, rollupSaleStatus(items("DELIVERED", "PENDING")))
+	assert.Equal(t, models.SaleStatusPartialDelivered, rollupSaleStatus(items("PARTIAL_DELIVERED")))
+	assert.Equal(t, models.SaleStatusDelivered, rollupSaleStatus(items("DELIVERED", "COMPLETED", "CANCELED")))
+	assert.Equal(t, models.SaleStatusCompleted, rollupSaleStatus(items("COMPLETED", "CANCELED")))
+	assert.Equal(t, models.SaleStatusCanceled, rollupSaleStatus(items("CANCELED")))
+}
@@ -1,111 +1,124 @@
-package saleService
-
-import (
-	"encoding/json"
-	"errors"
-	"fmt"
-	"time"
-
-	"prime-erp-core/internal/db"
-	"prime-erp-core/internal/models"
-	approvalService "prime-erp-core/internal/services/approval-service"
-
-	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-)
-
-type UpdateStatusApproveSaleRequest struct {
-	ID         uuid.UUID `json:"id"`
-	ApprovalID uuid.UUID `json:"approval_id"`
-	Status     string    `json:"status"` // REVIEW, REJECT, COMPLETED
-	Remark     string    `json:"remark"`
-}
-
-func UpdateStatusApproveSale(ctx *gin.Context, jsonPayload string) (interface{}, error) {
-	req := UpdateStatusApproveSaleRequest{}
-
-	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
-		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
-	}
-
-	sqlx, err := db.ConnectSqlx(`prime_erp`)
-	if err != nil {
-		return nil, err
-	}
-	defer sqlx.Close()
-
-	gormx, err := db.ConnectGORM(`prime_erp`)
-	if err != nil {
-		return nil, err
-	}
-	defer db.CloseGORM(gormx)
-
-	updateApprovalReq := []struct {
-		ID     uuid.UUID `json:"id"`
-		Status string    `json:"status"`
-		Remark string    `json:"remark"`
-	}{
-		{
-			ID:     req.ApprovalID,
-			Status: req.Status,
-			Remark: req.Remark,
-		},
-	}
-
-	updateApprovalPayload, _ := json.Marshal(updateApprovalReq)
-	approvalResult, err := approvalService.UpdateApproval(ctx, string(updateApprovalPayload))
-	if err != nil {
-		return nil, fmt.Errorf("failed to update approval: %v", err)
-	}
-
-	// Update quotation based on approval status
-	var quotationStatus, quotationStatusApprove string
-
-	switch req.Status {
-	case "REVIEW":
-		quotationStatus = "PENDING"
-		quotationStatusApprove = "REVIEW"
-	case "REJECT":
-		quotationStatus = "CANCELED"
-		quotationStatusApprove = "REJECT"
-	case "COMPLETED":
-		quotationStatus = "PENDING"
-		quotationStatusApprove = "COMPLETED"
-	default:
-		return nil, fmt.Errorf("invalid status: %s", req.Status)
-	}
-
-	now := time.Now()
-	nowDateOnly := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-
-	updateFields := map[string]interface{}{
-		"status":          quotationStatus,
-		"status_approve":  quotationStatusApprove,
-		"remark_approval": req.Remark,
-		"update_date":     nowDateOnly,
-	}
-
-	if err := gormx.Model(&models.Sale{}).
-		Where("id = ?", req.ID).
-		Updates(updateFields).Error; err != nil {
-		return nil, fmt.Errorf("failed to update sale status: %v", err)
-	}
-
-	// If status is REJECT, also update sale_item status to CANCELED
-	if req.Status == "REJECT" {
-		if err := gormx.Model(&models.SaleItem{}).
-			Where("sale_id = ?", req.ID).
-			Updates(map[string]interface{}{
-				"status":      "CANCELED",
-				"update_date": nowDateOnly,
-			}).Error; err != nil {
-			return nil, fmt.Errorf("failed to update sale items status: %v", err)
-		}
-	}
-
-	return map[string]interface{}{
-		"external_response": approvalResult,
-		"status":            "success",
-		"message":           "Approval updated successfully",
-	}, nil
-}
+package saleService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"prime-erp-core/internal/middleware"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/models"
+	approvalService "prime-erp-core/internal/services/approval-service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func init() {
+	approvalService.RegisterRejectHandler("SO", rejectSaleApproval)
+}
+
+type UpdateStatusApproveSaleRequest struct {
+	ID            uuid.UUID `json:"id"`
+	ApprovalID    uuid.UUID `json:"approval_id"`
+	Status        string    `json:"status"` // REVIEW, REJECT, COMPLETED
+	Remark        string    `json:"remark"`
+	IsFinalReject bool      `json:"is_final_reject"` // reject the sale instead of returning the approval to its previous step
+}
+
+func UpdateStatusApproveSale(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := UpdateStatusApproveSaleRequest{}
+
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+
+	gormx, err := db.FromContext(ctx).GORM(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlx, err := db.FromContext(ctx).Sqlx(`prime_erp`)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Status {
+	case models.SaleApproveReview, models.SaleApproveReject, models.SaleApproveCompleted:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", req.Status)
+	}
+
+	updateApprovalReq := []struct {
+		ID     uuid.UUID `json:"id"`
+		Status string    `json:"status"`
+		Remark string    `json:"remark"`
+	}{
+		{
+			ID:     req.ApprovalID,
+			Status: req.Status,
+			Remark: req.Remark,
+		},
+	}
+
+	// The sale transition is checked before the approval is touched, and rolled back if the approval update fails.
+	// Approve and reject go through the approval workflow; the sale only follows once the workflow reaches its final step.
+	user := middleware.GetUserCode(ctx)
+	var approvalResult interface{}
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		sale, err := lockSale(tx, req.ID)
+		if err != nil {
+			return err
+		}
+
+		if req.Status == models.SaleApproveReview {
+			if err := transitionSaleApproval(tx, sqlx, user, &sale, req.Status, req.Remark); err != nil {
+				return err
+			}
+
+			updateApprovalPayload, _ := json.Marshal(updateApprovalReq)
+			approvalResult, err = approvalService.UpdateApproval(ctx, string(updateApprovalPayload))
+			if err != nil {
+				return fmt.Errorf("failed to update approval: %v", err)
+			}
+			return nil
+		}
+
+		if err := checkSaleApproval(sale, req.Status); err != nil {
+			return err
+		}
+		actResult, err := approvalService.ApplyApprovalDecision(tx, req.ApprovalID, user, req.Status, req.Remark, req.IsFinalReject)
+		if err != nil {
+			return err
+		}
+		approvalResult = actResult
+		if !actResult.IsFinal {
+			return nil
+		}
+		return transitionSaleApproval(tx, sqlx, user, &sale, req.Status, req.Remark)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"external_response": approvalResult,
+		"status":            "success",
+		"message":           "Approval updated successfully",
+	}, nil
+}
+
+// rejectSaleApproval rejects the sale of an approval the escalation job auto-rejected.
+func rejectSaleApproval(tx *gorm.DB, approval models.Approval, remark string) error {
+	var sale models.Sale
+	if err := tx.Where("sale_code = ?", approval.DocumentCode).First(&sale).Error; err != nil {
+		return fmt.Errorf("failed to get sale %s: %v", approval.DocumentCode, err)
+	}
+
+	locked, err := lockSale(tx, sale.ID)
+	if err != nil {
+		return err
+	}
+	return transitionSaleApproval(tx, nil, middleware.SystemUser, &locked, models.SaleApproveReject, remark)
+}
@@ -0,0 +1,375 @@
+package systemConfigService
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"prime-erp-core/internal/db"
+	"prime-erp-core/internal/middleware"
+	"prime-erp-core/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrRunningConfigNotFound is returned when no running number config applies to the scope. The configs are seeded by
+// the scripts in migrations/.
+var ErrRunningConfigNotFound = errors.New("config not found")
+
+// NumberScope is the company/site a document number is issued for.
+type NumberScope struct {
+	CompanyCode string `json:"company_code"`
+	SiteCode    string `json:"site_code"`
+}
+
+type ReserveNumbersRequest struct {
+	ConfigCode  string `json:"config_code"`
+	CompanyCode string `json:"company_code"`
+	SiteCode    string `json:"site_code"`
+	Count       int    `json:"count"`
+	Prefix      string `json:"prefix"`      // overrides the configured prefix
+	YearFormat  string `json:"year_format"` // overrides the configured year format
+}
+
+type ReserveNumbersResponse struct {
+	ConfigCode string   `json:"config_code"`
+	Period     string   `json:"period"`
+	Data       []string `json:"data"`
+}
+
+// numberRangeDocuments maps a running config to the document table its numbers end up in, for gap auditing.
+var numberRangeDocuments = map[string]struct {
+	Table  string
+	Column string
+}{
+	"RUNNING_SO":  {Table: "sale", Column: "sale_code"},
+	"RUNNING_QU":  {Table: "quotation", Column: "quotation_code"},
+	"RUNNING_DBS": {Table: "delivery_booking", Column: "delivery_code"},
+	"RUNNING_PO":  {Table: "purchase", Column: "purchase_code"},
+	"RUNNING_PB":  {Table: "pre_purchase", Column: "pre_purchase_code"},
+	"RUNNING_AR":  {Table: "invoice", Column: "invoice_code"},
+	"RUNNING_AP":  {Table: "invoice", Column: "invoice_code"},
+	"RUNNING_CN":  {Table: "invoice", Column: "invoice_code"},
+	"RUNNING_DN":  {Table: "invoice", Column: "invoice_code"},
+	"RUNNING_PM":  {Table: "payment", Column: "payment_code"},
+}
+
+// ReserveNumbers atomically takes the next req.Count numbers of a running config.
+// The counter row is upserted with a row lock, so concurrent callers never receive the same number,
+// and every issued number is written to number_range_issue in the same transaction.
+func ReserveNumbers(ctx *gin.Context, req ReserveNumbersRequest) (*ReserveNumbersResponse, error) {
+	if req.ConfigCode == "" {
+		return nil, errors.New("config_code is required")
+	}
+	if req.Count <= 0 {
+		return nil, errors.New("count must be greater than 0")
+	}
+
+	gormx, err := db.FromContext(ctx).GORM("prime_erp")
+	if err != nil {
+		return nil, err
+	}
+
+	systemConfigs := []models.SystemConfig{}
+	if err := gormx.Where("config_code = ?", req.ConfigCode).Find(&systemConfigs).Error; err != nil {
+		return nil, err
+	}
+
+	systemConfig, found := selectRunningConfig(systemConfigs, req.CompanyCode, req.SiteCode)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrRunningConfigNotFound, req.ConfigCode)
+	}
+
+	var configJSON models.RunningConfigJSON
+	if err := json.Unmarshal([]byte(systemConfig.JSON), &configJSON); err != nil {
+		return nil, errors.New("failed to parse config JSON: " + err.Error())
+	}
+	if req.Prefix != "" {
+		configJSON.Prefix = req.Prefix
+	}
+	if req.YearFormat != "" {
+		configJSON.YearFormat = req.YearFormat
+	}
+
+	now := time.Now()
+	period, yearText, monthText := numberPeriod(configJSON, now)
+	count := req.Count
+	user := middleware.GetUserCode(ctx)
+
+	var reserved struct {
+		ID             uuid.UUID
+		CurrentRunning int
+	}
+	codes := make([]string, 0, count)
+
+	err = gormx.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(`
+			INSERT INTO number_range (id, config_code, company_code, site_code, period, current_running, create_dtm, update_dtm)
+			VALUES (?, ?, ?, ?, ?, ?, now(), now())
+			ON CONFLICT (config_code, company_code, site_code, period)
+			DO UPDATE SET current_running = number_range.current_running + ?, update_dtm = now()
+			RETURNING id, current_running
+		`, uuid.New(), req.ConfigCode, systemConfig.Cond1, systemConfig.Cond2, period,
+			seedRunning(configJSON, yearText, monthText)+count, count).Scan(&reserved).Error; err != nil {
+			return fmt.Errorf("failed to reserve running numbers: %v", err)
+		}
+
+		issues := make([]models.NumberRangeIssue, 0, count)
+		for running := reserved.CurrentRunning - count + 1; running <= reserved.CurrentRunning; running++ {
+			code := formatNumber(configJSON, yearText, monthText, running)
+			codes = append(codes, code)
+			issues = append(issues, models.NumberRangeIssue{
+				ID:            uuid.New(),
+				NumberRangeID: reserved.ID,
+				ConfigCode:    req.ConfigCode,
+				CompanyCode:   systemConfig.Cond1,
+				SiteCode:      systemConfig.Cond2,
+				Period:        period,
+				Running:       running,
+				DocumentCode:  code,
+				Status:        models.NumberIssueReserved,
+				CreateBy:      user,
+			})
+		}
+
+		return tx.CreateInBatches(issues, 500).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReserveNumbersResponse{
+		ConfigCode: req.ConfigCode,
+		Period:     period,
+		Data:       codes,
+	}, nil
+}
+
+// ReserveNumbersByScope reserves one number per scope and returns them in the same order.
+// ConfigCode, Prefix and YearFormat are taken from base; its company, site and count are ignored.
+func ReserveNumbersByScope(ctx *gin.Context, base ReserveNumbersRequest, scopes []NumberScope) ([]string, error) {
+	codes := make([]string, len(scopes))
+	if len(scopes) == 0 {
+		return codes, nil
+	}
+
+	indexesByScope := map[NumberScope][]int{}
+	order := []NumberScope{}
+	for i, scope := range scopes {
+		if _, exists := indexesByScope[scope]; !exists {
+			order = append(order, scope)
+		}
+		indexesByScope[scope] = append(indexesByScope[scope], i)
+	}
+
+	for _, scope := range order {
+		indexes := indexesByScope[scope]
+		req := base
+		req.CompanyCode = scope.CompanyCode
+		req.SiteCode = scope.SiteCode
+		req.Count = len(indexes)
+
+		res, err := ReserveNumbers(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.Data) != len(indexes) {
+			return nil, fmt.Errorf("failed to get correct number of codes from %s", base.ConfigCode)
+		}
+
+		for n, i := range indexes {
+			codes[i] = res.Data[n]
+		}
+	}
+
+	return codes, nil
+}
+
+// VoidReservedNumbers marks reserved numbers whose document was never saved, keeping the reason for the gap audit.
+func VoidReservedNumbers(ctx *gin.Context, configCode string, codes []string, remark string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	gormx, err := db.FromContext(ctx).GORM("prime_erp")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return gormx.Model(&models.NumberRangeIssue{}).
+		Where("config_code = ? AND document_code IN ? AND status = ?", configCode, codes, models.NumberIssueReserved).
+		Updates(map[string]interface{}{
+			"status":     models.NumberIssueVoid,
+			"remark":     remark,
+			"update_by":  middleware.GetUserCode(ctx),
+			"update_dtm": &now,
+		}).Error
+}
+
+type VoidNumbersRequest struct {
+	ConfigCode    string   `json:"config_code"`
+	DocumentCodes []string `json:"document_codes"`
+	Remark        string   `json:"remark"`
+}
+
+func VoidNumbers(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := VoidNumbersRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if req.ConfigCode == "" || len(req.DocumentCodes) == 0 {
+		return nil, errors.New("config_code and document_codes are required")
+	}
+	if strings.TrimSpace(req.Remark) == "" {
+		return nil, errors.New("remark is required to void numbers")
+	}
+
+	if err := VoidReservedNumbers(ctx, req.ConfigCode, req.DocumentCodes, req.Remark); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"status":  "success",
+		"message": "Numbers voided successfully",
+	}, nil
+}
+
+type GetNumberRangeGapsRequest struct {
+	ConfigCodes      []string `json:"config_codes"`
+	CompanyCode      string   `json:"company_code"`
+	SiteCode         string   `json:"site_code"`
+	Period           string   `json:"period"`
+	OlderThanMinutes int      `json:"older_than_minutes"` // reserved numbers younger than this may still be in flight; default 10
+}
+
+type GetNumberRangeGapsResponse struct {
+	Total int                       `json:"total"`
+	Gaps  []models.NumberRangeIssue `json:"gaps"`
+}
+
+// GetNumberRangeGaps lists issued numbers that have no document: voided ones, and reserved ones
+// that are older than older_than_minutes and whose document table has no row with that code.
+func GetNumberRangeGaps(ctx *gin.Context, jsonPayload string) (interface{}, error) {
+	req := GetNumberRangeGapsRequest{}
+	if err := json.Unmarshal([]byte(jsonPayload), &req); err != nil {
+		return nil, errors.New("failed to unmarshal JSON into struct: " + err.Error())
+	}
+	if req.OlderThanMinutes <= 0 {
+		req.OlderThanMinutes = 10
+	}
+	if len(req.ConfigCodes) == 0 {
+		for configCode := range numberRangeDocuments {
+			req.ConfigCodes = append(req.ConfigCodes, configCode)
+		}
+	}
+
+	gormx, err := db.FromContext(ctx).GORM("prime_erp")
+	if err != nil {
+		return nil, err
+	}
+
+	reservedBefore := time.Now().Add(-time.Duration(req.OlderThanMinutes) * time.Minute)
+	res := GetNumberRangeGapsResponse{Gaps: []models.NumberRangeIssue{}}
+
+	for _, configCode := range req.ConfigCodes {
+		query := gormx.Model(&models.NumberRangeIssue{}).Where("number_range_issue.config_code = ?", configCode)
+		if req.CompanyCode != "" {
+			query = query.Where("number_range_issue.company_code = ?", req.CompanyCode)
+		}
+		if req.SiteCode != "" {
+			query = query.Where("number_range_issue.site_code = ?", req.SiteCode)
+		}
+		if req.Period != "" {
+			query = query.Where("number_range_issue.period = ?", req.Period)
+		}
+
+		if document, exists := numberRangeDocuments[configCode]; exists {
+			query = query.Where(fmt.Sprintf(`number_range_issue.status = ? OR (number_range_issue.status = ? AND number_range_issue.create_dtm < ?
+				AND NOT EXISTS (SELECT 1 FROM %s d WHERE d.%s = number_range_issue.document_code))`, document.Table, document.Column),
+				models.NumberIssueVoid, models.NumberIssueReserved, reservedBefore)
+		} else {
+			query = query.Where("number_range_issue.status = ?", models.NumberIssueVoid)
+		}
+
+		gaps := []models.NumberRangeIssue{}
+		if err := query.Order("company_code, site_code, period, running").Find(&gaps).Error; err != nil {
+			return nil, err
+		}
+		res.Gaps = append(res.Gaps, gaps...)
+	}
+	res.Total = len(res.Gaps)
+
+	return res, nil
+}
+
+// selectRunningConfig picks the most specific config row: cond1/cond2 carry company/site, empty means any. Rows of
+// other companies or sites never apply, so a company without a row of its own only draws from a global one.
+func selectRunningConfig(systemConfigs []models.SystemConfig, companyCode string, siteCode string) (models.SystemConfig, bool) {
+	best := models.SystemConfig{}
+	bestScore := 0
+
+	for _, systemConfig := range systemConfigs {
+		score := 0
+		switch {
+		case systemConfig.Cond1 == "" && systemConfig.Cond2 == "":
+			score = 1
+		case systemConfig.Cond1 == companyCode && systemConfig.Cond2 == "":
+			score = 2
+		case systemConfig.Cond1 == companyCode && systemConfig.Cond2 == siteCode:
+			score = 3
+		}
+
+		if score > bestScore {
+			best = systemConfig
+			bestScore = score
+		}
+	}
+
+	return best, bestScore > 0
+}
+
+// numberPeriod returns the counter period for the reset policy plus the year and month text used in codes.
+func numberPeriod(configJSON models.RunningConfigJSON, now time.Time) (string, string, string) {
+	yearText := now.Format("2006")
+	if configJSON.YearFormat == models.NumberYearFormatBE {
+		yearText = fmt.Sprintf("%02d", (now.Year()+543)%100)
+	}
+	monthText := now.Format("01")
+
+	switch configJSON.ResetPolicy {
+	case models.NumberResetNever:
+		return "", yearText, monthText
+	case models.NumberResetYearly:
+		return yearText, yearText, monthText
+	default:
+		return yearText + monthText, yearText, monthText
+	}
+}
+
+// seedRunning carries the legacy current_running of system_config over when the counter row is first created.
+func seedRunning(configJSON models.RunningConfigJSON, yearText string, monthText string) int {
+	switch configJSON.ResetPolicy {
+	case models.NumberResetNever:
+		return configJSON.CurrentRunning
+	case models.NumberResetYearly:
+		if configJSON.Year == yearText {
+			return configJSON.CurrentRunning
+		}
+	default:
+		if configJSON.Year == yearText && configJSON.Month == monthText {
+			return configJSON.CurrentRunning
+		}
+	}
+
+	return 0
+}
+
+// formatNumber renders PREFIX + year + month + "-" + zero-padded running, e.g. SO202511-0001.
+func formatNumber(configJSON models.RunningConfigJSON, yearText string, monthText string, running int) string {
+	return fmt.Sprintf("%s%s%s-%0*d", configJSON.Prefix, yearText, monthText, configJSON.RunningDigit, running)
+}
@@ -0,0 +1,76 @@
+package systemConfigService
+
+import (
+	"testing"
+	"time"
+
+	"prime-erp-core/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumberPeriod_ResetPolicies(t *testing.T) {
+	now := time.Date(2025, time.November, 3, 10, 0, 0, 0, time.UTC)
+
+	period, year, month := numberPeriod(models.RunningConfigJSON{}, now)
+	assert.Equal(t, "202511", period, "monthly is the default")
+	assert.Equal(t, "2025", year)
+	assert.Equal(t, "11", month)
+
+	period, _, _ = numberPeriod(models.RunningConfigJSON{ResetPolicy: models.NumberResetYearly}, now)
+	assert.Equal(t, "2025", period)
+
+	period, _, _ = numberPeriod(models.RunningConfigJSON{ResetPolicy: models.NumberResetNever}, now)
+	assert.Equal(t, "", period)
+
+	period, year, _ = numberPeriod(models.RunningConfigJSON{YearFormat: models.NumberYearFormatBE}, now)
+	assert.Equal(t, "68", year)
+	assert.Equal(t, "6811", period)
+}
+
+func TestSeedRunning_OnlyCarriesCurrentPeriod(t *testing.T) {
+	legacy := models.RunningConfigJSON{Year: "2025", Month: "10", CurrentRunning: 42}
+
+	assert.Equal(t, 42, seedRunning(legacy, "2025", "10"))
+	assert.Equal(t, 0, seedRunning(legacy, "2025", "11"), "monthly counter restarts in a new month")
+
+	legacy.ResetPolicy = models.NumberResetYearly
+	assert.Equal(t, 42, seedRunning(legacy, "2025", "11"))
+	assert.Equal(t, 0, seedRunning(legacy, "2026", "01"))
+
+	legacy.ResetPolicy = models.NumberResetNever
+	assert.Equal(t, 42, seedRunning(legacy, "2030", "01"))
+}
+
+func TestFormatNumber(t *testing.T) {
+	config := models.RunningConfigJSON{Prefix: "SO", RunningDigit: 4}
+	assert.Equal(t, "SO202511-0007", formatNumber(config, "2025", "11", 7))
+
+	config = models.RunningConfigJSON{Prefix: "IV", RunningDigit: 5}
+	assert.Equal(t, "IV6811-00123", formatNumber(config, "68", "11", 123))
+}
+
+func TestSelectRunningConfig_PrefersMostSpecificScope(t *testing.T) {
+	configs := []models.SystemConfig{
+		{ConfigCode: "RUNNING_SO", JSON: "default"},
+		{ConfigCode: "RUNNING_SO", Cond1: "C1", JSON: "company"},
+		{ConfigCode: "RUNNING_SO", Cond1: "C1", Cond2: "S1", JSON: "site"},
+		{ConfigCode: "RUNNING_SO", Cond1: "C2", Cond2: "S9", JSON: "other"},
+	}
+
+	selected, found := selectRunningConfig(configs, "C1", "S1")
+	assert.True(t, found)
+	assert.Equal(t, "site", selected.JSON)
+
+	selected, _ = selectRunningConfig(configs, "C1", "S2")
+	assert.Equal(t, "company", selected.JSON)
+
+	selected, _ = selectRunningConfig(configs, "C3", "S1")
+	assert.Equal(t, "default", selected.JSON)
+
+	_, found = selectRunningConfig(nil, "C1", "S1")
+	assert.False(t, found)
+
+	_, found = selectRunningConfig(configs[1:], "C3", "S1")
+	assert.False(t, found, "another company's range is never a fallback")
+}
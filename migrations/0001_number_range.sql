@@ -0,0 +1,47 @@
+-- Number ranges: one counter row per running config, company/site scope and reset period.
+-- Counters are advanced with INSERT ... ON CONFLICT DO UPDATE so concurrent reservations serialize on the row lock.
+CREATE TABLE IF NOT EXISTS number_range (
+    id              uuid         PRIMARY KEY,
+    config_code     varchar(50)  NOT NULL,
+    company_code    varchar(50)  NOT NULL DEFAULT '',
+    site_code       varchar(50)  NOT NULL DEFAULT '',
+    period          varchar(10)  NOT NULL DEFAULT '',
+    current_running integer      NOT NULL DEFAULT 0,
+    create_dtm      timestamp    NOT NULL DEFAULT now(),
+    update_dtm      timestamp    NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_number_range_scope
+    ON number_range (config_code, company_code, site_code, period);
+
+-- Every issued number, so gaps (voided or never-saved documents) can be audited.
+CREATE TABLE IF NOT EXISTS number_range_issue (
+    id              uuid         PRIMARY KEY,
+    number_range_id uuid         NOT NULL REFERENCES number_range (id),
+    config_code     varchar(50)  NOT NULL,
+    company_code    varchar(50)  NOT NULL DEFAULT '',
+    site_code       varchar(50)  NOT NULL DEFAULT '',
+    period          varchar(10)  NOT NULL DEFAULT '',
+    running         integer      NOT NULL,
+    document_code   varchar(100) NOT NULL,
+    status          varchar(20)  NOT NULL,
+    remark          text         NOT NULL DEFAULT '',
+    create_by       varchar(100) NOT NULL DEFAULT '',
+    create_dtm      timestamp    NOT NULL DEFAULT now(),
+    update_by       varchar(100) NOT NULL DEFAULT '',
+    update_dtm      timestamp
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_number_range_issue_running
+    ON number_range_issue (number_range_id, running);
+CREATE INDEX IF NOT EXISTS ix_number_range_issue_document
+    ON number_range_issue (config_code, document_code);
+
+-- AP invoices previously fell back to a uuid code; give them their own running config next to RUNNING_AR.
+INSERT INTO system_config (topic_code, config_code, config_name, cond1, cond2, value, sequence, remark, json)
+SELECT topic_code, 'RUNNING_AP', 'Running AP Invoice', '', '', '', sequence, '',
+       '{"prefix":"AP","running_digit":4,"current_running":0,"reset_policy":"MONTHLY","year_format":"BE_YY"}'
+FROM system_config
+WHERE config_code = 'RUNNING_AR'
+  AND NOT EXISTS (SELECT 1 FROM system_config WHERE config_code = 'RUNNING_AP')
+LIMIT 1;
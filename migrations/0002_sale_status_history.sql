@@ -0,0 +1,19 @@
+-- One row per sale / sale item status or approval transition, written by the sale status state machine.
+CREATE TABLE IF NOT EXISTS sale_status_history (
+    id           uuid         PRIMARY KEY,
+    sale_id      uuid         NOT NULL,
+    sale_code    varchar(50)  NOT NULL,
+    sale_item_id uuid,
+    sale_item    varchar(50)  NOT NULL DEFAULT '',
+    field        varchar(20)  NOT NULL,
+    from_status  varchar(50)  NOT NULL DEFAULT '',
+    to_status    varchar(50)  NOT NULL,
+    remark       text         NOT NULL DEFAULT '',
+    create_by    varchar(100) NOT NULL DEFAULT '',
+    create_dtm   timestamp    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_sale_status_history_sale
+    ON sale_status_history (sale_id, create_dtm);
+CREATE INDEX IF NOT EXISTS ix_sale_status_history_code
+    ON sale_status_history (sale_code, create_dtm);
@@ -0,0 +1,13 @@
+-- Per-line tracking of how much of a quotation has been ordered, maintained by quotation-to-sale conversion.
+ALTER TABLE quotation_item ADD COLUMN IF NOT EXISTS converted_qty numeric NOT NULL DEFAULT 0;
+ALTER TABLE quotation_item ADD COLUMN IF NOT EXISTS remaining_qty numeric NOT NULL DEFAULT 0;
+
+-- Quotations marked COMPLETED were fully ordered before line tracking existed.
+UPDATE quotation_item qi
+   SET converted_qty = qi.qty
+  FROM quotation q
+ WHERE q.id = qi.quotation_id
+   AND q.status = 'COMPLETED';
+
+UPDATE quotation_item
+   SET remaining_qty = qty - converted_qty;
@@ -0,0 +1,16 @@
+-- Frozen copies of quotations taken by EditQuotation before a new revision is started.
+CREATE TABLE IF NOT EXISTS quotation_revision (
+    id             uuid         PRIMARY KEY,
+    quotation_id   uuid         NOT NULL,
+    quotation_code varchar(50)  NOT NULL,
+    revision       numeric      NOT NULL DEFAULT 0,
+    quotation      jsonb        NOT NULL,
+    items          jsonb        NOT NULL DEFAULT '[]',
+    create_by      varchar(100) NOT NULL DEFAULT '',
+    create_dtm     timestamp    NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_quotation_revision_code
+    ON quotation_revision (quotation_code, revision);
+CREATE INDEX IF NOT EXISTS ix_quotation_code_ref
+    ON quotation (quotation_code_ref);
@@ -0,0 +1,27 @@
+-- Workflow steps per approve topic; CreateApproval copies the steps whose condition holds onto approval_item.
+CREATE TABLE IF NOT EXISTS approval_template (
+    id            uuid         PRIMARY KEY,
+    approve_topic varchar(50)  NOT NULL,
+    step_seq      int          NOT NULL,
+    step_name     varchar(100) NOT NULL DEFAULT '',
+    condition     text         NOT NULL DEFAULT '',
+    approve_mode  varchar(10)  NOT NULL DEFAULT 'ANY',
+    md_item_code  varchar(50)  NOT NULL DEFAULT '',
+    user_codes    jsonb,
+    is_active     boolean      NOT NULL DEFAULT true,
+    create_by     varchar(100) NOT NULL DEFAULT '',
+    create_date   timestamp    NOT NULL DEFAULT now(),
+    update_by     varchar(100) NOT NULL DEFAULT '',
+    update_date   timestamp    NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_approval_template_step
+    ON approval_template (approve_topic, step_seq);
+
+-- Each approver's own decision, needed for all-of steps.
+ALTER TABLE approval_item_permission ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'PENDING';
+ALTER TABLE approval_item_permission ADD COLUMN IF NOT EXISTS action_date timestamp;
+ALTER TABLE approval_item_permission ADD COLUMN IF NOT EXISTS remark text NOT NULL DEFAULT '';
+
+-- Approvals created before steps were tracked are on their only step.
+UPDATE approval SET curent_step_seq = 1 WHERE curent_step_seq IS NULL OR curent_step_seq = 0;
@@ -0,0 +1,26 @@
+-- SLA actions taken by the approval-escalation job, preloaded with the approval.
+CREATE TABLE IF NOT EXISTS approval_escalation (
+    id               uuid         PRIMARY KEY,
+    approval_id      uuid         NOT NULL,
+    approval_item_id uuid         NOT NULL,
+    step_seq         int          NOT NULL,
+    action           varchar(20)  NOT NULL,
+    pending_since    timestamp    NOT NULL,
+    sla_hours        numeric      NOT NULL,
+    user_codes       jsonb,
+    create_dtm       timestamp    NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_approval_escalation_approval
+    ON approval_escalation (approval_id);
+CREATE INDEX IF NOT EXISTS ix_approval_escalation_item
+    ON approval_escalation (approval_item_id, create_dtm);
+
+-- When a step became the current step; the SLA clock starts here.
+ALTER TABLE approval_item ADD COLUMN IF NOT EXISTS pending_dtm timestamp;
+UPDATE approval_item SET pending_dtm = create_date WHERE status = 'PENDING' AND pending_dtm IS NULL;
+
+-- One APPROVAL row per approve topic: value = SLA hours, json = action / backup approvers. Example:
+-- INSERT INTO system_config (topic_code, config_code, config_name, cond1, cond2, value, sequence, remark, json)
+-- VALUES ('APPROVAL', 'SO', 'Sale order approval SLA', '', '', '24', 1, '',
+--         '{"action":"ESCALATE","backup_approvers":["MANAGER01"],"max_escalations":1}');
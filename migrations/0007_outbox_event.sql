@@ -0,0 +1,28 @@
+-- Calls to the order and document services, written with the change that needs them and delivered by the outbox-dispatch job.
+CREATE TABLE IF NOT EXISTS outbox_event (
+    id               uuid          PRIMARY KEY,
+    event_type       varchar(50)   NOT NULL,
+    aggregate_type   varchar(50)   NOT NULL,
+    aggregate_code   varchar(100)  NOT NULL,
+    idempotency_key  varchar(200)  NOT NULL,
+    payload          jsonb         NOT NULL,
+    status           varchar(20)   NOT NULL DEFAULT 'PENDING',
+    attempts         int           NOT NULL DEFAULT 0,
+    max_attempts     int           NOT NULL DEFAULT 8,
+    next_attempt_dtm timestamp     NOT NULL DEFAULT now(),
+    last_error       text          NOT NULL DEFAULT '',
+    response         jsonb,
+    processed_dtm    timestamp,
+    create_by        varchar(50)   NOT NULL DEFAULT '',
+    create_dtm       timestamp     NOT NULL DEFAULT now(),
+    update_by        varchar(50)   NOT NULL DEFAULT '',
+    update_dtm       timestamp     NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_outbox_event_idempotency_key
+    ON outbox_event (idempotency_key);
+CREATE INDEX IF NOT EXISTS ix_outbox_event_due
+    ON outbox_event (next_attempt_dtm)
+    WHERE status IN ('PENDING', 'PROCESSING');
+CREATE INDEX IF NOT EXISTS ix_outbox_event_aggregate
+    ON outbox_event (aggregate_type, aggregate_code);
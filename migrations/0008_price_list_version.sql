@@ -0,0 +1,20 @@
+-- Future-dated price list changes, applied to price_list_group / price_list_sub_group by the price-version-activation job.
+CREATE TABLE IF NOT EXISTS price_list_version (
+    id             uuid          PRIMARY KEY,
+    target_type    varchar(20)   NOT NULL,
+    target_id      uuid          NOT NULL,
+    effective_date timestamp     NOT NULL,
+    changes        jsonb         NOT NULL,
+    status         varchar(20)   NOT NULL DEFAULT 'STAGED',
+    activated_dtm  timestamp,
+    last_error     text          NOT NULL DEFAULT '',
+    create_by      varchar(50)   NOT NULL DEFAULT '',
+    create_dtm     timestamp     NOT NULL DEFAULT now(),
+    update_by      varchar(50)   NOT NULL DEFAULT '',
+    update_dtm     timestamp     NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_price_list_version_due
+    ON price_list_version (status, effective_date);
+CREATE INDEX IF NOT EXISTS ix_price_list_version_target
+    ON price_list_version (target_id);
@@ -0,0 +1,46 @@
+-- Formulas are edited through the price API: the live row in price_list_formulas carries the current version and every
+-- version, including the current one, is kept unchanged in price_list_formula_version.
+ALTER TABLE price_list_formulas
+    ADD COLUMN IF NOT EXISTS version    int         NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS status     varchar(20) NOT NULL DEFAULT 'ACTIVE',
+    ADD COLUMN IF NOT EXISTS create_by  varchar(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS update_by  varchar(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS update_dtm timestamp;
+
+CREATE TABLE IF NOT EXISTS price_list_formula_version (
+    id           uuid          PRIMARY KEY,
+    formula_code varchar(100)  NOT NULL,
+    version      int           NOT NULL,
+    name         text          NOT NULL,
+    uom          varchar(20)   NOT NULL,
+    formula_type varchar(50)   NOT NULL,
+    expression   text          NOT NULL DEFAULT '',
+    params       jsonb         NOT NULL DEFAULT '{}',
+    rounding     int           NOT NULL DEFAULT 0,
+    remark       text          NOT NULL DEFAULT '',
+    create_by    varchar(50)   NOT NULL DEFAULT '',
+    create_dtm   timestamp     NOT NULL DEFAULT now(),
+    CONSTRAINT uq_price_list_formula_version UNIQUE (formula_code, version)
+);
+
+-- Formulas seeded before versioning start as version 1
+INSERT INTO price_list_formula_version (id, formula_code, version, name, uom, formula_type, expression, params, rounding, create_dtm)
+SELECT gen_random_uuid(), formula_code, version, name, uom, formula_type, COALESCE(expression, ''), COALESCE(params, '{}'), rounding, create_dtm
+FROM price_list_formulas
+ON CONFLICT (formula_code, version) DO NOTHING;
+
+-- The formula version each calculated total was computed with
+ALTER TABLE price_list_sub_group
+    ADD COLUMN IF NOT EXISTS unit_formula_code      varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS unit_formula_version   int          NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS weight_formula_code    varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS weight_formula_version int          NOT NULL DEFAULT 0;
+
+ALTER TABLE price_list_sub_group_history
+    ADD COLUMN IF NOT EXISTS unit_formula_code      varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS unit_formula_version   int          NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS weight_formula_code    varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS weight_formula_version int          NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS ix_price_list_subgroup_formulas_map_formula
+    ON price_list_subgroup_formulas_map (price_list_formulas_code);
@@ -0,0 +1,9 @@
+-- Extras rule engine: expression conditions, priorities and effective dates per extra, stacking per group.
+ALTER TABLE price_list_group
+    ADD COLUMN IF NOT EXISTS extra_stacking varchar(20) NOT NULL DEFAULT 'LAST';
+
+ALTER TABLE price_list_group_extra
+    ADD COLUMN IF NOT EXISTS condition_expr text      NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS priority       int       NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS effective_date timestamp,
+    ADD COLUMN IF NOT EXISTS expiry_date    timestamp;
@@ -0,0 +1,43 @@
+-- Customer and customer group contract pricing, resolved by GetPriceList and checked by VerifyApprove.
+CREATE TABLE IF NOT EXISTS price_agreement (
+    id                   uuid          PRIMARY KEY,
+    agreement_code       varchar(50)   NOT NULL UNIQUE,
+    company_code         varchar(50)   NOT NULL,
+    site_code            varchar(50)   NOT NULL DEFAULT '',
+    customer_code        varchar(50)   NOT NULL DEFAULT '',
+    customer_group_code  varchar(50)   NOT NULL DEFAULT '',
+    customer_group_value varchar(100)  NOT NULL DEFAULT '',
+    valid_from           timestamp     NOT NULL,
+    valid_to             timestamp,
+    status               varchar(20)   NOT NULL DEFAULT 'ACTIVE',
+    remark               text          NOT NULL DEFAULT '',
+    create_by            varchar(50)   NOT NULL DEFAULT '',
+    create_dtm           timestamp     NOT NULL DEFAULT now(),
+    update_by            varchar(50)   NOT NULL DEFAULT '',
+    update_dtm           timestamp     NOT NULL DEFAULT now(),
+    CONSTRAINT ck_price_agreement_party CHECK (customer_code <> '' OR customer_group_code <> '')
+);
+
+CREATE INDEX IF NOT EXISTS ix_price_agreement_customer
+    ON price_agreement (company_code, customer_code, status);
+CREATE INDEX IF NOT EXISTS ix_price_agreement_customer_group
+    ON price_agreement (company_code, customer_group_code, customer_group_value, status);
+
+CREATE TABLE IF NOT EXISTS price_agreement_line (
+    id            uuid              PRIMARY KEY,
+    agreement_id  uuid              NOT NULL REFERENCES price_agreement (id) ON DELETE CASCADE,
+    group_code    varchar(50)       NOT NULL DEFAULT '',
+    subgroup_code varchar(100)      NOT NULL DEFAULT '',
+    product_code  varchar(50)       NOT NULL DEFAULT '',
+    pricing_type  varchar(20)       NOT NULL,
+    price_unit    double precision  NOT NULL DEFAULT 0,
+    price_weight  double precision  NOT NULL DEFAULT 0,
+    percent       double precision  NOT NULL DEFAULT 0,
+    amount        double precision  NOT NULL DEFAULT 0,
+    min_qty       double precision  NOT NULL DEFAULT 0,
+    min_weight    double precision  NOT NULL DEFAULT 0,
+    seq           int               NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS ix_price_agreement_line_agreement
+    ON price_agreement_line (agreement_id);
@@ -0,0 +1,23 @@
+-- Quantity and weight break tables of price list groups and sub groups, used by ComparePrice and
+-- GetCalculatedPriceListSubGroup.
+CREATE TABLE IF NOT EXISTS price_list_tier (
+    id               uuid              PRIMARY KEY,
+    company_code     varchar(50)       NOT NULL,
+    group_code       varchar(50)       NOT NULL,
+    subgroup_code    varchar(100)      NOT NULL DEFAULT '',
+    basis            varchar(10)       NOT NULL,
+    min_value        double precision  NOT NULL DEFAULT 0,
+    max_value        double precision,
+    discount_percent double precision  NOT NULL DEFAULT 0,
+    discount_unit    double precision  NOT NULL DEFAULT 0,
+    discount_weight  double precision  NOT NULL DEFAULT 0,
+    create_by        varchar(50)       NOT NULL DEFAULT '',
+    create_dtm       timestamp         NOT NULL DEFAULT now(),
+    update_by        varchar(50)       NOT NULL DEFAULT '',
+    update_dtm       timestamp         NOT NULL DEFAULT now(),
+    CONSTRAINT ck_price_list_tier_basis CHECK (basis IN ('QTY', 'WEIGHT')),
+    CONSTRAINT ck_price_list_tier_range CHECK (max_value IS NULL OR max_value > min_value)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_price_list_tier_break
+    ON price_list_tier (company_code, group_code, subgroup_code, min_value);
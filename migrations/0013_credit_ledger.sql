@@ -0,0 +1,55 @@
+-- Append-only customer credit ledger. Every change to a customer's credit, temporary extras, deposits, approved sales,
+-- payments and CN/DN is written as a delta entry; credit_balance keeps the running totals so GetCreditCurrent does not
+-- recompute them, and the credit-ledger-reconcile job records in credit_ledger_drift where the two disagree.
+CREATE TABLE IF NOT EXISTS credit_ledger (
+    id            bigserial         PRIMARY KEY,
+    customer_code varchar(50)       NOT NULL,
+    bucket        varchar(20)       NOT NULL,
+    source_type   varchar(20)       NOT NULL,
+    source_ref    varchar(100)      NOT NULL,
+    amount        double precision  NOT NULL DEFAULT 0,
+    is_active     boolean,
+    effective_dtm timestamp,
+    expire_dtm    timestamp,
+    entry_dtm     timestamp         NOT NULL DEFAULT now(),
+    create_by     varchar(50)       NOT NULL DEFAULT '',
+    CONSTRAINT ck_credit_ledger_bucket CHECK (bucket IN ('CREDIT', 'EXTRA', 'DEPOSIT', 'USED'))
+);
+
+CREATE INDEX IF NOT EXISTS ix_credit_ledger_customer
+    ON credit_ledger (customer_code, entry_dtm);
+CREATE INDEX IF NOT EXISTS ix_credit_ledger_source
+    ON credit_ledger (source_type, source_ref);
+
+CREATE TABLE IF NOT EXISTS credit_balance (
+    customer_code  varchar(50)       PRIMARY KEY,
+    is_active      boolean           NOT NULL DEFAULT false,
+    credit         double precision  NOT NULL DEFAULT 0,
+    remain_deposit double precision  NOT NULL DEFAULT 0,
+    used           double precision  NOT NULL DEFAULT 0,
+    last_entry_id  bigint            NOT NULL DEFAULT 0,
+    update_dtm     timestamp         NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS credit_ledger_drift (
+    id                      uuid              PRIMARY KEY,
+    run_id                  uuid              NOT NULL,
+    customer_code           varchar(50)       NOT NULL,
+    ledger_is_active        boolean           NOT NULL,
+    ledger_credit           double precision  NOT NULL DEFAULT 0,
+    ledger_extra            double precision  NOT NULL DEFAULT 0,
+    ledger_remain_deposit   double precision  NOT NULL DEFAULT 0,
+    ledger_used             double precision  NOT NULL DEFAULT 0,
+    computed_is_active      boolean           NOT NULL,
+    computed_credit         double precision  NOT NULL DEFAULT 0,
+    computed_extra          double precision  NOT NULL DEFAULT 0,
+    computed_remain_deposit double precision  NOT NULL DEFAULT 0,
+    computed_used           double precision  NOT NULL DEFAULT 0,
+    difference              double precision  NOT NULL DEFAULT 0,
+    create_dtm              timestamp         NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ix_credit_ledger_drift_run
+    ON credit_ledger_drift (run_id);
+CREATE INDEX IF NOT EXISTS ix_credit_ledger_drift_customer
+    ON credit_ledger_drift (customer_code, create_dtm);
@@ -0,0 +1,29 @@
+-- Credit a sale reserves from the moment it is created or approved, so sales checked at the same time cannot together
+-- exceed the customer's limit. A hold is converted as the sale is invoiced and released when the sale is canceled,
+-- closes short of its amount or the hold expires.
+CREATE TABLE IF NOT EXISTS credit_hold (
+    id               uuid              PRIMARY KEY,
+    customer_code    varchar(50)       NOT NULL,
+    sale_code        varchar(50)       NOT NULL,
+    amount           double precision  NOT NULL DEFAULT 0,
+    converted_amount double precision  NOT NULL DEFAULT 0,
+    released_amount  double precision  NOT NULL DEFAULT 0,
+    status           varchar(20)       NOT NULL DEFAULT 'OPEN',
+    release_reason   varchar(20)       NOT NULL DEFAULT '',
+    expire_dtm       timestamp         NOT NULL,
+    settle_dtm       timestamp,
+    create_by        varchar(50)       NOT NULL DEFAULT '',
+    create_dtm       timestamp         NOT NULL DEFAULT now(),
+    update_by        varchar(50)       NOT NULL DEFAULT '',
+    update_dtm       timestamp         NOT NULL DEFAULT now(),
+    CONSTRAINT ck_credit_hold_status CHECK (status IN ('OPEN', 'CONVERTED', 'RELEASED'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_credit_hold_sale
+    ON credit_hold (sale_code);
+CREATE INDEX IF NOT EXISTS ix_credit_hold_open
+    ON credit_hold (customer_code)
+    WHERE status = 'OPEN';
+CREATE INDEX IF NOT EXISTS ix_credit_hold_expire
+    ON credit_hold (expire_dtm)
+    WHERE status = 'OPEN';
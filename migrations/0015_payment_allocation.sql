@@ -0,0 +1,19 @@
+-- Payments are allocated to open AR/DN invoices by the allocation engine; what a payment does not allocate stays on
+-- it as unapplied customer credit, so the allocations of a payment never add up to more than its amount.
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS reference varchar(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS ix_payment_invoice_invoice
+    ON payment_invoice (invoice_code);
+CREATE INDEX IF NOT EXISTS ix_payment_invoice_payment
+    ON payment_invoice (payment_id);
+CREATE INDEX IF NOT EXISTS ix_payment_customer
+    ON payment (customer_code);
+
+-- Payment codes previously fell back to a uuid; give them a running config next to RUNNING_AR.
+INSERT INTO system_config (topic_code, config_code, config_name, cond1, cond2, value, sequence, remark, json)
+SELECT topic_code, 'RUNNING_PM', 'Running Payment', '', '', '', sequence, '',
+       '{"prefix":"PM","running_digit":4,"current_running":0,"reset_policy":"MONTHLY","year_format":"BE_YY"}'
+FROM system_config
+WHERE config_code = 'RUNNING_AR'
+  AND NOT EXISTS (SELECT 1 FROM system_config WHERE config_code = 'RUNNING_PM')
+LIMIT 1;
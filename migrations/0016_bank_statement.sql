@@ -0,0 +1,57 @@
+-- Imported bank statements. Each incoming credit is a line that is proposed matches to open AR/DN invoices; a
+-- confirmed line becomes a payment, and lines without a confident match wait in the reconciliation queue (UNMATCHED).
+CREATE TABLE IF NOT EXISTS bank_statement (
+    id             uuid         PRIMARY KEY,
+    file_name      varchar(255) NOT NULL DEFAULT '',
+    format         varchar(20)  NOT NULL,
+    account_no     varchar(100) NOT NULL DEFAULT '',
+    statement_ref  varchar(100) NOT NULL DEFAULT '',
+    statement_date date,
+    line_count     integer      NOT NULL DEFAULT 0,
+    create_by      varchar(50)  NOT NULL DEFAULT '',
+    create_dtm     timestamp    NOT NULL DEFAULT now(),
+    CONSTRAINT ck_bank_statement_format CHECK (format IN ('CSV', 'CAMT053'))
+);
+
+CREATE TABLE IF NOT EXISTS bank_statement_line (
+    id                   uuid              PRIMARY KEY,
+    statement_id         uuid              NOT NULL REFERENCES bank_statement (id),
+    line_no              integer           NOT NULL,
+    booking_date         date              NOT NULL,
+    value_date           date,
+    amount               double precision  NOT NULL,
+    currency             varchar(3)        NOT NULL DEFAULT '',
+    reference            text              NOT NULL DEFAULT '',
+    counterparty_name    varchar(255)      NOT NULL DEFAULT '',
+    counterparty_account varchar(100)      NOT NULL DEFAULT '',
+    bank_ref             varchar(100)      NOT NULL DEFAULT '',
+    line_hash            varchar(64)       NOT NULL,
+    status               varchar(20)       NOT NULL DEFAULT 'UNMATCHED',
+    proposals            jsonb             NOT NULL DEFAULT '[]',
+    customer_code        varchar(50)       NOT NULL DEFAULT '',
+    payment_code         varchar(50)       NOT NULL DEFAULT '',
+    remark               text              NOT NULL DEFAULT '',
+    create_by            varchar(50)       NOT NULL DEFAULT '',
+    create_dtm           timestamp         NOT NULL DEFAULT now(),
+    update_by            varchar(50)       NOT NULL DEFAULT '',
+    update_dtm           timestamp         NOT NULL DEFAULT now(),
+    CONSTRAINT ck_bank_statement_line_status CHECK (status IN ('UNMATCHED', 'PROPOSED', 'MATCHED', 'IGNORED'))
+);
+
+-- The same transaction imported twice, e.g. in overlapping statements, is skipped.
+CREATE UNIQUE INDEX IF NOT EXISTS ux_bank_statement_line_hash
+    ON bank_statement_line (line_hash);
+CREATE INDEX IF NOT EXISTS ix_bank_statement_line_statement
+    ON bank_statement_line (statement_id);
+CREATE INDEX IF NOT EXISTS ix_bank_statement_line_open
+    ON bank_statement_line (status)
+    WHERE status IN ('UNMATCHED', 'PROPOSED');
+
+-- Accounts customers have paid from, learned as statement lines are confirmed.
+CREATE TABLE IF NOT EXISTS customer_bank_account (
+    account_no    varchar(100) NOT NULL,
+    customer_code varchar(50)  NOT NULL,
+    create_by     varchar(50)  NOT NULL DEFAULT '',
+    create_dtm    timestamp    NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_no, customer_code)
+);
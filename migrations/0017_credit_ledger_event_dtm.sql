@@ -0,0 +1,12 @@
+-- Credit ledger entries are posted by the business events that change a customer's credit (credit grants and extras,
+-- deposits, sale approvals, invoices, payments, CN/DN) and carry when the event took effect, which GetCreditCurrent
+-- as_of and GetCreditLedger read. Entries written before carry the time they were written.
+ALTER TABLE credit_ledger ADD COLUMN IF NOT EXISTS event_dtm timestamp;
+
+UPDATE credit_ledger SET event_dtm = entry_dtm WHERE event_dtm IS NULL;
+
+ALTER TABLE credit_ledger ALTER COLUMN event_dtm SET NOT NULL;
+ALTER TABLE credit_ledger ALTER COLUMN event_dtm SET DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS ix_credit_ledger_customer_event
+    ON credit_ledger (customer_code, event_dtm);